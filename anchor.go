@@ -3,21 +3,202 @@ package diffx
 // Anchor elimination post-processing.
 //
 // The histogram algorithm handles stopword filtering during anchor selection,
-// so aggressive post-processing is no longer needed. This file provides the
-// eliminateWeakAnchors function which now simply merges adjacent operations.
+// so aggressive post-processing is not needed there. The Myers path, however,
+// still matches lone high-frequency elements such as "the" between two
+// unrelated changes, which fragments the output. Weak anchor elimination
+// converts such coincidental matches back into changes. It is opt-in because
+// it trades minimality for grouping.
 
-// eliminateWeakAnchors merges adjacent operations of the same type.
-//
-// Previously this converted stopwords at Equal boundaries to Delete+Insert pairs,
-// but that created confusing self-replacement patterns like [-for-] {+for+}.
-// The histogram algorithm now filters stopwords from being anchors, so this
-// post-processing just ensures operations are properly merged.
-func eliminateWeakAnchors(ops []DiffOp, a, b []Element) []DiffOp {
+// anchorOptions configures anchor elimination.
+type anchorOptions struct {
+	// removeWeak enables converting weak anchors into Delete+Insert pairs.
+	// When false, anchor elimination only merges adjacent operations.
+	removeWeak bool
+
+	// maxAnchorLen is the longest Equal region that can be considered weak.
+	maxAnchorLen int
+
+	// freqThreshold is the combined number of occurrences in both sequences
+	// at which an element counts as high-frequency.
+	freqThreshold int
+
+	// validateContext enables demoting anchors whose surrounding context
+	// does not match on either side.
+	validateContext bool
+
+	// contextWindow is the number of elements examined on each side of an
+	// anchor during context validation. Anchors at least this long are
+	// considered self-validating.
+	contextWindow int
+}
+
+func defaultAnchorOptions() *anchorOptions {
+	return &anchorOptions{
+		removeWeak:      false,
+		maxAnchorLen:    2,
+		freqThreshold:   4,
+		validateContext: false,
+		contextWindow:   3,
+	}
+}
+
+// eliminateWeakAnchors merges adjacent operations of the same type and,
+// when enabled in opts, removes weak anchors: short Equal regions
+// sandwiched between changes that consist entirely of high-frequency
+// elements or stopwords. Weak anchors are folded into the surrounding
+// changes so the result reads as one coherent replacement instead of
+// several fragments. A nil opts uses the defaults.
+func eliminateWeakAnchors(ops []DiffOp, a, b []Element, opts *anchorOptions) []DiffOp {
 	if len(ops) < 2 {
 		return ops
 	}
+	if opts == nil {
+		opts = defaultAnchorOptions()
+	}
 
-	return mergeAdjacentOps(ops)
+	ops = mergeAdjacentOps(ops)
+	if opts.removeWeak {
+		ops = removeWeakAnchors(ops, a, b, opts)
+	}
+	if opts.validateContext {
+		ops = validateAnchorContext(ops, a, b, opts)
+	}
+	return ops
+}
+
+// removeWeakAnchors folds weak anchors sandwiched between changes into the
+// surrounding change regions.
+func removeWeakAnchors(ops []DiffOp, a, b []Element, opts *anchorOptions) []DiffOp {
+	if len(ops) < 3 {
+		return ops
+	}
+
+	freq := elementFrequencies(a, b)
+
+	result := make([]DiffOp, 0, len(ops)+2)
+	changed := false
+	for i, op := range ops {
+		sandwiched := i > 0 && i < len(ops)-1 &&
+			ops[i-1].Type != Equal && ops[i+1].Type != Equal
+		if op.Type != Equal || !sandwiched || !isWeakAnchor(op, a, freq, opts) {
+			result = append(result, op)
+			continue
+		}
+
+		result = append(result, demoteEqual(op)...)
+		changed = true
+	}
+
+	if !changed {
+		return ops
+	}
+	return coalesceChanges(result)
+}
+
+// validateAnchorContext demotes short Equal regions between changes whose
+// context matches on neither side. A genuine anchor usually has related
+// content nearby in both sequences; an anchor surrounded by entirely
+// different elements is more likely a coincidental match.
+func validateAnchorContext(ops []DiffOp, a, b []Element, opts *anchorOptions) []DiffOp {
+	if len(ops) < 3 || opts.contextWindow <= 0 {
+		return ops
+	}
+
+	result := make([]DiffOp, 0, len(ops)+2)
+	changed := false
+	for i, op := range ops {
+		sandwiched := i > 0 && i < len(ops)-1 &&
+			ops[i-1].Type != Equal && ops[i+1].Type != Equal
+		if op.Type != Equal || !sandwiched || op.AEnd-op.AStart >= opts.contextWindow {
+			result = append(result, op)
+			continue
+		}
+
+		before := hasMatchingContext(a, b, op.AStart, op.BStart, opts.contextWindow, -1)
+		after := hasMatchingContext(a, b, op.AEnd, op.BEnd, opts.contextWindow, 1)
+		if before || after {
+			result = append(result, op)
+			continue
+		}
+
+		result = append(result, demoteEqual(op)...)
+		changed = true
+	}
+
+	if !changed {
+		return ops
+	}
+	return coalesceChanges(result)
+}
+
+// hasMatchingContext reports whether the window elements on one side of
+// the positions aPos and bPos share at least one element. With dir < 0 the
+// elements before the positions are examined, otherwise the elements at and
+// after them. Reaching the edge of both sequences counts as a match.
+func hasMatchingContext(a, b []Element, aPos, bPos, window, dir int) bool {
+	var aCtx, bCtx []Element
+	if dir < 0 {
+		aCtx = a[max(0, aPos-window):aPos]
+		bCtx = b[max(0, bPos-window):bPos]
+	} else {
+		aCtx = a[aPos:min(len(a), aPos+window)]
+		bCtx = b[bPos:min(len(b), bPos+window)]
+	}
+
+	if len(aCtx) == 0 && len(bCtx) == 0 {
+		return true
+	}
+
+	for _, x := range aCtx {
+		for _, y := range bCtx {
+			if x.Equal(y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// demoteEqual converts an Equal region into the equivalent Delete+Insert pair.
+func demoteEqual(op DiffOp) []DiffOp {
+	return []DiffOp{
+		{Type: Delete, AStart: op.AStart, AEnd: op.AEnd, BStart: op.BStart, BEnd: op.BStart},
+		{Type: Insert, AStart: op.AEnd, AEnd: op.AEnd, BStart: op.BStart, BEnd: op.BEnd},
+	}
+}
+
+// elementFrequencies counts occurrences of each element hash across both
+// sequences.
+func elementFrequencies(a, b []Element) map[uint64]int {
+	freq := make(map[uint64]int, len(a)+len(b))
+	for _, e := range a {
+		freq[e.Hash()]++
+	}
+	for _, e := range b {
+		freq[e.Hash()]++
+	}
+	return freq
+}
+
+// isWeakAnchor reports whether the Equal region op is a weak anchor: no
+// longer than opts.maxAnchorLen and made up only of stopwords, blank
+// elements, or elements at or above the frequency threshold.
+func isWeakAnchor(op DiffOp, a []Element, freq map[uint64]int, opts *anchorOptions) bool {
+	n := op.AEnd - op.AStart
+	if n == 0 || n > opts.maxAnchorLen {
+		return false
+	}
+
+	for _, e := range a[op.AStart:op.AEnd] {
+		if isStopword(e) || isBlank(e) {
+			continue
+		}
+		if freq[e.Hash()] >= opts.freqThreshold {
+			continue
+		}
+		return false
+	}
+	return true
 }
 
 // WithAnchorElimination enables or disables anchor elimination post-processing.
@@ -27,3 +208,65 @@ func WithAnchorElimination(enabled bool) Option {
 		o.anchorElimination = enabled
 	}
 }
+
+// WithWeakAnchorElimination enables removal of weak anchors: short Equal
+// regions between two changes that consist only of stopwords or
+// high-frequency elements, such as a lone "the". They are folded into the
+// surrounding changes to produce fewer, more coherent change regions.
+// Requires anchor elimination, which is enabled by default.
+// Default: false.
+func WithWeakAnchorElimination(enabled bool) Option {
+	return func(o *options) {
+		o.anchorOpts.removeWeak = enabled
+	}
+}
+
+// WithContextValidation enables a post-pass that demotes short Equal regions
+// between changes when the elements around them match on neither side,
+// converting them to Delete+Insert for more coherent change grouping.
+// Requires anchor elimination, which is enabled by default.
+// Default: false.
+func WithContextValidation(enabled bool) Option {
+	return func(o *options) {
+		o.anchorOpts.validateContext = enabled
+	}
+}
+
+// WithWeakAnchorMaxLen sets the longest Equal region, in elements, that weak
+// anchor elimination may remove. Values less than 1 restore the default.
+// Default: 2.
+func WithWeakAnchorMaxLen(n int) Option {
+	if n < 1 {
+		n = defaultAnchorOptions().maxAnchorLen
+	}
+	return func(o *options) {
+		o.anchorOpts.maxAnchorLen = n
+	}
+}
+
+// WithWeakAnchorFrequency sets how many times an element must occur across
+// both sequences before weak anchor elimination treats it as high-frequency.
+// Stopwords and blank elements are always treated as weak.
+// Values less than 1 restore the default.
+// Default: 4.
+func WithWeakAnchorFrequency(n int) Option {
+	if n < 1 {
+		n = defaultAnchorOptions().freqThreshold
+	}
+	return func(o *options) {
+		o.anchorOpts.freqThreshold = n
+	}
+}
+
+// WithContextWindow sets how many elements on each side of an anchor are
+// examined by context validation. Anchors at least this long are always kept.
+// Values less than 1 restore the default.
+// Default: 3.
+func WithContextWindow(n int) Option {
+	if n < 1 {
+		n = defaultAnchorOptions().contextWindow
+	}
+	return func(o *options) {
+		o.anchorOpts.contextWindow = n
+	}
+}
@@ -34,7 +34,7 @@ func TestEliminateWeakAnchors_Empty(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := eliminateWeakAnchors(tt.ops, a, b)
+			got := eliminateWeakAnchors(tt.ops, a, b, nil)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("eliminateWeakAnchors() = %v, want %v", got, tt.want)
 			}
@@ -53,7 +53,7 @@ func TestEliminateWeakAnchors_MergesAdjacent(t *testing.T) {
 	a := toElements([]string{"a", "b", "c"})
 	b := []Element{}
 
-	got := eliminateWeakAnchors(ops, a, b)
+	got := eliminateWeakAnchors(ops, a, b, nil)
 
 	if len(got) != 1 {
 		t.Fatalf("expected 1 merged op, got %d: %v", len(got), got)
@@ -75,7 +75,7 @@ func TestEliminateWeakAnchors_PreservesDifferentTypes(t *testing.T) {
 	a := toElements([]string{"a", "b"})
 	b := toElements([]string{"x", "b"})
 
-	got := eliminateWeakAnchors(ops, a, b)
+	got := eliminateWeakAnchors(ops, a, b, nil)
 
 	if len(got) != 3 {
 		t.Errorf("expected 3 ops preserved, got %d: %v", len(got), got)
@@ -95,7 +95,7 @@ func TestEliminateWeakAnchors_MergesNonAdjacent(t *testing.T) {
 	a := toElements([]string{"a", "b", "c", "d"})
 	b := toElements([]string{"a", "x", "d"})
 
-	got := eliminateWeakAnchors(ops, a, b)
+	got := eliminateWeakAnchors(ops, a, b, nil)
 
 	// Should have: Equal, merged Delete, Insert, Equal = 4 ops
 	if len(got) != 4 {
@@ -126,3 +126,236 @@ func TestWithAnchorElimination(t *testing.T) {
 		t.Errorf("without anchor elimination: got %v, want %v", result2, b)
 	}
 }
+
+func TestIsWeakAnchor(t *testing.T) {
+	a := toElements([]string{"the", "fox", "x", "x", "x", "x", "fox", "jumps", "over"})
+	freq := elementFrequencies(a, nil)
+	opts := defaultAnchorOptions()
+
+	tests := []struct {
+		name string
+		op   DiffOp
+		want bool
+	}{
+		{"stopword", DiffOp{Type: Equal, AStart: 0, AEnd: 1}, true},
+		{"rare word", DiffOp{Type: Equal, AStart: 1, AEnd: 2}, false},
+		{"high frequency", DiffOp{Type: Equal, AStart: 2, AEnd: 4}, true},
+		{"too long", DiffOp{Type: Equal, AStart: 2, AEnd: 5}, false},
+		{"mixed", DiffOp{Type: Equal, AStart: 5, AEnd: 7}, false},
+		{"empty", DiffOp{Type: Equal, AStart: 3, AEnd: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWeakAnchor(tt.op, a, freq, opts); got != tt.want {
+				t.Errorf("isWeakAnchor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEliminateWeakAnchors_RemovesSandwichedStopword(t *testing.T) {
+	// quick the brown -> slow the red
+	a := toElements([]string{"quick", "the", "brown"})
+	b := toElements([]string{"slow", "the", "red"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	// Disabled by default
+	if got := eliminateWeakAnchors(ops, a, b, nil); len(got) != 5 {
+		t.Errorf("expected weak anchor kept by default, got %v", got)
+	}
+
+	opts := defaultAnchorOptions()
+	opts.removeWeak = true
+	got := eliminateWeakAnchors(ops, a, b, opts)
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 0, BEnd: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("eliminateWeakAnchors() = %v, want %v", got, want)
+	}
+}
+
+func TestEliminateWeakAnchors_KeepsEdgeAnchors(t *testing.T) {
+	// "the" at the start is not between two changes
+	a := toElements([]string{"the", "cat"})
+	b := toElements([]string{"the", "dog"})
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+	}
+
+	opts := defaultAnchorOptions()
+	opts.removeWeak = true
+	got := eliminateWeakAnchors(ops, a, b, opts)
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("expected ops unchanged, got %v", got)
+	}
+}
+
+func TestWithWeakAnchorElimination(t *testing.T) {
+	a := []string{"quick", "the", "brown", "fox"}
+	b := []string{"slow", "the", "red", "fox"}
+
+	ops := Diff(a, b, WithPreprocessing(false), WithWeakAnchorElimination(true))
+	if got := applyDiff(a, b, ops); !reflect.DeepEqual(got, b) {
+		t.Fatalf("applying diff = %v, want %v", got, b)
+	}
+
+	for _, op := range ops {
+		if op.Type == Equal && a[op.AStart] == "the" {
+			t.Errorf("expected \"the\" to be eliminated as an anchor, got %v", ops)
+		}
+	}
+}
+
+func TestHasMatchingContext(t *testing.T) {
+	a := toElements([]string{"p", "q", "r", "ANCHOR", "s", "t"})
+	b := toElements([]string{"x", "q", "y", "ANCHOR", "z", "w"})
+
+	if !hasMatchingContext(a, b, 3, 3, 3, -1) {
+		t.Error("expected shared \"q\" before the anchor to match")
+	}
+	if hasMatchingContext(a, b, 3, 3, 1, -1) {
+		t.Error("expected no match with a window of 1")
+	}
+	if hasMatchingContext(a, b, 4, 4, 3, 1) {
+		t.Error("expected no match after the anchor")
+	}
+	if !hasMatchingContext(a, b, 0, 0, 3, -1) {
+		t.Error("expected start of both sequences to count as matching")
+	}
+	if !hasMatchingContext(a, b, 6, 6, 3, 1) {
+		t.Error("expected end of both sequences to count as matching")
+	}
+}
+
+func TestValidateAnchorContext(t *testing.T) {
+	// "fox" is matched, but nothing around it matches on either side
+	a := toElements([]string{"a1", "a2", "fox", "a3", "a4"})
+	b := toElements([]string{"b1", "b2", "fox", "b3", "b4"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+		{Type: Delete, AStart: 3, AEnd: 5, BStart: 3, BEnd: 3},
+		{Type: Insert, AStart: 5, AEnd: 5, BStart: 3, BEnd: 5},
+	}
+
+	opts := defaultAnchorOptions()
+	got := validateAnchorContext(ops, a, b, opts)
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 5, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 5, AEnd: 5, BStart: 0, BEnd: 5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("validateAnchorContext() = %v, want %v", got, want)
+	}
+
+	// A long enough anchor validates itself
+	opts.contextWindow = 1
+	if got := validateAnchorContext(ops, a, b, opts); !reflect.DeepEqual(got, ops) {
+		t.Errorf("expected anchor kept with window 1, got %v", got)
+	}
+}
+
+func TestValidateAnchorContext_KeepsSupportedAnchor(t *testing.T) {
+	// "a2" appears before the anchor in both sequences
+	a := toElements([]string{"a1", "a2", "x", "fox", "a3"})
+	b := toElements([]string{"b1", "a2", "y", "fox", "b3"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 3, BEnd: 4},
+		{Type: Delete, AStart: 4, AEnd: 5, BStart: 4, BEnd: 4},
+		{Type: Insert, AStart: 5, AEnd: 5, BStart: 4, BEnd: 5},
+	}
+
+	got := validateAnchorContext(ops, a, b, defaultAnchorOptions())
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("expected ops unchanged, got %v", got)
+	}
+}
+
+func TestWithContextValidation(t *testing.T) {
+	a := []string{"one", "two", "fox", "three", "four"}
+	b := []string{"uno", "dos", "fox", "tres", "cuatro"}
+
+	ops := Diff(a, b, WithPreprocessing(false), WithContextValidation(true))
+	if got := applyDiff(a, b, ops); !reflect.DeepEqual(got, b) {
+		t.Fatalf("applying diff = %v, want %v", got, b)
+	}
+	for _, op := range ops {
+		if op.Type == Equal {
+			t.Errorf("expected unsupported anchor to be demoted, got %v", ops)
+		}
+	}
+}
+
+func TestAnchorThresholdOptions(t *testing.T) {
+	o := defaultOptions()
+	WithWeakAnchorMaxLen(5)(o)
+	WithWeakAnchorFrequency(10)(o)
+	WithContextWindow(7)(o)
+
+	if o.anchorOpts.maxAnchorLen != 5 {
+		t.Errorf("maxAnchorLen = %d, want 5", o.anchorOpts.maxAnchorLen)
+	}
+	if o.anchorOpts.freqThreshold != 10 {
+		t.Errorf("freqThreshold = %d, want 10", o.anchorOpts.freqThreshold)
+	}
+	if o.anchorOpts.contextWindow != 7 {
+		t.Errorf("contextWindow = %d, want 7", o.anchorOpts.contextWindow)
+	}
+
+	// Non-positive values restore the defaults
+	WithWeakAnchorMaxLen(0)(o)
+	WithWeakAnchorFrequency(-1)(o)
+	WithContextWindow(0)(o)
+	if !reflect.DeepEqual(o.anchorOpts, defaultAnchorOptions()) {
+		t.Errorf("expected defaults restored, got %+v", o.anchorOpts)
+	}
+}
+
+func TestAnchorOptions_Independent(t *testing.T) {
+	// Options applied to one call must not leak into the next
+	Diff([]string{"a"}, []string{"b"}, WithWeakAnchorMaxLen(9))
+	if got := defaultOptions().anchorOpts.maxAnchorLen; got != 2 {
+		t.Errorf("default maxAnchorLen = %d, want 2", got)
+	}
+}
+
+func TestWithWeakAnchorMaxLen(t *testing.T) {
+	// "the of" is a two-element anchor made only of stopwords
+	a := []string{"jumps", "the", "of", "lazy"}
+	b := []string{"leaps", "the", "of", "sleepy"}
+
+	ops := Diff(a, b, WithPreprocessing(false), WithWeakAnchorElimination(true), WithWeakAnchorMaxLen(1))
+	kept := false
+	for _, op := range ops {
+		if op.Type == Equal {
+			kept = true
+		}
+	}
+	if !kept {
+		t.Errorf("expected two-element anchor kept with max length 1, got %v", ops)
+	}
+
+	ops = Diff(a, b, WithPreprocessing(false), WithWeakAnchorElimination(true), WithWeakAnchorMaxLen(2))
+	for _, op := range ops {
+		if op.Type == Equal {
+			t.Errorf("expected anchor removed with max length 2, got %v", ops)
+		}
+	}
+}
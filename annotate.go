@@ -0,0 +1,216 @@
+package diffx
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Explainable annotations.
+//
+// Stats and traces describe the algorithm's decisions in its own terms.
+// Annotations translate the decisions that visibly shaped the result into
+// short sentences attached to the ops they explain, so that documentation
+// reviewers can see why a diff aligned the way it did without learning how
+// it was computed.
+
+// Annotation is a human-readable reason for the shape of one op.
+type Annotation struct {
+	Op     int    // index of the annotated op in the edit script
+	Reason string // for example "anchored on rare element \"Fenestra\" (1 occurrence)"
+}
+
+// annotationTextLimit is the number of runes of an element shown in a
+// reason before it is truncated.
+const annotationTextLimit = 40
+
+// WithAnnotations sets *dst to reasons for the ops of the result, sorted by
+// op: matches taken from the common prefix or suffix, anchors chosen by
+// histogram diff, splits chosen by a heuristic instead of the optimal
+// search, and boundaries moved by postprocessing. Ops with nothing notable
+// to report have no annotation; an op can have several.
+// Default: nil (no annotations).
+func WithAnnotations(dst *[]Annotation) Option {
+	return func(o *options) {
+		o.annotations = dst
+	}
+}
+
+// annotate runs diff on a and b with opts, recording the trace events that
+// explain the result, and stores the annotations in o.annotations. filtered
+// reports whether diff runs the Myers algorithm on the preprocessed
+// sequences, whose indices its trim and middle-snake events use.
+func annotate(a, b []Element, opts []Option, o *options, filtered bool,
+	diff func(a, b []Element, opts ...Option) []DiffOp) []DiffOp {
+	dst := o.annotations
+
+	var mapping *indexMapping
+	if filtered {
+		_, _, mapping = filterConfusingElements(a, b)
+	}
+	n, m := len(a), len(b)
+	if mapping != nil {
+		n, m = len(mapping.aToOrig), len(mapping.bToOrig)
+	}
+
+	var events []TraceEvent
+	trace := o.trace
+	record := func(e TraceEvent) {
+		events = append(events, e)
+		if trace != nil {
+			trace(e)
+		}
+	}
+	ops := diff(a, b, append(opts[:len(opts):len(opts)], WithAnnotations(nil), WithTrace(record))...)
+
+	var notes []Annotation
+	note := func(op int, format string, args ...any) {
+		if op >= 0 {
+			notes = append(notes, Annotation{Op: op, Reason: fmt.Sprintf(format, args...)})
+		}
+	}
+	for _, e := range events {
+		switch e.Kind {
+		case TraceTrim:
+			// Only the trim of the whole input is a prefix or suffix; the
+			// algorithm trims every subproblem it divides
+			if e.AStart != 0 || e.BStart != 0 || e.AEnd != n || e.BEnd != m {
+				continue
+			}
+			if e.Prefix > 0 {
+				x, y := mapping.orig(0, 0)
+				note(opAt(ops, x, y), "part of the common prefix")
+			}
+			if e.Suffix > 0 {
+				x, y := mapping.orig(n-1, m-1)
+				note(opAt(ops, x, y), "part of the common suffix")
+			}
+		case TraceMiddleSnake:
+			if !e.Heuristic {
+				continue
+			}
+			x, y := mapping.orig(e.X, e.Y)
+			note(opAt(ops, x, y), "split by a heuristic after %d edits; the alignment may not be minimal", e.D)
+		case TraceAnchor:
+			occurrences := "occurrences"
+			if e.Frequency == 1 {
+				occurrences = "occurrence"
+			}
+			note(opAt(ops, e.X, e.Y), "anchored on rare element %s (%d %s)",
+				describeElement(a[e.X]), e.Frequency, occurrences)
+		case TraceShift:
+			note(shiftedOp(ops, e), "%s", shiftReason(e, a, b))
+		case TraceCoarse:
+			// The notes so far explain the abandoned search
+			notes = notes[:0]
+			for i, op := range ops {
+				if op.Type != Equal && op.AStart >= e.AStart && op.AEnd <= e.AEnd && op.BStart >= e.BStart && op.BEnd <= e.BEnd {
+					note(i, "diffed coarsely: the input was too repetitive to align within the work limit")
+				}
+			}
+		}
+	}
+
+	// Later decisions are reported after earlier ones for the same op
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Op < notes[j].Op })
+	*dst = dedupAnnotations(notes)
+	return ops
+}
+
+// orig maps a position in the filtered sequences to the original ones. A nil
+// mapping is the identity.
+func (m *indexMapping) orig(x, y int) (int, int) {
+	if m == nil {
+		return x, y
+	}
+	return origIndex(m.aToOrig, x, m.origN), origIndex(m.bToOrig, y, m.origM)
+}
+
+// origIndex maps filtered index i to the original index, mapping the end of
+// the filtered sequence to origLen.
+func origIndex(toOrig []int, i, origLen int) int {
+	if i < len(toOrig) {
+		return toOrig[i]
+	}
+	return origLen
+}
+
+// opAt returns the index of the first op covering element x of A or
+// element y of B, or -1 if there is none.
+func opAt(ops []DiffOp, x, y int) int {
+	for i, op := range ops {
+		if (op.AStart <= x && x < op.AEnd) || (op.BStart <= y && y < op.BEnd) {
+			return i
+		}
+	}
+	return -1
+}
+
+// shiftedOp returns the index of the change op starting the region a shift
+// event reports, or -1 if later processing moved it again.
+func shiftedOp(ops []DiffOp, e TraceEvent) int {
+	for i, op := range ops {
+		if op.Type != Equal && op.AStart == e.AStart && op.BStart == e.BStart {
+			return i
+		}
+	}
+	return -1
+}
+
+// shiftReason describes a shift event, naming the blank line the region
+// moved next to, if any.
+func shiftReason(e TraceEvent, a, b []Element) string {
+	dir, dist := "down", e.Shift
+	if dist < 0 {
+		dir, dist = "up", -dist
+	}
+	unit := "elements"
+	if dist == 1 {
+		unit = "element"
+	}
+	reason := fmt.Sprintf("boundary shifted %s %d %s", dir, dist, unit)
+
+	borders := func(seq []Element, start, end int) bool {
+		return start < end &&
+			((start > 0 && isBlank(seq[start-1])) || (end < len(seq) && isBlank(seq[end])))
+	}
+	if borders(a, e.AStart, e.AEnd) || borders(b, e.BStart, e.BEnd) {
+		reason += " to a blank line"
+	}
+	return reason
+}
+
+// describeElement returns a short quoted rendering of e for a reason.
+func describeElement(e Element) string {
+	var s string
+	switch v := e.(type) {
+	case StringElement:
+		s = string(v)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		s = fmt.Sprint(e)
+	}
+	if r := []rune(s); len(r) > annotationTextLimit {
+		s = string(r[:annotationTextLimit]) + "…"
+	}
+	return strconv.Quote(s)
+}
+
+// dedupAnnotations drops repeated reasons for the same op from sorted notes.
+func dedupAnnotations(notes []Annotation) []Annotation {
+	var result []Annotation
+	for _, n := range notes {
+		dup := false
+		for k := len(result) - 1; k >= 0 && result[k].Op == n.Op; k-- {
+			if result[k].Reason == n.Reason {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package diffx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithAnnotations_Anchors(t *testing.T) {
+	a := strings.Fields("header intro alpha beta Fenestra gamma delta footer")
+	b := strings.Fields("header intro beta alpha Fenestra delta gamma footer")
+
+	var notes []Annotation
+	ops := DiffHistogram(a, b, WithAnnotations(&notes))
+
+	fenestra := opAt(ops, 4, 4)
+	want := Annotation{Op: fenestra, Reason: `anchored on rare element "Fenestra" (1 occurrence)`}
+	found := false
+	for _, n := range notes {
+		if n == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("annotations = %+v, want to contain %+v", notes, want)
+	}
+	if notes[0] != (Annotation{Op: 0, Reason: "part of the common prefix"}) {
+		t.Errorf("first annotation = %+v, want the common prefix", notes[0])
+	}
+	if last := notes[len(notes)-1]; last != (Annotation{Op: len(ops) - 1, Reason: "part of the common suffix"}) {
+		t.Errorf("last annotation = %+v, want the common suffix", last)
+	}
+}
+
+func TestWithAnnotations_Heuristic(t *testing.T) {
+	// The same input as TestWithStats_HeuristicFallback
+	var a, b []string
+	a = append(a, "p")
+	b = append(b, "q")
+	for i := 0; i < 40; i++ {
+		a = append(a, fmt.Sprintf("run%d", i))
+		b = append(b, fmt.Sprintf("run%d", i))
+	}
+	for i := 0; i < 100; i++ {
+		a = append(a, fmt.Sprintf("x%d", i))
+		b = append(b, fmt.Sprintf("y%d", i))
+	}
+
+	var notes []Annotation
+	ops := Diff(a, b, WithCostLimit(5), WithPreprocessing(false), WithAnnotations(&notes))
+
+	found := false
+	for _, n := range notes {
+		if strings.HasPrefix(n.Reason, "split by a heuristic") {
+			found = true
+			if ops[n.Op].Type == Equal {
+				t.Errorf("heuristic split annotates Equal op %+v", ops[n.Op])
+			}
+		}
+	}
+	if !found {
+		t.Errorf("annotations = %+v, want a heuristic split", notes)
+	}
+}
+
+func TestWithAnnotations_Shift(t *testing.T) {
+	a := []string{"a", "", "b"}
+	b := []string{"a", "", "b", "", "c", "", "b"}
+
+	var notes []Annotation
+	ops := Diff(a, b, WithAnnotations(&notes))
+
+	want := []Annotation{
+		{Op: 0, Reason: "part of the common prefix"},
+		{Op: 1, Reason: "boundary shifted up 1 element to a blank line"},
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("annotations = %+v, want %+v (ops %v)", notes, want, ops)
+	}
+}
+
+func TestWithAnnotations_SameOps(t *testing.T) {
+	a := strings.Fields("a b c d e f g h")
+	b := strings.Fields("a x c d y f g z")
+
+	for _, diff := range []func(a, b []string, opts ...Option) []DiffOp{Diff, DiffHistogram} {
+		var notes []Annotation
+		traced := 0
+		got := diff(a, b, WithAnnotations(&notes), WithTrace(func(TraceEvent) { traced++ }))
+		if want := diff(a, b); !reflect.DeepEqual(got, want) {
+			t.Errorf("ops with annotations = %v, want %v", got, want)
+		}
+		if traced == 0 {
+			t.Error("WithAnnotations suppressed the caller's trace")
+		}
+		for _, n := range notes {
+			if n.Op < 0 || n.Op >= len(got) {
+				t.Errorf("annotation %+v refers to no op", n)
+			}
+		}
+	}
+}
+
+func TestDescribeElement(t *testing.T) {
+	tests := []struct {
+		e    Element
+		want string
+	}{
+		{StringElement("Fenestra"), `"Fenestra"`},
+		{StringElement("tab\there"), `"tab\there"`},
+		{StringElement(strings.Repeat("x", 50)), `"` + strings.Repeat("x", 40) + `…"`},
+	}
+	for _, tt := range tests {
+		if got := describeElement(tt.e); got != tt.want {
+			t.Errorf("describeElement(%v) = %s, want %s", tt.e, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package diffx
+
+// Blame-style attribution.
+//
+// Given the history of a document as an ordered series of versions, each
+// element of the latest version can be attributed to the version that
+// introduced it by chaining the diffs between consecutive versions:
+// unchanged elements inherit the attribution of their counterpart in the
+// previous version, and inserted elements are attributed to the version
+// that inserted them.
+
+// Blame returns, for each element of the last version, the index in
+// versions of the version that introduced it. Elements of the first
+// version are attributed to version 0. opts are passed to each Diff.
+// Blame returns nil if versions is empty.
+func Blame(versions [][]string, opts ...Option) []int {
+	elems := make([][]Element, len(versions))
+	for i, v := range versions {
+		elems[i] = toElements(v)
+	}
+	return BlameElements(elems, opts...)
+}
+
+// BlameElements is like Blame but for arbitrary Element slices.
+func BlameElements(versions [][]Element, opts ...Option) []int {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	origin := make([]int, len(versions[0]))
+	for v := 1; v < len(versions); v++ {
+		prev, cur := versions[v-1], versions[v]
+		next := make([]int, len(cur))
+		for _, op := range DiffElements(prev, cur, opts...) {
+			switch op.Type {
+			case Equal:
+				copy(next[op.BStart:op.BEnd], origin[op.AStart:op.AEnd])
+			case Insert:
+				for j := op.BStart; j < op.BEnd; j++ {
+					next[j] = v
+				}
+			}
+		}
+		origin = next
+	}
+
+	return origin
+}
@@ -0,0 +1,51 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlame(t *testing.T) {
+	versions := [][]string{
+		{"# Guide", "Install it.", "Run it."},
+		{"# Guide", "Install it.", "Configure it.", "Run it."},
+		{"# User Guide", "Install it.", "Configure it.", "Run it.", "Done."},
+	}
+
+	got := Blame(versions)
+	want := []int{2, 0, 1, 0, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Blame() = %v, want %v", got, want)
+	}
+}
+
+func TestBlame_ReintroducedElement(t *testing.T) {
+	// An element that is deleted and later added back belongs to the
+	// version that re-added it
+	versions := [][]string{
+		{"a", "b", "c"},
+		{"a", "c"},
+		{"a", "b", "c"},
+	}
+
+	got := Blame(versions)
+	want := []int{0, 2, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Blame() = %v, want %v", got, want)
+	}
+}
+
+func TestBlame_Edge(t *testing.T) {
+	if got := Blame(nil); got != nil {
+		t.Errorf("Blame(nil) = %v, want nil", got)
+	}
+
+	got := Blame([][]string{{"x", "y"}})
+	if !reflect.DeepEqual(got, []int{0, 0}) {
+		t.Errorf("Blame(single) = %v, want [0 0]", got)
+	}
+
+	if got := Blame([][]string{{"x"}, {}}); len(got) != 0 {
+		t.Errorf("Blame(emptied) = %v, want empty", got)
+	}
+}
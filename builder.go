@@ -0,0 +1,122 @@
+package diffx
+
+import "fmt"
+
+// Edit script construction and editing.
+//
+// Tools that let a user stage or discard individual hunks, or that build
+// a script from another tool's output, need to produce []DiffOp values
+// that the rest of the package accepts: contiguous, with adjacent
+// operations of one type merged, and with the deletion of each change
+// before its insertion. ScriptBuilder appends operations by length and
+// keeps those invariants; DropHunk and SplitAt edit an existing script.
+
+// ScriptBuilder builds an edit script from the start of both sequences
+// onwards. The zero value is an empty script ready to use. After an
+// invalid call, the builder ignores further calls and Script reports the
+// error.
+type ScriptBuilder struct {
+	ops  []DiffOp
+	i, j int // lengths of A and B covered so far
+	err  error
+}
+
+// Equal appends n unchanged elements.
+func (b *ScriptBuilder) Equal(n int) *ScriptBuilder {
+	return b.add(Equal, n, n)
+}
+
+// Delete appends n elements deleted from A.
+func (b *ScriptBuilder) Delete(n int) *ScriptBuilder {
+	return b.add(Delete, n, 0)
+}
+
+// Insert appends n elements inserted from B.
+func (b *ScriptBuilder) Insert(n int) *ScriptBuilder {
+	return b.add(Insert, 0, n)
+}
+
+// add appends an operation covering na elements of A and nb of B.
+func (b *ScriptBuilder) add(typ OpType, na, nb int) *ScriptBuilder {
+	if b.err != nil {
+		return b
+	}
+	if na < 0 || nb < 0 {
+		b.err = fmt.Errorf("diffx: %v of negative length %d", typ, min(na, nb))
+		return b
+	}
+	if na == 0 && nb == 0 {
+		return b
+	}
+	b.ops = appendOp(b.ops, DiffOp{Type: typ, AStart: b.i, AEnd: b.i + na, BStart: b.j, BEnd: b.j + nb})
+	b.i += na
+	b.j += nb
+	return b
+}
+
+// Len returns the lengths of A and B the script covers so far.
+func (b *ScriptBuilder) Len() (lenA, lenB int) {
+	return b.i, b.j
+}
+
+// Script returns a copy of the script built so far, or the error of the
+// first invalid call.
+func (b *ScriptBuilder) Script() (EditScript, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return append(EditScript(nil), b.ops...), nil
+}
+
+// Hunks returns the change regions of the script, numbered from 0 as
+// DropHunk expects.
+func (s EditScript) Hunks() []ChangeRegion {
+	return CompactOps(s)
+}
+
+// DropHunk discards hunk k, as when a user declines a change: the result
+// is a script of A and of B with that change undone, so the elements of A
+// it replaced are kept and the following indices of B are shifted by the
+// difference in length.
+func (s EditScript) DropHunk(k int) (EditScript, error) {
+	hunks := s.Hunks()
+	if k < 0 || k >= len(hunks) {
+		return nil, fmt.Errorf("diffx: hunk %d out of range [0, %d)", k, len(hunks))
+	}
+	var b ScriptBuilder
+	i := 0 // end of the previous hunk in A
+	for n, h := range hunks {
+		b.Equal(h.AStart - i)
+		if n == k {
+			b.Equal(h.AEnd - h.AStart)
+		} else {
+			b.Delete(h.AEnd - h.AStart).Insert(h.BEnd - h.BStart)
+		}
+		i = h.AEnd
+	}
+	if len(s) > 0 {
+		b.Equal(s[len(s)-1].AEnd - i)
+	}
+	return b.Script()
+}
+
+// SplitAt cuts the script before element i of A into a script of A[:i]
+// and B[:j] and a script of A[i:] and B[j:], each indexed from zero, and
+// returns j. Operations that span the cut are divided as by SubDiff.
+// ConcatDiffs puts the two parts together again.
+func (s EditScript) SplitAt(i int) (before, after EditScript, j int) {
+	n := 0
+	if len(s) > 0 {
+		n = s[len(s)-1].AEnd
+	}
+	i = max(0, min(i, n))
+	before = SubDiff(s, 0, i)
+	if len(before) > 0 {
+		j = before[len(before)-1].BEnd
+	}
+	if i < n {
+		// At i == n, SubDiff would repeat an insertion at the end of A
+		after = OffsetOps(SubDiff(s, i, n), -i, -j)
+	}
+	return before, after, j
+}
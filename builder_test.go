@@ -0,0 +1,95 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScriptBuilder(t *testing.T) {
+	var b ScriptBuilder
+	b.Equal(2).Insert(1).Delete(2).Equal(0).Equal(1).Insert(2).Insert(1)
+
+	got, err := b.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The change at 2 has its deletion first, and runs of one type merge
+	want := EditScript{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 4, AEnd: 4, BStart: 2, BEnd: 3},
+		{Type: Equal, AStart: 4, AEnd: 5, BStart: 3, BEnd: 4},
+		{Type: Insert, AStart: 5, AEnd: 5, BStart: 4, BEnd: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Script() =\n%v\nwant\n%v", got, want)
+	}
+	if lenA, lenB := b.Len(); lenA != 5 || lenB != 7 {
+		t.Errorf("Len() = %d, %d, want 5, 7", lenA, lenB)
+	}
+
+	// The result is a copy
+	got[0].AEnd = 99
+	if again, _ := b.Script(); again[0].AEnd != 2 {
+		t.Error("Script() shares memory with the builder")
+	}
+}
+
+func TestScriptBuilder_Error(t *testing.T) {
+	var b ScriptBuilder
+	b.Equal(1).Delete(-1).Insert(2)
+	if got, err := b.Script(); err == nil {
+		t.Errorf("Script() = %v, want an error", got)
+	}
+
+	var empty ScriptBuilder
+	if got, err := empty.Script(); got != nil || err != nil {
+		t.Errorf("empty: Script() = %v, %v", got, err)
+	}
+}
+
+func TestEditScript_DropHunk(t *testing.T) {
+	a := strings.Fields("a b c d e f")
+	b := strings.Fields("a X c d e f g h")
+	s := EditScript(Diff(a, b))
+	if n := len(s.Hunks()); n != 2 {
+		t.Fatalf("Hunks() = %v, want 2", s.Hunks())
+	}
+
+	// Keep b instead of X
+	got, err := s.DropHunk(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := strings.Fields("a b c d e f g h")
+	checkScript(t, a, kept, got)
+	if got.TotalInserted() != 2 || got.TotalDeleted() != 0 {
+		t.Errorf("DropHunk(0) = %v, want only the insertion of g h", got)
+	}
+
+	got, err = s.DropHunk(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkScript(t, a, strings.Fields("a X c d e f"), got)
+
+	if _, err := s.DropHunk(2); err == nil {
+		t.Error("DropHunk(2) succeeded, want an error")
+	}
+}
+
+func TestEditScript_SplitAt(t *testing.T) {
+	a := strings.Fields("a b c d e f")
+	b := strings.Fields("a X c d E f g")
+	s := EditScript(Diff(a, b))
+
+	for i := 0; i <= len(a); i++ {
+		before, after, j := s.SplitAt(i)
+		checkScript(t, a[:i], b[:j], before)
+		checkScript(t, a[i:], b[j:], after)
+		if got := ConcatDiffs(before, after); !reflect.DeepEqual(EditScript(got), s) {
+			t.Errorf("SplitAt(%d) parts join to %v, want %v", i, got, s)
+		}
+	}
+}
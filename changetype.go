@@ -0,0 +1,40 @@
+package diffx
+
+// ChangeType identifies how an item, such as a record, key, or cell,
+// changed between two versions. The structured diff packages report their
+// changes with it and name the values they use in their own terms, so
+// that code handling several formats can switch on one type.
+type ChangeType int
+
+const (
+	// ChangeUnchanged means the item is the same in both versions.
+	ChangeUnchanged ChangeType = iota
+	// ChangeAdded means the item is only present in B.
+	ChangeAdded
+	// ChangeRemoved means the item is only present in A.
+	ChangeRemoved
+	// ChangeModified means the item is present in both with different
+	// content.
+	ChangeModified
+	// ChangeMoved means the item is present in both with the same content,
+	// but its position relative to the other items changed.
+	ChangeMoved
+)
+
+// String returns a string representation of the ChangeType.
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeUnchanged:
+		return "Unchanged"
+	case ChangeAdded:
+		return "Added"
+	case ChangeRemoved:
+		return "Removed"
+	case ChangeModified:
+		return "Modified"
+	case ChangeMoved:
+		return "Moved"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,22 @@
+package diffx
+
+import "testing"
+
+func TestChangeType_String(t *testing.T) {
+	tests := []struct {
+		c    ChangeType
+		want string
+	}{
+		{ChangeUnchanged, "Unchanged"},
+		{ChangeAdded, "Added"},
+		{ChangeRemoved, "Removed"},
+		{ChangeModified, "Modified"},
+		{ChangeMoved, "Moved"},
+		{ChangeType(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("ChangeType(%d).String() = %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package diffx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error-returning API.
+//
+// Diff and DiffElements trust their inputs: a nil element or an Element
+// whose Hash disagrees with its Equal makes them panic or return a wrong
+// script. Services that cannot let a library dependency take down a request
+// handler use DiffE and DiffElementsE instead, which validate the inputs,
+// check the result, and turn any panic into an error.
+
+var (
+	// ErrNilElement is reported for a nil element in the input.
+	ErrNilElement = errors.New("diffx: nil element")
+
+	// ErrInconsistentElement is reported for elements that break the
+	// Element contract: an element that is not equal to itself, or a
+	// matched pair whose Equal is not symmetric or whose hashes differ.
+	ErrInconsistentElement = errors.New("diffx: inconsistent element")
+
+	// ErrPanic is reported when diffing panicked, usually inside a method
+	// of a custom Element.
+	ErrPanic = errors.New("diffx: panic during diff")
+)
+
+// DiffE is like Diff, but returns an error instead of panicking.
+func DiffE(a, b []string, opts ...Option) (ops []DiffOp, err error) {
+	defer recoverDiff(&ops, &err)
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return DiffElementsE(o.ignoreOpts.elements(a), o.ignoreOpts.elements(b), opts...)
+}
+
+// DiffElementsE is like DiffElements, but validates its inputs and result
+// and returns an error instead of panicking. Errors wrap ErrTooLarge,
+// ErrNilElement, ErrInconsistentElement, or ErrPanic, for use with
+// errors.Is.
+//
+// Every element is checked to be non-nil and equal to itself, and every
+// pair matched by the result to be equal both ways with equal hashes.
+// Inconsistent pairs the algorithm did not match can go undetected; see
+// the diffxtest package for an exhaustive check.
+func DiffElementsE(a, b []Element, opts ...Option) (ops []DiffOp, err error) {
+	defer recoverDiff(&ops, &err)
+
+	if err := checkSize(len(a), len(b)); err != nil {
+		return nil, err
+	}
+	if err := validateElements("A", a); err != nil {
+		return nil, err
+	}
+	if err := validateElements("B", b); err != nil {
+		return nil, err
+	}
+
+	ops = DiffElements(a, b, opts...)
+
+	for _, op := range ops {
+		if op.Type != Equal {
+			continue
+		}
+		for i, j := op.AStart, op.BStart; i < op.AEnd; i, j = i+1, j+1 {
+			if !a[i].Equal(b[j]) || !b[j].Equal(a[i]) {
+				return nil, fmt.Errorf("%w: A[%d] and B[%d] are matched but Equal is not symmetric", ErrInconsistentElement, i, j)
+			}
+			if a[i].Hash() != b[j].Hash() {
+				return nil, fmt.Errorf("%w: A[%d] and B[%d] are equal but have different hashes", ErrInconsistentElement, i, j)
+			}
+		}
+	}
+	return ops, nil
+}
+
+// validateElements checks that every element of seq, named name in errors,
+// is non-nil and equal to itself.
+func validateElements(name string, seq []Element) error {
+	for i, e := range seq {
+		if e == nil {
+			return fmt.Errorf("%w: %s[%d]", ErrNilElement, name, i)
+		}
+		if !e.Equal(e) {
+			return fmt.Errorf("%w: %s[%d] is not equal to itself", ErrInconsistentElement, name, i)
+		}
+	}
+	return nil
+}
+
+// recoverDiff turns a panic into an ErrPanic error in *err, clearing *ops.
+// It must be deferred directly.
+func recoverDiff(ops *[]DiffOp, err *error) {
+	if r := recover(); r != nil {
+		*ops = nil
+		*err = fmt.Errorf("%w: %v", ErrPanic, r)
+	}
+}
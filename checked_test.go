@@ -0,0 +1,106 @@
+package diffx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// brokenElement is an Element whose methods misbehave on demand.
+type brokenElement struct {
+	s          string
+	hash       uint64 // returned by Hash
+	notSelf    bool   // Equal(itself) reports false
+	panicEqual bool   // Equal panics
+}
+
+func (e brokenElement) Equal(other Element) bool {
+	if e.panicEqual {
+		panic("broken Equal")
+	}
+	o, ok := other.(brokenElement)
+	return ok && !e.notSelf && e.s == o.s
+}
+
+func (e brokenElement) Hash() uint64 { return e.hash }
+
+func TestDiffE(t *testing.T) {
+	a := strings.Fields("a b c d")
+	b := strings.Fields("a x c y")
+
+	got, err := DiffE(a, b)
+	if err != nil {
+		t.Fatalf("DiffE: %v", err)
+	}
+	if want := Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffE = %v, want %v", got, want)
+	}
+}
+
+func TestDiffElementsE_Errors(t *testing.T) {
+	ok := func(s string) Element { return brokenElement{s: s, hash: uint64(len(s))} }
+
+	tests := []struct {
+		name    string
+		a, b    []Element
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "nil element",
+			a:       []Element{ok("a"), nil},
+			b:       []Element{ok("a")},
+			wantErr: ErrNilElement,
+			wantMsg: "A[1]",
+		},
+		{
+			name:    "not equal to itself",
+			a:       []Element{ok("a")},
+			b:       []Element{ok("a"), brokenElement{s: "b", notSelf: true}},
+			wantErr: ErrInconsistentElement,
+			wantMsg: "B[1] is not equal to itself",
+		},
+		{
+			name:    "equal with different hashes",
+			a:       []Element{ok("x"), brokenElement{s: "a", hash: 1}, ok("y")},
+			b:       []Element{ok("x"), brokenElement{s: "a", hash: 2}, ok("z")},
+			wantErr: ErrInconsistentElement,
+			wantMsg: "A[1] and B[1] are equal but have different hashes",
+		},
+		{
+			name:    "panicking Equal",
+			a:       []Element{brokenElement{s: "a", panicEqual: true}},
+			b:       []Element{ok("a")},
+			wantErr: ErrPanic,
+			wantMsg: "broken Equal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := DiffElementsE(tt.a, tt.b, WithPreprocessing(false))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.wantMsg)
+			}
+			if ops != nil {
+				t.Errorf("ops = %v, want nil", ops)
+			}
+		})
+	}
+}
+
+func TestDiffElementsE_Valid(t *testing.T) {
+	a := toElements(strings.Fields("a b c d e"))
+	b := toElements(strings.Fields("a c d x e"))
+
+	got, err := DiffElementsE(a, b)
+	if err != nil {
+		t.Fatalf("DiffElementsE: %v", err)
+	}
+	if want := DiffElements(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffElementsE = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package diffx
+
+// Efficiency cleanup post-processing.
+//
+// Based on the cleanupEfficiency pass described in Neil Fraser's
+// "Diff Strategies" (https://neil.fraser.name/writing/diff/) and implemented
+// in diff-match-patch as Diff_EditCost.
+//
+// A diff made of many tiny change regions separated by one- or two-element
+// equalities is technically minimal but tiring to read. Each hunk carries a
+// fixed cost for the reader, so it is often cheaper to fold a short equality
+// into the surrounding change and present one larger hunk instead.
+
+// cleanupEfficiency folds short Equal regions that sit between change regions
+// into those changes. editCost is the cost of an extra hunk measured in
+// elements: an equality shorter than editCost that has both a deletion and an
+// insertion on each side is folded, as is an equality shorter than half of
+// editCost that has three of those four edits around it.
+//
+// An editCost of zero or less disables the cleanup.
+func cleanupEfficiency(ops []DiffOp, editCost int) []DiffOp {
+	if editCost <= 0 || len(ops) < 3 {
+		return ops
+	}
+
+	result := coalesceChanges(ops)
+	for changed := true; changed; {
+		changed = false
+		for i := 1; i < len(result)-1; i++ {
+			op := result[i]
+			if op.Type != Equal {
+				continue
+			}
+
+			size := op.AEnd - op.AStart
+			if size >= editCost {
+				continue
+			}
+
+			preDel, preIns := changeKinds(result, i-1, -1)
+			postDel, postIns := changeKinds(result, i+1, 1)
+			if !(preDel || preIns) || !(postDel || postIns) {
+				continue
+			}
+
+			edits := countTrue(preDel, preIns, postDel, postIns)
+			if edits == 4 || (edits == 3 && size*2 < editCost) {
+				result = foldEquality(result, i)
+				changed = true
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// changeKinds reports whether the run of change operations starting at
+// ops[i] and walking in direction dir contains a deletion and an insertion.
+func changeKinds(ops []DiffOp, i, dir int) (hasDelete, hasInsert bool) {
+	for ; i >= 0 && i < len(ops) && ops[i].Type != Equal; i += dir {
+		switch ops[i].Type {
+		case Delete:
+			hasDelete = true
+		case Insert:
+			hasInsert = true
+		}
+	}
+	return hasDelete, hasInsert
+}
+
+// countTrue returns the number of true values.
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// foldEquality replaces the Equal operation at ops[i] with a Delete+Insert
+// pair and coalesces it with the neighboring change regions.
+func foldEquality(ops []DiffOp, i int) []DiffOp {
+	eq := ops[i]
+	folded := make([]DiffOp, 0, len(ops)+1)
+	folded = append(folded, ops[:i]...)
+	folded = append(folded,
+		DiffOp{Type: Delete, AStart: eq.AStart, AEnd: eq.AEnd, BStart: eq.BStart, BEnd: eq.BStart},
+		DiffOp{Type: Insert, AStart: eq.AEnd, AEnd: eq.AEnd, BStart: eq.BStart, BEnd: eq.BEnd},
+	)
+	folded = append(folded, ops[i+1:]...)
+	return coalesceChanges(folded)
+}
+
+// coalesceChanges rewrites every run of consecutive change operations as a
+// single Delete followed by a single Insert covering the same ranges.
+// Equal operations are merged with adjacent Equal operations.
+func coalesceChanges(ops []DiffOp) []DiffOp {
+	if len(ops) == 0 {
+		return ops
+	}
+
+	result := make([]DiffOp, 0, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].Type == Equal {
+			result = append(result, ops[i])
+			i++
+			continue
+		}
+
+		// Find the extent of this change run
+		aStart, bStart := ops[i].AStart, ops[i].BStart
+		aEnd, bEnd := ops[i].AEnd, ops[i].BEnd
+		for i < len(ops) && ops[i].Type != Equal {
+			aEnd, bEnd = ops[i].AEnd, ops[i].BEnd
+			i++
+		}
+
+		if aEnd > aStart {
+			result = append(result, DiffOp{Type: Delete, AStart: aStart, AEnd: aEnd, BStart: bStart, BEnd: bStart})
+		}
+		if bEnd > bStart {
+			result = append(result, DiffOp{Type: Insert, AStart: aEnd, AEnd: aEnd, BStart: bStart, BEnd: bEnd})
+		}
+	}
+
+	return mergeAdjacentOps(result)
+}
+
+// WithEditCost enables the efficiency cleanup, which folds Equal regions
+// shorter than n elements into the surrounding changes when that reduces
+// the number of hunks. This mirrors diff-match-patch's Diff_EditCost.
+// 0 disables the cleanup.
+// Default: 0.
+func WithEditCost(n int) Option {
+	return func(o *options) {
+		o.editCost = n
+	}
+}
@@ -0,0 +1,148 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCleanupEfficiency_Disabled(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	got := cleanupEfficiency(ops, 0)
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("editCost 0 should leave ops unchanged, got %v", got)
+	}
+}
+
+func TestCleanupEfficiency_FoldsSurroundedEquality(t *testing.T) {
+	// a: x1 the y1  ->  b: x2 the y2
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	got := cleanupEfficiency(ops, 4)
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 0, BEnd: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cleanupEfficiency() = %v, want %v", got, want)
+	}
+}
+
+func TestCleanupEfficiency_KeepsLongEquality(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 6, BStart: 1, BEnd: 6},
+		{Type: Delete, AStart: 6, AEnd: 7, BStart: 6, BEnd: 6},
+		{Type: Insert, AStart: 7, AEnd: 7, BStart: 6, BEnd: 7},
+	}
+
+	got := cleanupEfficiency(ops, 4)
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("equality longer than editCost should be kept, got %v", got)
+	}
+}
+
+func TestCleanupEfficiency_ThreeEdits(t *testing.T) {
+	// Delete+Insert before, Insert only after: folded only when the
+	// equality is shorter than half the edit cost.
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3},
+	}
+
+	if got := cleanupEfficiency(ops, 2); !reflect.DeepEqual(got, ops) {
+		t.Errorf("editCost 2 should keep the equality, got %v", got)
+	}
+
+	got := cleanupEfficiency(ops, 4)
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cleanupEfficiency() = %v, want %v", got, want)
+	}
+}
+
+func TestCleanupEfficiency_EdgeEqualitiesKept(t *testing.T) {
+	// Equalities at the start or end are not between two changes
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	got := cleanupEfficiency(ops, 4)
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("edge equalities should be kept, got %v", got)
+	}
+}
+
+func TestCoalesceChanges(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 1, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 2, BEnd: 3},
+	}
+
+	got := coalesceChanges(ops)
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 0, BEnd: 2},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 2, BEnd: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("coalesceChanges() = %v, want %v", got, want)
+	}
+}
+
+func TestWithEditCost(t *testing.T) {
+	a := strings.Fields("one two the three four the five six")
+	b := strings.Fields("uno dos the tres cuatro the cinco seis")
+
+	countHunks := func(ops []DiffOp) int {
+		hunks := 0
+		inChange := false
+		for _, op := range ops {
+			if op.Type == Equal {
+				inChange = false
+			} else if !inChange {
+				hunks++
+				inChange = true
+			}
+		}
+		return hunks
+	}
+
+	cleaned := Diff(a, b, WithPreprocessing(false), WithEditCost(4))
+
+	if got := applyDiff(a, b, cleaned); !reflect.DeepEqual(got, b) {
+		t.Fatalf("applying cleaned diff = %v, want %v", got, b)
+	}
+	if countHunks(cleaned) != 1 {
+		t.Errorf("expected a single hunk, got %d: %v", countHunks(cleaned), cleaned)
+	}
+
+	hist := DiffHistogram(a, b, WithEditCost(4))
+	if got := applyDiff(a, b, hist); !reflect.DeepEqual(got, b) {
+		t.Errorf("applying cleaned histogram diff = %v, want %v", got, b)
+	}
+}
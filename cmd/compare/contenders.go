@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/dacharyc/diffx"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// A contender is one diff configuration under test. Every contender
+// returns its result as a diffx edit script over the lines of a and b, so
+// that all of them are measured the same way.
+type contender struct {
+	name string
+	diff func(a, b []string) []diffx.DiffOp
+}
+
+// contenders returns the configurations compared by the harness: the
+// diffx algorithms and notable option sets, followed by the competitor
+// libraries with their default settings.
+func contenders() []contender {
+	return []contender{
+		{"diffx", func(a, b []string) []diffx.DiffOp {
+			return diffx.Diff(a, b)
+		}},
+		{"diffx-minimal", func(a, b []string) []diffx.DiffOp {
+			return diffx.Diff(a, b, diffx.WithMinimal(true))
+		}},
+		{"diffx-indent", func(a, b []string) []diffx.DiffOp {
+			return diffx.Diff(a, b, diffx.WithIndentHeuristic(true))
+		}},
+		{"diffx-weak-anchors", func(a, b []string) []diffx.DiffOp {
+			return diffx.Diff(a, b, diffx.WithWeakAnchorElimination(true))
+		}},
+		{"diffx-histogram", func(a, b []string) []diffx.DiffOp {
+			return diffx.DiffHistogram(a, b)
+		}},
+		{"go-diff", goDiff},
+		{"go-difflib", goDifflib},
+	}
+}
+
+// minimalEdits returns the size of a shortest edit script for a and b,
+// the baseline for minimality excess. It is computed from the length of a
+// longest common subsequence by dynamic programming, independently of the
+// diff algorithms under test, in O(len(a)*len(b)) time.
+func minimalEdits(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return len(a) + len(b) - 2*prev[len(b)]
+}
+
+// goDiff diffs lines with sergi/go-diff in line mode, which encodes each
+// distinct line as one rune.
+func goDiff(a, b []string) []diffx.DiffOp {
+	dmp := diffmatchpatch.New()
+	runesA, runesB, _ := dmp.DiffLinesToRunes(strings.Join(a, ""), strings.Join(b, ""))
+
+	var ops []diffx.DiffOp
+	i, j := 0, 0
+	for _, d := range dmp.DiffMainRunes(runesA, runesB, false) {
+		n := len([]rune(d.Text))
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			ops = append(ops, diffx.DiffOp{Type: diffx.Equal, AStart: i, AEnd: i + n, BStart: j, BEnd: j + n})
+			i, j = i+n, j+n
+		case diffmatchpatch.DiffDelete:
+			ops = append(ops, diffx.DiffOp{Type: diffx.Delete, AStart: i, AEnd: i + n, BStart: j, BEnd: j})
+			i += n
+		case diffmatchpatch.DiffInsert:
+			ops = append(ops, diffx.DiffOp{Type: diffx.Insert, AStart: i, AEnd: i, BStart: j, BEnd: j + n})
+			j += n
+		}
+	}
+	return ops
+}
+
+// goDifflib diffs lines with pmezard/go-difflib's SequenceMatcher, a port
+// of Python's difflib. Replacements become a Delete followed by an Insert.
+func goDifflib(a, b []string) []diffx.DiffOp {
+	var ops []diffx.DiffOp
+	for _, c := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch c.Tag {
+		case 'e':
+			ops = append(ops, diffx.DiffOp{Type: diffx.Equal, AStart: c.I1, AEnd: c.I2, BStart: c.J1, BEnd: c.J2})
+		case 'd':
+			ops = append(ops, diffx.DiffOp{Type: diffx.Delete, AStart: c.I1, AEnd: c.I2, BStart: c.J1, BEnd: c.J1})
+		case 'i':
+			ops = append(ops, diffx.DiffOp{Type: diffx.Insert, AStart: c.I1, AEnd: c.I1, BStart: c.J1, BEnd: c.J2})
+		case 'r':
+			ops = append(ops,
+				diffx.DiffOp{Type: diffx.Delete, AStart: c.I1, AEnd: c.I2, BStart: c.J1, BEnd: c.J1},
+				diffx.DiffOp{Type: diffx.Insert, AStart: c.I2, AEnd: c.I2, BStart: c.J1, BEnd: c.J2})
+		}
+	}
+	return ops
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dacharyc/diffx/diffxtest"
+)
+
+// corpusCase is a before/after pair, split into lines that keep their
+// terminators.
+type corpusCase struct {
+	name string
+	a, b []string
+}
+
+// loadCorpus reads every before/after pair under dir, sorted by name. A
+// ".before" file without its ".after" counterpart is an error.
+func loadCorpus(dir string) ([]corpusCase, error) {
+	var cases []corpusCase
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.Contains(d.Name(), ".before") {
+			return err
+		}
+
+		afterPath := filepath.Join(filepath.Dir(path), strings.Replace(d.Name(), ".before", ".after", 1))
+		before, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		after, err := os.ReadFile(afterPath)
+		if err != nil {
+			return fmt.Errorf("%s has no matching .after file: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, corpusCase{
+			name: filepath.ToSlash(strings.Replace(rel, ".before", "", 1)),
+			a:    splitLines(string(before)),
+			b:    splitLines(string(after)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].name < cases[j].name })
+	return cases, nil
+}
+
+// splitLines splits text into lines, keeping each line's terminator.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// mutationsPerCase is the number of realistic edits applied to make each
+// synthetic case.
+const mutationsPerCase = 3
+
+// synthesize returns n synthetic cases per corpus case, each pairing the
+// case's before text with a realistic mutation of it. The cases are named
+// after their source with a "~N" suffix and are reproducible for a seed.
+func synthesize(cases []corpusCase, n int, seed int64) []corpusCase {
+	g := diffxtest.NewGenerator(seed)
+	var out []corpusCase
+	for _, c := range cases {
+		doc := make([]string, len(c.a))
+		for i, line := range c.a {
+			doc[i] = strings.TrimSuffix(line, "\n")
+		}
+		for i := 1; i <= n; i++ {
+			mutated := g.Mutate(doc, mutationsPerCase)
+			b := make([]string, len(mutated))
+			for j, line := range mutated {
+				b[j] = line + "\n"
+			}
+			out = append(out, corpusCase{name: c.name + "~" + strconv.Itoa(i), a: c.a, b: b})
+		}
+	}
+	return out
+}
@@ -0,0 +1,263 @@
+// Command compare measures diff quality and speed on a corpus of real
+// edits, comparing diffx configurations with other Go diff libraries.
+//
+// Usage:
+//
+//	compare [flags]
+//
+// The corpus is a directory of before/after pairs: each file whose name
+// contains ".before" is paired with the file of the same name with
+// ".after" in its place, for example readme.before.md and readme.after.md.
+// Subdirectories are searched too. Each pair is diffed line by line by
+// every contender, and the results are printed as a table:
+//
+//	time     median wall time over -runs runs
+//	changes  number of Delete and Insert operations
+//	regions  runs of consecutive changes; fewer means less fragmented output
+//	edits    lines deleted plus lines inserted
+//	excess   edits beyond a minimal edit script
+//
+// A contender whose script does not transform A into B is reported as
+// invalid.
+//
+// With -mutate, each corpus case also yields synthetic cases that pair its
+// before text with realistic edits of it: inserted paragraphs, rewrapped
+// lines, renamed identifiers, and reordered sections. These are named
+// after their source, such as readme.md~1.
+//
+// Flags:
+//
+//	-corpus dir   directory of before/after pairs (default "testdata/corpus")
+//	-runs n       timed runs per case (default 5)
+//	-csv          print comma-separated values instead of a table
+//	-mutate n     add n synthetic cases per corpus case (default 0)
+//	-seed n       random seed for synthetic cases (default 1)
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dacharyc/diffx"
+)
+
+// result holds the measurements of one contender on one case.
+type result struct {
+	caseName  string
+	contender string
+	time      time.Duration
+	stat      diffx.DiffStat
+	changes   int
+	excess    int
+	valid     bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns its exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	corpusDir := fs.String("corpus", "testdata/corpus", "directory of before/after pairs")
+	runs := fs.Int("runs", 5, "timed runs per case")
+	asCSV := fs.Bool("csv", false, "print comma-separated values instead of a table")
+	mutate := fs.Int("mutate", 0, "synthetic cases to add per corpus case")
+	seed := fs.Int64("seed", 1, "random seed for synthetic cases")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *runs < 1 {
+		fmt.Fprintf(stderr, "compare: invalid run count %d\n", *runs)
+		return 2
+	}
+	if *mutate < 0 {
+		fmt.Fprintf(stderr, "compare: invalid mutation count %d\n", *mutate)
+		return 2
+	}
+
+	cases, err := loadCorpus(*corpusDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "compare: %v\n", err)
+		return 2
+	}
+	if len(cases) == 0 {
+		fmt.Fprintf(stderr, "compare: no before/after pairs in %s\n", *corpusDir)
+		return 2
+	}
+
+	cases = append(cases, synthesize(cases, *mutate, *seed)...)
+
+	var results []result
+	for _, c := range cases {
+		results = append(results, measure(c, contenders(), *runs)...)
+	}
+
+	if *asCSV {
+		err = writeCSV(stdout, results)
+	} else {
+		err = writeTable(stdout, results)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "compare: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// measure runs every contender on a case.
+func measure(c corpusCase, contenders []contender, runs int) []result {
+	minimal := minimalEdits(c.a, c.b)
+
+	results := make([]result, 0, len(contenders))
+	for _, k := range contenders {
+		var ops []diffx.DiffOp
+		times := make([]time.Duration, runs)
+		for i := range times {
+			start := time.Now()
+			ops = k.diff(c.a, c.b)
+			times[i] = time.Since(start)
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+		stat := diffx.Stat(ops)
+		results = append(results, result{
+			caseName:  c.name,
+			contender: k.name,
+			time:      times[len(times)/2],
+			stat:      stat,
+			changes:   countChanges(ops),
+			excess:    stat.Changes() - minimal,
+			valid:     transforms(ops, c.a, c.b),
+		})
+	}
+	return results
+}
+
+// countChanges returns the number of Delete and Insert operations in ops.
+func countChanges(ops []diffx.DiffOp) int {
+	n := 0
+	for _, op := range ops {
+		if op.Type != diffx.Equal {
+			n++
+		}
+	}
+	return n
+}
+
+// transforms reports whether ops is a contiguous script that turns a into
+// b.
+func transforms(ops []diffx.DiffOp, a, b []string) bool {
+	i, j := 0, 0
+	for _, op := range ops {
+		if op.AStart != i || op.BStart != j || op.AEnd < op.AStart || op.BEnd < op.BStart {
+			return false
+		}
+		switch op.Type {
+		case diffx.Equal:
+			if op.AEnd-op.AStart != op.BEnd-op.BStart {
+				return false
+			}
+			for k := 0; k < op.AEnd-op.AStart; k++ {
+				if a[op.AStart+k] != b[op.BStart+k] {
+					return false
+				}
+			}
+		case diffx.Delete:
+			if op.BEnd != op.BStart {
+				return false
+			}
+		case diffx.Insert:
+			if op.AEnd != op.AStart {
+				return false
+			}
+		}
+		i, j = op.AEnd, op.BEnd
+	}
+	return i == len(a) && j == len(b)
+}
+
+// writeTable prints the results per case, followed by totals per
+// contender.
+func writeTable(w io.Writer, results []result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "case\tcontender\ttime\tchanges\tregions\tedits\texcess\t")
+
+	totals := map[string]*result{}
+	var order []string
+	prev := ""
+	for _, r := range results {
+		name := r.caseName
+		if name == prev {
+			name = ""
+		}
+		prev = r.caseName
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%s\t\n",
+			name, r.contender, r.time.Round(time.Microsecond), r.changes, r.stat.Hunks, r.stat.Changes(), excessText(r))
+
+		t, ok := totals[r.contender]
+		if !ok {
+			t = &result{contender: r.contender, valid: true}
+			totals[r.contender] = t
+			order = append(order, r.contender)
+		}
+		t.time += r.time
+		t.stat = t.stat.Add(r.stat)
+		t.changes += r.changes
+		t.excess += r.excess
+		t.valid = t.valid && r.valid
+	}
+
+	fmt.Fprintln(tw, "\t\t\t\t\t\t\t")
+	for i, name := range order {
+		t := totals[name]
+		label := ""
+		if i == 0 {
+			label = "total"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%s\t\n",
+			label, t.contender, t.time.Round(time.Microsecond), t.changes, t.stat.Hunks, t.stat.Changes(), excessText(*t))
+	}
+	return tw.Flush()
+}
+
+// excessText formats the minimality excess, or "invalid" for a script
+// that does not transform A into B.
+func excessText(r result) string {
+	if !r.valid {
+		return "invalid"
+	}
+	return strconv.Itoa(r.excess)
+}
+
+// writeCSV prints one record per case and contender, with times in
+// microseconds.
+func writeCSV(w io.Writer, results []result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"case", "contender", "time_us", "changes", "regions", "edits", "excess", "valid"})
+	for _, r := range results {
+		cw.Write([]string{
+			r.caseName,
+			r.contender,
+			strconv.FormatInt(r.time.Microseconds(), 10),
+			strconv.Itoa(r.changes),
+			strconv.Itoa(r.stat.Hunks),
+			strconv.Itoa(r.stat.Changes()),
+			strconv.Itoa(r.excess),
+			strconv.FormatBool(r.valid),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
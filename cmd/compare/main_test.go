@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dacharyc/diffx"
+)
+
+func TestLoadCorpus(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"b.before.txt":     "1\n2\n",
+		"b.after.txt":      "1\n3\n",
+		"sub/a.before.md":  "x\n",
+		"sub/a.after.md":   "y\n",
+		"notes/README.txt": "ignored\n",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases, err := loadCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 || cases[0].name != "b.txt" || cases[1].name != "sub/a.md" {
+		t.Fatalf("cases = %+v", cases)
+	}
+	if got := strings.Join(cases[0].b, ""); got != "1\n3\n" {
+		t.Errorf("after text = %q", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "lonely.before.txt"), []byte("x\n"), 0o644)
+	if _, err := loadCorpus(dir); err == nil {
+		t.Error("expected an error for a missing .after file")
+	}
+}
+
+func TestMinimalEdits(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"abc", "", 3},
+		{"abcabba", "cbabac", 5},
+		{"xaby", "aybx", 4},
+	}
+	for _, tt := range tests {
+		a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+		if got := minimalEdits(a, b); got != tt.want {
+			t.Errorf("minimalEdits(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	a := []string{"a", "b", "c"}
+	b := []string{"a", "x", "c"}
+
+	valid := []diffx.DiffOp{
+		{Type: diffx.Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: diffx.Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: diffx.Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: diffx.Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+	if !transforms(valid, a, b) {
+		t.Error("valid script rejected")
+	}
+
+	gap := []diffx.DiffOp{
+		{Type: diffx.Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: diffx.Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: diffx.Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3},
+	}
+	if transforms(gap, a, b) {
+		t.Error("script with a gap accepted")
+	}
+
+	mismatch := []diffx.DiffOp{{Type: diffx.Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3}}
+	if transforms(mismatch, a, b) {
+		t.Error("Equal over different lines accepted")
+	}
+}
+
+func TestCompetitors(t *testing.T) {
+	cases, err := loadCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		for _, k := range []contender{{"go-diff", goDiff}, {"go-difflib", goDifflib}} {
+			if ops := k.diff(c.a, c.b); !transforms(ops, c.a, c.b) {
+				t.Errorf("%s on %s: invalid script %v", k.name, c.name, ops)
+			}
+		}
+	}
+}
+
+// TestGolden_NoMoreRegions holds diffx to the claim the harness measures:
+// on every corpus case, its default configuration produces a valid script
+// with no more change regions than either competitor.
+func TestGolden_NoMoreRegions(t *testing.T) {
+	cases, err := loadCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		ops := diffx.Diff(c.a, c.b)
+		if !transforms(ops, c.a, c.b) {
+			t.Errorf("diffx on %s: invalid script %v", c.name, ops)
+			continue
+		}
+		regions := diffx.Stat(ops).Hunks
+		for _, k := range []contender{{"go-diff", goDiff}, {"go-difflib", goDifflib}} {
+			if other := diffx.Stat(k.diff(c.a, c.b)).Hunks; regions > other {
+				t.Errorf("%s: diffx has %d change regions, %s has %d", c.name, regions, k.name, other)
+			}
+		}
+	}
+}
+
+func TestRun_CSV(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-csv", "-runs", "1"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, _ := loadCorpus("testdata/corpus")
+	if want := 1 + len(cases)*len(contenders()); len(records) != want {
+		t.Errorf("got %d records, want %d", len(records), want)
+	}
+	if records[0][0] != "case" || records[0][6] != "excess" {
+		t.Errorf("header = %v", records[0])
+	}
+}
+
+func TestRun_EmptyCorpus(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-corpus", t.TempDir()}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "no before/after pairs") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestSynthesize(t *testing.T) {
+	cases := []corpusCase{{name: "doc.md", a: []string{"# A\n", "\n", "one two\n", "\n", "# B\n", "\n", "three\n"}}}
+	synth := synthesize(cases, 2, 1)
+	if len(synth) != 2 || synth[0].name != "doc.md~1" || synth[1].name != "doc.md~2" {
+		t.Fatalf("cases = %+v", synth)
+	}
+	for _, c := range synth {
+		for _, line := range c.b {
+			if !strings.HasSuffix(line, "\n") {
+				t.Errorf("%s: line %q has no terminator", c.name, line)
+			}
+		}
+	}
+	if again := synthesize(cases, 2, 1); strings.Join(again[1].b, "") != strings.Join(synth[1].b, "") {
+		t.Error("synthetic cases differ for the same seed")
+	}
+}
+
+func TestRun_Mutate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-csv", "-runs", "1", "-mutate", "2"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases, _ := loadCorpus("testdata/corpus")
+	if want := 1 + 3*len(cases)*len(contenders()); len(records) != want {
+		t.Errorf("got %d records, want %d", len(records), want)
+	}
+	for _, r := range records[1:] {
+		if r[7] != "true" {
+			t.Errorf("%s on %s: invalid script", r[1], r[0])
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dacharyc/diffx"
+	"github.com/dacharyc/diffx/htmlreport"
+)
+
+// compare diffs two texts according to cfg, writes the result, and
+// reports whether the texts differ. With -stat or -numstat, the summary is
+// recorded under nameB instead of written.
+func compare(w io.Writer, cfg *config, nameA, nameB, textA, textB string) (bool, error) {
+	a, b, ops := diffTexts(cfg, textA, textB)
+	differ := differs(cfg, a, b, ops)
+
+	if cfg.stats != nil {
+		if differ {
+			cfg.stats.add(nameB, diffx.Stat(ops))
+		}
+		return differ, nil
+	}
+
+	if cfg.brief {
+		if differ {
+			_, err := fmt.Fprintf(w, "Files %s and %s differ\n", nameA, nameB)
+			return true, err
+		}
+		return false, nil
+	}
+
+	var hunks []hunk
+	if differ {
+		hunks = visibleHunks(cfg, a, b, ops)
+	}
+	if cfg.maxLineLength > 0 && cfg.format != "json" {
+		a, b = truncateLines(a, cfg.maxLineLength), truncateLines(b, cfg.maxLineLength)
+	}
+	if cfg.expandTabs && cfg.format != "json" {
+		a, b = expandTabs(a, cfg.tabSize), expandTabs(b, cfg.tabSize)
+	}
+	if cfg.showInvisibles && cfg.format != "json" {
+		a, b = showInvisibles(a), showInvisibles(b)
+	}
+
+	var err error
+	switch cfg.format {
+	case "inline":
+		if differ {
+			err = writeInline(w, a, b, ops, cfg.palette)
+		}
+	case "context":
+		err = writeContext(w, nameA, nameB, a, b, hunks, cfg.palette)
+	case "sidebyside":
+		if differ {
+			err = writeSideBySide(w, a, b, ops, cfg.palette)
+		}
+	case "json":
+		if differ {
+			err = writeJSON(w, nameA, nameB, a, b, ops)
+		}
+	case "html":
+		if differ {
+			err = writeHTML(w, cfg, nameA, nameB, a, b, ops, hunks)
+		}
+	case "report":
+		if differ {
+			cfg.report = append(cfg.report, htmlreport.File{NameA: nameA, NameB: nameB, A: a, B: b, Ops: ops})
+		}
+	default:
+		err = writeUnified(w, nameA, nameB, a, b, hunks, cfg.palette)
+	}
+	return differ, err
+}
+
+// showInvisibles returns tokens with invisible characters made visible,
+// for display.
+func showInvisibles(tokens []string) []string {
+	shown := make([]string, len(tokens))
+	for i, t := range tokens {
+		shown[i] = diffx.ShowInvisibles(t)
+	}
+	return shown
+}
+
+// truncateLines returns tokens shortened to at most n bytes, for display.
+func truncateLines(tokens []string, n int) []string {
+	truncated := make([]string, len(tokens))
+	for i, t := range tokens {
+		truncated[i] = diffx.TruncateLine(t, n)
+	}
+	return truncated
+}
+
+// expandTabs returns tokens with tabs expanded to spaces at tab stops
+// every width columns, for display. Columns restart at each token, so
+// stops line up only for line granularity.
+func expandTabs(tokens []string, width int) []string {
+	expanded := make([]string, len(tokens))
+	for i, t := range tokens {
+		expanded[i] = diffx.ExpandTabs(t, width)
+	}
+	return expanded
+}
+
+// diffTexts splits two texts into the configured units and diffs them.
+func diffTexts(cfg *config, textA, textB string) (a, b []string, ops []diffx.DiffOp) {
+	if cfg.granularity == "line" {
+		a, b = splitLines(textA), splitLines(textB)
+	} else {
+		a, b = tokenize(textA, cfg.granularity), tokenize(textB, cfg.granularity)
+	}
+	return a, b, diffTokens(cfg, a, b)
+}
+
+// differs reports whether ops contains a change that is not ignored by
+// -ignore-matching-lines.
+func differs(cfg *config, a, b []string, ops []diffx.DiffOp) bool {
+	return hasChanges(ops) && !onlyIgnoredChanges(cfg, a, b, ops)
+}
+
+// visibleHunks groups ops into hunks for output, leaving out hunks whose
+// changes are all ignored by -ignore-matching-lines, as GNU diff -I does.
+// Ignored changes in a hunk that also has other changes are still shown.
+func visibleHunks(cfg *config, a, b []string, ops []diffx.DiffOp) []hunk {
+	hunks := buildHunks(ops, cfg.context)
+	if len(cfg.ignoreLines) == 0 {
+		return hunks
+	}
+	visible := hunks[:0]
+	for _, h := range hunks {
+		if !onlyIgnoredChanges(cfg, a, b, h.ops) {
+			visible = append(visible, h)
+		}
+	}
+	return visible
+}
+
+// onlyIgnoredChanges reports whether every deleted and inserted line in
+// ops matches an -ignore-matching-lines pattern.
+func onlyIgnoredChanges(cfg *config, a, b []string, ops []diffx.DiffOp) bool {
+	if len(cfg.ignoreLines) == 0 {
+		return false
+	}
+	for _, op := range ops {
+		var lines []string
+		switch op.Type {
+		case diffx.Delete:
+			lines = a[op.AStart:op.AEnd]
+		case diffx.Insert:
+			lines = b[op.BStart:op.BEnd]
+		}
+		for _, line := range lines {
+			if !cfg.ignoredLine(strings.TrimSuffix(line, "\n")) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasChanges reports whether ops contains a change.
+func hasChanges(ops []diffx.DiffOp) bool {
+	for _, op := range ops {
+		if op.Type != diffx.Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// diffTokens runs the configured algorithm on a and b, comparing them as
+// the ignore options direct.
+func diffTokens(cfg *config, a, b []string) []diffx.DiffOp {
+	opts := diffOptions(cfg)
+	if cfg.algorithm == "histogram" {
+		return diffx.DiffHistogram(a, b, opts...)
+	}
+	return diffx.Diff(a, b, opts...)
+}
+
+// diffOptions returns the library options for the configured comparison.
+func diffOptions(cfg *config) []diffx.Option {
+	opts := []diffx.Option{
+		diffx.WithMinimal(cfg.minimal),
+		diffx.WithIgnoreCase(cfg.ignoreCase),
+		diffx.WithIgnoreAllSpace(cfg.ignoreAllSpace),
+		diffx.WithIgnoreSpaceChange(cfg.ignoreSpaceChange),
+		diffx.WithIgnoreTrailingSpace(cfg.ignoreTrailing),
+		diffx.WithNormalizeEOL(cfg.stripTrailingCR),
+		diffx.WithNormalizeReferences(cfg.normalizeRefs),
+		diffx.WithIgnoreMarkup(cfg.ignoreMarkup),
+		diffx.WithNormalizePunctuation(cfg.normalizePunct),
+		diffx.WithMaskLogs(cfg.maskLogs),
+	}
+	if cfg.ignoreTabs {
+		opts = append(opts, diffx.WithExpandTabs(cfg.tabSize))
+	}
+	return opts
+}
+
+// splitLines splits text into lines, keeping each line's terminator so
+// that a missing final newline is a difference.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// tokenize splits text into words and runs of white space or punctuation
+// for word granularity, into characters for char granularity, or into
+// grapheme clusters for grapheme granularity. Concatenating the tokens
+// reproduces the text.
+func tokenize(text, granularity string) []string {
+	switch granularity {
+	case "grapheme":
+		return diffx.SplitGraphemes(text)
+	case "char":
+		tokens := make([]string, 0, utf8.RuneCountInString(text))
+		for _, r := range text {
+			tokens = append(tokens, string(r))
+		}
+		return tokens
+	default:
+		return diffx.SplitWords(text)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+	"github.com/dacharyc/diffx/compress"
+)
+
+// runDirs compares two directory trees and returns the exit code. Output
+// follows GNU diff -r: files present on one side are reported with "Only
+// in", and each modified file is preceded by a "diff -r" line. Structured
+// formats instead show added and removed files as diffs against /dev/null.
+func runDirs(cfg *config, dirA, dirB string, stdout, stderr io.Writer) int {
+	diffs, err := diffx.DiffDirs(dirA, dirB, diffx.WithExclude(cfg.exclude...), compress.WithDecompress(cfg.decompress))
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+
+	code := exitSame
+	for _, fd := range diffs {
+		pathA := filepath.Join(dirA, filepath.FromSlash(fd.Path))
+		pathB := filepath.Join(dirB, filepath.FromSlash(fd.Path))
+
+		if cfg.stats != nil {
+			if collectDirStat(cfg, fd) {
+				code = exitDiffer
+			}
+			continue
+		}
+
+		if cfg.structured() {
+			if fd.Status == diffx.FileAdded {
+				pathA = devNull
+			}
+			if fd.Status == diffx.FileRemoved {
+				pathB = devNull
+			}
+		}
+
+		switch {
+		case fd.Status == diffx.FileAdded && !cfg.structured():
+			fmt.Fprintf(stdout, "Only in %s: %s\n", onlyInDir(dirB, fd.Path), path.Base(fd.Path))
+			code = exitDiffer
+		case fd.Status == diffx.FileRemoved && !cfg.structured():
+			fmt.Fprintf(stdout, "Only in %s: %s\n", onlyInDir(dirA, fd.Path), path.Base(fd.Path))
+			code = exitDiffer
+		case fd.Binary:
+			if err := writeBinary(stdout, cfg, pathA, pathB); err != nil {
+				fmt.Fprintf(stderr, "diffx: %v\n", err)
+				return exitTrouble
+			}
+			code = exitDiffer
+		default:
+			var buf bytes.Buffer
+			differ, err := compare(&buf, cfg, pathA, pathB, strings.Join(fd.A, ""), strings.Join(fd.B, ""))
+			if err != nil {
+				fmt.Fprintf(stderr, "diffx: %v\n", err)
+				return exitTrouble
+			}
+			if !differ {
+				continue
+			}
+			if !cfg.brief && !cfg.structured() {
+				header := fmt.Sprintf("diff -r %s %s", pathA, pathB)
+				fmt.Fprintln(stdout, cfg.palette.paint(cfg.palette.meta, header))
+			}
+			stdout.Write(buf.Bytes())
+			code = exitDiffer
+		}
+	}
+	return code
+}
+
+// collectDirStat records the summary of a file for -stat or -numstat and
+// reports whether the file differs.
+func collectDirStat(cfg *config, fd diffx.FileDiff) bool {
+	if fd.Binary {
+		cfg.stats.addBinary(fd.Path)
+		return true
+	}
+
+	ops := fd.Ops
+	if fd.Status == diffx.FileModified {
+		// Re-diff with the command's granularity and ignore options
+		var a, b []string
+		a, b, ops = diffTexts(cfg, strings.Join(fd.A, ""), strings.Join(fd.B, ""))
+		if !differs(cfg, a, b, ops) {
+			return false
+		}
+	}
+	cfg.stats.add(fd.Path, diffx.Stat(ops))
+	return true
+}
+
+// onlyInDir returns the directory containing rel under root, for "Only in"
+// messages.
+func onlyInDir(root, rel string) string {
+	return filepath.Join(root, filepath.FromSlash(path.Dir(rel)))
+}
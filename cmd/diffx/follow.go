@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/dacharyc/diffx"
+)
+
+// runFollowSignals runs -follow until interrupted.
+func runFollowSignals(cfg *config, nameA, nameB string, stdin io.Reader, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return runFollow(ctx, cfg, nameA, nameB, stdin, stdout, stderr)
+}
+
+// runFollow watches nameB and prints the hunks of its diff against nameA
+// that are new since the file last changed, until ctx is done. The exit
+// code is exitDiffer if any hunk was printed.
+func runFollow(ctx context.Context, cfg *config, nameA, nameB string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if nameB == "-" {
+		fmt.Fprintln(stderr, "diffx: cannot follow standard input")
+		return exitTrouble
+	}
+	textA, err := readInput(nameA, stdin, cfg.decompress)
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+	a := splitLines(textA)
+
+	labelA, labelB := nameA, nameB
+	if len(cfg.labels) > 0 {
+		labelA = cfg.labels[0]
+	}
+	if len(cfg.labels) > 1 {
+		labelB = cfg.labels[1]
+	}
+
+	code := exitSame
+	err = diffx.Follow(ctx, nameB, a, cfg.followInterval, func(u diffx.FollowUpdate) error {
+		var hunks []hunk
+		for _, h := range followHunks(u.New) {
+			if !onlyIgnoredChanges(cfg, a, u.Lines, h.ops) {
+				hunks = append(hunks, h)
+			}
+		}
+		if len(hunks) == 0 {
+			return nil
+		}
+		code = exitDiffer
+		return writeUnified(stdout, labelA, labelB, a, u.Lines, hunks, cfg.palette)
+	}, diffOptions(cfg)...)
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+	return code
+}
+
+// followHunks groups the new changes of a followed file into hunks without
+// context, pairing a deletion with the insertion that replaces it.
+func followHunks(ops []diffx.DiffOp) []hunk {
+	var hunks []hunk
+	for i := 0; i < len(ops); i++ {
+		h := hunk{}
+		h.add(ops[i])
+		if op := ops[i]; op.Type == diffx.Delete && i+1 < len(ops) && ops[i+1].Type == diffx.Insert && ops[i+1].AStart == op.AEnd {
+			h.add(ops[i+1])
+			h.bStart = ops[i+1].BStart
+			i++
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dacharyc/diffx"
+	"github.com/dacharyc/diffx/htmlreport"
+)
+
+// Output formats other than unified and inline.
+//
+// context and sidebyside follow GNU diff -c and diff -y, for people who
+// prefer those layouts. json and html are "structured" formats: they are
+// meant to be consumed whole, so the plain-text headers used in directory
+// and git mode are left out and files that exist on only one side are
+// shown as a diff against empty content.
+
+// sideBySideWidth is the total width of side-by-side output, as in GNU
+// diff's default.
+const sideBySideWidth = 130
+
+// tabWidth is the tab stop used when aligning side-by-side columns.
+const tabWidth = 8
+
+// writeContext writes hunks in context format. Nothing is written if there
+// are no hunks.
+func writeContext(w io.Writer, nameA, nameB string, a, b []string, hunks []hunk, pal palette) error {
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(pal.paint(pal.meta, "*** "+nameA) + "\n")
+	bw.WriteString(pal.paint(pal.meta, "--- "+nameB) + "\n")
+	for _, h := range hunks {
+		marks := contextMarks(h.ops)
+		hasDel, hasIns := false, false
+		for _, op := range h.ops {
+			hasDel = hasDel || op.Type == diffx.Delete
+			hasIns = hasIns || op.Type == diffx.Insert
+		}
+
+		bw.WriteString("***************\n")
+		bw.WriteString(pal.paint(pal.frag, "*** "+contextRange(h.aStart, h.aEnd)+" ****") + "\n")
+		if hasDel {
+			for i, op := range h.ops {
+				switch op.Type {
+				case diffx.Equal:
+					writeLines(bw, "  ", a[op.AStart:op.AEnd], "", pal)
+				case diffx.Delete:
+					writeLines(bw, marks[i]+" ", a[op.AStart:op.AEnd], pal.old, pal)
+				}
+			}
+		}
+		bw.WriteString(pal.paint(pal.frag, "--- "+contextRange(h.bStart, h.bEnd)+" ----") + "\n")
+		if hasIns {
+			for i, op := range h.ops {
+				switch op.Type {
+				case diffx.Equal:
+					writeLines(bw, "  ", b[op.BStart:op.BEnd], "", pal)
+				case diffx.Insert:
+					writeLines(bw, marks[i]+" ", b[op.BStart:op.BEnd], pal.new, pal)
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// contextMarks returns the line prefix for each operation: "!" for
+// deletions and insertions that replace each other, and "-" or "+" for
+// changes on one side only.
+func contextMarks(ops []diffx.DiffOp) []string {
+	marks := make([]string, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].Type == diffx.Equal {
+			i++
+			continue
+		}
+		j := i
+		hasDel, hasIns := false, false
+		for ; j < len(ops) && ops[j].Type != diffx.Equal; j++ {
+			hasDel = hasDel || ops[j].Type == diffx.Delete
+			hasIns = hasIns || ops[j].Type == diffx.Insert
+		}
+		for k := i; k < j; k++ {
+			switch {
+			case hasDel && hasIns:
+				marks[k] = "!"
+			case ops[k].Type == diffx.Delete:
+				marks[k] = "-"
+			default:
+				marks[k] = "+"
+			}
+		}
+		i = j
+	}
+	return marks
+}
+
+// contextRange formats a line range for a context hunk header the way GNU
+// diff does: first and last line, a single line as its number, and an
+// empty range as the line before it.
+func contextRange(start, end int) string {
+	if end-start > 1 {
+		return fmt.Sprintf("%d,%d", start+1, end)
+	}
+	return fmt.Sprintf("%d", end)
+}
+
+// writeSideBySide writes every line of both files in two columns, as in
+// GNU diff -y. The gutter shows "|" for changed lines, "<" for deleted
+// lines, and ">" for inserted lines.
+func writeSideBySide(w io.Writer, a, b []string, ops []diffx.DiffOp, pal palette) error {
+	col := (sideBySideWidth - 3) / 2
+	bw := bufio.NewWriter(w)
+	row := func(left, right string, mark byte, color string) {
+		line := padColumn(left, col) + " " + string(mark)
+		if right != "" {
+			line += " " + truncateColumn(right, col)
+		} else if mark == ' ' {
+			line = strings.TrimRight(line, " ")
+		}
+		bw.WriteString(pal.paint(color, line) + "\n")
+	}
+
+	for i := 0; i < len(ops); {
+		op := ops[i]
+		if op.Type == diffx.Equal {
+			for k := 0; k < op.AEnd-op.AStart; k++ {
+				row(lineText(a[op.AStart+k]), lineText(b[op.BStart+k]), ' ', "")
+			}
+			i++
+			continue
+		}
+
+		// Pair the deleted and inserted lines of a change region
+		var dels, ins []string
+		for ; i < len(ops) && ops[i].Type != diffx.Equal; i++ {
+			if ops[i].Type == diffx.Delete {
+				dels = append(dels, a[ops[i].AStart:ops[i].AEnd]...)
+			} else {
+				ins = append(ins, b[ops[i].BStart:ops[i].BEnd]...)
+			}
+		}
+		n := min(len(dels), len(ins))
+		for k := 0; k < n; k++ {
+			row(lineText(dels[k]), lineText(ins[k]), '|', "")
+		}
+		for _, line := range dels[n:] {
+			row(lineText(line), "", '<', pal.old)
+		}
+		for _, line := range ins[n:] {
+			row("", lineText(line), '>', pal.new)
+		}
+	}
+	return bw.Flush()
+}
+
+// lineText returns line without its newline, with tabs expanded.
+func lineText(line string) string {
+	line = strings.TrimSuffix(line, "\n")
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var sb strings.Builder
+	n := 0
+	for _, r := range line {
+		if r == '\t' {
+			pad := tabWidth - n%tabWidth
+			sb.WriteString(strings.Repeat(" ", pad))
+			n += pad
+			continue
+		}
+		sb.WriteRune(r)
+		n++
+	}
+	return sb.String()
+}
+
+// truncateColumn cuts s to at most width characters.
+func truncateColumn(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// padColumn cuts or pads s to exactly width characters.
+func padColumn(s string, width int) string {
+	s = truncateColumn(s, width)
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// jsonDiff is the JSON form of one file comparison.
+type jsonDiff struct {
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Binary bool     `json:"binary,omitempty"`
+	Ops    []jsonOp `json:"ops,omitempty"`
+}
+
+// jsonOp is the JSON form of a diffx.DiffOp, with the text it covers.
+type jsonOp struct {
+	Op     string `json:"op"`
+	AStart int    `json:"aStart"`
+	AEnd   int    `json:"aEnd"`
+	BStart int    `json:"bStart"`
+	BEnd   int    `json:"bEnd"`
+	Text   string `json:"text"`
+}
+
+// writeJSON writes the diff as a single line of JSON listing every
+// operation, including unchanged text, so that B can be rebuilt from the
+// output. Comparing directories produces one line per changed file.
+func writeJSON(w io.Writer, nameA, nameB string, a, b []string, ops []diffx.DiffOp) error {
+	d := jsonDiff{From: nameA, To: nameB, Ops: make([]jsonOp, len(ops))}
+	for i, op := range ops {
+		text := a[op.AStart:op.AEnd]
+		if op.Type == diffx.Insert {
+			text = b[op.BStart:op.BEnd]
+		}
+		d.Ops[i] = jsonOp{
+			Op:     strings.ToLower(op.Type.String()),
+			AStart: op.AStart,
+			AEnd:   op.AEnd,
+			BStart: op.BStart,
+			BEnd:   op.BEnd,
+			Text:   strings.Join(text, ""),
+		}
+	}
+	return encodeJSON(w, d)
+}
+
+// encodeJSON writes v as a line of JSON without escaping HTML characters.
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// htmlHeader and htmlFooter enclose html output in a standalone document.
+const (
+	htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>diffx</title>
+<style>
+.diffx-file { margin-bottom: 1.5em; font-family: monospace; }
+.diffx-header { font-weight: bold; white-space: pre; }
+.diffx-hunk { color: #0550ae; background: #ddf4ff; }
+table.diffx { border-collapse: collapse; width: 100%; }
+table.diffx td { padding: 0 0.5em; white-space: pre-wrap; vertical-align: top; }
+table.diffx td.ln { color: #6e7781; text-align: right; user-select: none; }
+tr.delete, del { background: #ffebe9; }
+tr.insert, ins { background: #dafbe1; }
+ins, del { text-decoration: none; }
+pre.diffx { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+`
+	htmlFooter = `</body>
+</html>
+`
+)
+
+// writeHTML writes the diff as an HTML fragment: a table of hunks with
+// line numbers for line granularity, and running text with <del> and <ins>
+// otherwise.
+func writeHTML(w io.Writer, cfg *config, nameA, nameB string, a, b []string, ops []diffx.DiffOp, hunks []hunk) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<div class="diffx-file">` + "\n")
+	fmt.Fprintf(bw, `<div class="diffx-header">--- %s`+"\n"+`+++ %s</div>`+"\n", html.EscapeString(nameA), html.EscapeString(nameB))
+
+	if cfg.granularity != "line" {
+		bw.WriteString(`<pre class="diffx">`)
+		for _, op := range ops {
+			switch op.Type {
+			case diffx.Equal:
+				bw.WriteString(html.EscapeString(strings.Join(a[op.AStart:op.AEnd], "")))
+			case diffx.Delete:
+				bw.WriteString("<del>" + html.EscapeString(strings.Join(a[op.AStart:op.AEnd], "")) + "</del>")
+			case diffx.Insert:
+				bw.WriteString("<ins>" + html.EscapeString(strings.Join(b[op.BStart:op.BEnd], "")) + "</ins>")
+			}
+		}
+		bw.WriteString("</pre>\n</div>\n")
+		return bw.Flush()
+	}
+
+	bw.WriteString(`<table class="diffx">` + "\n")
+	for _, h := range hunks {
+		header := fmt.Sprintf("@@ -%s +%s @@", unifiedRange(h.aStart, h.aEnd), unifiedRange(h.bStart, h.bEnd))
+		fmt.Fprintf(bw, `<tr class="diffx-hunk"><td colspan="3">%s</td></tr>`+"\n", header)
+		for _, op := range h.ops {
+			switch op.Type {
+			case diffx.Equal:
+				for k := 0; k < op.AEnd-op.AStart; k++ {
+					writeHTMLRow(bw, "equal", op.AStart+k+1, op.BStart+k+1, a[op.AStart+k])
+				}
+			case diffx.Delete:
+				for i := op.AStart; i < op.AEnd; i++ {
+					writeHTMLRow(bw, "delete", i+1, 0, a[i])
+				}
+			case diffx.Insert:
+				for j := op.BStart; j < op.BEnd; j++ {
+					writeHTMLRow(bw, "insert", 0, j+1, b[j])
+				}
+			}
+		}
+	}
+	bw.WriteString("</table>\n</div>\n")
+	return bw.Flush()
+}
+
+// writeHTMLRow writes one line of an HTML hunk. A line number of 0 leaves
+// its cell empty.
+func writeHTMLRow(w *bufio.Writer, class string, lineA, lineB int, text string) {
+	num := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return fmt.Sprint(n)
+	}
+	fmt.Fprintf(w, `<tr class="%s"><td class="ln">%s</td><td class="ln">%s</td><td>%s</td></tr>`+"\n",
+		class, num(lineA), num(lineB), html.EscapeString(strings.TrimSuffix(text, "\n")))
+}
+
+// writeBinary reports that two binary files differ in the configured
+// format.
+func writeBinary(w io.Writer, cfg *config, nameA, nameB string) error {
+	var err error
+	switch cfg.format {
+	case "json":
+		err = encodeJSON(w, jsonDiff{From: nameA, To: nameB, Binary: true})
+	case "html":
+		_, err = fmt.Fprintf(w, `<div class="diffx-file"><div class="diffx-header">Binary files %s and %s differ</div></div>`+"\n",
+			html.EscapeString(nameA), html.EscapeString(nameB))
+	case "report":
+		cfg.report = append(cfg.report, htmlreport.File{NameA: nameA, NameB: nameB, Binary: true})
+	default:
+		_, err = fmt.Fprintf(w, "Binary files %s and %s differ\n", nameA, nameB)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// devNull is the name git uses for the missing side of an added or deleted
+// file.
+const devNull = "/dev/null"
+
+// runGit handles git's external diff invocation:
+//
+//	path old-file old-hex old-mode new-file new-hex new-mode [new-path xfrm-msg]
+//
+// It always exits 0 unless there was trouble, because git aborts the diff
+// when the driver exits non-zero.
+func runGit(cfg *config, args []string, stdout, stderr io.Writer) int {
+	oldPath, newPath := args[0], args[0]
+	if len(args) == 9 {
+		newPath = args[7]
+	}
+	oldFile, newFile := args[1], args[4]
+
+	textA, err := readInput(oldFile, nil, cfg.decompress)
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+	textB, err := readInput(newFile, nil, cfg.decompress)
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+
+	labelA, labelB := "a/"+oldPath, "b/"+newPath
+	if oldFile == devNull {
+		labelA = devNull
+	}
+	if newFile == devNull {
+		labelB = devNull
+	}
+
+	var buf bytes.Buffer
+	differ, err := compare(&buf, cfg, labelA, labelB, textA, textB)
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+	if differ && !cfg.brief && cfg.stats == nil && !cfg.structured() {
+		header := fmt.Sprintf("diff --git a/%s b/%s", oldPath, newPath)
+		fmt.Fprintln(stdout, cfg.palette.paint(cfg.palette.meta, header))
+	}
+	stdout.Write(buf.Bytes())
+	return exitSame
+}
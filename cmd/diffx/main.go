@@ -0,0 +1,501 @@
+// Command diffx compares two files using the diffx library.
+//
+// Usage:
+//
+//	diffx [flags] FILE1 FILE2
+//	diffx -r [flags] DIR1 DIR2
+//	diffx -follow [flags] BASELINE FILE
+//	diffx [flags] PATH OLD-FILE OLD-HEX OLD-MODE NEW-FILE NEW-HEX NEW-MODE
+//
+// Either file may be "-" to read standard input, and either may be a pipe,
+// so one side can come from a command:
+//
+//	git show HEAD:README.md | diffx -label a/README.md - README.md
+//	diffx <(sort old.txt) <(sort new.txt)
+//
+// By default the files are compared line by line and printed as a unified
+// diff. With -granularity=word or -granularity=char, the files are
+// compared as streams of words or characters and printed inline, with
+// deletions in [-brackets-] and insertions in {+braces+}.
+// -granularity=grapheme compares user-perceived characters, so that
+// combining accents and emoji sequences are never split.
+//
+// The -format flag selects another rendering: context and sidebyside, as
+// in GNU diff -c and -y; json, one object per compared file listing every
+// operation and the text it covers; html, a standalone page; and report, a
+// self-contained page with a side-by-side view of every changed file,
+// collapsible unchanged regions, and hunk navigation, suitable as a CI
+// artifact. Unified, context, side-by-side, and report output require line
+// granularity.
+//
+// Flags:
+//
+//	-algorithm string     diff algorithm: myers or histogram (default "myers")
+//	-granularity string   unit of comparison: line, word, char, or grapheme (default "line")
+//	-format string        output format: unified, context, sidebyside, inline, json,
+//	                      html, or report (default depends on granularity)
+//	-U, -unified int      lines of context in unified output (default 3)
+//	-L, -label label      use label instead of the file name in headers
+//	                      (repeatable: first for FILE1, then for FILE2)
+//	-minimal              find a minimal diff, at the cost of speed
+//	-i, -ignore-case      ignore case differences
+//	-w, -ignore-all-space ignore all white space
+//	-b, -ignore-space-change
+//	                      ignore changes in the amount of white space
+//	-Z, -ignore-trailing-space
+//	                      ignore white space at line end
+//	-I, -ignore-matching-lines regexp
+//	                      ignore changes whose lines all match regexp (repeatable)
+//	-E, -ignore-tab-expansion
+//	                      ignore changes due to tab expansion
+//	-strip-trailing-cr    treat CRLF, LF, and CR line endings as equal
+//	-ignore-markup        ignore inline emphasis, strikethrough, and code markers
+//	-normalize-punctuation
+//	                      treat curly and straight quotes, dashes and hyphens,
+//	                      and "…" and "..." as equal
+//	-mask-logs            ignore timestamps, request and process IDs, and
+//	                      durations in log lines
+//	-normalize-references compare Markdown and reStructuredText reference links
+//	                      and footnotes by target, ignoring renumbered labels
+//	-t, -expand-tabs      expand tabs to spaces in the output
+//	-tabsize int          columns between tab stops (default 8)
+//	-max-line-length int  shorten output lines longer than int bytes (default 0, no limit)
+//	-show-invisibles      show tabs, carriage returns, trailing spaces, and
+//	                      other invisible characters in the output
+//	-q, -brief            report only whether the files differ
+//	-r, -recursive        compare directories recursively
+//	-x, -exclude pattern  skip files and directories matching pattern (repeatable)
+//	-decompress           compare the content of gzip and zstd files
+//	-git                  accept git's external diff arguments
+//	-color when           color output: always, never, or auto (default "auto")
+//	-no-pager             do not pipe output through a pager
+//	-stat                 print a histogram of changes per file
+//	-numstat              print insertion and deletion counts per file
+//	-follow               watch FILE and print new changes against BASELINE
+//	-follow-interval duration
+//	                      how often -follow checks FILE (default 1s)
+//
+// With -color=auto, output is colored when standard output is a terminal,
+// the NO_COLOR environment variable is not set, and TERM is not "dumb".
+// When standard output is a terminal, output is piped through the pager
+// named by DIFFX_PAGER or PAGER (default "less"). As in git, LESS defaults
+// to FRX so that short output is printed directly and colors pass through.
+//
+// # Following a file
+//
+// With -follow, diffx watches a file that a process is writing or
+// regenerating and compares it with a baseline each time it changes,
+// until interrupted. Each time, only the changes that were not there at
+// the previous check are printed, as a unified diff without context
+// against the baseline, so a growing log shows its new lines and an
+// edited artifact its new edits:
+//
+//	diffx -follow -mask-logs golden.log build.log
+//
+// The exit status is 1 if any change was printed.
+//
+// # Git integration
+//
+// diffx can be used as git's external diff driver, for example:
+//
+//	git config diff.external 'diffx -granularity=word'
+//
+// Git runs the driver with seven arguments (nine for renames) describing
+// each changed file. diffx recognizes this form when -git is given or when
+// git's GIT_DIFF_PATH_COUNTER environment variable is set, prints a
+// "diff --git" header and labels the files a/PATH and b/PATH, and exits
+// with status 0 when the files differ, since git treats any other status
+// as a failure.
+//
+// As with GNU diff, the exit status is 0 if the inputs are the same, 1 if
+// they differ, and 2 if there was trouble.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dacharyc/diffx"
+	"github.com/dacharyc/diffx/compress"
+	"github.com/dacharyc/diffx/htmlreport"
+)
+
+// config holds the parsed command-line flags.
+type config struct {
+	algorithm         string
+	granularity       string
+	format            string
+	context           int
+	minimal           bool
+	ignoreCase        bool
+	ignoreAllSpace    bool
+	ignoreSpaceChange bool
+	ignoreTrailing    bool
+	ignoreTabs        bool
+	stripTrailingCR   bool
+	normalizeRefs     bool
+	ignoreMarkup      bool
+	normalizePunct    bool
+	maskLogs          bool
+	expandTabs        bool
+	tabSize           int
+	maxLineLength     int
+	showInvisibles    bool
+	ignorePatterns    stringList
+	brief             bool
+	recursive         bool
+	exclude           stringList
+	decompress        bool
+	labels            stringList
+	git               bool
+	color             string
+	noPager           bool
+	stat              bool
+	numstat           bool
+	follow            bool
+	followInterval    time.Duration
+
+	ignoreLines []*regexp.Regexp  // compiled from ignorePatterns
+	palette     palette           // derived from color and the output terminal
+	stats       *statCollector    // collects summaries for -stat and -numstat
+	report      []htmlreport.File // collects changed files for -format=report
+}
+
+// stringList is a flag that can be given more than once.
+type stringList []string
+
+// String returns the values joined with commas.
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set appends a value.
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// Exit codes, as in GNU diff.
+const (
+	exitSame    = 0
+	exitDiffer  = 1
+	exitTrouble = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cfg, files, err := parseFlags(args, stderr)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitSame
+		}
+		return exitTrouble
+	}
+
+	if useColor(cfg.color, stdout) && !cfg.structured() {
+		cfg.palette = ansiPalette
+	}
+	// Git runs its own pager around external diff drivers
+	if !cfg.noPager && !cfg.git && isTerminal(stdout) {
+		if pager, wait, err := startPager(stdout, stderr); err == nil {
+			defer wait()
+			stdout = pager
+		}
+	}
+
+	if cfg.stat || cfg.numstat {
+		cfg.stats = &statCollector{}
+	}
+
+	page := cfg.format == "html" && cfg.stats == nil && !cfg.brief
+	if page {
+		io.WriteString(stdout, htmlHeader)
+	}
+
+	var code int
+	switch {
+	case cfg.recursive:
+		code = runDirs(cfg, files[0], files[1], stdout, stderr)
+	case cfg.follow:
+		code = runFollowSignals(cfg, files[0], files[1], stdin, stdout, stderr)
+	case cfg.git:
+		code = runGit(cfg, files, stdout, stderr)
+	default:
+		code = runFiles(cfg, files[0], files[1], stdin, stdout, stderr)
+	}
+	if page {
+		io.WriteString(stdout, htmlFooter)
+	}
+	if cfg.format == "report" && cfg.stats == nil && !cfg.brief && code != exitTrouble {
+		if err := htmlreport.Write(stdout, cfg.report, htmlreport.WithContext(cfg.context)); err != nil {
+			fmt.Fprintf(stderr, "diffx: %v\n", err)
+			return exitTrouble
+		}
+	}
+	if code == exitTrouble || cfg.stats == nil {
+		return code
+	}
+
+	if cfg.numstat {
+		err = writeNumstat(stdout, cfg.stats.entries)
+	} else {
+		err = writeStat(stdout, cfg.stats.entries, cfg.palette)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+	return code
+}
+
+// runFiles compares two files and returns the exit code. Labels given
+// with -label replace the file names in the output.
+func runFiles(cfg *config, nameA, nameB string, stdin io.Reader, stdout, stderr io.Writer) int {
+	textA, textB, err := readInputs(nameA, nameB, stdin, cfg.decompress)
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+
+	if len(cfg.labels) > 0 {
+		nameA = cfg.labels[0]
+	}
+	if len(cfg.labels) > 1 {
+		nameB = cfg.labels[1]
+	}
+
+	differ, err := compare(stdout, cfg, nameA, nameB, textA, textB)
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return exitTrouble
+	}
+	if differ {
+		return exitDiffer
+	}
+	return exitSame
+}
+
+// parseFlags parses and validates the command line.
+func parseFlags(args []string, stderr io.Writer) (*config, []string, error) {
+	cfg := &config{}
+	fs := flag.NewFlagSet("diffx", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: diffx [flags] FILE1 FILE2")
+		fmt.Fprintln(fs.Output(), "       diffx -r [flags] DIR1 DIR2")
+		fmt.Fprintln(fs.Output(), "       diffx -follow [flags] BASELINE FILE")
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&cfg.algorithm, "algorithm", "myers", "diff algorithm: myers or histogram")
+	fs.StringVar(&cfg.granularity, "granularity", "line", "unit of comparison: line, word, char, or grapheme")
+	fs.StringVar(&cfg.format, "format", "", "output format: unified, context, sidebyside, inline, json, html, or report (default depends on granularity)")
+	fs.IntVar(&cfg.context, "unified", 3, "lines of context in unified output")
+	fs.IntVar(&cfg.context, "U", 3, "lines of context in unified output (shorthand)")
+	fs.Var(&cfg.labels, "label", "use `label` instead of the file name in headers (repeatable)")
+	fs.Var(&cfg.labels, "L", "use `label` instead of the file name in headers (shorthand)")
+	fs.BoolVar(&cfg.minimal, "minimal", false, "find a minimal diff, at the cost of speed")
+	fs.BoolVar(&cfg.ignoreCase, "ignore-case", false, "ignore case differences")
+	fs.BoolVar(&cfg.ignoreCase, "i", false, "ignore case differences (shorthand)")
+	fs.BoolVar(&cfg.ignoreAllSpace, "ignore-all-space", false, "ignore all white space")
+	fs.BoolVar(&cfg.ignoreAllSpace, "w", false, "ignore all white space (shorthand)")
+	fs.BoolVar(&cfg.ignoreSpaceChange, "ignore-space-change", false, "ignore changes in the amount of white space")
+	fs.BoolVar(&cfg.ignoreSpaceChange, "b", false, "ignore changes in the amount of white space (shorthand)")
+	fs.BoolVar(&cfg.ignoreTrailing, "ignore-trailing-space", false, "ignore white space at line end")
+	fs.BoolVar(&cfg.ignoreTrailing, "Z", false, "ignore white space at line end (shorthand)")
+	fs.BoolVar(&cfg.ignoreTabs, "ignore-tab-expansion", false, "ignore changes due to tab expansion")
+	fs.BoolVar(&cfg.ignoreTabs, "E", false, "ignore changes due to tab expansion (shorthand)")
+	fs.BoolVar(&cfg.stripTrailingCR, "strip-trailing-cr", false, "treat CRLF, LF, and CR line endings as equal")
+	fs.BoolVar(&cfg.ignoreMarkup, "ignore-markup", false, "ignore inline emphasis, strikethrough, and code markers")
+	fs.BoolVar(&cfg.normalizePunct, "normalize-punctuation", false, "treat curly and straight quotes, dashes and hyphens, and ellipses as equal")
+	fs.BoolVar(&cfg.maskLogs, "mask-logs", false, "ignore timestamps, request and process IDs, and durations in log lines")
+	fs.BoolVar(&cfg.normalizeRefs, "normalize-references", false, "compare reference links and footnotes by target, ignoring renumbered labels")
+	fs.BoolVar(&cfg.expandTabs, "expand-tabs", false, "expand tabs to spaces in the output")
+	fs.BoolVar(&cfg.expandTabs, "t", false, "expand tabs to spaces in the output (shorthand)")
+	fs.IntVar(&cfg.tabSize, "tabsize", 8, "columns between tab stops")
+	fs.IntVar(&cfg.maxLineLength, "max-line-length", 0, "shorten output lines longer than this many bytes (0 for no limit)")
+	fs.BoolVar(&cfg.showInvisibles, "show-invisibles", false, "show tabs, carriage returns, trailing spaces, and other invisible characters")
+	fs.Var(&cfg.ignorePatterns, "ignore-matching-lines", "ignore changes whose lines all match `regexp` (repeatable)")
+	fs.Var(&cfg.ignorePatterns, "I", "ignore changes whose lines all match `regexp` (shorthand)")
+	fs.BoolVar(&cfg.brief, "brief", false, "report only whether the files differ")
+	fs.BoolVar(&cfg.brief, "q", false, "report only whether the files differ (shorthand)")
+	fs.BoolVar(&cfg.recursive, "recursive", false, "compare directories recursively")
+	fs.BoolVar(&cfg.recursive, "r", false, "compare directories recursively (shorthand)")
+	fs.Var(&cfg.exclude, "exclude", "skip files and directories matching `pattern` (repeatable)")
+	fs.Var(&cfg.exclude, "x", "skip files and directories matching `pattern` (shorthand)")
+	fs.BoolVar(&cfg.decompress, "decompress", false, "compare the content of gzip and zstd files")
+	fs.BoolVar(&cfg.git, "git", false, "accept git's external diff arguments")
+	fs.StringVar(&cfg.color, "color", "auto", "color output: always, never, or auto")
+	fs.BoolVar(&cfg.noPager, "no-pager", false, "do not pipe output through a pager")
+	fs.BoolVar(&cfg.stat, "stat", false, "print a histogram of changes per file")
+	fs.BoolVar(&cfg.numstat, "numstat", false, "print insertion and deletion counts per file")
+	fs.BoolVar(&cfg.follow, "follow", false, "watch the second file and print new changes against the first")
+	fs.DurationVar(&cfg.followInterval, "follow-interval", diffx.DefaultFollowInterval, "how often -follow checks the file")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		return nil, nil, err
+	}
+	// Git runs external diff drivers with 7 arguments, or 9 for renames
+	n := fs.NArg()
+	if (n == 7 || n == 9) && os.Getenv("GIT_DIFF_PATH_COUNTER") != "" {
+		cfg.git = true
+	}
+	var err error
+	switch {
+	case cfg.git && n != 7 && n != 9:
+		err = fmt.Errorf("expected 7 or 9 git external diff arguments, got %d", n)
+	case !cfg.git && n != 2:
+		err = fmt.Errorf("expected two files, got %d", n)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "diffx: %v\n", err)
+		fs.Usage()
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// validate checks flag values and fills in defaults that depend on other
+// flags.
+func (c *config) validate() error {
+	switch c.algorithm {
+	case "myers", "histogram":
+	default:
+		return fmt.Errorf("unknown algorithm %q", c.algorithm)
+	}
+
+	switch c.granularity {
+	case "line", "word", "char", "grapheme":
+	default:
+		return fmt.Errorf("unknown granularity %q", c.granularity)
+	}
+
+	if c.tabSize <= 0 {
+		return fmt.Errorf("invalid tab size %d", c.tabSize)
+	}
+
+	if c.format == "" {
+		c.format = "unified"
+		if c.granularity != "line" {
+			c.format = "inline"
+		}
+	}
+	switch c.format {
+	case "unified", "context", "sidebyside", "report":
+		if c.granularity != "line" {
+			return fmt.Errorf("format %s requires line granularity", c.format)
+		}
+	case "inline", "json", "html":
+	default:
+		return fmt.Errorf("unknown format %q", c.format)
+	}
+
+	switch c.color {
+	case "always", "never", "auto":
+	default:
+		return fmt.Errorf("unknown color mode %q", c.color)
+	}
+
+	if c.context < 0 {
+		return fmt.Errorf("invalid context length %d", c.context)
+	}
+	if len(c.labels) > 2 {
+		return fmt.Errorf("too many labels: %d", len(c.labels))
+	}
+	if c.follow {
+		switch {
+		case c.format != "unified":
+			return fmt.Errorf("follow requires unified format")
+		case c.algorithm != "myers":
+			return fmt.Errorf("follow requires the myers algorithm")
+		case c.recursive || c.git || c.brief || c.stat || c.numstat:
+			return fmt.Errorf("follow cannot be combined with -recursive, -git, -brief, -stat, or -numstat")
+		}
+	}
+
+	if len(c.ignorePatterns) > 0 && c.granularity != "line" {
+		return fmt.Errorf("ignore-matching-lines requires line granularity")
+	}
+	for _, p := range c.ignorePatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid ignore-matching-lines pattern: %v", err)
+		}
+		c.ignoreLines = append(c.ignoreLines, re)
+	}
+	return nil
+}
+
+// ignoredLine reports whether line matches an -ignore-matching-lines
+// pattern.
+func (c *config) ignoredLine(line string) bool {
+	for _, re := range c.ignoreLines {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// structured reports whether the output format is meant to be parsed or
+// rendered as a whole, so plain-text headers and color must be left out.
+func (c *config) structured() bool {
+	return c.format == "json" || c.format == "html" || c.format == "report"
+}
+
+// readInputs reads both files. At most one of them may be "-".
+func readInputs(nameA, nameB string, stdin io.Reader, decompress bool) (string, string, error) {
+	if nameA == "-" && nameB == "-" {
+		return "", "", fmt.Errorf("cannot read standard input twice")
+	}
+	a, err := readInput(nameA, stdin, decompress)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := readInput(nameB, stdin, decompress)
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
+
+// readInput reads a file, or standard input for "-", transcoding UTF-16
+// and Latin-1 text to UTF-8. With decompress, gzip and zstd data is
+// decompressed first. /dev/null is read as empty on every platform, since
+// git passes it for added and deleted files.
+func readInput(name string, stdin io.Reader, decompress bool) (string, error) {
+	var data []byte
+	var err error
+	if name == devNull {
+		return "", nil
+	}
+	if name == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if decompress {
+		if data, _, err = compress.Decompress(data); err != nil {
+			return "", fmt.Errorf("decompress %s: %w", name, err)
+		}
+	}
+	text, _ := diffx.DecodeText(data)
+	return text, nil
+}
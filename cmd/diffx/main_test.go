@@ -0,0 +1,853 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dacharyc/diffx"
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeFiles writes two temporary files and returns their paths.
+func writeFiles(t *testing.T, a, b string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.txt")
+	pathB := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(pathA, []byte(a), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathB, []byte(b), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return pathA, pathB
+}
+
+// runDiffx runs the command and returns its exit code and output.
+func runDiffx(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Unified(t *testing.T) {
+	a, b := writeFiles(t, "one\ntwo\nthree\nfour\n", "one\n2\nthree\nfour\n")
+
+	_, out, errOut := runDiffx(t, "", "-U", "1", a, b)
+	if errOut != "" {
+		t.Fatalf("unexpected stderr: %s", errOut)
+	}
+
+	want := "--- " + a + "\n+++ " + b + "\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" one\n" +
+		"-two\n" +
+		"+2\n" +
+		" three\n"
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRun_NoNewlineAtEOF(t *testing.T) {
+	a, b := writeFiles(t, "x\ny", "x\ny\n")
+
+	_, out, _ := runDiffx(t, "", a, b)
+	if !strings.Contains(out, "-y\n\\ No newline at end of file\n+y\n") {
+		t.Errorf("missing no-newline marker:\n%s", out)
+	}
+}
+
+func TestRun_Identical(t *testing.T) {
+	a, b := writeFiles(t, "same\n", "same\n")
+
+	code, out, _ := runDiffx(t, "", a, b)
+	if code != 0 || out != "" {
+		t.Errorf("identical files: code %d, output %q", code, out)
+	}
+}
+
+func TestRun_WordGranularity(t *testing.T) {
+	a, b := writeFiles(t, "The quick brown fox jumps.\n", "The quick red fox leaps.\n")
+
+	_, out, _ := runDiffx(t, "", "-granularity", "word", a, b)
+	want := "The quick [-brown-]{+red+} fox [-jumps-]{+leaps+}.\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestRun_CharGranularity(t *testing.T) {
+	a, b := writeFiles(t, "color", "colour")
+
+	_, out, _ := runDiffx(t, "", "-granularity=char", a, b)
+	if out != "colo{+u+}r" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRun_GraphemeGranularity(t *testing.T) {
+	// Adding an accent changes the whole character, not just the mark
+	a, b := writeFiles(t, "cafe", "cafe\u0301")
+
+	_, out, _ := runDiffx(t, "", "-granularity=char", a, b)
+	if out != "cafe{+\u0301+}" {
+		t.Errorf("char: output = %q", out)
+	}
+	_, out, _ = runDiffx(t, "", "-granularity=grapheme", a, b)
+	if out != "caf[-e-]{+e\u0301+}" {
+		t.Errorf("grapheme: output = %q", out)
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	_, b := writeFiles(t, "", "a\nb\n")
+
+	_, out, _ := runDiffx(t, "a\n", "-", b)
+	if !strings.Contains(out, "--- -\n") || !strings.Contains(out, "+b\n") {
+		t.Errorf("output =\n%s", out)
+	}
+}
+
+func TestRun_IgnoreOptions(t *testing.T) {
+	a, b := writeFiles(t, "Hello World\n", "hello   world\n")
+
+	if _, out, _ := runDiffx(t, "", a, b); out == "" {
+		t.Error("expected a difference without ignore options")
+	}
+	if _, out, _ := runDiffx(t, "", "-ignore-case", "-ignore-all-space", a, b); out != "" {
+		t.Errorf("expected no difference, got\n%s", out)
+	}
+}
+
+func TestRun_StripTrailingCR(t *testing.T) {
+	a, b := writeFiles(t, "one\r\ntwo\r\n", "one\ntwo\n")
+
+	if code, _, _ := runDiffx(t, "", a, b); code != exitDiffer {
+		t.Errorf("without -strip-trailing-cr: code %d, want %d", code, exitDiffer)
+	}
+	if code, out, _ := runDiffx(t, "", "-strip-trailing-cr", a, b); code != exitSame || out != "" {
+		t.Errorf("-strip-trailing-cr: code %d, output\n%s", code, out)
+	}
+}
+
+func TestRun_ShowInvisibles(t *testing.T) {
+	a, b := writeFiles(t, "one\r\n\ttwo\n", "one\n\ttwo \n")
+
+	_, out, _ := runDiffx(t, "", "-show-invisibles", a, b)
+	for _, want := range []string{"-one^M\n", "+one\n", "-→two\n", "+→two·\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output =\n%s\nwant it to contain %q", out, want)
+		}
+	}
+
+	// JSON output keeps the text exact
+	_, out, _ = runDiffx(t, "", "-show-invisibles", "-format=json", a, b)
+	if strings.Contains(out, "^M") || strings.Contains(out, "→") {
+		t.Errorf("json output = %s, want the text unchanged", out)
+	}
+}
+
+func TestRun_Tabs(t *testing.T) {
+	a, b := writeFiles(t, "\tone\ntwo\n", "    one\nTWO\n")
+
+	if code, _, _ := runDiffx(t, "", "-E", a, b); code != exitDiffer {
+		t.Errorf("-E: code %d, want %d", code, exitDiffer)
+	}
+	_, out, _ := runDiffx(t, "", "-E", "-tabsize=4", a, b)
+	if !strings.Contains(out, "\n \tone\n-two\n+TWO\n") {
+		t.Errorf("-E -tabsize=4: output =\n%s\nwant only the second line changed", out)
+	}
+	if code, _, _ := runDiffx(t, "", "-E", "-tabsize=4", "-i", a, b); code != exitSame {
+		t.Errorf("-E -tabsize=4 -i: code %d, want %d", code, exitSame)
+	}
+
+	_, out, _ = runDiffx(t, "", "-t", "-tabsize=2", a, b)
+	if !strings.Contains(out, "-  one\n") || !strings.Contains(out, "+    one\n") {
+		t.Errorf("-t -tabsize=2: output =\n%s\nwant tabs expanded", out)
+	}
+
+	if code, _, _ := runDiffx(t, "", "-tabsize=0", a, b); code != exitTrouble {
+		t.Errorf("-tabsize=0: code %d, want %d", code, exitTrouble)
+	}
+}
+
+func TestRun_Decompress(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("one\ntwo\n"))
+	zw.Close()
+	a, b := writeFiles(t, buf.String(), "one\nTWO\n")
+
+	if code, out, _ := runDiffx(t, "", a, b); code != exitDiffer || strings.Contains(out, "-one") {
+		t.Errorf("without -decompress: code %d, output\n%s\nwant a binary difference", code, out)
+	}
+	code, out, _ := runDiffx(t, "", "-decompress", a, b)
+	if code != exitDiffer || !strings.Contains(out, "\n one\n-two\n+TWO\n") {
+		t.Errorf("-decompress: code %d, output\n%s\nwant only the second line changed", code, out)
+	}
+	if code, out, _ := runDiffx(t, "one\ntwo\n", "-decompress", a, "-"); code != exitSame {
+		t.Errorf("-decompress with stdin: code %d, output\n%s", code, out)
+	}
+
+	zstdW, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zstdW.Close()
+	c, _ := writeFiles(t, string(zstdW.EncodeAll([]byte("one\nTWO\n"), nil)), "")
+	if code, out, _ := runDiffx(t, "", "-decompress", a, c); code != exitDiffer || !strings.Contains(out, "\n one\n-two\n+TWO\n") {
+		t.Errorf("-decompress with zstd: code %d, output\n%s\nwant only the second line changed", code, out)
+	}
+}
+
+func TestRun_MaxLineLength(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	a, b := writeFiles(t, "short\n"+long+"1\n", "short\n"+long+"2\n")
+
+	_, out, _ := runDiffx(t, "", "-max-line-length=10", a, b)
+	for _, want := range []string{" short\n", "-xxxxxxxxxx… [91 more bytes]\n", "+xxxxxxxxxx… [91 more bytes]\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output =\n%s\nwant it to contain %q", out, want)
+		}
+	}
+
+	_, out, _ = runDiffx(t, "", "-max-line-length=10", "-format=json", a, b)
+	if !strings.Contains(out, long+"1") {
+		t.Errorf("json output = %s, want the lines unchanged", out)
+	}
+}
+
+func TestRun_NormalizeReferences(t *testing.T) {
+	a, b := writeFiles(t,
+		"See [docs][1].\n\n[1]: https://example.com\n",
+		"New [link][1].\nSee [docs][2].\n\n[1]: https://example.org\n[2]: https://example.com\n")
+
+	code, out, _ := runDiffx(t, "", "-normalize-references", a, b)
+	if code != exitDiffer || strings.Contains(out, "\n-") {
+		t.Errorf("code %d, output\n%s\nwant only insertions", code, out)
+	}
+	if _, out, _ := runDiffx(t, "", a, b); !strings.Contains(out, "\n-See [docs][1].\n") {
+		t.Errorf("without -normalize-references: output\n%s\nwant the renumbered line changed", out)
+	}
+}
+
+func TestRun_IgnoreShorthands(t *testing.T) {
+	a, b := writeFiles(t, "a  b\nX\n", "a b\nx\n")
+
+	if code, out, _ := runDiffx(t, "", "-b", "-i", a, b); code != exitSame || out != "" {
+		t.Errorf("-b -i: code %d, output\n%s", code, out)
+	}
+	if code, _, _ := runDiffx(t, "", "-b", a, b); code != exitDiffer {
+		t.Errorf("-b: code %d, want %d", code, exitDiffer)
+	}
+	if code, _, _ := runDiffx(t, "", "-w", "-i", a, b); code != exitSame {
+		t.Errorf("-w -i: code %d, want %d", code, exitSame)
+	}
+
+	a, b = writeFiles(t, "Run `make`.\n", "Run **make**.\n")
+	if code, _, _ := runDiffx(t, "", "-ignore-markup", a, b); code != exitSame {
+		t.Errorf("-ignore-markup: code %d, want %d", code, exitSame)
+	}
+
+	a, b = writeFiles(t, "\"Don't\" -- wait...\n", "“Don’t” — wait…\n")
+	if code, _, _ := runDiffx(t, "", "-normalize-punctuation", a, b); code != exitSame {
+		t.Errorf("-normalize-punctuation: code %d, want %d", code, exitSame)
+	}
+	if code, _, _ := runDiffx(t, "", a, b); code != exitDiffer {
+		t.Errorf("no flags: code %d, want %d", code, exitDiffer)
+	}
+
+	a, b = writeFiles(t, "one  \ntwo\n", "one\n two\n")
+	code, out, _ := runDiffx(t, "", "-Z", a, b)
+	if code != exitDiffer || !strings.Contains(out, "\n one  \n-two\n+ two\n") {
+		t.Errorf("-Z: code %d, output\n%s\nwant only the second line changed", code, out)
+	}
+}
+
+func TestRun_MaskLogs(t *testing.T) {
+	a, b := writeFiles(t,
+		"2024-05-01T12:00:00Z app[12]: start\n2024-05-01T12:00:01Z app[12]: request_id=a1 ok in 3ms\n",
+		"2024-06-11T08:30:00Z app[97]: start\n2024-06-11T08:30:04Z app[97]: request_id=b2 failed in 9ms\n")
+
+	code, out, _ := runDiffx(t, "", "-mask-logs", a, b)
+	if code != exitDiffer {
+		t.Fatalf("code %d, want %d", code, exitDiffer)
+	}
+	if !strings.Contains(out, "\n 2024-05-01T12:00:00Z app[12]: start\n-2024-05-01T12:00:01Z") {
+		t.Errorf("output\n%s\nwant only the second line changed, shown unmasked", out)
+	}
+}
+
+func TestRun_IgnoreMatchingLines(t *testing.T) {
+	a, b := writeFiles(t,
+		"// v1\none\ntwo\nthree\nfour\nfive\nsix\nseven\nlast\n",
+		"// v2\none\ntwo\nthree\nfour\nfive\nsix\nseven\nLAST\n")
+
+	code, out, _ := runDiffx(t, "", "-I", "^//", a, b)
+	if code != exitDiffer {
+		t.Errorf("code %d, want %d", code, exitDiffer)
+	}
+	if strings.Contains(out, "v1") || !strings.Contains(out, "-last\n+LAST\n") {
+		t.Errorf("output =\n%s", out)
+	}
+
+	code, out, _ = runDiffx(t, "", "-ignore-matching-lines=^//", "-i", a, b)
+	if code != exitSame || out != "" {
+		t.Errorf("all changes ignored: code %d, output\n%s", code, out)
+	}
+
+	code, _, errOut := runDiffx(t, "", "-I", "(", a, b)
+	if code != exitTrouble || !strings.Contains(errOut, "invalid ignore-matching-lines pattern") {
+		t.Errorf("bad pattern: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestRun_Histogram(t *testing.T) {
+	a, b := writeFiles(t, "a\nb\nc\n", "a\nc\n")
+
+	_, out, _ := runDiffx(t, "", "-algorithm=histogram", a, b)
+	if !strings.Contains(out, "-b\n") {
+		t.Errorf("output =\n%s", out)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	a, _ := writeFiles(t, "", "")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing file", []string{a, filepath.Join(t.TempDir(), "nope")}, "no such file"},
+		{"one argument", []string{a}, "usage"},
+		{"bad algorithm", []string{"-algorithm=patience", a, a}, "unknown algorithm"},
+		{"bad granularity", []string{"-granularity=sentence", a, a}, "unknown granularity"},
+		{"unified words", []string{"-granularity=word", "-format=unified", a, a}, "requires line granularity"},
+		{"stdin twice", []string{"-", "-"}, "standard input twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errOut := runDiffx(t, "", tt.args...)
+			if code == 0 || !strings.Contains(errOut, tt.want) {
+				t.Errorf("code %d, stderr %q, want failure mentioning %q", code, errOut, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHunks_MergesNearbyChanges(t *testing.T) {
+	a := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	b := []string{"1", "X", "3", "4", "5", "6", "7", "8", "Y", "10"}
+
+	ops := diffTokens(&config{algorithm: "myers"}, a, b)
+	if got := len(buildHunks(ops, 3)); got != 1 {
+		t.Errorf("context 3: %d hunks, want 1", got)
+	}
+	if got := len(buildHunks(ops, 1)); got != 2 {
+		t.Errorf("context 1: %d hunks, want 2", got)
+	}
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	a, b := writeFiles(t, "x\n", "y\n")
+
+	if code, _, _ := runDiffx(t, "", a, a); code != exitSame {
+		t.Errorf("same files: code %d, want %d", code, exitSame)
+	}
+	if code, _, _ := runDiffx(t, "", a, b); code != exitDiffer {
+		t.Errorf("different files: code %d, want %d", code, exitDiffer)
+	}
+	if code, _, _ := runDiffx(t, "", a, filepath.Join(t.TempDir(), "missing")); code != exitTrouble {
+		t.Errorf("missing file: code %d, want %d", code, exitTrouble)
+	}
+	if code, _, _ := runDiffx(t, "", "-algorithm=nope", a, b); code != exitTrouble {
+		t.Errorf("bad flag: code %d, want %d", code, exitTrouble)
+	}
+}
+
+func TestRun_Encodings(t *testing.T) {
+	// "one\ntwo\n" in UTF-16LE with a byte order mark, and "two" in Latin-1
+	utf16 := "\xff\xfeo\x00n\x00e\x00\n\x00t\x00w\x00o\x00\n\x00"
+	a, b := writeFiles(t, utf16, "one\ncaf\xe9\n")
+
+	_, out, _ := runDiffx(t, "", a, b)
+	if !strings.Contains(out, "-two\n+café\n") {
+		t.Errorf("output =\n%s\nwant two replaced by café", out)
+	}
+	same, _ := writeFiles(t, "one\ntwo\n", "")
+	if code, _, _ := runDiffx(t, "", a, same); code != exitSame {
+		t.Errorf("UTF-16 and UTF-8 versions of the same text: code %d, want %d", code, exitSame)
+	}
+}
+
+func TestRun_Brief(t *testing.T) {
+	a, b := writeFiles(t, "x\n", "y\n")
+
+	code, out, _ := runDiffx(t, "", "-q", a, b)
+	if code != exitDiffer || out != "Files "+a+" and "+b+" differ\n" {
+		t.Errorf("brief: code %d, output %q", code, out)
+	}
+
+	code, out, _ = runDiffx(t, "", "--brief", a, a)
+	if code != exitSame || out != "" {
+		t.Errorf("brief identical: code %d, output %q", code, out)
+	}
+}
+
+func TestRun_Recursive(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	for _, f := range []struct{ dir, name, content string }{
+		{dirA, "same.txt", "x\n"},
+		{dirB, "same.txt", "x\n"},
+		{dirA, "sub/changed.txt", "old\n"},
+		{dirB, "sub/changed.txt", "new\n"},
+		{dirA, "gone.txt", "1\n"},
+		{dirB, "new.txt", "2\n"},
+		{dirA, "skip.log", "a\n"},
+		{dirB, "skip.log", "b\n"},
+	} {
+		p := filepath.Join(f.dir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, out, errOut := runDiffx(t, "", "-r", "-x", "*.log", dirA, dirB)
+	if code != exitDiffer {
+		t.Fatalf("code %d, stderr %s", code, errOut)
+	}
+
+	changedA := filepath.Join(dirA, "sub", "changed.txt")
+	changedB := filepath.Join(dirB, "sub", "changed.txt")
+	for _, want := range []string{
+		"Only in " + dirA + ": gone.txt\n",
+		"Only in " + dirB + ": new.txt\n",
+		"diff -r " + changedA + " " + changedB + "\n",
+		"-old\n+new\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "skip.log") || strings.Contains(out, "same.txt") {
+		t.Errorf("output includes excluded or unchanged files:\n%s", out)
+	}
+}
+
+func TestRun_GitExternalDiff(t *testing.T) {
+	oldFile, newFile := writeFiles(t, "one\ntwo\n", "one\n2\n")
+	args := []string{"docs/x.txt", oldFile, "abc123", "100644", newFile, "def456", "100644"}
+
+	t.Setenv("GIT_DIFF_PATH_COUNTER", "1")
+	code, out, errOut := runDiffx(t, "", args...)
+	if code != 0 {
+		t.Fatalf("code %d, stderr %s", code, errOut)
+	}
+
+	want := "diff --git a/docs/x.txt b/docs/x.txt\n" +
+		"--- a/docs/x.txt\n" +
+		"+++ b/docs/x.txt\n"
+	if !strings.HasPrefix(out, want) {
+		t.Errorf("output =\n%s\nwant prefix\n%s", out, want)
+	}
+}
+
+func TestRun_GitExternalDiff_AddedAndRenamed(t *testing.T) {
+	_, newFile := writeFiles(t, "", "new\n")
+
+	code, out, _ := runDiffx(t, "", "-git", "n.txt", "/dev/null", ".", ".", newFile, "abc", "100644")
+	if code != 0 || !strings.Contains(out, "--- /dev/null\n+++ b/n.txt\n") {
+		t.Errorf("added file: code %d, output\n%s", code, out)
+	}
+
+	oldFile, newFile := writeFiles(t, "x\n", "y\n")
+	_, out, _ = runDiffx(t, "", "-git", "old.txt", oldFile, "a", "100644", newFile, "b", "100644",
+		"new.txt", "similarity index 50%\n")
+	if !strings.HasPrefix(out, "diff --git a/old.txt b/new.txt\n--- a/old.txt\n+++ b/new.txt\n") {
+		t.Errorf("renamed file output =\n%s", out)
+	}
+}
+
+func TestRun_GitExternalDiff_WrongArgs(t *testing.T) {
+	code, _, errOut := runDiffx(t, "", "-git", "a", "b")
+	if code != exitTrouble || !strings.Contains(errOut, "expected 7 or 9") {
+		t.Errorf("code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestRun_Color(t *testing.T) {
+	a, b := writeFiles(t, "x\n", "y\n")
+
+	_, out, _ := runDiffx(t, "", "-color=always", a, b)
+	for _, want := range []string{"\x1b[1m--- " + a + "\x1b[m\n", "\x1b[31m-x\x1b[m\n", "\x1b[32m+y\x1b[m\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("colored output missing %q:\n%q", want, out)
+		}
+	}
+
+	// Output to a buffer is not a terminal
+	if _, out, _ := runDiffx(t, "", "-color=auto", a, b); strings.Contains(out, "\x1b[") {
+		t.Errorf("auto color wrote escapes to a non-terminal: %q", out)
+	}
+	if _, out, _ := runDiffx(t, "", "-color=never", a, b); strings.Contains(out, "\x1b[") {
+		t.Errorf("color=never wrote escapes: %q", out)
+	}
+
+	if code, _, errOut := runDiffx(t, "", "-color=sometimes", a, b); code != exitTrouble || !strings.Contains(errOut, "unknown color mode") {
+		t.Errorf("bad color mode: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestRun_ColorInline(t *testing.T) {
+	a, b := writeFiles(t, "red fox", "blue fox")
+
+	_, out, _ := runDiffx(t, "", "-granularity=word", "-color=always", a, b)
+	if out != "\x1b[31mred\x1b[m\x1b[32mblue\x1b[m fox" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRun_Numstat(t *testing.T) {
+	a, b := writeFiles(t, "1\n2\n3\n", "1\nX\n3\n4\n")
+
+	code, out, _ := runDiffx(t, "", "-numstat", a, b)
+	if code != exitDiffer || out != "2\t1\t"+b+"\n" {
+		t.Errorf("code %d, output %q", code, out)
+	}
+}
+
+func TestRun_StatRecursive(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	files := map[string][2]string{
+		"a.txt": {"1\n2\n", "1\n2\n3\n"},
+		"b.txt": {"x\ny\n", "y\n"},
+		"c.bin": {"\x00", "\x01\x00"},
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dirA, name), []byte(content[0]), 0o644)
+		os.WriteFile(filepath.Join(dirB, name), []byte(content[1]), 0o644)
+	}
+
+	_, out, _ := runDiffx(t, "", "-r", "-stat", dirA, dirB)
+	want := " a.txt |   1 +\n" +
+		" b.txt |   1 -\n" +
+		" c.bin | Bin\n" +
+		" 3 files changed, 1 insertion(+), 1 deletion(-)\n"
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestWriteStat_Scales(t *testing.T) {
+	entries := []statEntry{
+		{name: "big.txt", stat: diffx.DiffStat{Insertions: 1000, Deletions: 500}},
+		{name: "small.txt", stat: diffx.DiffStat{Insertions: 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeStat(&buf, entries, palette{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if len(line) > statLineWidth {
+			t.Errorf("line exceeds %d columns: %q", statLineWidth, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "small.txt |    1 +\n") {
+		t.Errorf("small change should keep one marker:\n%s", buf.String())
+	}
+}
+
+func TestStatSummary(t *testing.T) {
+	tests := []struct {
+		files int
+		stat  diffx.DiffStat
+		want  string
+	}{
+		{1, diffx.DiffStat{Insertions: 1}, " 1 file changed, 1 insertion(+)"},
+		{2, diffx.DiffStat{Deletions: 3}, " 2 files changed, 3 deletions(-)"},
+		{2, diffx.DiffStat{Insertions: 2, Deletions: 1}, " 2 files changed, 2 insertions(+), 1 deletion(-)"},
+		{1, diffx.DiffStat{}, " 1 file changed, 0 insertions(+), 0 deletions(-)"},
+	}
+	for _, tt := range tests {
+		if got := statSummary(tt.files, tt.stat); got != tt.want {
+			t.Errorf("statSummary(%d, %+v) = %q, want %q", tt.files, tt.stat, got, tt.want)
+		}
+	}
+}
+
+func TestRun_ContextFormat(t *testing.T) {
+	a, b := writeFiles(t, "one\ntwo\nthree\nfour\nfive\n", "one\n2\nthree\nfour\nfive\nsix\n")
+
+	_, out, _ := runDiffx(t, "", "-format=context", "-U", "1", a, b)
+	want := "*** " + a + "\n--- " + b + "\n" +
+		"***************\n" +
+		"*** 1,3 ****\n" +
+		"  one\n" +
+		"! two\n" +
+		"  three\n" +
+		"--- 1,3 ----\n" +
+		"  one\n" +
+		"! 2\n" +
+		"  three\n" +
+		"***************\n" +
+		"*** 5 ****\n" +
+		"--- 5,6 ----\n" +
+		"  five\n" +
+		"+ six\n"
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRun_SideBySideFormat(t *testing.T) {
+	a, b := writeFiles(t, "one\ntwo\nthree\n", "one\n2\n")
+
+	_, out, _ := runDiffx(t, "", "-format=sidebyside", a, b)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), out)
+	}
+	col := (sideBySideWidth - 3) / 2
+	for i, want := range []struct {
+		left  string
+		mark  string
+		right string
+	}{
+		{"one", " ", "one"},
+		{"two", "|", "2"},
+		{"three", "<", ""},
+	} {
+		left, rest := lines[i][:col], lines[i][col:]
+		if strings.TrimRight(left, " ") != want.left || strings.TrimSpace(rest) != strings.TrimSpace(want.mark+" "+want.right) {
+			t.Errorf("line %d = %q, want %q %s %q", i, lines[i], want.left, want.mark, want.right)
+		}
+	}
+}
+
+func TestRun_JSONFormat(t *testing.T) {
+	a, b := writeFiles(t, "one\ntwo\n", "one\n2\n")
+
+	_, out, _ := runDiffx(t, "", "-format=json", a, b)
+	var got jsonDiff
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	want := jsonDiff{From: a, To: b, Ops: []jsonOp{
+		{Op: "equal", AStart: 0, AEnd: 1, BStart: 0, BEnd: 1, Text: "one\n"},
+		{Op: "delete", AStart: 1, AEnd: 2, BStart: 1, BEnd: 1, Text: "two\n"},
+		{Op: "insert", AStart: 2, AEnd: 2, BStart: 1, BEnd: 2, Text: "2\n"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestRun_JSONFormatRecursive(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(dirA, "changed.txt"), []byte("a\n"), 0o644)
+	os.WriteFile(filepath.Join(dirB, "changed.txt"), []byte("b\n"), 0o644)
+	os.WriteFile(filepath.Join(dirB, "new.txt"), []byte("n\n"), 0o644)
+
+	_, out, _ := runDiffx(t, "", "-r", "-format=json", dirA, dirB)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want one JSON line per file, got:\n%s", out)
+	}
+	for _, line := range lines {
+		var d jsonDiff
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		if strings.HasSuffix(d.To, "new.txt") && d.From != devNull {
+			t.Errorf("added file from = %q, want %q", d.From, devNull)
+		}
+	}
+}
+
+func TestRun_HTMLFormat(t *testing.T) {
+	a, b := writeFiles(t, "<a>\n", "<b>\n")
+
+	_, out, _ := runDiffx(t, "", "-format=html", "-color=always", a, b)
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<tr class="delete"><td class="ln">1</td><td class="ln"></td><td>&lt;a&gt;</td></tr>`,
+		`<tr class="insert"><td class="ln"></td><td class="ln">1</td><td>&lt;b&gt;</td></tr>`,
+		"</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("HTML output contains color escapes:\n%s", out)
+	}
+
+	_, out, _ = runDiffx(t, "", "-format=html", "-granularity=word", a, b)
+	if !strings.Contains(out, "&lt;<del>a</del><ins>b</ins>&gt;") {
+		t.Errorf("word HTML output:\n%s", out)
+	}
+}
+
+func TestRun_ReportFormat(t *testing.T) {
+	a, b := writeFiles(t, "same\nthe quick fox\n", "same\nthe slow fox\n")
+
+	code, out, _ := runDiffx(t, "", "-format=report", "-color=always", a, b)
+	if code != exitDiffer {
+		t.Errorf("code = %d, want %d", code, exitDiffer)
+	}
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<tbody class="hunk" id="file-0-hunk-0">`,
+		"<del>quick</del>",
+		"<ins>slow</ins>",
+		"</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "<!DOCTYPE html>") != 1 || strings.Contains(out, "\x1b[") {
+		t.Errorf("output is not one plain document:\n%s", out)
+	}
+
+	// Directories produce one page covering every changed file
+	dirA, dirB := t.TempDir(), t.TempDir()
+	for _, f := range []struct{ dir, name, content string }{
+		{dirA, "one.txt", "1\n"},
+		{dirB, "one.txt", "one\n"},
+		{dirA, "two.txt", "2\n"},
+		{dirB, "two.txt", "two\n"},
+	} {
+		if err := os.WriteFile(filepath.Join(f.dir, f.name), []byte(f.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, out, _ = runDiffx(t, "", "-r", "-format=report", dirA, dirB)
+	if strings.Count(out, "<!DOCTYPE html>") != 1 || !strings.Contains(out, `id="file-1-hunk-0"`) {
+		t.Errorf("recursive report:\n%s", out)
+	}
+
+	if code, _, _ := runDiffx(t, "", "-format=report", "-granularity=word", a, b); code != exitTrouble {
+		t.Errorf("word granularity: code = %d, want %d", code, exitTrouble)
+	}
+}
+
+func TestRun_Labels(t *testing.T) {
+	_, b := writeFiles(t, "", "new\n")
+
+	_, out, _ := runDiffx(t, "old\n", "-label", "a/README.md", "-L", "b/README.md", "-", b)
+	if !strings.HasPrefix(out, "--- a/README.md\n+++ b/README.md\n") {
+		t.Errorf("output =\n%s", out)
+	}
+
+	_, out, _ = runDiffx(t, "old\n", "-label", "HEAD", "-", b)
+	if !strings.HasPrefix(out, "--- HEAD\n+++ "+b+"\n") {
+		t.Errorf("one label: output =\n%s", out)
+	}
+
+	code, _, errOut := runDiffx(t, "", "-L", "1", "-L", "2", "-L", "3", b, b)
+	if code != exitTrouble || !strings.Contains(errOut, "too many labels") {
+		t.Errorf("three labels: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestRun_Pipe(t *testing.T) {
+	if _, err := os.Stat("/dev/fd/0"); err != nil {
+		t.Skip("no /dev/fd")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	go func() {
+		w.WriteString("old\n")
+		w.Close()
+	}()
+	_, b := writeFiles(t, "", "new\n")
+
+	// As passed by a shell for <(command)
+	_, out, errOut := runDiffx(t, "", fmt.Sprintf("/dev/fd/%d", r.Fd()), b)
+	if !strings.Contains(out, "-old\n+new\n") {
+		t.Errorf("output =\n%s\nstderr: %s", out, errOut)
+	}
+}
+
+// followWriter records -follow output and calls next after each write.
+type followWriter struct {
+	bytes.Buffer
+	next func()
+}
+
+func (w *followWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	w.next()
+	return n, err
+}
+
+func TestRun_Follow(t *testing.T) {
+	a, b := writeFiles(t, "one\nlast\n", "one\ntwo\nlast\n")
+
+	var stderr bytes.Buffer
+	cfg, files, err := parseFlags([]string{"-follow", "-follow-interval=10ms", a, b}, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	writes := 0
+	stdout := &followWriter{next: func() {
+		writes++
+		if writes == 2 {
+			cancel()
+			return
+		}
+		// Change the file; only the new line should be printed next
+		if err := os.WriteFile(b, []byte("one\ntwo\nlast\nthree\n"), 0o644); err != nil {
+			t.Error(err)
+		}
+	}}
+	code := runFollow(ctx, cfg, files[0], files[1], nil, stdout, &stderr)
+	if code != exitDiffer || stderr.Len() > 0 {
+		t.Fatalf("code %d, stderr %q", code, stderr.String())
+	}
+
+	header := "--- " + a + "\n+++ " + b + "\n"
+	want := header + "@@ -1,0 +2 @@\n+two\n" + header + "@@ -2,0 +4 @@\n+three\n"
+	if out := stdout.String(); out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRun_FollowErrors(t *testing.T) {
+	a, b := writeFiles(t, "one\n", "one\n")
+	for _, args := range [][]string{
+		{"-follow", "-format=context", a, b},
+		{"-follow", "-algorithm=histogram", a, b},
+		{"-follow", "-stat", a, b},
+		{"-follow", a, "-"},
+		{"-follow", a, b + ".missing"},
+	} {
+		code, _, errOut := runDiffx(t, "", args...)
+		if code != exitTrouble || errOut == "" {
+			t.Errorf("%v: code %d, stderr %q, want trouble", args, code, errOut)
+		}
+	}
+}
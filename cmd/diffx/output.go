@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// palette holds the escape sequences used to color output. The zero
+// palette disables color.
+type palette struct {
+	meta  string // file headers
+	frag  string // hunk headers
+	old   string // deleted text
+	new   string // inserted text
+	reset string
+}
+
+// ansiPalette colors output the way git does by default.
+var ansiPalette = palette{
+	meta:  "\x1b[1m",
+	frag:  "\x1b[36m",
+	old:   "\x1b[31m",
+	new:   "\x1b[32m",
+	reset: "\x1b[m",
+}
+
+// paint wraps s in color, unless the palette is disabled.
+func (p palette) paint(color, s string) string {
+	if color == "" || s == "" {
+		return s
+	}
+	return color + s + p.reset
+}
+
+// writeInline writes the diff as running text with deletions in
+// [-brackets-] and insertions in {+braces+}. With color, changes are
+// shown in color instead of brackets, as in git's --word-diff=color.
+func writeInline(w io.Writer, a, b []string, ops []diffx.DiffOp, pal palette) error {
+	bw := bufio.NewWriter(w)
+	for _, op := range ops {
+		switch op.Type {
+		case diffx.Equal:
+			bw.WriteString(strings.Join(a[op.AStart:op.AEnd], ""))
+		case diffx.Delete:
+			text := strings.Join(a[op.AStart:op.AEnd], "")
+			if pal.old != "" {
+				bw.WriteString(pal.paint(pal.old, text))
+			} else {
+				bw.WriteString("[-" + text + "-]")
+			}
+		case diffx.Insert:
+			text := strings.Join(b[op.BStart:op.BEnd], "")
+			if pal.new != "" {
+				bw.WriteString(pal.paint(pal.new, text))
+			} else {
+				bw.WriteString("{+" + text + "+}")
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// hunk is a group of operations printed together, with surrounding
+// context.
+type hunk struct {
+	aStart, aEnd int
+	bStart, bEnd int
+	ops          []diffx.DiffOp
+}
+
+// add appends op to the hunk and extends its ranges.
+func (h *hunk) add(op diffx.DiffOp) {
+	if len(h.ops) == 0 {
+		h.aStart, h.bStart = op.AStart, op.BStart
+	}
+	h.aEnd, h.bEnd = op.AEnd, op.BEnd
+	h.ops = append(h.ops, op)
+}
+
+// buildHunks groups ops into hunks, keeping up to context unchanged lines
+// around each change. Changes separated by at most 2*context unchanged
+// lines share a hunk.
+func buildHunks(ops []diffx.DiffOp, context int) []hunk {
+	var hunks []hunk
+	var cur *hunk
+
+	for i, op := range ops {
+		if op.Type == diffx.Equal {
+			if cur == nil {
+				continue
+			}
+			n := op.AEnd - op.AStart
+			if i < len(ops)-1 && n <= 2*context {
+				cur.add(op)
+				continue
+			}
+			k := min(n, context)
+			if k > 0 {
+				cur.add(diffx.DiffOp{Type: diffx.Equal, AStart: op.AStart, AEnd: op.AStart + k, BStart: op.BStart, BEnd: op.BStart + k})
+			}
+			hunks = append(hunks, *cur)
+			cur = nil
+			continue
+		}
+
+		if cur == nil {
+			cur = &hunk{}
+			if i > 0 && ops[i-1].Type == diffx.Equal {
+				p := ops[i-1]
+				if k := min(p.AEnd-p.AStart, context); k > 0 {
+					cur.add(diffx.DiffOp{Type: diffx.Equal, AStart: p.AEnd - k, AEnd: p.AEnd, BStart: p.BEnd - k, BEnd: p.BEnd})
+				}
+			}
+		}
+		cur.add(op)
+	}
+	if cur != nil {
+		hunks = append(hunks, *cur)
+	}
+	return hunks
+}
+
+// writeUnified writes hunks in unified format. Nothing is written if there
+// are no hunks.
+func writeUnified(w io.Writer, nameA, nameB string, a, b []string, hunks []hunk, pal palette) error {
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(pal.paint(pal.meta, "--- "+nameA) + "\n")
+	bw.WriteString(pal.paint(pal.meta, "+++ "+nameB) + "\n")
+	for _, h := range hunks {
+		header := fmt.Sprintf("@@ -%s +%s @@", unifiedRange(h.aStart, h.aEnd), unifiedRange(h.bStart, h.bEnd))
+		bw.WriteString(pal.paint(pal.frag, header) + "\n")
+		for _, op := range h.ops {
+			switch op.Type {
+			case diffx.Equal:
+				writeLines(bw, " ", a[op.AStart:op.AEnd], "", pal)
+			case diffx.Delete:
+				writeLines(bw, "-", a[op.AStart:op.AEnd], pal.old, pal)
+			case diffx.Insert:
+				writeLines(bw, "+", b[op.BStart:op.BEnd], pal.new, pal)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// unifiedRange formats a line range for a hunk header the way GNU diff
+// does: a single line is printed as its number, and an empty range as the
+// line before it with a count of zero.
+func unifiedRange(start, end int) string {
+	switch n := end - start; n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, n)
+	}
+}
+
+// writeLines writes lines with a prefix in the given color, marking a
+// line without a final newline.
+func writeLines(w *bufio.Writer, prefix string, lines []string, color string, pal palette) {
+	for _, line := range lines {
+		text, hasEOL := strings.CutSuffix(line, "\n")
+		w.WriteString(pal.paint(color, prefix+text))
+		w.WriteString("\n")
+		if !hasEOL {
+			w.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
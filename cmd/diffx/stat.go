@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// statLineWidth is the width that --stat output is scaled to fit, as in
+// git.
+const statLineWidth = 80
+
+// statEntry is the summary of one changed file.
+type statEntry struct {
+	name   string
+	stat   diffx.DiffStat
+	binary bool
+}
+
+// statCollector accumulates per-file summaries for --stat and --numstat.
+type statCollector struct {
+	entries []statEntry
+}
+
+// add records the summary of a changed file.
+func (c *statCollector) add(name string, stat diffx.DiffStat) {
+	c.entries = append(c.entries, statEntry{name: name, stat: stat})
+}
+
+// addBinary records a changed binary file.
+func (c *statCollector) addBinary(name string) {
+	c.entries = append(c.entries, statEntry{name: name, binary: true})
+}
+
+// writeNumstat writes one line per file with the insertion and deletion
+// counts, tab separated, as in git diff --numstat. Binary files show "-"
+// for both counts.
+func writeNumstat(w io.Writer, entries []statEntry) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		if e.binary {
+			fmt.Fprintf(bw, "-\t-\t%s\n", e.name)
+			continue
+		}
+		fmt.Fprintf(bw, "%d\t%d\t%s\n", e.stat.Insertions, e.stat.Deletions, e.name)
+	}
+	return bw.Flush()
+}
+
+// writeStat writes a histogram of changes per file followed by a summary
+// line, as in git diff --stat.
+func writeStat(w io.Writer, entries []statEntry, pal palette) error {
+	nameWidth, maxChanges := 0, 0
+	var total diffx.DiffStat
+	for _, e := range entries {
+		nameWidth = max(nameWidth, len(e.name))
+		maxChanges = max(maxChanges, e.stat.Changes())
+		total = total.Add(e.stat)
+	}
+	numWidth := max(len(strconv.Itoa(maxChanges)), len("Bin"))
+
+	// " name | count graph"
+	graphWidth := max(statLineWidth-nameWidth-numWidth-6, 10)
+
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		if e.binary {
+			fmt.Fprintf(bw, " %-*s | %*s\n", nameWidth, e.name, numWidth, "Bin")
+			continue
+		}
+
+		ins, del := e.stat.Insertions, e.stat.Deletions
+		if maxChanges > graphWidth {
+			ins, del = scaleStat(ins, graphWidth, maxChanges), scaleStat(del, graphWidth, maxChanges)
+		}
+		graph := pal.paint(pal.new, strings.Repeat("+", ins)) + pal.paint(pal.old, strings.Repeat("-", del))
+		fmt.Fprintf(bw, " %-*s | %*d %s\n", nameWidth, e.name, numWidth, e.stat.Changes(), graph)
+	}
+	bw.WriteString(statSummary(len(entries), total) + "\n")
+	return bw.Flush()
+}
+
+// scaleStat scales n from [0, maxN] to [0, width], rounding to nearest,
+// and keeps at least one character for a non-zero count.
+func scaleStat(n, width, maxN int) int {
+	if n == 0 {
+		return 0
+	}
+	return max(1, (n*width*2+maxN)/(maxN*2))
+}
+
+// statSummary returns git's summary line, such as " 2 files changed,
+// 3 insertions(+), 1 deletion(-)".
+func statSummary(files int, total diffx.DiffStat) string {
+	s := fmt.Sprintf(" %d %s changed", files, plural(files, "file", "files"))
+	if total.Insertions > 0 || total.Deletions == 0 {
+		s += fmt.Sprintf(", %d %s(+)", total.Insertions, plural(total.Insertions, "insertion", "insertions"))
+	}
+	if total.Deletions > 0 || total.Insertions == 0 {
+		s += fmt.Sprintf(", %d %s(-)", total.Deletions, plural(total.Deletions, "deletion", "deletions"))
+	}
+	return s
+}
+
+// plural returns one or many depending on n.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// useColor reports whether output to w should be colored in the given
+// mode.
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// startPager starts the pager named by DIFFX_PAGER or PAGER, writing to
+// stdout, and returns a writer feeding it and a function that waits for it
+// to exit. An empty or "cat" pager is an error, so that output goes to
+// stdout directly.
+func startPager(stdout, stderr io.Writer) (io.Writer, func(), error) {
+	command := os.Getenv("DIFFX_PAGER")
+	if command == "" {
+		command = os.Getenv("PAGER")
+	}
+	if command == "" {
+		command = "less"
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil, nil, exec.ErrNotFound
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if os.Getenv("LV") == "" {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	wait := func() {
+		in.Close()
+		cmd.Wait()
+	}
+	return in, wait, nil
+}
@@ -0,0 +1,275 @@
+package diffx
+
+import "sort"
+
+// Adversarial input protection.
+//
+// Preprocessing and histogram diff both rely on rare elements:
+// preprocessing keeps them for the Myers search and hides the frequent
+// ones, and histogram diff anchors on them. Inputs made of a few values
+// repeated over and over, such as millions of identical tokens with
+// scattered noise, have none, and so many equally good alignments that
+// divide and conquer runs tens of thousands of searches, each as deep as
+// the heuristics allow. A diff of a hundred thousand such elements takes
+// minutes, and one of a million takes hours.
+//
+// The Myers search therefore counts its work, in elements compared and
+// diagonals visited. When a diff exceeds its work limit, the search is
+// abandoned and the inputs are diffed coarsely instead, in
+// O((n+m) log(n+m)) time: elements that occur once in each sequence anchor
+// runs of matches, and everything between the runs is replaced as a block.
+// DiffResult and its variants report the switch in their result, and
+// Stats.CoarseFallbacks and a TraceCoarse event report it too.
+
+// defaultWorkLimit is the default limit set by WithWorkLimit: the work
+// above which Estimate classifies a diff as TimeExpensive.
+const defaultWorkLimit = slowWork
+
+// WithWorkLimit sets how much work, in elements compared and diagonals
+// visited, the Myers search may do during one diff, including the Myers
+// regions of histogram diff. A diff that exceeds it is abandoned and
+// computed coarsely instead, which Stats.CoarseFallbacks reports. Diffs of
+// ordinary inputs stay far below the default, which takes tens of seconds
+// to reach. A limit of 0 or less disables it, as does WithMinimal(true).
+// Default: 1e9.
+func WithWorkLimit(n int) Option {
+	return func(o *options) {
+		o.workLimit = n
+	}
+}
+
+// Result is an edit script together with how it was computed.
+type Result struct {
+	Ops []DiffOp
+
+	// Coarse reports that the diff exceeded its work limit, so Ops, while
+	// a valid script, was computed coarsely and can be far from minimal.
+	// See WithWorkLimit.
+	Coarse bool
+}
+
+// DiffResult is like Diff, but also reports whether the diff was computed
+// coarsely.
+func DiffResult(a, b []string, opts ...Option) Result {
+	var r Result
+	r.Ops = Diff(a, b, withCoarseFlag(opts, &r.Coarse)...)
+	return r
+}
+
+// DiffElementsResult is like DiffElements, but also reports whether the
+// diff was computed coarsely.
+func DiffElementsResult(a, b []Element, opts ...Option) Result {
+	var r Result
+	r.Ops = DiffElements(a, b, withCoarseFlag(opts, &r.Coarse)...)
+	return r
+}
+
+// DiffHistogramResult is like DiffHistogram, but also reports whether the
+// diff was computed coarsely.
+func DiffHistogramResult(a, b []string, opts ...Option) Result {
+	var r Result
+	r.Ops = DiffHistogram(a, b, withCoarseFlag(opts, &r.Coarse)...)
+	return r
+}
+
+// DiffElementsHistogramResult is like DiffElementsHistogram, but also
+// reports whether the diff was computed coarsely.
+func DiffElementsHistogramResult(a, b []Element, opts ...Option) Result {
+	var r Result
+	r.Ops = DiffElementsHistogram(a, b, withCoarseFlag(opts, &r.Coarse)...)
+	return r
+}
+
+// withCoarseFlag returns opts with an option that sets *coarse when the
+// diff falls back to coarseDiff. opts itself is not modified.
+func withCoarseFlag(opts []Option, coarse *bool) []Option {
+	return append(opts[:len(opts):len(opts)], func(o *options) {
+		o.coarse = coarse
+	})
+}
+
+// workBudget counts down the work left to one diff. The searches of
+// a diff share it; a nil budget is unlimited.
+type workBudget struct {
+	left int
+}
+
+// workLimitExceeded is the panic value that abandons a diff over its work
+// limit. It is recovered by withWorkLimit.
+type workLimitExceeded struct{}
+
+// spend records n units of work, abandoning the diff if the budget runs out.
+func (w *workBudget) spend(n int) {
+	if w == nil {
+		return
+	}
+	w.left -= n
+	if w.left < 0 {
+		panic(workLimitExceeded{})
+	}
+}
+
+// withWorkLimit returns core limited to the work limit of its options.
+// When core exceeds the limit, the result of coarseDiff is returned
+// instead.
+func withWorkLimit(core func(a, b []Element, o *options) []DiffOp) func(a, b []Element, o *options) []DiffOp {
+	return func(a, b []Element, o *options) (ops []DiffOp) {
+		if o.workLimit <= 0 || o.forceMinimal {
+			return core(a, b, o)
+		}
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if _, ok := r.(workLimitExceeded); !ok {
+				panic(r)
+			}
+			o.stats.recordCoarse()
+			if o.coarse != nil {
+				*o.coarse = true
+			}
+			if o.trace != nil {
+				o.trace(TraceEvent{Kind: TraceCoarse, AEnd: len(a), BEnd: len(b)})
+			}
+			ops = coarseDiff(a, b)
+		}()
+
+		limited := *o
+		limited.budget = &workBudget{left: o.workLimit}
+		return core(a, b, &limited)
+	}
+}
+
+// matchPair is a pair of equal elements, at index a of A and b of B.
+type matchPair struct {
+	a, b int
+}
+
+// coarseDiff diffs a and b in O((n+m) log(n+m)) time. Elements that occur
+// exactly once in each sequence are matched, as in patience diff, keeping
+// the longest subsequence of matches whose positions increase in both
+// sequences. Each match is extended over the equal elements around it,
+// and the elements between the extended runs are deleted and inserted as
+// blocks.
+func coarseDiff(a, b []Element) []DiffOp {
+	var ops []DiffOp
+
+	// Common prefix
+	i, j := 0, 0
+	for i < len(a) && j < len(b) && a[i].Equal(b[j]) {
+		i++
+		j++
+	}
+	if i > 0 {
+		ops = append(ops, DiffOp{Type: Equal, AStart: 0, AEnd: i, BStart: 0, BEnd: j})
+	}
+
+	for _, p := range longestIncreasing(uniquePairs(a, b)) {
+		if p.a < i || p.b < j {
+			continue // inside the run of an earlier match
+		}
+		x, y := p.a, p.b
+		for x > i && y > j && a[x-1].Equal(b[y-1]) {
+			x--
+			y--
+		}
+		ops = appendBlock(ops, i, x, j, y)
+
+		i, j = p.a+1, p.b+1
+		for i < len(a) && j < len(b) && a[i].Equal(b[j]) {
+			i++
+			j++
+		}
+		ops = append(ops, DiffOp{Type: Equal, AStart: x, AEnd: i, BStart: y, BEnd: j})
+	}
+
+	// The rest, up to the common suffix
+	x, y := len(a), len(b)
+	for x > i && y > j && a[x-1].Equal(b[y-1]) {
+		x--
+		y--
+	}
+	ops = appendBlock(ops, i, x, j, y)
+	if x < len(a) {
+		ops = append(ops, DiffOp{Type: Equal, AStart: x, AEnd: len(a), BStart: y, BEnd: len(b)})
+	}
+	return mergeAdjacentOps(ops)
+}
+
+// appendBlock appends the deletion of a[i:x] and the insertion of b[j:y]
+// to ops, leaving out empty ones.
+func appendBlock(ops []DiffOp, i, x, j, y int) []DiffOp {
+	if x > i {
+		ops = append(ops, DiffOp{Type: Delete, AStart: i, AEnd: x, BStart: j, BEnd: j})
+	}
+	if y > j {
+		ops = append(ops, DiffOp{Type: Insert, AStart: x, AEnd: x, BStart: j, BEnd: y})
+	}
+	return ops
+}
+
+// uniquePairs returns the pairs of equal elements that occur exactly once
+// in each of a and b, ordered by their index in A.
+func uniquePairs(a, b []Element) []matchPair {
+	type count struct {
+		a, b int
+		bIdx int
+	}
+	counts := make(map[uint64]count)
+	for j, e := range b {
+		h := e.Hash()
+		c := counts[h]
+		c.b++
+		c.bIdx = j
+		counts[h] = c
+	}
+	hashes := make([]uint64, len(a))
+	for i, e := range a {
+		hashes[i] = e.Hash()
+		if c, ok := counts[hashes[i]]; ok {
+			c.a++
+			counts[hashes[i]] = c
+		}
+	}
+
+	var pairs []matchPair
+	for i, e := range a {
+		if c := counts[hashes[i]]; c.a == 1 && c.b == 1 && e.Equal(b[c.bIdx]) {
+			pairs = append(pairs, matchPair{i, c.bIdx})
+		}
+	}
+	return pairs
+}
+
+// longestIncreasing returns the longest subsequence of pairs, which are
+// ordered by their index in A and have distinct indices in B, whose
+// indices in B increase too.
+func longestIncreasing(pairs []matchPair) []matchPair {
+	// tails[k] is the pair ending the increasing subsequence of length k+1
+	// with the lowest index in B found so far; prev links each pair to the
+	// one before it in its subsequence
+	var tails []int
+	prev := make([]int, len(pairs))
+	for p, pair := range pairs {
+		k := sort.Search(len(tails), func(k int) bool { return pairs[tails[k]].b > pair.b })
+		prev[p] = -1
+		if k > 0 {
+			prev[p] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, p)
+		} else {
+			tails[k] = p
+		}
+	}
+
+	if len(tails) == 0 {
+		return nil
+	}
+	run := make([]matchPair, len(tails))
+	for k, p := len(tails)-1, tails[len(tails)-1]; k >= 0; k, p = k-1, prev[p] {
+		run[k] = pairs[p]
+	}
+	return run
+}
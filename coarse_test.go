@@ -0,0 +1,174 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// noisyInput returns n elements, mostly "x", with the noise tokens "a"
+// and "b" at pseudo-random positions: an input with no rare element.
+func noisyInput(n int, seed uint32) []string {
+	s := make([]string, n)
+	for i := range s {
+		seed = seed*1664525 + 1013904223
+		switch seed >> 29 {
+		case 0, 1:
+			s[i] = "a"
+		case 2:
+			s[i] = "b"
+		default:
+			s[i] = "x"
+		}
+	}
+	return s
+}
+
+func TestCoarseDiff(t *testing.T) {
+	tests := []struct {
+		a, b        string
+		wantMatched int
+	}{
+		{"a b c", "a b c", 3},
+		{"", "a b", 0},
+		{"a b", "", 0},
+		{"x x x", "y y", 0},
+		{"x y y x x", "x x y y x", 2},             // no unique element: prefix and suffix only
+		{"p x x q x", "p x q x x", 4},             // anchored on q, extended both ways
+		{"u x x x v", "u x x x w v", 5},           // gap before the anchor
+		{"p m q", "q m p", 1},                     // crossed anchors keep one
+		{"s x 1 x t x 2 x", "s x 2 x t x 1 x", 4}, // t anchors, the rest is replaced
+	}
+
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		ops := coarseDiff(toElements(a), toElements(b))
+		checkScript(t, a, b, ops)
+		matched := 0
+		for _, op := range ops {
+			if op.Type == Equal {
+				matched += op.AEnd - op.AStart
+			}
+		}
+		if matched != tt.wantMatched {
+			t.Errorf("coarseDiff(%q, %q) matched %d elements, want %d: %v", tt.a, tt.b, matched, tt.wantMatched, ops)
+		}
+	}
+}
+
+func TestLongestIncreasing(t *testing.T) {
+	tests := []struct {
+		pairs []matchPair
+		want  []matchPair
+	}{
+		{nil, nil},
+		{[]matchPair{{0, 0}}, []matchPair{{0, 0}}},
+		{
+			[]matchPair{{0, 3}, {1, 1}, {2, 2}, {3, 0}, {4, 4}},
+			[]matchPair{{1, 1}, {2, 2}, {4, 4}},
+		},
+		{
+			[]matchPair{{0, 2}, {1, 1}, {2, 0}},
+			[]matchPair{{2, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := longestIncreasing(tt.pairs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("longestIncreasing(%v) = %v, want %v", tt.pairs, got, tt.want)
+		}
+	}
+}
+
+func TestWithWorkLimit(t *testing.T) {
+	a, b := noisyInput(2000, 1), noisyInput(2000, 2)
+	diffs := []struct {
+		name string
+		diff func(a, b []string, opts ...Option) []DiffOp
+	}{
+		{"myers", Diff},
+		{"histogram", DiffHistogram},
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantCoarse int
+	}{
+		{"default", nil, 0},
+		{"limit", []Option{WithWorkLimit(1000)}, 1},
+		{"no limit", []Option{WithWorkLimit(0)}, 0},
+		{"minimal", []Option{WithWorkLimit(1000), WithMinimal(true)}, 0},
+		{"hints", []Option{WithWorkLimit(1000), WithCommonPrefix(1)}, 1},
+	}
+
+	for _, d := range diffs {
+		for _, tt := range tests {
+			var s Stats
+			ops := d.diff(a, b, append(tt.opts, WithStats(&s))...)
+			checkScript(t, a, b, ops)
+			if s.CoarseFallbacks != tt.wantCoarse {
+				t.Errorf("%s, %s: CoarseFallbacks = %d, want %d", d.name, tt.name, s.CoarseFallbacks, tt.wantCoarse)
+			}
+		}
+	}
+}
+
+func TestWithWorkLimit_Reported(t *testing.T) {
+	a, b := noisyInput(500, 3), noisyInput(600, 4)
+
+	var events []TraceEvent
+	Diff(a, b, WithWorkLimit(100), WithPostprocessing(false), collectTrace(&events))
+	coarse := eventsOf(events, TraceCoarse)
+	want := []TraceEvent{{Kind: TraceCoarse, AEnd: len(a), BEnd: len(b)}}
+	if !reflect.DeepEqual(coarse, want) {
+		t.Errorf("coarse events = %v, want %v", coarse, want)
+	}
+
+	var notes []Annotation
+	ops := Diff(a, b, WithWorkLimit(100), WithPostprocessing(false), WithAnnotations(&notes))
+	if len(notes) == 0 {
+		t.Fatal("no annotations")
+	}
+	for _, n := range notes {
+		if ops[n.Op].Type == Equal || !strings.Contains(n.Reason, "coarsely") {
+			t.Errorf("annotation %q on %v, want only coarse reasons on changes", n.Reason, ops[n.Op])
+		}
+	}
+}
+
+func TestDiffResult(t *testing.T) {
+	a, b := noisyInput(2000, 1), noisyInput(2000, 2)
+	diffs := []struct {
+		name string
+		diff func(a, b []string, opts ...Option) Result
+	}{
+		{"myers", DiffResult},
+		{"histogram", DiffHistogramResult},
+		{"myers elements", func(a, b []string, opts ...Option) Result {
+			return DiffElementsResult(toElements(a), toElements(b), opts...)
+		}},
+		{"histogram elements", func(a, b []string, opts ...Option) Result {
+			return DiffElementsHistogramResult(toElements(a), toElements(b), opts...)
+		}},
+	}
+
+	for _, d := range diffs {
+		var s Stats
+		opts := make([]Option, 0, 4)
+		opts = append(opts, WithWorkLimit(1000), WithStats(&s))
+		r := d.diff(a, b, opts...)
+		checkScript(t, a, b, r.Ops)
+		if !r.Coarse || s.CoarseFallbacks != 1 {
+			t.Errorf("%s: Coarse = %v, CoarseFallbacks = %d, want true, 1", d.name, r.Coarse, s.CoarseFallbacks)
+		}
+		if len(opts) != 2 || opts[:3][2] != nil {
+			t.Errorf("%s: the caller's options were modified", d.name)
+		}
+
+		r = d.diff(a[:100], b[:100])
+		checkScript(t, a[:100], b[:100], r.Ops)
+		if r.Coarse {
+			t.Errorf("%s: Coarse = true for a small input", d.name)
+		}
+	}
+}
@@ -9,6 +9,9 @@ package diffx
 //   - yoff, ylim: bounds in yvec [yoff, ylim)
 //   - findMinimal: if true, find the truly minimal edit script
 func (ctx *diffContext) compareSeq(xoff, xlim, yoff, ylim int, findMinimal bool) {
+	ctx.stats.recordSubproblem()
+	x0, x1, y0, y1 := xoff, xlim, yoff, ylim
+
 	// 1. Trim matching elements from the start
 	for xoff < xlim && yoff < ylim && ctx.equal(xoff, yoff) {
 		xoff++
@@ -20,6 +23,7 @@ func (ctx *diffContext) compareSeq(xoff, xlim, yoff, ylim int, findMinimal bool)
 		xlim--
 		ylim--
 	}
+	traceTrim(ctx.trace, x0, x1, y0, y1, xoff-x0, x1-xlim)
 
 	// 3. Base cases: one sequence is empty
 	if xoff == xlim {
@@ -35,6 +39,17 @@ func (ctx *diffContext) compareSeq(xoff, xlim, yoff, ylim int, findMinimal bool)
 
 	// 4. Find the middle snake (optimal split point)
 	part := ctx.findMiddleSnake(xoff, xlim, yoff, ylim, findMinimal)
+	ctx.stats.recordSnake(part.d, part.heuristic)
+	if ctx.trace != nil {
+		ctx.trace(TraceEvent{
+			Kind:   TraceMiddleSnake,
+			AStart: xoff, AEnd: xlim,
+			BStart: yoff, BEnd: ylim,
+			X: part.xmid, Y: part.ymid,
+			D:         part.d,
+			Heuristic: part.heuristic,
+		})
+	}
 
 	// 5. Recurse on both halves
 	// Process smaller subproblem first for better memory behavior
@@ -0,0 +1,137 @@
+// Package compress decompresses gzip and Zstandard input for diffing.
+//
+// Rotated logs and archived exports are usually compressed, and diffing
+// them meant decompressing both sides to temporary files first. Decompress
+// recognizes gzip and Zstandard data by its magic number and returns its
+// content, and WithDecompress makes diffx.DiffDirs compare compressed files
+// by their content, so two rotated logs compare line by line even when
+// their compressed bytes share nothing. It is a separate package so that
+// the diff library itself does not depend on a Zstandard decoder.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dacharyc/diffx"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Format is a compression format recognized by Detect.
+type Format int
+
+const (
+	// None means the data is not compressed.
+	None Format = iota
+	// Gzip is gzip, as written by gzip and logrotate.
+	Gzip
+	// Zstd is Zstandard, as written by zstd and newer logrotate and
+	// database dump configurations.
+	Zstd
+)
+
+// String returns a string representation of the Format.
+func (f Format) String() string {
+	switch f {
+	case None:
+		return "None"
+	case Gzip:
+		return "gzip"
+	case Zstd:
+		return "zstd"
+	default:
+		return "Unknown"
+	}
+}
+
+// MaxSize is the largest decompressed size, in bytes, that Decompress
+// accepts. A few kilobytes of gzip or Zstandard can expand to gigabytes,
+// and DiffDirs decompresses every file it finds, so a small file in a
+// walked tree must not be able to exhaust memory.
+const MaxSize = 1 << 30
+
+// ErrTooLarge is reported for data that decompresses to more than MaxSize
+// bytes.
+var ErrTooLarge = errors.New("compress: decompressed data too large")
+
+// Magic numbers.
+var (
+	magicGzip = []byte{0x1F, 0x8B}
+	magicZstd = []byte{0x28, 0xB5, 0x2F, 0xFD}
+)
+
+// Detect returns the compression format of data, judged by its magic
+// number rather than a file name, so that standard input and renamed files
+// are recognized too.
+func Detect(data []byte) Format {
+	switch {
+	case bytes.HasPrefix(data, magicGzip):
+		return Gzip
+	case bytes.HasPrefix(data, magicZstd):
+		return Zstd
+	default:
+		return None
+	}
+}
+
+// Decompress returns the decompressed content of data and its compression
+// format. Data that is not compressed is returned unchanged. Concatenated
+// gzip members or Zstandard frames, as produced by appending to a
+// compressed file, are decompressed as one stream. Content of more than
+// MaxSize bytes is reported with an error wrapping ErrTooLarge.
+func Decompress(data []byte) ([]byte, Format, error) {
+	return decompress(data, MaxSize)
+}
+
+// decompress is Decompress with a limit of limit bytes.
+func decompress(data []byte, limit int64) ([]byte, Format, error) {
+	f := Detect(data)
+	switch f {
+	case Gzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, f, fmt.Errorf("compress: gzip: %w", err)
+		}
+		// Read one byte past the limit to tell a full stream from a cut one
+		out, err := io.ReadAll(io.LimitReader(zr, limit+1))
+		if err != nil {
+			return nil, f, fmt.Errorf("compress: gzip: %w", err)
+		}
+		if int64(len(out)) > limit {
+			return nil, f, fmt.Errorf("compress: gzip: %w", ErrTooLarge)
+		}
+		return out, f, nil
+	case Zstd:
+		zr, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err != nil {
+			return nil, f, fmt.Errorf("compress: zstd: %w", err)
+		}
+		defer zr.Close()
+		out, err := zr.DecodeAll(data, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+			return nil, f, fmt.Errorf("compress: zstd: %w", ErrTooLarge)
+		}
+		if err != nil {
+			return nil, f, fmt.Errorf("compress: zstd: %w", err)
+		}
+		return out, f, nil
+	default:
+		return data, f, nil
+	}
+}
+
+// WithDecompress makes DiffDirs decompress gzip and Zstandard files
+// before comparing them; see Decompress.
+// Default: false.
+func WithDecompress(enabled bool) diffx.DirOption {
+	if !enabled {
+		return diffx.WithTransform(nil)
+	}
+	return diffx.WithTransform(func(data []byte) ([]byte, error) {
+		out, _, err := Decompress(data)
+		return out, err
+	})
+}
@@ -0,0 +1,143 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dacharyc/diffx"
+	"github.com/klauspost/compress/zstd"
+)
+
+// gzipBytes compresses s with gzip.
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zstdBytes compresses s with Zstandard.
+func zstdBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	zw, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zw.Close()
+	return zw.EncodeAll([]byte(s), nil)
+}
+
+func TestDecompress(t *testing.T) {
+	gz := gzipBytes(t, "one\ntwo\n")
+	zst := zstdBytes(t, "one\ntwo\n")
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		f    Format
+	}{
+		{"plain", []byte("one\n"), "one\n", None},
+		{"empty", nil, "", None},
+		{"gzip", gz, "one\ntwo\n", Gzip},
+		{"concatenated gzip", append(gzipBytes(t, "one\n"), gzipBytes(t, "two\n")...), "one\ntwo\n", Gzip},
+		{"zstd", zst, "one\ntwo\n", Zstd},
+		{"concatenated zstd", append(zstdBytes(t, "one\n"), zstdBytes(t, "two\n")...), "one\ntwo\n", Zstd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, f, err := Decompress(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want || f != tt.f {
+				t.Errorf("Decompress() = %q, %v, want %q, %v", got, f, tt.want, tt.f)
+			}
+		})
+	}
+
+	if _, _, err := Decompress(gz[:len(gz)-4]); err == nil {
+		t.Error("truncated gzip: err = nil, want an error")
+	}
+	if _, _, err := Decompress(zst[:len(zst)-4]); err == nil {
+		t.Error("truncated zstd: err = nil, want an error")
+	}
+}
+
+func TestDecompress_TooLarge(t *testing.T) {
+	// A megabyte of zeros compresses to about a kilobyte
+	zeros := strings.Repeat("\x00", 1<<20)
+	for _, data := range [][]byte{gzipBytes(t, zeros), zstdBytes(t, zeros)} {
+		f := Detect(data)
+		if _, _, err := decompress(data, 1<<16); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%v over the limit: err = %v, want ErrTooLarge", f, err)
+		}
+		if got, _, err := decompress(data, 1<<20); err != nil || len(got) != 1<<20 {
+			t.Errorf("%v at the limit: %d bytes, err = %v, want %d bytes", f, len(got), err, 1<<20)
+		}
+	}
+}
+
+// writeTree creates files under root from a map of names to content.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithDecompress(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTree(t, dirA, map[string]string{
+		"app.log.1.gz": string(gzipBytes(t, "start\nrequest 1\nstop\n")),
+		"app.log.2.gz": string(gzipBytes(t, "same\n")),
+		"dump.sql.zst": string(zstdBytes(t, "CREATE TABLE a;\n")),
+	})
+	writeTree(t, dirB, map[string]string{
+		"app.log.1.gz": string(gzipBytes(t, "start\nrequest 2\nstop\n")),
+		"app.log.2.gz": string(append(gzipBytes(t, "sa"), gzipBytes(t, "me\n")...)),
+		"dump.sql.zst": string(zstdBytes(t, "CREATE TABLE b;\n")),
+	})
+
+	diffs, err := diffx.DiffDirs(dirA, dirB, WithDecompress(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 3 || !diffs[0].Binary {
+		t.Fatalf("without decompression: DiffDirs() = %+v, want 3 binary files", diffs)
+	}
+
+	diffs, err = diffx.DiffDirs(dirA, dirB, WithDecompress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 || diffs[0].Path != "app.log.1.gz" || diffs[1].Path != "dump.sql.zst" || diffs[0].Binary || diffs[1].Binary {
+		t.Fatalf("DiffDirs() = %+v, want app.log.1.gz and dump.sql.zst as text", diffs)
+	}
+	for _, d := range diffs {
+		if got, want := strings.Join(d.B, ""), "start\nrequest 2\nstop\n"; d.Path == "app.log.1.gz" && got != want {
+			t.Errorf("%s: B = %q, want %q", d.Path, got, want)
+		}
+		if got, want := strings.Join(d.B, ""), "CREATE TABLE b;\n"; d.Path == "dump.sql.zst" && got != want {
+			t.Errorf("%s: B = %q, want %q", d.Path, got, want)
+		}
+	}
+
+	writeTree(t, dirB, map[string]string{"app.log.2.gz": "\x1f\x8b broken"})
+	if _, err := diffx.DiffDirs(dirA, dirB, WithDecompress(true)); err == nil {
+		t.Error("corrupt gzip: err = nil, want an error")
+	}
+}
@@ -8,16 +8,29 @@ type partition struct {
 	xmid, ymid int  // midpoint coordinates in the edit graph
 	loMinimal  bool // whether lower half needs minimal search
 	hiMinimal  bool // whether upper half needs minimal search
+	d          int  // edit distance the search reached
+	heuristic  bool // whether a heuristic chose the split
+}
+
+// at returns p as found at edit distance d, by a heuristic or not.
+func (p partition) at(d int, heuristic bool) partition {
+	p.d = d
+	p.heuristic = heuristic
+	return p
 }
 
 // diffContext holds algorithm state during comparison.
 type diffContext struct {
-	xvec, yvec   []Element // sequences being compared
-	fdiag, bdiag []int     // forward/backward diagonal arrays
-	xchanges     []bool    // marks changed elements in xvec
-	ychanges     []bool    // marks changed elements in yvec
-	useHeuristic bool      // enable speed heuristics
-	costLimit    int       // max cost before early termination
+	xvec, yvec   []Element   // sequences being compared
+	fdiag, bdiag []int       // forward/backward diagonal arrays
+	xchanges     []bool      // marks changed elements in xvec
+	ychanges     []bool      // marks changed elements in yvec
+	useHeuristic bool        // enable speed heuristics
+	costLimit    int         // max cost before early termination
+	stats        *Stats      // statistics to record, or nil
+	budget       *workBudget // work left, or nil
+
+	trace func(TraceEvent) // receives trace events, or nil
 }
 
 // newDiffContext creates a new context for comparing two sequences.
@@ -25,9 +38,10 @@ func newDiffContext(a, b []Element, opts *options) *diffContext {
 	n := len(a)
 	m := len(b)
 
-	// Diagonal array size: we need diagonals from -(m) to +(n)
-	// The array is indexed by [k + offset] where offset = m
-	diagSize := n + m + 3
+	// Diagonal array size: findMiddleSnake explores diagonals up to
+	// (n+m+1)/2 steps away from the start, plus one on each side. It fits
+	// in an int because the entry points reject inputs over MaxElements
+	diagSize := n + m + 4
 
 	ctx := &diffContext{
 		xvec:         a,
@@ -38,6 +52,9 @@ func newDiffContext(a, b []Element, opts *options) *diffContext {
 		ychanges:     make([]bool, m),
 		useHeuristic: opts.useHeuristic,
 		costLimit:    opts.costLimit,
+		stats:        opts.stats,
+		budget:       opts.budget,
+		trace:        opts.trace,
 	}
 
 	// Auto-calculate cost limit if not specified
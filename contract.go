@@ -0,0 +1,131 @@
+package diffx
+
+import "fmt"
+
+// Element contract checking.
+//
+// The algorithms rely on the Element contract: Equal is symmetric, equal
+// elements have equal hashes, and an element's hash does not change. An
+// implementation that breaks it, say by hashing a field that Equal
+// ignores, does not fail; it makes the algorithms count, filter, and
+// anchor on wrong assumptions and return a valid-looking but wrong diff.
+// WithContractCheck watches every call the algorithms make and reports the
+// first violation, for use in tests and while developing an Element.
+
+// WithContractCheck checks the elements being diffed against the Element
+// contract on every call the diff makes: each time Equal reports two
+// elements equal, the reverse comparison must agree and their hashes must
+// be equal, and every call of Hash on an element must return the same
+// value. Equal elements that the diff never compared because their hashes
+// differ are found by also comparing the deleted and inserted elements of
+// each change with each other. After the diff, *err holds the first
+// violation, wrapping ErrInconsistentElement, or nil. Elements whose hashes
+// collide without being equal are allowed by the contract and are not
+// reported.
+//
+// Checking wraps every element and adds comparisons, quadratic in the
+// size of each change, so enable it in tests rather than in production.
+// DiffElementsE checks only the matched pairs of the result, without the
+// cost.
+// Default: nil (no checking).
+func WithContractCheck(err *error) Option {
+	return func(o *options) {
+		o.contractErr = err
+	}
+}
+
+// contractCheck holds the state of a contract check.
+type contractCheck struct {
+	hashes [2][]uint64 // first hash seen for each element of A and B
+	hashed [2][]bool
+	err    error
+}
+
+// checkedElement wraps an Element to check the calls of its methods.
+type checkedElement struct {
+	Element
+	side  int // 0 for A, 1 for B
+	index int
+	check *contractCheck
+}
+
+// name returns the element's position for error messages, such as "A[3]".
+func (c checkedElement) name() string {
+	return fmt.Sprintf("%c[%d]", "AB"[c.side], c.index)
+}
+
+// Equal compares the wrapped elements and checks a positive result.
+func (c checkedElement) Equal(other Element) bool {
+	o, wrapped := other.(checkedElement)
+	if !wrapped {
+		return c.Element.Equal(unwrapElement(other))
+	}
+	eq := c.Element.Equal(o.Element)
+	if !eq || c.check.err != nil {
+		return eq
+	}
+	switch {
+	case !o.Element.Equal(c.Element):
+		c.check.fail("%s equals %s but not the other way round", c.name(), o.name())
+	case c.Hash() != o.Hash():
+		c.check.fail("%s and %s are equal but have different hashes", c.name(), o.name())
+	}
+	return eq
+}
+
+// Hash hashes the wrapped element and checks that the hash is stable.
+func (c checkedElement) Hash() uint64 {
+	h := c.Element.Hash()
+	chk := c.check
+	switch {
+	case !chk.hashed[c.side][c.index]:
+		chk.hashed[c.side][c.index] = true
+		chk.hashes[c.side][c.index] = h
+	case chk.hashes[c.side][c.index] != h && chk.err == nil:
+		chk.fail("%s returned hashes %#x and %#x", c.name(), chk.hashes[c.side][c.index], h)
+	}
+	return h
+}
+
+// fail records a violation, unless one was recorded before.
+func (chk *contractCheck) fail(format string, args ...any) {
+	if chk.err == nil {
+		chk.err = fmt.Errorf("%w: "+format, append([]any{ErrInconsistentElement}, args...)...)
+	}
+}
+
+// checkedDiff runs diff on a and b wrapped to check their calls, and
+// stores the first violation in *o.contractErr. The algorithms only
+// compare elements whose hashes suggest they may be equal, so afterwards
+// the deleted and inserted elements of each change are also compared
+// with each other, to find equal elements that were never compared
+// because their hashes differ.
+func checkedDiff(a, b []Element, opts []Option, o *options,
+	diff func(a, b []Element, opts ...Option) []DiffOp) []DiffOp {
+	ca, cb, chk := checkContract(a, b)
+	ops := diff(ca, cb, append(opts[:len(opts):len(opts)], WithContractCheck(nil))...)
+	for _, r := range CompactOps(ops) {
+		for i := r.AStart; i < r.AEnd && chk.err == nil; i++ {
+			for j := r.BStart; j < r.BEnd && chk.err == nil; j++ {
+				ca[i].Equal(cb[j])
+			}
+		}
+	}
+	*o.contractErr = chk.err
+	return ops
+}
+
+// checkContract wraps a and b to check their calls.
+func checkContract(a, b []Element) ([]Element, []Element, *contractCheck) {
+	chk := &contractCheck{}
+	wrap := func(side int, elems []Element) []Element {
+		chk.hashes[side] = make([]uint64, len(elems))
+		chk.hashed[side] = make([]bool, len(elems))
+		wrapped := make([]Element, len(elems))
+		for i, e := range elems {
+			wrapped[i] = checkedElement{Element: e, side: side, index: i, check: chk}
+		}
+		return wrapped
+	}
+	return wrap(0, a), wrap(1, b), chk
+}
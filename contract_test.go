@@ -0,0 +1,104 @@
+package diffx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// asymmetricElement is equal to any element with the same prefix when it
+// is the longer one, which breaks symmetry.
+type asymmetricElement string
+
+func (e asymmetricElement) Equal(other Element) bool {
+	o, ok := other.(asymmetricElement)
+	return ok && strings.HasPrefix(string(e), string(o))
+}
+
+func (e asymmetricElement) Hash() uint64 { return 0 }
+
+// unstableElement returns a different hash on every call.
+type unstableElement struct {
+	s     string
+	calls *uint64
+}
+
+func (e unstableElement) Equal(other Element) bool {
+	o, ok := other.(unstableElement)
+	return ok && e.s == o.s
+}
+
+func (e unstableElement) Hash() uint64 {
+	*e.calls++
+	return *e.calls
+}
+
+func TestWithContractCheck(t *testing.T) {
+	var calls uint64
+	unstable := func(s string) Element { return unstableElement{s: s, calls: &calls} }
+
+	tests := []struct {
+		name    string
+		a, b    []Element
+		wantMsg string
+	}{
+		{
+			name:    "different hashes",
+			a:       []Element{brokenElement{s: "x", hash: 1}, brokenElement{s: "a", hash: 1}},
+			b:       []Element{brokenElement{s: "y", hash: 1}, brokenElement{s: "a", hash: 2}},
+			wantMsg: "A[1] and B[1] are equal but have different hashes",
+		},
+		{
+			name:    "asymmetric",
+			a:       []Element{asymmetricElement("ab"), asymmetricElement("q")},
+			b:       []Element{asymmetricElement("a"), asymmetricElement("r")},
+			wantMsg: "A[0] equals B[0] but not the other way round",
+		},
+		{
+			name:    "unstable hash",
+			a:       []Element{unstable("p"), unstable("q"), unstable("p")},
+			b:       []Element{unstable("q"), unstable("p"), unstable("q")},
+			wantMsg: "returned hashes",
+		},
+	}
+	for _, tt := range tests {
+		for _, algo := range []struct {
+			name string
+			diff func(a, b []Element, opts ...Option) []DiffOp
+		}{{"myers", DiffElements}, {"histogram", DiffElementsHistogram}} {
+			t.Run(tt.name+"/"+algo.name, func(t *testing.T) {
+				var err error
+				algo.diff(tt.a, tt.b, WithContractCheck(&err))
+				if !errors.Is(err, ErrInconsistentElement) {
+					t.Fatalf("err = %v, want %v", err, ErrInconsistentElement)
+				}
+				if !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Errorf("err = %q, want it to contain %q", err, tt.wantMsg)
+				}
+			})
+		}
+	}
+}
+
+func TestWithContractCheck_Valid(t *testing.T) {
+	a := strings.Fields("the quick brown fox jumps over the lazy dog")
+	b := strings.Fields("the slow brown fox leaps over a lazy cat")
+
+	// A previous error is cleared, and checking does not change the result
+	err := errors.New("stale")
+	var counts CallCounts
+	got := Diff(a, b, WithContractCheck(&err), WithCallCounts(&counts))
+	if err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if want := Diff(a, b); Stat(got) != Stat(want) {
+		t.Errorf("Diff with check = %v, want %v", got, want)
+	}
+
+	// Collisions are allowed
+	collide := func(s string) Element { return brokenElement{s: s, hash: 7} }
+	DiffElementsHistogram([]Element{collide("a"), collide("b")}, []Element{collide("b"), collide("c")}, WithContractCheck(&err))
+	if err != nil {
+		t.Errorf("hash collision: err = %v, want nil", err)
+	}
+}
@@ -0,0 +1,94 @@
+package diffx
+
+// Copy detection.
+//
+// A plain edit script reports duplicated content as an ordinary insertion.
+// In documentation it is common to duplicate a section and then edit the
+// copy, so it is useful to know that an inserted block is really a copy of
+// text that is still present in A. DetectCopies finds such blocks after the
+// diff has been computed; the script itself is left unchanged.
+
+// minCopyLen is the default minimum number of elements in a copied block.
+// Shorter matches are too likely to be coincidental.
+const minCopyLen = 3
+
+// CopyOp describes a block of inserted elements in B that duplicates a
+// block of A that is kept unchanged by the script.
+type CopyOp struct {
+	Op     int // index of the Insert operation containing the copy
+	AStart int // start of the source block in A (inclusive)
+	AEnd   int // end of the source block in A (exclusive)
+	BStart int // start of the copied block in B (inclusive)
+	BEnd   int // end of the copied block in B (exclusive)
+}
+
+// DetectCopies finds inserted blocks that duplicate content still present
+// in A. ops must be a script for a and b, such as one returned by
+// DiffElements. Each inserted region is scanned from the start, and the
+// longest block matching a kept part of A is reported if it has at least
+// three elements (see WithMinBlockLen); the scan then continues after that
+// block. Ties are broken in favor of the earliest source position.
+func DetectCopies(ops []DiffOp, a, b []Element, opts ...MoveOption) []CopyOp {
+	minLen := newMoveOptions(opts).blockLen(minCopyLen)
+
+	// Mark which elements of A survive into B
+	kept := make([]bool, len(a))
+	for _, op := range ops {
+		if op.Type == Equal {
+			for i := op.AStart; i < op.AEnd; i++ {
+				kept[i] = true
+			}
+		}
+	}
+
+	index := make(map[uint64][]int)
+	for i, e := range a {
+		if kept[i] {
+			h := e.Hash()
+			index[h] = append(index[h], i)
+		}
+	}
+
+	var copies []CopyOp
+	for k, op := range ops {
+		if op.Type != Insert {
+			continue
+		}
+
+		for j := op.BStart; j < op.BEnd; {
+			bestLen, bestA := longestKeptMatch(a, b, kept, index[b[j].Hash()], j, op.BEnd)
+			if bestLen < minLen {
+				j++
+				continue
+			}
+
+			copies = append(copies, CopyOp{
+				Op:     k,
+				AStart: bestA,
+				AEnd:   bestA + bestLen,
+				BStart: j,
+				BEnd:   j + bestLen,
+			})
+			j += bestLen
+		}
+	}
+
+	return copies
+}
+
+// longestKeptMatch returns the length and A position of the longest run
+// matching b[j:bEnd] that starts at one of the candidate positions and
+// stays within kept elements of A.
+func longestKeptMatch(a, b []Element, kept []bool, candidates []int, j, bEnd int) (int, int) {
+	bestLen, bestA := 0, -1
+	for _, i := range candidates {
+		n := 0
+		for j+n < bEnd && i+n < len(a) && kept[i+n] && a[i+n].Equal(b[j+n]) {
+			n++
+		}
+		if n > bestLen {
+			bestLen, bestA = n, i
+		}
+	}
+	return bestLen, bestA
+}
@@ -0,0 +1,95 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectCopies_DuplicatedSection(t *testing.T) {
+	a := []string{"# Intro", "one", "two", "three", "# End"}
+	b := []string{"# Intro", "one", "two", "three", "# Copy", "one", "two", "three", "# End"}
+
+	ea, eb := toElements(a), toElements(b)
+	ops := DiffElements(ea, eb)
+
+	got := DetectCopies(ops, ea, eb)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 copy, got %d: %v", len(got), got)
+	}
+
+	c := got[0]
+	if ops[c.Op].Type != Insert {
+		t.Errorf("copy refers to %v, want an Insert op", ops[c.Op])
+	}
+	if !reflect.DeepEqual(a[c.AStart:c.AEnd], b[c.BStart:c.BEnd]) {
+		t.Errorf("source %v does not match copy %v", a[c.AStart:c.AEnd], b[c.BStart:c.BEnd])
+	}
+	if c.AEnd-c.AStart != 3 {
+		t.Errorf("copy length = %d, want 3", c.AEnd-c.AStart)
+	}
+}
+
+func TestDetectCopies_IgnoresDeletedSource(t *testing.T) {
+	// The block moved rather than being copied: its source was deleted
+	a := toElements([]string{"one", "two", "three", "x", "y"})
+	b := toElements([]string{"x", "y", "one", "two", "three"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+		{Type: Equal, AStart: 3, AEnd: 5, BStart: 0, BEnd: 2},
+		{Type: Insert, AStart: 5, AEnd: 5, BStart: 2, BEnd: 5},
+	}
+
+	if got := DetectCopies(ops, a, b); len(got) != 0 {
+		t.Errorf("expected no copies, got %v", got)
+	}
+}
+
+func TestDetectCopies_ShortMatchesIgnored(t *testing.T) {
+	a := toElements([]string{"one", "two", "three"})
+	b := toElements([]string{"one", "two", "three", "one", "two"})
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 3, BEnd: 5},
+	}
+
+	if got := DetectCopies(ops, a, b); len(got) != 0 {
+		t.Errorf("expected no copies below the minimum length, got %v", got)
+	}
+}
+
+func TestDetectCopies_MultipleBlocks(t *testing.T) {
+	a := toElements([]string{"a", "b", "c", "x", "y", "z"})
+	b := toElements([]string{"a", "b", "c", "x", "y", "z", "new", "x", "y", "z", "a", "b", "c"})
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 6, BStart: 0, BEnd: 6},
+		{Type: Insert, AStart: 6, AEnd: 6, BStart: 6, BEnd: 13},
+	}
+
+	got := DetectCopies(ops, a, b)
+	want := []CopyOp{
+		{Op: 1, AStart: 3, AEnd: 6, BStart: 7, BEnd: 10},
+		{Op: 1, AStart: 0, AEnd: 3, BStart: 10, BEnd: 13},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectCopies() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectCopies_MinBlockLen(t *testing.T) {
+	a := toElements([]string{"x", "y", "z"})
+	b := toElements([]string{"x", "y", "z", "x", "y"})
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 3, BEnd: 5},
+	}
+
+	if got := DetectCopies(ops, a, b); len(got) != 0 {
+		t.Errorf("default: expected no copies, got %v", got)
+	}
+
+	got := DetectCopies(ops, a, b, WithMinBlockLen(2))
+	want := []CopyOp{{Op: 1, AStart: 0, AEnd: 2, BStart: 3, BEnd: 5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WithMinBlockLen(2) = %v, want %v", got, want)
+	}
+}
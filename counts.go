@@ -0,0 +1,79 @@
+package diffx
+
+// Element call counting.
+//
+// The diff algorithms treat Equal and Hash as cheap, and call them freely:
+// once per element for hashing, and up to O((N+M)·D) times for equality.
+// For StringElement that is true, but custom elements such as AST nodes can
+// compare whole subtrees. CallCounts measures how often each method is
+// called, so users can see which one to optimize, or whether caching a hash
+// or interning values would pay off.
+
+// CallCounts records how often a diff called the Equal and Hash methods of
+// its elements. Pass a *CallCounts to WithCallCounts to have a diff fill it
+// in.
+//
+// Counters accumulate across calls that share a CallCounts; reset it with
+// *c = CallCounts{}. A CallCounts must not be shared by concurrent calls.
+type CallCounts struct {
+	Equal int // calls of Element.Equal
+	Hash  int // calls of Element.Hash
+}
+
+// WithCallCounts counts the Equal and Hash calls made on the elements being
+// diffed in c. Counting wraps every element, which adds an allocation per
+// element and an indirection per call, so enable it for profiling rather
+// than in production.
+// Default: nil (no counting).
+func WithCallCounts(c *CallCounts) Option {
+	return func(o *options) {
+		o.callCounts = c
+	}
+}
+
+// countedElement wraps an Element to count calls of its methods.
+type countedElement struct {
+	Element
+	counts *CallCounts
+}
+
+// Equal counts the call and compares the wrapped elements.
+func (c countedElement) Equal(other Element) bool {
+	c.counts.Equal++
+	if o, ok := other.(countedElement); ok {
+		other = o.Element
+	}
+	return c.Element.Equal(other)
+}
+
+// Hash counts the call and hashes the wrapped element.
+func (c countedElement) Hash() uint64 {
+	c.counts.Hash++
+	return c.Element.Hash()
+}
+
+// countCalls wraps elems to count their calls in counts.
+func countCalls(elems []Element, counts *CallCounts) []Element {
+	wrapped := make([]Element, len(elems))
+	for i, e := range elems {
+		wrapped[i] = countedElement{Element: e, counts: counts}
+	}
+	return wrapped
+}
+
+// unwrapElement returns the element that countedElement and
+// checkedElement wrappers wrap, or e itself. Code that inspects the
+// concrete type of an element must look through the wrappers, so that
+// counting and checking do not change the result.
+func unwrapElement(e Element) Element {
+	for {
+		switch w := e.(type) {
+		case countedElement:
+			e = w.Element
+		case checkedElement:
+			e = w.Element
+		default:
+			return e
+		}
+	}
+}
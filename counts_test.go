@@ -0,0 +1,70 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithCallCounts(t *testing.T) {
+	a := toElements(strings.Fields("a b c d e f g h"))
+	b := toElements(strings.Fields("a x c d y f g z"))
+
+	for name, diff := range map[string]func(a, b []Element, opts ...Option) []DiffOp{
+		"myers":     DiffElements,
+		"histogram": DiffElementsHistogram,
+	} {
+		var c CallCounts
+		diff(a, b, WithCallCounts(&c))
+		if c.Equal == 0 || c.Hash == 0 {
+			t.Errorf("%s: counts = %+v, want both > 0", name, c)
+		}
+
+		// Counters accumulate across calls
+		first := c
+		diff(a, b, WithCallCounts(&c))
+		if c != (CallCounts{Equal: 2 * first.Equal, Hash: 2 * first.Hash}) {
+			t.Errorf("%s: counts after two calls = %+v, want twice %+v", name, c, first)
+		}
+	}
+}
+
+func TestWithCallCounts_SameOps(t *testing.T) {
+	// Blank lines, indentation, and stopwords steer postprocessing and
+	// anchor choice, so counting must look through its wrapper
+	a := []string{"a", "", "b", "if x {", "\ty()", "}", "z"}
+	b := []string{"a", "", "b", "", "c", "", "b", "if x {", "\ty()", "}", "if w {", "\ty()", "}", "z"}
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithIndentHeuristic(true)},
+		{WithPreprocessing(false)},
+	} {
+		var c CallCounts
+		for _, diff := range []func(a, b []string, opts ...Option) []DiffOp{Diff, DiffHistogram} {
+			want := diff(a, b, opts...)
+			got := diff(a, b, append(opts, WithCallCounts(&c))...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ops with counting = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestCountedElement_Equal(t *testing.T) {
+	var c CallCounts
+	wrapped := countCalls(toElements([]string{"x", "y"}), &c)
+
+	if !wrapped[0].Equal(StringElement("x")) {
+		t.Error("wrapped element does not equal a plain one")
+	}
+	if wrapped[0].Equal(wrapped[1]) {
+		t.Error("wrapped elements x and y are equal")
+	}
+	if !StringElement("y").Equal(unwrapElement(wrapped[1])) {
+		t.Error("unwrapped element does not equal a plain one")
+	}
+	if c.Equal != 2 || c.Hash != 0 {
+		t.Errorf("counts = %+v, want {Equal:2 Hash:0}", c)
+	}
+}
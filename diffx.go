@@ -5,8 +5,21 @@
 //   - Preprocessing: Filters out high-frequency elements that cause spurious matches
 //   - Heuristics: Early termination for expensive comparisons
 //   - Postprocessing: Shifts diff boundaries for more readable output
+//
+// # Concurrency
+//
+// The package keeps no mutable state between calls, so Diff, DiffHistogram,
+// and the other functions may be called from any number of goroutines, also
+// with the same input slices and the same Option values. Elements shared by
+// concurrent calls must have Equal and Hash methods that are safe for
+// concurrent use, as those of StringElement are. Options that write results
+// to a value of the caller, such as WithStats, WithAnnotations,
+// WithCallCounts, and WithContractCheck, must be given a separate value for
+// each call.
 package diffx
 
+import "fmt"
+
 // OpType identifies the type of edit operation.
 type OpType int
 
@@ -42,14 +55,126 @@ type DiffOp struct {
 	BEnd   int // end index in sequence B (exclusive)
 }
 
+// String returns a compact description of the operation, such as
+// "= a[0:3] b[0:3]", "D a[3:7]", or "I b[3:5]". The position of a
+// deletion in B and of an insertion in A is left out, unless its range is
+// not empty, which makes the operation invalid.
+func (op DiffOp) String() string {
+	a := fmt.Sprintf("a[%d:%d]", op.AStart, op.AEnd)
+	b := fmt.Sprintf("b[%d:%d]", op.BStart, op.BEnd)
+	switch {
+	case op.Type == Equal:
+		return "= " + a + " " + b
+	case op.Type == Delete && op.BStart == op.BEnd:
+		return "D " + a
+	case op.Type == Delete:
+		return "D " + a + " " + b
+	case op.Type == Insert && op.AStart == op.AEnd:
+		return "I " + b
+	case op.Type == Insert:
+		return "I " + a + " " + b
+	default:
+		return "? " + a + " " + b
+	}
+}
+
+// LenA returns the number of elements of A the operation covers: zero for
+// an insertion.
+func (op DiffOp) LenA() int {
+	return op.AEnd - op.AStart
+}
+
+// LenB returns the number of elements of B the operation covers: zero for
+// a deletion.
+func (op DiffOp) LenB() int {
+	return op.BEnd - op.BStart
+}
+
+// IsChange reports whether the operation is a deletion or an insertion.
+func (op DiffOp) IsChange() bool {
+	return op.Type != Equal
+}
+
+// A returns the elements of a the operation covers, as a sub-slice of a
+// without copying. Its capacity ends with it, so appending to it does not
+// overwrite the rest of a. a must be the A sequence that was diffed.
+func (op DiffOp) A(a []string) []string {
+	return SliceA(op, a)
+}
+
+// B is like A, for the elements of b.
+func (op DiffOp) B(b []string) []string {
+	return SliceB(op, b)
+}
+
+// SliceA is like DiffOp.A for sequences of any element type, such as
+// []Element or the slices behind a custom Element.
+func SliceA[T any](op DiffOp, a []T) []T {
+	return a[op.AStart:op.AEnd:op.AEnd]
+}
+
+// SliceB is like DiffOp.B for sequences of any element type.
+func SliceB[T any](op DiffOp, b []T) []T {
+	return b[op.BStart:op.BEnd:op.BEnd]
+}
+
+// myersCore runs preprocessing and the Myers algorithm on a and b and
+// returns the ops in their indices, before any postprocessing.
+func myersCore(a, b []Element, o *options) []DiffOp {
+	origA, origB := a, b
+
+	// Preprocessing: filter confusing elements
+	var mapping *indexMapping
+	if o.preprocessing {
+		a, b, mapping = filterConfusingElements(a, b)
+		o.stats.recordFilter(origA, origB, len(a), len(b))
+	}
+
+	// Run the core algorithm
+	ctx := newDiffContext(a, b, o)
+	if len(a) > 0 || len(b) > 0 {
+		ctx.compareSeq(0, len(a), 0, len(b), o.forceMinimal)
+	}
+
+	// Build operations from change marks
+	ops := ctx.buildOps()
+
+	// Map indices back to original sequences
+	if mapping != nil {
+		ops = mapping.mapOps(ops)
+		if o.gapRefinement {
+			ops = mapping.refineGaps(ops, origA, origB, o)
+		}
+	}
+	return ops
+}
+
 // options holds configuration for the diff algorithm.
 type options struct {
 	useHeuristic      bool
 	forceMinimal      bool
 	costLimit         int
 	preprocessing     bool
+	gapRefinement     bool
 	postprocessing    bool
 	anchorElimination bool
+	commonPrefix      int
+	commonSuffix      int
+	editCost          int
+	indentHeuristic   bool
+	chainLimit        int
+	chainFallback     bool
+	workLimit         int
+	budget            *workBudget // per call, set by withWorkLimit
+	coarse            *bool       // set when the diff falls back to coarseDiff
+	anchorOpts        *anchorOptions
+	ignoreOpts        *ignoreOptions
+	stats             *Stats
+	trace             func(TraceEvent)
+	annotations       *[]Annotation
+	callCounts        *CallCounts
+	contractErr       *error
+	metrics           Metrics
 }
 
 // defaultOptions returns options with sensible defaults.
@@ -61,6 +186,14 @@ func defaultOptions() *options {
 		preprocessing:     true,
 		postprocessing:    true,
 		anchorElimination: true,
+		editCost:          0, // efficiency cleanup disabled
+		indentHeuristic:   false,
+		chainLimit:        defaultChainLimit,
+		chainFallback:     false,
+		workLimit:         defaultWorkLimit,
+		anchorOpts:        defaultAnchorOptions(),
+		ignoreOpts:        defaultIgnoreOptions(),
+		metrics:           registeredMetrics(),
 	}
 }
 
@@ -114,7 +247,11 @@ func WithPostprocessing(enabled bool) Option {
 // Diff compares two string slices using the Myers algorithm.
 // For histogram-style diff, use DiffHistogram instead.
 func Diff(a, b []string, opts ...Option) []DiffOp {
-	return DiffElements(toElements(a), toElements(b), opts...)
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return DiffElements(o.ignoreOpts.elements(a), o.ignoreOpts.elements(b), opts...)
 }
 
 // DiffElements compares arbitrary Element slices using the Myers algorithm.
@@ -125,6 +262,24 @@ func DiffElements(a, b []Element, opts ...Option) []DiffOp {
 	for _, opt := range opts {
 		opt(o)
 	}
+	if err := checkSize(len(a), len(b)); err != nil {
+		panic(err)
+	}
+
+	if o.annotations != nil {
+		return annotate(a, b, opts, o, o.preprocessing, DiffElements)
+	}
+	if o.metrics != nil {
+		return observe("myers", a, b, opts, o, DiffElements)
+	}
+	if o.contractErr != nil {
+		return checkedDiff(a, b, opts, o, DiffElements)
+	}
+	if o.callCounts != nil {
+		a, b = countCalls(a, o.callCounts), countCalls(b, o.callCounts)
+	}
+
+	o.stats.recordCall(a, b)
 
 	// Handle trivial cases
 	if len(a) == 0 && len(b) == 0 {
@@ -149,45 +304,26 @@ func DiffElements(a, b []Element, opts ...Option) []DiffOp {
 		}}
 	}
 
-	// Keep original sequences for postprocessing
-	origA, origB := a, b
-
-	// Create context and run algorithm
-	ctx := newDiffContext(a, b, o)
+	// Run the core algorithm, with preprocessing, between any common
+	// prefix and suffix the caller hinted at, within the work limit
+	ops := diffWindow(a, b, o, withWorkLimit(myersCore))
 
-	// Preprocessing: filter confusing elements
-	var mapping *indexMapping
-	if o.preprocessing {
-		a, b, mapping = filterConfusingElements(a, b)
-		if len(a) > 0 || len(b) > 0 {
-			// Re-create context with filtered sequences
-			ctx = newDiffContext(a, b, o)
-		}
-	}
-
-	// Run the core algorithm
-	if len(a) > 0 || len(b) > 0 {
-		ctx.compareSeq(0, len(a), 0, len(b), o.forceMinimal)
-	}
-
-	// Build operations from change marks
-	ops := ctx.buildOps()
-
-	// Map indices back to original sequences
-	if mapping != nil {
-		ops = mapping.mapOps(ops)
-	}
-
-	// Anchor elimination: remove weak anchors (short, high-frequency Equal regions)
+	// Anchor elimination: merge ops and optionally remove weak anchors
+	// (short, high-frequency Equal regions).
 	// This must happen before boundary shifting so the shifted boundaries are clean
 	if o.anchorElimination {
-		ops = eliminateWeakAnchors(ops, origA, origB)
+		ops = eliminateWeakAnchors(ops, a, b, o.anchorOpts)
+	}
+
+	// Efficiency cleanup: fold short equalities between changes into larger hunks
+	if o.editCost > 0 {
+		ops = cleanupEfficiency(ops, o.editCost)
 	}
 
 	// Postprocessing: shift boundaries for readability
 	// Use original sequences since ops now have original indices
 	if o.postprocessing {
-		ops = shiftBoundaries(ops, origA, origB)
+		ops = shiftBoundaries(ops, a, b, o)
 	}
 
 	return ops
@@ -1,7 +1,12 @@
 package diffx
 
 import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math/rand"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -218,6 +223,79 @@ func TestOpType_String(t *testing.T) {
 	}
 }
 
+func TestDiffOp_String(t *testing.T) {
+	tests := []struct {
+		op   DiffOp
+		want string
+	}{
+		{DiffOp{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3}, "= a[0:3] b[0:3]"},
+		{DiffOp{Type: Delete, AStart: 3, AEnd: 7, BStart: 3, BEnd: 3}, "D a[3:7]"},
+		{DiffOp{Type: Insert, AStart: 7, AEnd: 7, BStart: 3, BEnd: 5}, "I b[3:5]"},
+		{DiffOp{Type: Delete, AStart: 3, AEnd: 7, BStart: 3, BEnd: 4}, "D a[3:7] b[3:4]"},
+		{DiffOp{Type: Insert, AStart: 1, AEnd: 2, BStart: 3, BEnd: 5}, "I a[1:2] b[3:5]"},
+		{DiffOp{Type: OpType(9), AStart: 1, AEnd: 2, BStart: 3, BEnd: 4}, "? a[1:2] b[3:4]"},
+	}
+	for _, tt := range tests {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+
+	ops := []DiffOp{tests[0].op, tests[1].op}
+	if got := fmt.Sprint(ops); got != "[= a[0:3] b[0:3] D a[3:7]]" {
+		t.Errorf("fmt.Sprint(ops) = %q", got)
+	}
+}
+
+func TestDiffOp_Accessors(t *testing.T) {
+	tests := []struct {
+		op         DiffOp
+		lenA, lenB int
+		change     bool
+	}{
+		{DiffOp{Type: Equal, AStart: 2, AEnd: 5, BStart: 1, BEnd: 4}, 3, 3, false},
+		{DiffOp{Type: Delete, AStart: 5, AEnd: 7, BStart: 4, BEnd: 4}, 2, 0, true},
+		{DiffOp{Type: Insert, AStart: 7, AEnd: 7, BStart: 4, BEnd: 8}, 0, 4, true},
+	}
+	for _, tt := range tests {
+		if tt.op.LenA() != tt.lenA || tt.op.LenB() != tt.lenB || tt.op.IsChange() != tt.change {
+			t.Errorf("%v: LenA %d, LenB %d, IsChange %v; want %d, %d, %v",
+				tt.op, tt.op.LenA(), tt.op.LenB(), tt.op.IsChange(), tt.lenA, tt.lenB, tt.change)
+		}
+	}
+}
+
+func TestDiffOp_Slices(t *testing.T) {
+	a := []string{"the", "quick", "fox"}
+	b := []string{"the", "slow", "red", "fox"}
+	ops := Diff(a, b)
+
+	var gotA, gotB []string
+	for _, op := range ops {
+		gotA = append(gotA, op.A(a)...)
+		gotB = append(gotB, op.B(b)...)
+	}
+	if !reflect.DeepEqual(gotA, a) || !reflect.DeepEqual(gotB, b) {
+		t.Errorf("ops cover %q and %q, want %q and %q", gotA, gotB, a, b)
+	}
+
+	// Appending to a view must not overwrite the sequence
+	first := ops[0].A(a)
+	_ = append(first, "overwritten")
+	if a[1] != "quick" {
+		t.Errorf("append to A() changed a[1] to %q", a[1])
+	}
+
+	elems := []Element{StringElement("x"), StringElement("y")}
+	op := DiffOp{Type: Delete, AStart: 1, AEnd: 2}
+	if got := SliceA(op, elems); len(got) != 1 || got[0] != StringElement("y") || cap(got) != 1 {
+		t.Errorf("SliceA() = %v (cap %d), want [y] with cap 1", got, cap(got))
+	}
+	if got := SliceB(op, elems); len(got) != 0 {
+		t.Errorf("SliceB() = %v, want empty", got)
+	}
+}
+
 func TestDiff_WithOptions(t *testing.T) {
 	a := []string{"a", "b", "c"}
 	b := []string{"a", "x", "c"}
@@ -430,3 +508,151 @@ func BenchmarkDiff_Large(b *testing.B) {
 		Diff(a, bSeq)
 	}
 }
+
+// determinismInputs returns pseudo-random pairs of token sequences with
+// many repeated tokens, so that every tie-breaking rule is exercised.
+func determinismInputs() [][2][]string {
+	rng := rand.New(rand.NewSource(1))
+	vocab := []string{"the", "a", "x", "y", "z", "{", "}", "", "    return", "end"}
+	seq := func(n int) []string {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = vocab[rng.Intn(len(vocab))]
+		}
+		return s
+	}
+	var pairs [][2][]string
+	for _, n := range []int{5, 20, 60, 200, 600} {
+		a := seq(n)
+		b := append([]string(nil), a...)
+		for i := 0; i < n/4+1; i++ {
+			j := rng.Intn(len(b))
+			switch rng.Intn(3) {
+			case 0:
+				b = append(b[:j], b[j+1:]...)
+			case 1:
+				b = append(b[:j], append([]string{vocab[rng.Intn(len(vocab))]}, b[j:]...)...)
+			default:
+				b[j] = vocab[rng.Intn(len(vocab))]
+			}
+		}
+		pairs = append(pairs, [2][]string{a, b}, [2][]string{a, seq(n)})
+	}
+	return pairs
+}
+
+// hashOps returns a checksum of an edit script.
+func hashOps(h hash.Hash64, ops []DiffOp) {
+	for _, op := range ops {
+		fmt.Fprintf(h, "%d %d %d %d %d;", op.Type, op.AStart, op.AEnd, op.BStart, op.BEnd)
+	}
+	h.Write([]byte{'\n'})
+}
+
+func TestDiff_Deterministic(t *testing.T) {
+	// Users cache diffs by content hash, so identical inputs must produce
+	// identical ops on every run and every architecture. The checksums
+	// below pin the output; an intentional change to the algorithms
+	// requires updating them.
+	configs := []struct {
+		name      string
+		histogram bool
+		opts      []Option
+		want      uint64
+	}{
+		{"myers", false, nil, 0x2a928ed3bb7b3bbe},
+		{"myers minimal", false, []Option{WithMinimal(true)}, 0x8176687aa1d58281},
+		{"myers indent", false, []Option{WithIndentHeuristic(true)}, 0xe0eeaf77b1eefd47},
+		{"myers raw", false, []Option{WithPreprocessing(false), WithPostprocessing(false), WithAnchorElimination(false)}, 0x81b3aad3ebb074f0},
+		{"histogram", true, nil, 0xce7e5c7eeaf4b157},
+		{"histogram indent", true, []Option{WithIndentHeuristic(true)}, 0x73a3409fa8a05ded},
+	}
+
+	inputs := determinismInputs()
+	for _, cfg := range configs {
+		t.Run(cfg.name, func(t *testing.T) {
+			run := func() ([][]DiffOp, uint64) {
+				h := fnv.New64a()
+				var all [][]DiffOp
+				for _, in := range inputs {
+					var ops []DiffOp
+					if cfg.histogram {
+						ops = DiffHistogram(in[0], in[1], cfg.opts...)
+					} else {
+						ops = Diff(in[0], in[1], cfg.opts...)
+					}
+					hashOps(h, ops)
+					all = append(all, ops)
+				}
+				return all, h.Sum64()
+			}
+
+			first, sum := run()
+			for i := 0; i < 3; i++ {
+				if again, _ := run(); !reflect.DeepEqual(again, first) {
+					t.Fatalf("run %d differs from the first run", i+2)
+				}
+			}
+			if sum != cfg.want {
+				t.Errorf("checksum = %#x, want %#x", sum, cfg.want)
+			}
+		})
+	}
+}
+
+func TestDiff_Concurrent(t *testing.T) {
+	// Concurrent calls may share inputs and Option values; run with -race
+	// to check that nothing they share is written
+	opts := []Option{
+		WithIgnoreCase(true),
+		WithIndentHeuristic(true),
+		WithWeakAnchorElimination(true),
+		WithWeakAnchorMaxLen(0),
+		WithWeakAnchorFrequency(0),
+		WithContextWindow(0),
+		WithHistogramChainLimit(0),
+		WithHistogramChainFallback(true),
+	}
+	inputs := determinismInputs()
+	elems := make([][2][]Element, len(inputs))
+	for i, in := range inputs {
+		elems[i] = [2][]Element{toElements(in[0]), toElements(in[1])}
+	}
+
+	diffs := []struct {
+		name string
+		diff func(i int) []DiffOp
+	}{
+		{"Diff", func(i int) []DiffOp { return Diff(inputs[i][0], inputs[i][1], opts...) }},
+		{"DiffHistogram", func(i int) []DiffOp { return DiffHistogram(inputs[i][0], inputs[i][1], opts...) }},
+		{"DiffElements", func(i int) []DiffOp { return DiffElements(elems[i][0], elems[i][1], opts...) }},
+		{"DiffElementsHistogram", func(i int) []DiffOp { return DiffElementsHistogram(elems[i][0], elems[i][1], opts...) }},
+	}
+	for _, d := range diffs {
+		t.Run(d.name, func(t *testing.T) {
+			want := make([][]DiffOp, len(inputs))
+			for i := range inputs {
+				want[i] = d.diff(i)
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan string, 8*len(inputs))
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range inputs {
+						if got := d.diff(i); !reflect.DeepEqual(got, want[i]) {
+							errs <- fmt.Sprintf("input %d: concurrent result differs from sequential", i)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+		})
+	}
+}
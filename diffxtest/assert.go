@@ -0,0 +1,145 @@
+package diffxtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dacharyc/diffx"
+)
+
+// Assertion helpers.
+//
+// Comparing multi-line strings with reflect.DeepEqual or == fails with
+// both values dumped in full, leaving the reader to find the difference.
+// These helpers fail with a line diff instead, highlighting the words that
+// changed within each changed line.
+
+// assertContext is the number of unchanged lines shown around each change.
+const assertContext = 3
+
+// ANSI escape sequences for failure messages.
+const (
+	colorWant  = "\x1b[31m"
+	colorGot   = "\x1b[32m"
+	colorReset = "\x1b[m"
+	reverseOn  = "\x1b[7m"
+	reverseOff = "\x1b[27m"
+)
+
+// AssertEqualLines reports an error on t if got differs from want, with a
+// line diff of the two as the message, and returns whether they are equal.
+// Removed and added lines are shown in red and green, with the words that
+// changed in reverse video; when the NO_COLOR environment variable is set
+// or TERM is "dumb", changed words are marked [-like this-] and
+// {+like this+} instead.
+func AssertEqualLines(t testing.TB, want, got string) bool {
+	t.Helper()
+	if want == got {
+		return true
+	}
+	t.Errorf("lines differ (-want +got):\n%s", lineDiff(want, got, useColor()))
+	return false
+}
+
+// RequireEqualLines is like AssertEqualLines but stops the test with
+// t.Fatalf when the strings differ.
+func RequireEqualLines(t testing.TB, want, got string) {
+	t.Helper()
+	if want != got {
+		t.Fatalf("lines differ (-want +got):\n%s", lineDiff(want, got, useColor()))
+	}
+}
+
+// useColor reports whether failure messages should use ANSI color.
+func useColor() bool {
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// lineDiff renders a diff of want and got, one line per output line, with
+// "- " and "+ " prefixes for removed and added lines and "  " for
+// unchanged ones. Long unchanged runs are elided.
+func lineDiff(want, got string, color bool) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+	ops := diffx.Diff(a, b)
+
+	var out []string
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		switch op.Type {
+		case diffx.Equal:
+			out = appendContext(out, a[op.AStart:op.AEnd], i == 0, i == len(ops)-1)
+		case diffx.Delete:
+			oldText := strings.Join(a[op.AStart:op.AEnd], "\n")
+			if i+1 < len(ops) && ops[i+1].Type == diffx.Insert {
+				next := ops[i+1]
+				del, ins := diffx.HighlightText(oldText, strings.Join(b[next.BStart:next.BEnd], "\n"))
+				out = appendMarked(out, "- ", del, colorWant, "[-", "-]", color)
+				out = appendMarked(out, "+ ", ins, colorGot, "{+", "+}", color)
+				i++
+				continue
+			}
+			out = appendMarked(out, "- ", []diffx.Segment{{Text: oldText}}, colorWant, "", "", color)
+		case diffx.Insert:
+			newText := strings.Join(b[op.BStart:op.BEnd], "\n")
+			out = appendMarked(out, "+ ", []diffx.Segment{{Text: newText}}, colorGot, "", "", color)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// appendContext appends unchanged lines, keeping only assertContext lines
+// next to changes.
+func appendContext(out, lines []string, first, last bool) []string {
+	head, tail := assertContext, assertContext
+	if first {
+		head = 0
+	}
+	if last {
+		tail = 0
+	}
+	if len(lines) > head+tail+1 {
+		for _, l := range lines[:head] {
+			out = append(out, "  "+l)
+		}
+		out = append(out, "  ...")
+		lines = lines[len(lines)-tail:]
+	}
+	for _, l := range lines {
+		out = append(out, "  "+l)
+	}
+	return out
+}
+
+// appendMarked appends the lines of segs with the given prefix. With
+// color, whole lines take lineColor and changed segments are reversed;
+// without it, changed segments are wrapped in open and close.
+func appendMarked(out []string, prefix string, segs []diffx.Segment, lineColor, open, close string, color bool) []string {
+	if color {
+		open, close = reverseOn, reverseOff
+	}
+	var sb strings.Builder
+	flush := func() {
+		line := prefix + sb.String()
+		if color {
+			line = lineColor + line + colorReset
+		}
+		out = append(out, line)
+		sb.Reset()
+	}
+	for _, s := range segs {
+		for i, part := range strings.Split(s.Text, "\n") {
+			if i > 0 {
+				flush()
+			}
+			if s.Changed && part != "" {
+				sb.WriteString(open + part + close)
+			} else {
+				sb.WriteString(part)
+			}
+		}
+	}
+	flush()
+	return out
+}
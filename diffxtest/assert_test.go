@@ -0,0 +1,108 @@
+package diffxtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		want, got string
+		diff      string
+	}{
+		{
+			name: "changed word",
+			want: "alpha\nthe quick fox\nomega",
+			got:  "alpha\nthe slow fox\nomega",
+			diff: "  alpha\n- the [-quick-] fox\n+ the {+slow+} fox\n  omega",
+		},
+		{
+			name: "added line",
+			want: "a\nb",
+			got:  "a\nnew\nb",
+			diff: "  a\n+ new\n  b",
+		},
+		{
+			name: "removed line",
+			want: "a\nold\nb",
+			got:  "a\nb",
+			diff: "  a\n- old\n  b",
+		},
+		{
+			name: "trailing newline",
+			want: "a\n",
+			got:  "a",
+			diff: "  a\n- ",
+		},
+		{
+			name: "long context elided",
+			want: "1\n2\n3\n4\n5\n6\nx\n7\n8\n9\n10\n11",
+			got:  "1\n2\n3\n4\n5\n6\ny\n7\n8\n9\n10\n11",
+			diff: "  ...\n  4\n  5\n  6\n- [-x-]\n+ {+y+}\n  7\n  8\n  9\n  ...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lineDiff(tt.want, tt.got, false); got != tt.diff {
+				t.Errorf("lineDiff() =\n%s\nwant\n%s", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestLineDiff_Color(t *testing.T) {
+	got := lineDiff("the quick fox", "the slow fox", true)
+	want := "\x1b[31m- the \x1b[7mquick\x1b[27m fox\x1b[m\n\x1b[32m+ the \x1b[7mslow\x1b[27m fox\x1b[m"
+	if got != want {
+		t.Errorf("lineDiff() = %q, want %q", got, want)
+	}
+}
+
+// recorder captures failures reported through testing.TB.
+type recorder struct {
+	testing.TB
+	msg   string
+	fatal bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.msg = fmt.Sprintf(format, args...)
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.msg = fmt.Sprintf(format, args...)
+	r.fatal = true
+}
+
+func TestAssertEqualLines(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	r := &recorder{}
+	if !AssertEqualLines(r, "same", "same") || r.msg != "" {
+		t.Errorf("equal strings reported a failure: %q", r.msg)
+	}
+
+	r = &recorder{}
+	if AssertEqualLines(r, "a\nb", "a\nc") {
+		t.Error("AssertEqualLines returned true for different strings")
+	}
+	want := "lines differ (-want +got):\n  a\n- [-b-]\n+ {+c+}"
+	if r.msg != want || r.fatal {
+		t.Errorf("message = %q (fatal %v), want %q", r.msg, r.fatal, want)
+	}
+}
+
+func TestRequireEqualLines(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	r := &recorder{}
+	RequireEqualLines(r, "x", "y")
+	if !r.fatal || !strings.Contains(r.msg, "- [-x-]") {
+		t.Errorf("message = %q (fatal %v), want a fatal diff", r.msg, r.fatal)
+	}
+}
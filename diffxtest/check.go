@@ -0,0 +1,106 @@
+package diffxtest
+
+import (
+	"fmt"
+
+	"github.com/dacharyc/diffx"
+)
+
+// CheckScript reports the first way in which ops fails to be an edit
+// script for a and b, or nil if it is one. A valid script covers both
+// sequences with contiguous, non-empty operations in order; Equal
+// operations pair equal elements, Delete operations cover only A, and
+// Insert operations cover only B.
+func CheckScript(ops []diffx.DiffOp, a, b []diffx.Element) error {
+	if err := checkScript(ops, a, b); err != nil {
+		return fmt.Errorf("diffxtest: %w", err)
+	}
+	return nil
+}
+
+// checkScript implements CheckScript without the package prefix on errors.
+func checkScript(ops []diffx.DiffOp, a, b []diffx.Element) error {
+	i, j := 0, 0
+	for k, op := range ops {
+		if op.AStart != i || op.BStart != j {
+			return fmt.Errorf("op %d %v does not start at A[%d], B[%d]", k, op, i, j)
+		}
+		if op.AEnd < op.AStart || op.BEnd < op.BStart || op.AEnd > len(a) || op.BEnd > len(b) {
+			return fmt.Errorf("op %d %v has invalid ranges for lengths %d and %d", k, op, len(a), len(b))
+		}
+		if op.AEnd == op.AStart && op.BEnd == op.BStart {
+			return fmt.Errorf("op %d %v is empty", k, op)
+		}
+		switch op.Type {
+		case diffx.Equal:
+			if op.AEnd-op.AStart != op.BEnd-op.BStart {
+				return fmt.Errorf("op %d %v has ranges of different lengths", k, op)
+			}
+			for x, y := op.AStart, op.BStart; x < op.AEnd; x, y = x+1, y+1 {
+				if !a[x].Equal(b[y]) {
+					return fmt.Errorf("op %d %v pairs unequal elements A[%d] and B[%d]", k, op, x, y)
+				}
+			}
+		case diffx.Delete:
+			if op.BEnd != op.BStart {
+				return fmt.Errorf("op %d %v deletes but covers B", k, op)
+			}
+		case diffx.Insert:
+			if op.AEnd != op.AStart {
+				return fmt.Errorf("op %d %v inserts but covers A", k, op)
+			}
+		default:
+			return fmt.Errorf("op %d has unknown type %v", k, op.Type)
+		}
+		i, j = op.AEnd, op.BEnd
+	}
+	if i != len(a) || j != len(b) {
+		return fmt.Errorf("script ends at A[%d], B[%d], want A[%d], B[%d]", i, j, len(a), len(b))
+	}
+	return nil
+}
+
+// CheckElements reports the first violation of the Element contract
+// among elems, or nil: Equal must be reflexive and symmetric, and equal
+// elements must have equal hashes. It compares every pair, so keep elems
+// small.
+func CheckElements(elems []diffx.Element) error {
+	for i, e := range elems {
+		if !e.Equal(e) {
+			return fmt.Errorf("diffxtest: element %d is not equal to itself", i)
+		}
+		for j := i + 1; j < len(elems); j++ {
+			f := elems[j]
+			eq := e.Equal(f)
+			if eq != f.Equal(e) {
+				return fmt.Errorf("diffxtest: elements %d and %d: Equal is not symmetric", i, j)
+			}
+			if eq && e.Hash() != f.Hash() {
+				return fmt.Errorf("diffxtest: elements %d and %d are equal but have different hashes", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// Check diffs a and b with both the Myers and histogram algorithms under
+// opts and checks each script with CheckScript.
+func Check(a, b []diffx.Element, opts ...diffx.Option) error {
+	if err := checkScript(diffx.DiffElements(a, b, opts...), a, b); err != nil {
+		return fmt.Errorf("diffxtest: DiffElements: %w", err)
+	}
+	if err := checkScript(diffx.DiffElementsHistogram(a, b, opts...), a, b); err != nil {
+		return fmt.Errorf("diffxtest: DiffElementsHistogram: %w", err)
+	}
+	return nil
+}
+
+// Elements converts strings to StringElements, for checking generated
+// sequences without a custom Element type.
+func Elements(strs []string) []diffx.Element {
+	elems := make([]diffx.Element, len(strs))
+	for i, s := range strs {
+		elems[i] = diffx.StringElement(s)
+	}
+	return elems
+}
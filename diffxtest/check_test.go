@@ -0,0 +1,128 @@
+package diffxtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dacharyc/diffx"
+)
+
+func TestCheckScript(t *testing.T) {
+	a := Elements([]string{"a", "b", "c"})
+	b := Elements([]string{"a", "x", "c"})
+
+	tests := []struct {
+		name string
+		ops  []diffx.DiffOp
+		want string
+	}{
+		{
+			name: "valid",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: diffx.Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: diffx.Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+				{Type: diffx.Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+			},
+		},
+		{
+			name: "gap",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: diffx.Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+			},
+			want: "does not start at A[1], B[1]",
+		},
+		{
+			name: "unequal elements",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+			},
+			want: "pairs unequal elements A[1] and B[1]",
+		},
+		{
+			name: "out of range",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Delete, AStart: 0, AEnd: 4, BStart: 0, BEnd: 0},
+			},
+			want: "invalid ranges",
+		},
+		{
+			name: "empty op",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Insert, AStart: 0, AEnd: 0, BStart: 0, BEnd: 0},
+			},
+			want: "is empty",
+		},
+		{
+			name: "delete covers B",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+			},
+			want: "deletes but covers B",
+		},
+		{
+			name: "incomplete",
+			ops: []diffx.DiffOp{
+				{Type: diffx.Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+			},
+			want: "script ends at A[1], B[1], want A[3], B[3]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckScript(tt.ops, a, b)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// badHash is an Element whose Equal ignores case but whose Hash does not.
+type badHash string
+
+func (s badHash) Equal(other diffx.Element) bool {
+	o, ok := other.(badHash)
+	return ok && strings.EqualFold(string(s), string(o))
+}
+
+func (s badHash) Hash() uint64 {
+	return diffx.StringElement(s).Hash()
+}
+
+func TestCheckElements(t *testing.T) {
+	if err := CheckElements(Elements([]string{"a", "b", "a"})); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := CheckElements([]diffx.Element{badHash("a"), badHash("A")})
+	if err == nil || !strings.Contains(err.Error(), "different hashes") {
+		t.Errorf("error = %v, want a hash mismatch", err)
+	}
+}
+
+func TestCheck_Generated(t *testing.T) {
+	configs := [][]Option{
+		nil,
+		{WithVocabulary(3)},
+		{WithRepetition(0.8), WithEditRate(0.3)},
+		{WithLength(100, 300), WithEditRate(0.02)},
+	}
+	for i, opts := range configs {
+		g := NewGenerator(int64(i), opts...)
+		for n := 0; n < 200; n++ {
+			a, b := g.Pair()
+			if err := Check(Elements(a), Elements(b)); err != nil {
+				t.Fatalf("config %d, pair %d: %v\nA: %v\nB: %v", i, n, err, a, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package diffxtest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerator_Deterministic(t *testing.T) {
+	a1, b1 := NewGenerator(7).Pair()
+	a2, b2 := NewGenerator(7).Pair()
+	if !reflect.DeepEqual(a1, a2) || !reflect.DeepEqual(b1, b2) {
+		t.Error("generators with the same seed produced different pairs")
+	}
+}
+
+func TestGenerator_Sequence(t *testing.T) {
+	g := NewGenerator(1, WithLength(5, 10), WithVocabulary(4))
+	for i := 0; i < 100; i++ {
+		seq := g.Sequence()
+		if len(seq) < 5 || len(seq) > 10 {
+			t.Fatalf("len = %d, want 5 to 10", len(seq))
+		}
+		for _, tok := range seq {
+			switch tok {
+			case "t0", "t1", "t2", "t3":
+			default:
+				t.Fatalf("token %q is outside a vocabulary of 4", tok)
+			}
+		}
+	}
+}
+
+func TestGenerator_Repetition(t *testing.T) {
+	g := NewGenerator(1, WithVocabulary(1000), WithRepetition(1), WithLength(200, 200))
+	for _, tok := range g.Sequence() {
+		if tok != "t0" && tok != "t1" && tok != "t2" {
+			t.Fatalf("token %q is not a common token", tok)
+		}
+	}
+}
+
+func TestGenerator_EditRate(t *testing.T) {
+	g := NewGenerator(1, WithEditRate(0), WithLength(50, 50))
+	a, b := g.Pair()
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("edit rate 0 changed the sequence:\n%v\n%v", a, b)
+	}
+
+	g = NewGenerator(1, WithEditRate(1), WithVocabulary(1000), WithLength(50, 50))
+	a, b = g.Pair()
+	if reflect.DeepEqual(a, b) {
+		t.Error("edit rate 1 left the sequence unchanged")
+	}
+}
+
+func TestGenerator_EditDoesNotModifyInput(t *testing.T) {
+	g := NewGenerator(1, WithEditRate(1))
+	a := []string{"x", "y", "z"}
+	g.Edit(a)
+	if !reflect.DeepEqual(a, []string{"x", "y", "z"}) {
+		t.Errorf("Edit modified its input: %v", a)
+	}
+}
+
+func TestOptions_Clamp(t *testing.T) {
+	o := defaultOptions()
+	WithVocabulary(0)(o)
+	WithRepetition(-1)(o)
+	WithEditRate(2)(o)
+	WithLength(-3, -5)(o)
+	want := &options{vocabulary: 1, repetition: 0, editRate: 1, minLen: 0, maxLen: 0}
+	if !reflect.DeepEqual(o, want) {
+		t.Errorf("options = %+v, want %+v", o, want)
+	}
+}
@@ -0,0 +1,260 @@
+package diffxtest
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Realistic edits.
+//
+// Random token noise exercises the algorithm's invariants but not its
+// output quality: real edits insert whole paragraphs, rewrap text, rename
+// identifiers everywhere they occur, and move sections around. These
+// mutations synthesize such edits from seed documents, so that quality
+// can be benchmarked on representative workloads. Documents are slices of
+// lines without line terminators.
+
+// words is the vocabulary for inserted text and new identifier names.
+var words = []string{
+	"the", "a", "of", "to", "and", "in", "is", "for", "that", "with",
+	"value", "request", "server", "config", "update", "return", "error",
+	"user", "file", "line", "change", "result", "option", "default",
+	"process", "handle", "buffer", "record", "table", "index", "cache",
+}
+
+// identPattern matches identifiers that are candidates for renaming.
+var identPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]{2,}`)
+
+// Mutate returns a copy of doc with n realistic edits applied, each chosen
+// at random from InsertParagraph, Rewrap, RenameIdentifier, and
+// ReorderSections. doc is not modified.
+func (g *Generator) Mutate(doc []string, n int) []string {
+	mutations := []func([]string) []string{
+		g.InsertParagraph,
+		g.Rewrap,
+		g.RenameIdentifier,
+		g.ReorderSections,
+	}
+	for i := 0; i < n; i++ {
+		doc = mutations[g.rng.Intn(len(mutations))](doc)
+	}
+	return clone(doc)
+}
+
+// InsertParagraph returns a copy of doc with a new paragraph of prose
+// inserted at a paragraph boundary, separated by a blank line.
+func (g *Generator) InsertParagraph(doc []string) []string {
+	var para []string
+	for i, n := 0, 1+g.rng.Intn(4); i < n; i++ {
+		para = append(para, g.sentence())
+	}
+
+	// Candidate positions: the start, the end, and after each blank line
+	positions := []int{0, len(doc)}
+	for i, line := range doc {
+		if isBlankLine(line) {
+			positions = append(positions, i+1)
+		}
+	}
+	at := positions[g.rng.Intn(len(positions))]
+
+	out := make([]string, 0, len(doc)+len(para)+1)
+	out = append(out, doc[:at]...)
+	if at == len(doc) && at > 0 && !isBlankLine(doc[at-1]) {
+		out = append(out, "")
+	}
+	out = append(out, para...)
+	if at < len(doc) {
+		out = append(out, "")
+	}
+	return append(out, doc[at:]...)
+}
+
+// Rewrap returns a copy of doc with one paragraph reflowed to a different
+// line width, keeping the paragraph's words and indentation. A document
+// without paragraphs is returned unchanged.
+func (g *Generator) Rewrap(doc []string) []string {
+	paras := paragraphs(doc)
+	if len(paras) == 0 {
+		return clone(doc)
+	}
+	p := paras[g.rng.Intn(len(paras))]
+	lines := doc[p[0]:p[1]]
+
+	indent := leadingSpace(lines[0])
+	fields := strings.Fields(strings.Join(lines, " "))
+	width := 30 + g.rng.Intn(60)
+
+	var wrapped []string
+	line := ""
+	for _, f := range fields {
+		if line != "" && len(indent)+len(line)+1+len(f) > width {
+			wrapped = append(wrapped, indent+line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += f
+	}
+	if line != "" {
+		wrapped = append(wrapped, indent+line)
+	}
+
+	out := make([]string, 0, len(doc)-len(lines)+len(wrapped))
+	out = append(out, doc[:p[0]]...)
+	out = append(out, wrapped...)
+	return append(out, doc[p[1]:]...)
+}
+
+// RenameIdentifier returns a copy of doc with every whole-word occurrence
+// of one identifier, chosen at random, replaced by a new name. A document
+// without identifiers is returned unchanged.
+func (g *Generator) RenameIdentifier(doc []string) []string {
+	seen := map[string]bool{}
+	for _, line := range doc {
+		for _, id := range identPattern.FindAllString(line, -1) {
+			seen[id] = true
+		}
+	}
+	if len(seen) == 0 {
+		return clone(doc)
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // Map order is random; the choice must not be
+
+	old := ids[g.rng.Intn(len(ids))]
+	name := old + capitalize(words[g.rng.Intn(len(words))])
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(old) + `\b`)
+
+	out := make([]string, len(doc))
+	for i, line := range doc {
+		out[i] = re.ReplaceAllLiteralString(line, name)
+	}
+	return out
+}
+
+// ReorderSections returns a copy of doc with one section moved to another
+// position. Sections start at Markdown headings ("#" lines); a document
+// without headings is split into paragraphs instead. A document with
+// fewer than two sections is returned unchanged.
+func (g *Generator) ReorderSections(doc []string) []string {
+	sections := sections(doc)
+	if len(sections) < 2 {
+		return clone(doc)
+	}
+
+	// Give the last section the blank line that ends the others, so that
+	// it is not run together with whatever follows it once moved
+	trimmed := false
+	last, prev := sections[len(sections)-1], sections[len(sections)-2]
+	if !isBlankLine(last[len(last)-1]) && isBlankLine(prev[len(prev)-1]) {
+		sections[len(sections)-1] = append(clone(last), "")
+		trimmed = true
+	}
+
+	from := g.rng.Intn(len(sections))
+	to := g.rng.Intn(len(sections) - 1)
+	if to >= from {
+		to++
+	}
+	moved := sections[from]
+	rest := append(append([][]string{}, sections[:from]...), sections[from+1:]...)
+	order := append(append(append([][]string{}, rest[:to]...), moved), rest[to:]...)
+
+	out := make([]string, 0, len(doc))
+	for _, s := range order {
+		out = append(out, s...)
+	}
+	if trimmed {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// sentence returns a random sentence built from the word list.
+func (g *Generator) sentence() string {
+	n := 4 + g.rng.Intn(10)
+	ws := make([]string, n)
+	for i := range ws {
+		ws[i] = words[g.rng.Intn(len(words))]
+	}
+	return capitalize(strings.Join(ws, " ")) + "."
+}
+
+// paragraphs returns the [start, end) line ranges of the runs of
+// non-blank lines in doc.
+func paragraphs(doc []string) [][2]int {
+	var paras [][2]int
+	start := -1
+	for i, line := range doc {
+		switch {
+		case isBlankLine(line) && start >= 0:
+			paras = append(paras, [2]int{start, i})
+			start = -1
+		case !isBlankLine(line) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		paras = append(paras, [2]int{start, len(doc)})
+	}
+	return paras
+}
+
+// sections splits doc into sections that each start at a Markdown
+// heading, or at a paragraph if doc has no headings. Lines before the
+// first section start form a section of their own, and every line belongs
+// to exactly one section.
+func sections(doc []string) [][]string {
+	var starts []int
+	for i, line := range doc {
+		if strings.HasPrefix(line, "#") {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 {
+		for _, p := range paragraphs(doc) {
+			starts = append(starts, p[0])
+		}
+	}
+	if len(starts) == 0 || starts[0] != 0 {
+		starts = append([]int{0}, starts...)
+	}
+
+	var out [][]string
+	for i, start := range starts {
+		end := len(doc)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		if end > start {
+			out = append(out, doc[start:end])
+		}
+	}
+	return out
+}
+
+func isBlankLine(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func clone(doc []string) []string {
+	return append([]string(nil), doc...)
+}
@@ -0,0 +1,129 @@
+package diffxtest
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var seedDoc = []string{
+	"# Intro",
+	"",
+	"The server reads its config at startup.",
+	"",
+	"# Usage",
+	"",
+	"Call handleRequest with a request. handleRequest returns",
+	"an error when the request is invalid.",
+	"",
+	"# Notes",
+	"",
+	"See the changelog.",
+}
+
+func TestInsertParagraph(t *testing.T) {
+	g := NewGenerator(1)
+	for i := 0; i < 50; i++ {
+		out := g.InsertParagraph(seedDoc)
+		if len(out) <= len(seedDoc) {
+			t.Fatalf("no lines inserted: %q", out)
+		}
+		// The original lines stay in order
+		if !isSubsequence(seedDoc, out) {
+			t.Fatalf("original lines reordered or lost: %q", out)
+		}
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	g := NewGenerator(1)
+	for i := 0; i < 50; i++ {
+		out := g.Rewrap(seedDoc)
+		if got, want := strings.Fields(strings.Join(out, " ")), strings.Fields(strings.Join(seedDoc, " ")); !reflect.DeepEqual(got, want) {
+			t.Fatalf("words changed:\n%q\n%q", got, want)
+		}
+		if got, want := len(paragraphs(out)), len(paragraphs(seedDoc)); got != want {
+			t.Fatalf("got %d paragraphs, want %d", got, want)
+		}
+	}
+}
+
+func TestRenameIdentifier(t *testing.T) {
+	g := NewGenerator(3)
+	doc := []string{"total = total + 1", "totals"}
+	for i := 0; i < 20; i++ {
+		out := g.RenameIdentifier(doc)
+		switch {
+		case out[0] != doc[0] && out[1] == doc[1]:
+			// "total" is renamed everywhere, but not within "totals"
+			name := strings.Fields(out[0])[0]
+			if name == "total" || out[0] != name+" = "+name+" + 1" {
+				t.Fatalf("rename not applied globally: %q", out)
+			}
+		case out[0] == doc[0] && out[1] != doc[1]:
+			// "totals" is renamed
+		default:
+			t.Fatalf("unexpected rename: %q", out)
+		}
+	}
+	if out := g.RenameIdentifier([]string{"1 + 2", ""}); !reflect.DeepEqual(out, []string{"1 + 2", ""}) {
+		t.Errorf("document without identifiers changed: %q", out)
+	}
+}
+
+func TestReorderSections(t *testing.T) {
+	g := NewGenerator(1)
+	for i := 0; i < 50; i++ {
+		out := g.ReorderSections(seedDoc)
+		if len(out) != len(seedDoc) {
+			t.Fatalf("got %d lines, want %d: %q", len(out), len(seedDoc), out)
+		}
+		got, want := clone(out), clone(seedDoc)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("lines changed: %q", out)
+		}
+		if !strings.HasPrefix(out[0], "#") {
+			t.Fatalf("sections split off their headings: %q", out)
+		}
+	}
+
+	// Without headings, paragraphs are sections and stay separated
+	doc := []string{"one", "", "two", "", "three"}
+	for i := 0; i < 20; i++ {
+		out := g.ReorderSections(doc)
+		if len(out) != 5 || out[1] != "" || out[3] != "" {
+			t.Fatalf("paragraphs run together: %q", out)
+		}
+	}
+}
+
+func TestMutate(t *testing.T) {
+	doc := clone(seedDoc)
+	out := NewGenerator(5).Mutate(doc, 4)
+	if reflect.DeepEqual(out, seedDoc) {
+		t.Error("Mutate made no changes")
+	}
+	if !reflect.DeepEqual(doc, seedDoc) {
+		t.Error("Mutate modified its input")
+	}
+	if again := NewGenerator(5).Mutate(doc, 4); !reflect.DeepEqual(again, out) {
+		t.Error("Mutate is not deterministic for a seed")
+	}
+	if err := Check(Elements(doc), Elements(out)); err != nil {
+		t.Error(err)
+	}
+}
+
+// isSubsequence reports whether sub appears in seq in order.
+func isSubsequence(sub, seq []string) bool {
+	i := 0
+	for _, s := range seq {
+		if i < len(sub) && sub[i] == s {
+			i++
+		}
+	}
+	return i == len(sub)
+}
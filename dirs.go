@@ -0,0 +1,277 @@
+package diffx
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Directory comparison.
+//
+// DiffDirs walks two directory trees, pairs files by relative path, and
+// diffs each pair line by line, so that callers comparing two checkouts or
+// two generated output trees do not have to write the walking and pairing
+// themselves.
+
+// FileStatus identifies how a file differs between two directories.
+type FileStatus int
+
+const (
+	// FileModified means the file exists in both trees with different content.
+	FileModified FileStatus = iota
+	// FileAdded means the file only exists in the second tree.
+	FileAdded
+	// FileRemoved means the file only exists in the first tree.
+	FileRemoved
+	// FileRenamed means the file moved to another path with the same
+	// content. DiffDirs does not report renames; see DiffManifests.
+	FileRenamed
+)
+
+// String returns a string representation of the FileStatus.
+func (s FileStatus) String() string {
+	switch s {
+	case FileModified:
+		return "Modified"
+	case FileAdded:
+		return "Added"
+	case FileRemoved:
+		return "Removed"
+	case FileRenamed:
+		return "Renamed"
+	default:
+		return "Unknown"
+	}
+}
+
+// FileDiff describes a file that differs between two directory trees.
+type FileDiff struct {
+	Path   string // slash-separated path relative to the tree roots
+	Status FileStatus
+	Binary bool // either version looks binary; A, B, and Ops are empty
+
+	// A and B are the lines of each version, each including its line
+	// terminator, as returned by strings.SplitAfter. Ops is a line diff of
+	// A and B; for added and removed files it covers the whole file.
+	A, B []string
+	Ops  []DiffOp
+
+	// EncodingA and EncodingB are the encodings detected for each version.
+	// A and B hold the text transcoded to UTF-8, so files that differ only
+	// in encoding have no changes in Ops.
+	EncodingA, EncodingB Encoding
+}
+
+// DirOption configures DiffDirs.
+type DirOption func(*dirOptions)
+
+// dirOptions configures the directory walk of DiffDirs.
+type dirOptions struct {
+	exclude   []string                     // patterns of paths to skip
+	transform func([]byte) ([]byte, error) // applied to file content before comparing
+	diffOpts  []Option                     // options of each file's line diff
+}
+
+// binarySniffLen is how much of a file is checked for NUL bytes when
+// deciding whether it is binary, as in Git.
+const binarySniffLen = 8000
+
+// DiffDirs compares the regular files of two directory trees and returns
+// the files that differ, sorted by path. Files and directories matching a
+// pattern set with WithExclude are skipped. Options set with
+// WithDiffOptions configure the line diff of each modified file; a file
+// whose only differences are ignored by options such as WithIgnoreAllSpace
+// is not reported. Text in UTF-16 or Latin-1 is transcoded to UTF-8 before
+// it is split into lines; see DecodeText. A file whose text is unchanged
+// but whose encoding changed is reported with Ops of only Equal
+// operations. A function set with WithTransform, such as the decompression
+// of the compress package, is applied to the content of each file that
+// differs before it is compared.
+func DiffDirs(dirA, dirB string, opts ...DirOption) ([]FileDiff, error) {
+	o := &dirOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	filesA, err := listFiles(dirA, o.exclude)
+	if err != nil {
+		return nil, err
+	}
+	filesB, err := listFiles(dirB, o.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(filesA)+len(filesB))
+	for p := range filesA {
+		paths = append(paths, p)
+	}
+	for p := range filesB {
+		if !filesA[p] {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var diffs []FileDiff
+	for _, p := range paths {
+		var dataA, dataB []byte
+		if filesA[p] {
+			if dataA, err = os.ReadFile(filepath.Join(dirA, filepath.FromSlash(p))); err != nil {
+				return nil, err
+			}
+		}
+		if filesB[p] {
+			if dataB, err = os.ReadFile(filepath.Join(dirB, filepath.FromSlash(p))); err != nil {
+				return nil, err
+			}
+		}
+
+		fd := FileDiff{Path: p, Status: FileModified}
+		switch {
+		case !filesB[p]:
+			fd.Status = FileRemoved
+		case !filesA[p]:
+			fd.Status = FileAdded
+		case bytes.Equal(dataA, dataB):
+			continue
+		}
+
+		if o.transform != nil {
+			if dataA, err = o.transform(dataA); err != nil {
+				return nil, &fs.PathError{Op: "transform", Path: filepath.Join(dirA, filepath.FromSlash(p)), Err: err}
+			}
+			if dataB, err = o.transform(dataB); err != nil {
+				return nil, &fs.PathError{Op: "transform", Path: filepath.Join(dirB, filepath.FromSlash(p)), Err: err}
+			}
+		}
+
+		textA, encA, binA := decodeFile(dataA)
+		textB, encB, binB := decodeFile(dataB)
+		if binA || binB {
+			fd.Binary = true
+			diffs = append(diffs, fd)
+			continue
+		}
+
+		fd.EncodingA, fd.EncodingB = encA, encB
+		fd.A, fd.B = splitLinesKeepEOL(textA), splitLinesKeepEOL(textB)
+		fd.Ops = Diff(fd.A, fd.B, o.diffOpts...)
+		if fd.Status == FileModified && countChangeRegions(fd.Ops) == 0 && encA == encB {
+			continue
+		}
+		diffs = append(diffs, fd)
+	}
+
+	return diffs, nil
+}
+
+// WithExclude skips files and directories whose base name or slash-separated
+// relative path matches one of the patterns, using path.Match syntax.
+// Default: none.
+func WithExclude(patterns ...string) DirOption {
+	return func(o *dirOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// WithDiffOptions sets the options of the line diff of each modified file.
+// Default: none.
+func WithDiffOptions(opts ...Option) DirOption {
+	return func(o *dirOptions) {
+		o.diffOpts = append(o.diffOpts, opts...)
+	}
+}
+
+// WithTransform sets a function that DiffDirs applies to the content of
+// each file that differs before decoding and comparing it, for example to
+// decompress it, as WithDecompress of package
+// github.com/dacharyc/diffx/compress does. An error it returns stops
+// DiffDirs. A nil function leaves the content as it is.
+// Default: nil.
+func WithTransform(fn func([]byte) ([]byte, error)) DirOption {
+	return func(o *dirOptions) {
+		o.transform = fn
+	}
+}
+
+// listFiles returns the set of regular files under root, by relative path.
+func listFiles(root string, exclude []string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if excluded(rel, exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			files[rel] = true
+		}
+		return nil
+	})
+	return files, err
+}
+
+// excluded reports whether rel or its base name matches a pattern.
+func excluded(rel string, patterns []string) bool {
+	base := path.Base(rel)
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, base); ok {
+			return true
+		}
+		if ok, _ := path.Match(pat, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinary reports whether data contains a NUL byte near its start.
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// decodeFile returns the text of a file's data and its encoding, or binary
+// true if the data does not look like text. UTF-16 contains NUL bytes, so
+// it is recognized before the binary check.
+func decodeFile(data []byte) (text string, enc Encoding, binary bool) {
+	enc = DetectEncoding(data)
+	if enc != EncodingUTF16LE && enc != EncodingUTF16BE && isBinary(data) {
+		return "", enc, true
+	}
+	text, enc = DecodeText(data)
+	return text, enc, false
+}
+
+// splitLinesKeepEOL splits text into lines, keeping each line's terminator.
+func splitLinesKeepEOL(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
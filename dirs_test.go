@@ -0,0 +1,201 @@
+package diffx
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files under root from a map of slash paths to content.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiffDirs(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTree(t, dirA, map[string]string{
+		"same.txt":       "x\n",
+		"docs/guide.md":  "# Guide\nold\n",
+		"removed.txt":    "gone\n",
+		"image.bin":      "\x00\x01",
+		"build/out.txt":  "a\n",
+		"notes/skip.log": "1\n",
+	})
+	writeTree(t, dirB, map[string]string{
+		"same.txt":       "x\n",
+		"docs/guide.md":  "# Guide\nnew\n",
+		"added.txt":      "hello\n",
+		"image.bin":      "\x00\x02",
+		"build/out.txt":  "b\n",
+		"notes/skip.log": "2\n",
+	})
+
+	diffs, err := DiffDirs(dirA, dirB, WithExclude("build", "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		Path   string
+		Status FileStatus
+		Binary bool
+	}
+	var got []summary
+	for _, d := range diffs {
+		got = append(got, summary{d.Path, d.Status, d.Binary})
+	}
+	want := []summary{
+		{"added.txt", FileAdded, false},
+		{"docs/guide.md", FileModified, false},
+		{"image.bin", FileModified, true},
+		{"removed.txt", FileRemoved, false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DiffDirs() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("diffs[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	guide := diffs[1]
+	if result := applyDiffStrings(guide.A, guide.B, guide.Ops); !reflect.DeepEqual(result, guide.B) {
+		t.Errorf("ops do not transform A into B: %q", result)
+	}
+	if added := diffs[0]; len(added.Ops) != 1 || added.Ops[0].Type != Insert {
+		t.Errorf("added file ops = %v, want a single Insert", added.Ops)
+	}
+}
+
+func TestDiffDirs_MissingDir(t *testing.T) {
+	if _, err := DiffDirs(filepath.Join(t.TempDir(), "nope"), t.TempDir()); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestDiffDirs_DiffOptions(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTree(t, dirA, map[string]string{
+		"spaced.txt":  "a b\nc\n",
+		"changed.txt": "a b\nc\n",
+	})
+	writeTree(t, dirB, map[string]string{
+		"spaced.txt":  "a  b\nc\n",
+		"changed.txt": "a  b\nd\n",
+	})
+
+	diffs, err := DiffDirs(dirA, dirB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("without options: DiffDirs() = %+v, want changed.txt and spaced.txt", diffs)
+	}
+
+	// Only the file with a change besides white space is reported
+	diffs, err = DiffDirs(dirA, dirB, WithDiffOptions(WithIgnoreAllSpace(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].Path != "changed.txt" {
+		t.Fatalf("WithIgnoreAllSpace: DiffDirs() = %+v, want changed.txt", diffs)
+	}
+	if n := countChangeRegions(diffs[0].Ops); n != 1 {
+		t.Errorf("changed.txt: %d change regions, want 1: %v", n, diffs[0].Ops)
+	}
+}
+
+func TestDiffDirs_Encodings(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTree(t, dirA, map[string]string{
+		"notes.txt": string(utf16Bytes("first\nsecond\nthird\n", false, true)),
+		"menu.txt":  "caf\xe9\nth\xe9\n",
+		"same.txt":  "one\ntwo\n",
+	})
+	writeTree(t, dirB, map[string]string{
+		"notes.txt": "first\nchanged\nthird\n",
+		"menu.txt":  "café\nthé\n",
+		"same.txt":  string(utf16Bytes("one\ntwo\n", true, true)),
+	})
+
+	diffs, err := DiffDirs(dirA, dirB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("DiffDirs() = %+v, want menu.txt, notes.txt, and same.txt", diffs)
+	}
+
+	// Versions of the same text in different encodings are equal; only
+	// the encoding is reported as changed
+	for _, d := range []struct {
+		fd         FileDiff
+		path       string
+		encA, encB Encoding
+	}{
+		{diffs[0], "menu.txt", EncodingLatin1, EncodingUTF8},
+		{diffs[2], "same.txt", EncodingUTF8, EncodingUTF16BE},
+	} {
+		if d.fd.Path != d.path || d.fd.EncodingA != d.encA || d.fd.EncodingB != d.encB {
+			t.Errorf("%s: got %s from %v to %v, want %v to %v", d.path, d.fd.Path, d.fd.EncodingA, d.fd.EncodingB, d.encA, d.encB)
+		}
+		if countChangeRegions(d.fd.Ops) != 0 {
+			t.Errorf("%s: ops = %v, want no changes", d.path, d.fd.Ops)
+		}
+	}
+
+	// The UTF-16 file is diffed line by line, not as binary
+	notes := diffs[1]
+	if notes.Binary || notes.EncodingA != EncodingUTF16LE {
+		t.Fatalf("notes.txt = %+v, want UTF-16LE text", notes)
+	}
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+	if !reflect.DeepEqual(notes.Ops, want) {
+		t.Errorf("notes.txt ops = %v, want %v", notes.Ops, want)
+	}
+}
+
+func TestDiffDirs_Transform(t *testing.T) {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTree(t, dirA, map[string]string{"same.txt": "ONE\n", "changed.txt": "one\ntwo\n"})
+	writeTree(t, dirB, map[string]string{"same.txt": "one\n", "changed.txt": "ONE\nthree\n"})
+
+	lower := func(data []byte) ([]byte, error) { return bytes.ToLower(data), nil }
+	diffs, err := DiffDirs(dirA, dirB, WithTransform(lower))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].Path != "changed.txt" {
+		t.Fatalf("DiffDirs() = %+v, want only changed.txt", diffs)
+	}
+	if got := strings.Join(applyDiffStrings(diffs[0].A, diffs[0].B, diffs[0].Ops), ""); got != "one\nthree\n" {
+		t.Errorf("reconstructed B = %q, want the transformed content", got)
+	}
+
+	errBad := errors.New("bad content")
+	_, err = DiffDirs(dirA, dirB, WithTransform(func([]byte) ([]byte, error) { return nil, errBad }))
+	var pathErr *fs.PathError
+	if !errors.Is(err, errBad) || !errors.As(err, &pathErr) || pathErr.Path != filepath.Join(dirA, "changed.txt") {
+		t.Errorf("failing transform: err = %v, want a PathError for changed.txt wrapping the error", err)
+	}
+}
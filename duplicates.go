@@ -0,0 +1,133 @@
+package diffx
+
+// Duplicate-block reporting.
+//
+// Repetitive inputs (blank lines, closing braces, repeated boilerplate) can
+// be aligned in more than one equally good way, and the differ has to pick
+// one. The choice can look arbitrary to a reader: why was the second copy
+// of a paragraph deleted rather than the first? FindDuplicates reports the
+// blocks of a script whose content is repeated and flags the ones whose
+// alignment could have been different.
+
+// DuplicateBlock describes an operation whose content occurs more than once
+// in A or in B.
+type DuplicateBlock struct {
+	Op        int   // index of the operation in the script
+	A         []int // start positions in A where the content occurs
+	B         []int // start positions in B where the content occurs
+	Ambiguous bool  // the content could have been aligned differently
+}
+
+// FindDuplicates reports the operations of ops whose content appears more
+// than once in either sequence. ops must be a script for a and b, such as
+// one returned by DiffElements.
+//
+// A block is marked ambiguous when another alignment of its content was
+// possible: an Equal block whose content occurs elsewhere in A or B could
+// have been matched there instead, and a Delete (or Insert) block whose
+// content also occurs in an Equal block could have been kept while the
+// other copy was removed (or added).
+func FindDuplicates(ops []DiffOp, a, b []Element) []DuplicateBlock {
+	indexA := hashIndex(a)
+	indexB := hashIndex(b)
+
+	keptA := make([]bool, len(a))
+	keptB := make([]bool, len(b))
+	for _, op := range ops {
+		if op.Type == Equal {
+			for i := op.AStart; i < op.AEnd; i++ {
+				keptA[i] = true
+			}
+			for j := op.BStart; j < op.BEnd; j++ {
+				keptB[j] = true
+			}
+		}
+	}
+
+	var dups []DuplicateBlock
+	for k, op := range ops {
+		var block []Element
+		switch op.Type {
+		case Equal, Delete:
+			block = a[op.AStart:op.AEnd]
+		case Insert:
+			block = b[op.BStart:op.BEnd]
+		}
+		if len(block) == 0 {
+			continue
+		}
+
+		inA := occurrences(a, block, indexA)
+		inB := occurrences(b, block, indexB)
+		if len(inA) < 2 && len(inB) < 2 {
+			continue
+		}
+
+		var ambiguous bool
+		switch op.Type {
+		case Equal:
+			ambiguous = true
+		case Delete:
+			ambiguous = anyOtherKept(inA, op.AStart, len(block), keptA)
+		case Insert:
+			ambiguous = anyOtherKept(inB, op.BStart, len(block), keptB)
+		}
+
+		dups = append(dups, DuplicateBlock{Op: k, A: inA, B: inB, Ambiguous: ambiguous})
+	}
+
+	return dups
+}
+
+// hashIndex maps each element hash to the positions where it occurs.
+func hashIndex(elems []Element) map[uint64][]int {
+	index := make(map[uint64][]int)
+	for i, e := range elems {
+		h := e.Hash()
+		index[h] = append(index[h], i)
+	}
+	return index
+}
+
+// occurrences returns the start positions in seq where block occurs,
+// including overlapping occurrences.
+func occurrences(seq, block []Element, index map[uint64][]int) []int {
+	var positions []int
+	for _, p := range index[block[0].Hash()] {
+		if p+len(block) > len(seq) {
+			continue
+		}
+		match := true
+		for k, e := range block {
+			if !seq[p+k].Equal(e) {
+				match = false
+				break
+			}
+		}
+		if match {
+			positions = append(positions, p)
+		}
+	}
+	return positions
+}
+
+// anyOtherKept reports whether an occurrence other than the one at self is
+// entirely kept.
+func anyOtherKept(positions []int, self, n int, kept []bool) bool {
+	for _, p := range positions {
+		if p == self {
+			continue
+		}
+		all := true
+		for i := p; i < p+n; i++ {
+			if !kept[i] {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates_AmbiguousDelete(t *testing.T) {
+	a := toElements([]string{"x", "y", "x"})
+	b := toElements([]string{"x", "y"})
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+	}
+
+	dups := FindDuplicates(ops, a, b)
+	if len(dups) != 1 {
+		t.Fatalf("expected 1 duplicate block, got %+v", dups)
+	}
+
+	want := DuplicateBlock{Op: 1, A: []int{0, 2}, B: []int{0}, Ambiguous: true}
+	if !reflect.DeepEqual(dups[0], want) {
+		t.Errorf("got %+v, want %+v", dups[0], want)
+	}
+}
+
+func TestFindDuplicates_AmbiguousEqual(t *testing.T) {
+	a := toElements([]string{"}", "a", "}"})
+	b := toElements([]string{"}", "b"})
+	ops := DiffElements(a, b)
+
+	dups := FindDuplicates(ops, a, b)
+	var found bool
+	for _, d := range dups {
+		if ops[d.Op].Type == Equal {
+			found = true
+			if !d.Ambiguous {
+				t.Errorf("Equal block %+v should be ambiguous", d)
+			}
+			if !reflect.DeepEqual(d.A, []int{0, 2}) {
+				t.Errorf("A occurrences = %v, want [0 2]", d.A)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected the matched brace to be reported, got %+v", dups)
+	}
+}
+
+func TestFindDuplicates_NotAmbiguous(t *testing.T) {
+	// Both copies are deleted, so no other alignment was possible
+	a := toElements([]string{"dup", "keep", "dup"})
+	b := toElements([]string{"keep"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 1, BEnd: 1},
+	}
+
+	dups := FindDuplicates(ops, a, b)
+	if len(dups) != 2 {
+		t.Fatalf("expected 2 duplicate blocks, got %+v", dups)
+	}
+	for _, d := range dups {
+		if d.Ambiguous {
+			t.Errorf("block %+v should not be ambiguous", d)
+		}
+	}
+}
+
+func TestFindDuplicates_Unique(t *testing.T) {
+	a := toElements([]string{"a", "b", "c"})
+	b := toElements([]string{"a", "x", "c"})
+
+	if dups := FindDuplicates(DiffElements(a, b), a, b); len(dups) != 0 {
+		t.Errorf("expected no duplicates, got %+v", dups)
+	}
+}
@@ -0,0 +1,127 @@
+package diffx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Edit graph export.
+//
+// Myers' algorithm searches the edit graph: a grid with a node for every
+// pair of positions in A and B, where moving right deletes an element of A,
+// moving down inserts an element of B, and diagonals follow matching
+// elements. Drawing that grid with the path the diff took and the split
+// points the search chose shows directly where a heuristic cut the search
+// short, which makes it useful both for teaching the algorithm and for
+// diagnosing surprising output on small inputs.
+
+// editGraphLimit is the largest number of nodes WriteEditGraphDOT renders.
+// Graphviz becomes unusable well before the graph reaches this size.
+const editGraphLimit = 2500
+
+// WriteEditGraphDOT diffs a and b with opts and writes their edit graph to
+// w in Graphviz DOT format. Node (i, j) stands for having consumed i
+// elements of A and j of B. Matching diagonals are drawn dashed, the path
+// taken by the edit script in bold, split points chosen by the middle-snake
+// search as double circles, and split points chosen by a heuristic in red.
+// Split points are marked only with WithPreprocessing(false), since
+// preprocessing makes the search run on a reduced graph.
+//
+// Nodes carry fixed positions, so render the output with neato, for
+// example "neato -Tsvg graph.dot > graph.svg". Inputs whose graph would
+// exceed 2500 nodes are rejected with an error.
+func WriteEditGraphDOT(w io.Writer, a, b []string, opts ...Option) error {
+	if nodes := int64(len(a)+1) * int64(len(b)+1); nodes > editGraphLimit {
+		return fmt.Errorf("diffx: edit graph of %d x %d elements has %d nodes, more than %d", len(a), len(b), nodes, editGraphLimit)
+	}
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	// Split points are reported in filtered indices when preprocessing
+	// hides elements, so they are recorded only when it is disabled
+	splits := map[[2]int]bool{} // split point -> chosen by a heuristic
+	trace := o.trace
+	recordSplit := func(e TraceEvent) {
+		if e.Kind == TraceMiddleSnake && !o.preprocessing {
+			splits[[2]int{e.X, e.Y}] = e.Heuristic
+		}
+		if trace != nil {
+			trace(e)
+		}
+	}
+	ops := Diff(a, b, append(opts[:len(opts):len(opts)], WithTrace(recordSplit))...)
+	ea, eb := o.ignoreOpts.elements(a), o.ignoreOpts.elements(b)
+
+	// Edges on the path of the edit script
+	path := map[[4]int]bool{}
+	for _, op := range ops {
+		i, j := op.AStart, op.BStart
+		for i < op.AEnd || j < op.BEnd {
+			ni, nj := i, j
+			switch op.Type {
+			case Equal:
+				ni, nj = i+1, j+1
+			case Delete:
+				ni = i + 1
+			case Insert:
+				nj = j + 1
+			}
+			path[[4]int{i, j, ni, nj}] = true
+			i, j = ni, nj
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph editgraph {\n")
+	bw.WriteString("\tnode [shape=circle, label=\"\", width=0.15, fixedsize=true];\n")
+	bw.WriteString("\tedge [arrowsize=0.4, color=gray];\n")
+
+	// Axis labels: elements of A across the top, elements of B down the left
+	for i := 0; i < len(a); i++ {
+		fmt.Fprintf(bw, "\ta%d [shape=plaintext, fixedsize=false, label=%s, pos=\"%d,1!\"];\n", i, strconv.Quote(a[i]), 2*i+1)
+	}
+	for j := 0; j < len(b); j++ {
+		fmt.Fprintf(bw, "\tb%d [shape=plaintext, fixedsize=false, label=%s, pos=\"-1,%d!\"];\n", j, strconv.Quote(b[j]), -2*j-1)
+	}
+
+	for i := 0; i <= len(a); i++ {
+		for j := 0; j <= len(b); j++ {
+			attrs := fmt.Sprintf("pos=\"%d,%d!\"", 2*i, -2*j)
+			if heuristic, ok := splits[[2]int{i, j}]; ok {
+				attrs += ", shape=doublecircle"
+				if heuristic {
+					attrs += ", color=red"
+				}
+			}
+			fmt.Fprintf(bw, "\tn%d_%d [%s];\n", i, j, attrs)
+		}
+	}
+
+	edge := func(i, j, ni, nj int, style string) {
+		attrs := style
+		if path[[4]int{i, j, ni, nj}] {
+			attrs += ", penwidth=3, color=black"
+		}
+		fmt.Fprintf(bw, "\tn%d_%d -> n%d_%d [%s];\n", i, j, ni, nj, attrs)
+	}
+	for i := 0; i <= len(a); i++ {
+		for j := 0; j <= len(b); j++ {
+			if i < len(a) {
+				edge(i, j, i+1, j, "style=solid")
+			}
+			if j < len(b) {
+				edge(i, j, i, j+1, "style=solid")
+			}
+			if i < len(a) && j < len(b) && ea[i].Equal(eb[j]) {
+				edge(i, j, i+1, j+1, "style=dashed")
+			}
+		}
+	}
+
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,68 @@
+package diffx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteEditGraphDOT(t *testing.T) {
+	a := []string{"a", "b", "c"}
+	b := []string{"a", "x", "c"}
+
+	var sb strings.Builder
+	if err := WriteEditGraphDOT(&sb, a, b, WithPreprocessing(false)); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"digraph editgraph {",
+		`a1 [shape=plaintext, fixedsize=false, label="b"`,
+		`b1 [shape=plaintext, fixedsize=false, label="x"`,
+		"n3_3 [",
+		// Matching diagonals, on the path
+		"n0_0 -> n1_1 [style=dashed, penwidth=3, color=black];",
+		"n2_2 -> n3_3 [style=dashed, penwidth=3, color=black];",
+		// The replace of b by x
+		"n1_1 -> n2_1 [style=solid, penwidth=3, color=black];",
+		"n2_1 -> n2_2 [style=solid, penwidth=3, color=black];",
+		// An edge off the path
+		"n0_0 -> n1_0 [style=solid];",
+		"shape=doublecircle",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "n1_1 -> n2_2") {
+		t.Error("output has a diagonal between unequal elements")
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Error("output is not terminated")
+	}
+}
+
+func TestWriteEditGraphDOT_KeepsTrace(t *testing.T) {
+	var events int
+	var sb strings.Builder
+	err := WriteEditGraphDOT(&sb, []string{"a", "b"}, []string{"a", "c"},
+		WithTrace(func(TraceEvent) { events++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events == 0 {
+		t.Error("caller's trace function was not called")
+	}
+}
+
+func TestWriteEditGraphDOT_TooLarge(t *testing.T) {
+	// 65536 x 65536 nodes overflow a 32-bit int to 0
+	for _, n := range []int{100, 65535} {
+		a := make([]string, n)
+		b := make([]string, n)
+		err := WriteEditGraphDOT(&strings.Builder{}, a, b)
+		if err == nil || !strings.HasPrefix(err.Error(), "diffx: ") {
+			t.Errorf("%d elements: err = %v, want a diffx error", n, err)
+		}
+	}
+}
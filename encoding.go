@@ -0,0 +1,141 @@
+package diffx
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encoding detection.
+//
+// Files checked out on different platforms or produced by different tools
+// do not always share an encoding: Windows tools write UTF-16 with a byte
+// order mark, and older files are often Latin-1. Diffing their raw bytes
+// reports every line of a UTF-16 file as changed, or shows Latin-1 accents
+// as invalid UTF-8. DecodeText detects the encoding and transcodes to UTF-8
+// before the text is split, so the diff compares characters, not bytes.
+
+// Encoding is a text encoding recognized by DetectEncoding.
+type Encoding int
+
+const (
+	// EncodingUTF8 is UTF-8, with or without a byte order mark.
+	EncodingUTF8 Encoding = iota
+	// EncodingUTF16LE is little-endian UTF-16.
+	EncodingUTF16LE
+	// EncodingUTF16BE is big-endian UTF-16.
+	EncodingUTF16BE
+	// EncodingLatin1 is ISO 8859-1, assumed for text that is not valid UTF-8.
+	EncodingLatin1
+)
+
+// String returns a string representation of the Encoding.
+func (e Encoding) String() string {
+	switch e {
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingLatin1:
+		return "Latin-1"
+	default:
+		return "Unknown"
+	}
+}
+
+// Byte order marks.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DetectEncoding guesses the encoding of data. A byte order mark decides;
+// without one, data that is mostly printable ASCII characters interleaved
+// with NUL bytes is taken as UTF-16, valid UTF-8 as UTF-8, and anything
+// else as Latin-1. Only the first 8000 bytes are examined for UTF-16.
+func DetectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+
+	sniff := data
+	if len(sniff) > binarySniffLen {
+		sniff = sniff[:binarySniffLen]
+	}
+	if len(sniff) >= 2 && len(sniff)%2 == 0 {
+		// Mostly ASCII UTF-16 has a NUL next to nearly every printable
+		// character; binary data rarely lines up that way
+		var ascii [2]int // ASCII code units read little- and big-endian
+		for i := 0; i < len(sniff); i += 2 {
+			switch {
+			case sniff[i+1] == 0 && isTextByte(sniff[i]):
+				ascii[0]++
+			case sniff[i] == 0 && isTextByte(sniff[i+1]):
+				ascii[1]++
+			}
+		}
+		units := len(sniff) / 2
+		switch {
+		case ascii[0]*4 >= units*3:
+			return EncodingUTF16LE
+		case ascii[1]*4 >= units*3:
+			return EncodingUTF16BE
+		}
+	}
+
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	return EncodingLatin1
+}
+
+// isTextByte reports whether c is printable ASCII or common white space.
+func isTextByte(c byte) bool {
+	return (c >= ' ' && c < 0x7F) || c == '\t' || c == '\n' || c == '\r'
+}
+
+// DecodeText transcodes data to UTF-8 from the encoding DetectEncoding
+// reports, removing any byte order mark. Invalid UTF-16 sequences become
+// U+FFFD. Valid UTF-8 without a byte order mark is returned unchanged.
+func DecodeText(data []byte) (string, Encoding) {
+	enc := DetectEncoding(data)
+	switch enc {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		if enc == EncodingUTF16LE {
+			data = bytes.TrimPrefix(data, bomUTF16LE)
+		} else {
+			data = bytes.TrimPrefix(data, bomUTF16BE)
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if enc == EncodingUTF16LE {
+				units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+			} else {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			}
+		}
+		text := string(utf16.Decode(units))
+		if len(data)%2 == 1 {
+			text += string(utf8.RuneError)
+		}
+		return text, enc
+	case EncodingLatin1:
+		var sb strings.Builder
+		sb.Grow(len(data) + len(data)/8)
+		for _, c := range data {
+			sb.WriteRune(rune(c))
+		}
+		return sb.String(), enc
+	default:
+		return string(bytes.TrimPrefix(data, bomUTF8)), enc
+	}
+}
@@ -0,0 +1,83 @@
+package diffx
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16Bytes encodes s as UTF-16 in the given byte order, with a byte
+// order mark if bom is set.
+func utf16Bytes(s string, bigEndian, bom bool) []byte {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	data := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		if bigEndian {
+			data = append(data, byte(u>>8), byte(u))
+		} else {
+			data = append(data, byte(u), byte(u>>8))
+		}
+	}
+	return data
+}
+
+func TestEncoding_String(t *testing.T) {
+	tests := []struct {
+		e    Encoding
+		want string
+	}{
+		{EncodingUTF8, "UTF-8"},
+		{EncodingUTF16LE, "UTF-16LE"},
+		{EncodingUTF16BE, "UTF-16BE"},
+		{EncodingLatin1, "Latin-1"},
+		{Encoding(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.e.String(); got != tt.want {
+			t.Errorf("Encoding(%d).String() = %q, want %q", tt.e, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	const text = "café\nnaïve\n"
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantEnc Encoding
+	}{
+		{"utf-8", []byte(text), text, EncodingUTF8},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, text...), text, EncodingUTF8},
+		{"utf-16le bom", utf16Bytes(text, false, true), text, EncodingUTF16LE},
+		{"utf-16be bom", utf16Bytes(text, true, true), text, EncodingUTF16BE},
+		{"utf-16le", utf16Bytes("plain ascii\r\n", false, false), "plain ascii\r\n", EncodingUTF16LE},
+		{"utf-16be", utf16Bytes("plain ascii\r\n", true, false), "plain ascii\r\n", EncodingUTF16BE},
+		{"latin-1", []byte("caf\xe9\nna\xefve\n"), text, EncodingLatin1},
+		{"odd utf-16", append(utf16Bytes("ab", false, true), 'c'), "ab�", EncodingUTF16LE},
+		{"empty", nil, "", EncodingUTF8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, enc := DecodeText(tt.data)
+			if got != tt.want || enc != tt.wantEnc {
+				t.Errorf("DecodeText() = %q, %v, want %q, %v", got, enc, tt.want, tt.wantEnc)
+			}
+		})
+	}
+}
+
+func TestDetectEncoding_Binary(t *testing.T) {
+	// NUL bytes next to control characters are binary, not UTF-16
+	for _, data := range [][]byte{
+		{0x00, 0x01},
+		{0x00, 0x00, 0x00, 0x00},
+		{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A},
+	} {
+		if enc := DetectEncoding(data); enc == EncodingUTF16LE || enc == EncodingUTF16BE {
+			t.Errorf("DetectEncoding(%q) = %v, want not UTF-16", data, enc)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package diffx
+
+import "math"
+
+// Pre-flight cost estimation.
+//
+// Myers' algorithm takes O((N+M)·D) time, where D is the size of the
+// shortest edit script, and the heuristics cap D per search at the cost
+// limit. D is not known before diffing, but cheap bounds on it are: common
+// prefixes and suffixes cost nothing, and elements that occur more often in
+// one sequence than in the other must be edited. Estimate combines these
+// bounds with the repetition in the inputs, which decides how much work the
+// search does before the heuristics stop it, so servers can route huge or
+// pathological inputs to coarser settings before committing to a diff.
+
+// TimeClass is a rough class of the time a diff takes.
+type TimeClass int
+
+const (
+	// TimeInstant means well under a millisecond.
+	TimeInstant TimeClass = iota
+	// TimeFast means up to about a hundred milliseconds.
+	TimeFast
+	// TimeSlow means up to about ten seconds.
+	TimeSlow
+	// TimeExpensive means longer; consider coarser settings, such as a
+	// lower WithCostLimit, or splitting the input. Such diffs can exceed
+	// the default work limit and be computed coarsely; see WithWorkLimit.
+	TimeExpensive
+)
+
+// String returns a string representation of the TimeClass.
+func (c TimeClass) String() string {
+	switch c {
+	case TimeInstant:
+		return "Instant"
+	case TimeFast:
+		return "Fast"
+	case TimeSlow:
+		return "Slow"
+	case TimeExpensive:
+		return "Expensive"
+	default:
+		return "Unknown"
+	}
+}
+
+// Work thresholds, in elements compared, between time classes.
+const (
+	instantWork = 1e5
+	fastWork    = 1e7
+	slowWork    = 1e9
+)
+
+// CostEstimate is the expected cost of diffing two sequences with the
+// default options.
+type CostEstimate struct {
+	// Trimmed is the number of elements in the common prefix and suffix,
+	// which cost nothing to diff.
+	Trimmed int
+
+	// MinEdits is a lower bound on the number of elements inserted plus
+	// deleted: the elements that occur more often in one sequence than in
+	// the other. Reordered elements are not counted, so the true edit
+	// distance can be much larger.
+	MinEdits int
+
+	// Similarity is an upper bound on the fraction of elements that can be
+	// matched, from 0 for disjoint inputs to 1 for identical ones.
+	Similarity float64
+
+	// Repetition is the fraction of elements whose value occurs often
+	// enough for preprocessing to treat them as poor anchors. Repetitive
+	// inputs have many equally good alignments, and the search explores
+	// them up to the cost limit.
+	Repetition float64
+
+	// Work estimates the number of element comparisons.
+	Work float64
+
+	// Memory estimates the working memory, in bytes, excluding the inputs
+	// and the result.
+	Memory int64
+
+	// Time classifies Work.
+	Time TimeClass
+}
+
+// Estimate returns the expected cost of diffing a and b with DiffElements
+// and the default options, in time linear in the input size. The estimate
+// is rough: it is meant to separate trivial inputs from huge or
+// pathological ones, not to predict running times.
+func Estimate(a, b []Element) CostEstimate {
+	var est CostEstimate
+
+	// Common prefix and suffix
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix].Equal(b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix].Equal(b[len(b)-1-suffix]) {
+		suffix++
+	}
+	est.Trimmed = prefix + suffix
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		est.Similarity = 1
+		return est
+	}
+
+	// Multiset intersection bounds the matches
+	aFreq := make(map[uint64]int, n)
+	bFreq := make(map[uint64]int, m)
+	for _, e := range a {
+		aFreq[e.Hash()]++
+	}
+	for _, e := range b {
+		bFreq[e.Hash()]++
+	}
+	matches := 0
+	for h, fa := range aFreq {
+		matches += min(fa, bFreq[h])
+	}
+	est.MinEdits = n + m - 2*matches
+	est.Similarity = float64(2*(matches+est.Trimmed)) / float64(n+m+2*est.Trimmed)
+
+	// Repetition, with the threshold preprocessing uses
+	threshold := max(5+(n+m)/64, 8)
+	repeated := 0
+	for h, fa := range aFreq {
+		if fa+bFreq[h] > threshold {
+			repeated += fa
+		}
+	}
+	for h, fb := range bFreq {
+		if aFreq[h]+fb > threshold {
+			repeated += fb
+		}
+	}
+	est.Repetition = float64(repeated) / float64(n+m)
+
+	// The search goes about as deep as the edits it must make, and deeper
+	// on repetitive inputs. The cost limit stops it only by splitting at a
+	// long run of matches, so inputs with too few matches to form one are
+	// searched to the end.
+	depth := min(est.MinEdits+int(est.Repetition*float64(n+m)/2), (n+m+1)/2)
+	if matches >= significantMatchLen {
+		costLimit := max(int(math.Sqrt(float64(n))*math.Sqrt(float64(m))/4), 256)
+		depth = min(depth, costLimit)
+	}
+	depth = max(depth, 1)
+	est.Work = float64(n+m) * float64(depth)
+
+	// Diagonal vectors and change marks, frequency maps, and the filtered
+	// copies and index mapping built by preprocessing
+	const mapEntry = 48
+	est.Memory = int64(n+m+4)*2*8 + int64(n+m) +
+		int64(len(aFreq)+len(bFreq))*mapEntry +
+		int64(n+m)*(16+8)
+
+	switch {
+	case est.Work <= instantWork:
+		est.Time = TimeInstant
+	case est.Work <= fastWork:
+		est.Time = TimeFast
+	case est.Work <= slowWork:
+		est.Time = TimeSlow
+	default:
+		est.Time = TimeExpensive
+	}
+	return est
+}
@@ -0,0 +1,100 @@
+package diffx
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestTimeClass_String(t *testing.T) {
+	tests := []struct {
+		c    TimeClass
+		want string
+	}{
+		{TimeInstant, "Instant"},
+		{TimeFast, "Fast"},
+		{TimeSlow, "Slow"},
+		{TimeExpensive, "Expensive"},
+		{TimeClass(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("TimeClass(%d).String() = %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate_Identical(t *testing.T) {
+	a := toElements([]string{"a", "b", "c"})
+	est := Estimate(a, a)
+	if est.Trimmed != 3 || est.MinEdits != 0 || est.Similarity != 1 || est.Work != 0 || est.Time != TimeInstant {
+		t.Errorf("Estimate of identical inputs = %+v", est)
+	}
+
+	if est := Estimate(nil, nil); est.Similarity != 1 || est.Time != TimeInstant {
+		t.Errorf("Estimate of empty inputs = %+v", est)
+	}
+}
+
+func TestEstimate_Bounds(t *testing.T) {
+	a := toElements([]string{"p", "a", "b", "c", "q"})
+	b := toElements([]string{"p", "a", "x", "c", "c", "q"})
+	est := Estimate(a, b)
+
+	if est.Trimmed != 4 { // "p", "a" and "c", "q"
+		t.Errorf("Trimmed = %d, want 4", est.Trimmed)
+	}
+	// "b" must be deleted, and "x" and one "c" inserted
+	if est.MinEdits != 3 {
+		t.Errorf("MinEdits = %d, want 3", est.MinEdits)
+	}
+	if want := 8.0 / 11; est.Similarity != want {
+		t.Errorf("Similarity = %v, want %v", est.Similarity, want)
+	}
+	if est.MinEdits > MinimalEditDistance(a, b) {
+		t.Errorf("MinEdits = %d exceeds the minimal edit distance %d", est.MinEdits, MinimalEditDistance(a, b))
+	}
+}
+
+func TestEstimate_Classes(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	lines := func(n int, prefix string) []Element {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return toElements(s)
+	}
+	repetitive := func(n int) []Element {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = []string{"{", "}", ""}[rng.Intn(3)]
+		}
+		return toElements(s)
+	}
+
+	small := Estimate(lines(10, "a"), lines(10, "b"))
+	if small.Time != TimeInstant {
+		t.Errorf("small inputs: Time = %v, want Instant (%+v)", small.Time, small)
+	}
+
+	// One changed line in a large file is cheap
+	a := lines(100000, "x")
+	b := append(append([]Element{}, a[:50000]...), StringElement("changed"))
+	b = append(b, a[50001:]...)
+	if est := Estimate(a, b); est.Time != TimeInstant || est.Trimmed != 99999 {
+		t.Errorf("one change: Time = %v, Trimmed = %d, want Instant, 99999", est.Time, est.Trimmed)
+	}
+
+	// Disjoint large files are expensive, and cost more than small ones
+	large := Estimate(lines(100000, "a"), lines(100000, "b"))
+	if large.Time != TimeExpensive || large.Similarity != 0 || large.Memory <= small.Memory {
+		t.Errorf("disjoint large inputs: %+v", large)
+	}
+
+	// Repetition deepens the search
+	rep := Estimate(repetitive(20000), repetitive(20000))
+	if rep.Repetition < 0.99 || rep.Time < TimeSlow {
+		t.Errorf("repetitive inputs: %+v", rep)
+	}
+}
@@ -139,6 +139,76 @@ func (m *indexMapping) mapOps(ops []DiffOp) []DiffOp {
 	return mergeOps(result)
 }
 
+// refineGaps re-diffs the change regions of ops, as returned by mapOps on
+// a and b, that contain elements preprocessing hid from the core algorithm.
+// mapOps reports those elements as changed even where they match, so a
+// high-frequency element common to a deletion and the insertion replacing
+// it, such as a repeated punctuation token, only lands in an Equal
+// operation through this second pass. Regions are diffed without
+// preprocessing, with the heuristics of o, and are not traced.
+func (m *indexMapping) refineGaps(ops []DiffOp, a, b []Element, o *options) []DiffOp {
+	if m == nil {
+		return ops
+	}
+	keptA := make([]bool, m.origN)
+	for _, i := range m.aToOrig {
+		keptA[i] = true
+	}
+	keptB := make([]bool, m.origM)
+	for _, j := range m.bToOrig {
+		keptB[j] = true
+	}
+	hidden := func(kept []bool, start, end int) bool {
+		for _, k := range kept[start:end] {
+			if !k {
+				return true
+			}
+		}
+		return false
+	}
+
+	result := make([]DiffOp, 0, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].Type == Equal {
+			result = append(result, ops[i])
+			i++
+			continue
+		}
+		j := i
+		aStart, aEnd, bStart, bEnd := ops[i].AStart, ops[i].AEnd, ops[i].BStart, ops[i].BEnd
+		for ; j < len(ops) && ops[j].Type != Equal; j++ {
+			aEnd, bEnd = max(aEnd, ops[j].AEnd), max(bEnd, ops[j].BEnd)
+		}
+		if aStart == aEnd || bStart == bEnd || !hidden(keptA, aStart, aEnd) && !hidden(keptB, bStart, bEnd) {
+			result = append(result, ops[i:j]...)
+			i = j
+			continue
+		}
+
+		sub := *o
+		sub.preprocessing = false
+		sub.trace = nil // events in original indices would not fit the others
+		ctx := newDiffContext(a[aStart:aEnd], b[bStart:bEnd], &sub)
+		ctx.compareSeq(0, aEnd-aStart, 0, bEnd-bStart, sub.forceMinimal)
+		result = append(result, OffsetOps(ctx.buildOps(), aStart, bStart)...)
+		i = j
+	}
+	return mergeOps(result)
+}
+
+// WithGapRefinement makes the Myers algorithm re-diff the change regions
+// that hold elements hidden by preprocessing, so that high-frequency
+// elements matching on both sides of a change, such as repeated
+// punctuation, are reported as equal instead of deleted and re-inserted.
+// The extra matches can split a change into several smaller ones. Has no
+// effect without preprocessing.
+// Default: false.
+func WithGapRefinement(enabled bool) Option {
+	return func(o *options) {
+		o.gapRefinement = enabled
+	}
+}
+
 // mergeOps merges adjacent operations of the same type.
 func mergeOps(ops []DiffOp) []DiffOp {
 	if len(ops) <= 1 {
@@ -188,6 +258,47 @@ func filterConfusingElements(a, b []Element) ([]Element, []Element, *indexMappin
 		return a, b, nil
 	}
 
+	aClass, bClass := classifyElements(a, b)
+
+	// Check if filtering would help
+	// If most elements would be kept, skip filtering
+	keepCount := 0
+	for _, c := range aClass {
+		if c == keep {
+			keepCount++
+		}
+	}
+	for _, c := range bClass {
+		if c == keep {
+			keepCount++
+		}
+	}
+	if int64(keepCount) > int64(len(a)+len(b))*3/4 {
+		return a, b, nil
+	}
+
+	// Filter sequences: keep elements, discard provisionals surrounded by discards
+	filteredA, aToOrig := filterSequence(a, aClass)
+	filteredB, bToOrig := filterSequence(b, bClass)
+
+	// If filtering removed everything, return original
+	if len(filteredA) == 0 && len(filteredB) == 0 {
+		return a, b, nil
+	}
+
+	mapping := &indexMapping{
+		aToOrig: aToOrig,
+		bToOrig: bToOrig,
+		origN:   len(a),
+		origM:   len(b),
+	}
+
+	return filteredA, filteredB, mapping
+}
+
+// classifyElements classifies each element of a and b as keep, discard,
+// or provisional by how often it occurs in both sequences.
+func classifyElements(a, b []Element) (aClass, bClass []elementClass) {
 	// Build frequency maps using element hashes
 	aFreq := make(map[uint64]int)
 	bFreq := make(map[uint64]int)
@@ -207,7 +318,7 @@ func filterConfusingElements(a, b []Element) ([]Element, []Element, *indexMappin
 	}
 
 	// Classify elements in A
-	aClass := make([]elementClass, len(a))
+	aClass = make([]elementClass, len(a))
 	for i, e := range a {
 		h := e.Hash()
 		inB := bFreq[h] > 0
@@ -223,7 +334,7 @@ func filterConfusingElements(a, b []Element) ([]Element, []Element, *indexMappin
 	}
 
 	// Classify elements in B
-	bClass := make([]elementClass, len(b))
+	bClass = make([]elementClass, len(b))
 	for i, e := range b {
 		h := e.Hash()
 		inA := aFreq[h] > 0
@@ -238,40 +349,7 @@ func filterConfusingElements(a, b []Element) ([]Element, []Element, *indexMappin
 		}
 	}
 
-	// Check if filtering would help
-	// If most elements would be kept, skip filtering
-	keepCount := 0
-	for _, c := range aClass {
-		if c == keep {
-			keepCount++
-		}
-	}
-	for _, c := range bClass {
-		if c == keep {
-			keepCount++
-		}
-	}
-	if keepCount > (len(a)+len(b))*3/4 {
-		return a, b, nil
-	}
-
-	// Filter sequences: keep elements, discard provisionals surrounded by discards
-	filteredA, aToOrig := filterSequence(a, aClass)
-	filteredB, bToOrig := filterSequence(b, bClass)
-
-	// If filtering removed everything, return original
-	if len(filteredA) == 0 && len(filteredB) == 0 {
-		return a, b, nil
-	}
-
-	mapping := &indexMapping{
-		aToOrig: aToOrig,
-		bToOrig: bToOrig,
-		origN:   len(a),
-		origM:   len(b),
-	}
-
-	return filteredA, filteredB, mapping
+	return aClass, bClass
 }
 
 // filterSequence filters a sequence based on element classes.
@@ -2,6 +2,7 @@ package diffx
 
 import (
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -342,6 +343,78 @@ func TestFilterConfusingElements_Integration(t *testing.T) {
 	}
 }
 
+func TestWithGapRefinement(t *testing.T) {
+	// The commas are frequent enough to be provisional; the one between
+	// the replaced words sits among discarded elements and is hidden
+	var a, b []string
+	for i := 0; i < 10; i++ {
+		k := "k" + strconv.Itoa(i)
+		a = append(a, k, ",")
+		b = append(b, k, ",")
+	}
+	a = append(a, "p1", ",", "p2", "end")
+	b = append(b, "q1", ",", "q2", "end")
+
+	comma := DiffOp{Type: Equal, AStart: 21, AEnd: 22, BStart: 21, BEnd: 22}
+	contains := func(ops []DiffOp, want DiffOp) bool {
+		for _, op := range ops {
+			if op.Type == Equal && op.AStart <= want.AStart && want.AEnd <= op.AEnd && op.BStart-op.AStart == want.BStart-want.AStart {
+				return true
+			}
+		}
+		return false
+	}
+
+	var s Stats
+	plain := Diff(a, b, WithStats(&s), WithPostprocessing(false), WithAnchorElimination(false))
+	if s.FilteredA == 0 {
+		t.Fatal("nothing was filtered")
+	}
+	if contains(plain, comma) {
+		t.Fatalf("without refinement the hidden comma matched: %v", plain)
+	}
+
+	refined := Diff(a, b, WithGapRefinement(true), WithPostprocessing(false), WithAnchorElimination(false))
+	if !contains(refined, comma) {
+		t.Errorf("with refinement = %v, want the comma a[21] matched to b[21]", refined)
+	}
+	if got := applyDiff(a, b, refined); !reflect.DeepEqual(got, b) {
+		t.Errorf("applying refined diff = %v, want %v", got, b)
+	}
+
+	// Without preprocessing there is nothing to refine
+	if got, want := Diff(a, b, WithGapRefinement(true), WithPreprocessing(false)), Diff(a, b, WithPreprocessing(false)); !reflect.DeepEqual(got, want) {
+		t.Errorf("without preprocessing = %v, want %v", got, want)
+	}
+}
+
+func TestIndexMapping_RefineGaps_Unhidden(t *testing.T) {
+	// A change region without hidden elements is left alone, even if a
+	// diff of it would find matches
+	a := toElements([]string{"x", "y", "z"})
+	b := toElements([]string{"y", "w"})
+	m := &indexMapping{aToOrig: []int{0, 1, 2}, bToOrig: []int{0, 1}, origN: 3, origM: 2}
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 0, BEnd: 2},
+	}
+	if got := m.refineGaps(ops, a, b, defaultOptions()); !reflect.DeepEqual(got, ops) {
+		t.Errorf("refineGaps() = %v, want %v", got, ops)
+	}
+
+	// Hiding an element of the region has it re-diffed
+	m.aToOrig = []int{0, 2}
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 2},
+	}
+	if got := m.refineGaps(ops, a, b, defaultOptions()); !reflect.DeepEqual(got, want) {
+		t.Errorf("refineGaps() = %v, want %v", got, want)
+	}
+}
+
 // Benchmark filtering
 func BenchmarkFilterConfusingElements_Small(b *testing.B) {
 	a := toElements([]string{"the", "quick", "brown", "fox", "jumps"})
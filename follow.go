@@ -0,0 +1,172 @@
+package diffx
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Continuous diff of a changing file.
+//
+// Generated artifacts, logs, and build outputs are often watched while a
+// process writes them, to see how they depart from a known-good version.
+// Re-running a diff after every write prints the same hunks again and
+// again. Follow re-diffs the file against a baseline whenever it changes
+// and reports only the changes that were not there the previous time, so
+// a growing file shows just its new lines and an edited file just its new
+// edits.
+
+// DefaultFollowInterval is how often Follow checks the file when no
+// interval is given.
+const DefaultFollowInterval = time.Second
+
+// FollowUpdate describes a version of a followed file.
+type FollowUpdate struct {
+	// Lines are the lines of the version, each including its line
+	// terminator. Ops is a line diff of the baseline and Lines.
+	Lines []string
+	Ops   []DiffOp
+
+	// New holds the Delete and Insert operations of Ops, or the parts of
+	// them, that were not in the diff of the previous version. Adjacent
+	// operations of New are not merged, so a replacement is a Delete
+	// followed by an Insert at the same position in the baseline.
+	New []DiffOp
+}
+
+// Follower diffs successive versions of a text against a baseline and
+// tells which changes are new in each version. The zero Follower is not
+// usable; create one with NewFollower.
+type Follower struct {
+	baseline []string
+	opts     []Option
+	seen     map[followKey]bool // changes in the diff of the previous version
+}
+
+// followKey identifies a changed line in a diff against the baseline. A
+// deleted line is identified by its baseline index; an inserted line by
+// the baseline position it is inserted at, its text, and how many lines
+// with the same text precede it in the insertion.
+type followKey struct {
+	insert bool
+	pos    int
+	text   string
+	n      int
+}
+
+// NewFollower returns a Follower that diffs versions against baseline.
+// The options configure the line diff.
+func NewFollower(baseline []string, opts ...Option) *Follower {
+	return &Follower{baseline: baseline, opts: opts}
+}
+
+// Update diffs a new version against the baseline and returns the diff
+// with the changes that were not in the previous version's diff. The
+// first update reports every change as new. A change that goes away and
+// comes back is reported again.
+func (f *Follower) Update(lines []string) FollowUpdate {
+	ops := Diff(f.baseline, lines, f.opts...)
+	u := FollowUpdate{Lines: lines, Ops: ops}
+
+	seen := make(map[followKey]bool)
+	for _, op := range ops {
+		switch op.Type {
+		case Delete:
+			start := -1 // first line of the current run of new deletions
+			for i := op.AStart; i <= op.AEnd; i++ {
+				key := followKey{pos: i}
+				if i < op.AEnd {
+					seen[key] = true
+				}
+				if i < op.AEnd && !f.seen[key] {
+					if start < 0 {
+						start = i
+					}
+					continue
+				}
+				if start >= 0 {
+					u.New = append(u.New, DiffOp{Type: Delete, AStart: start, AEnd: i, BStart: op.BStart, BEnd: op.BStart})
+					start = -1
+				}
+			}
+		case Insert:
+			count := make(map[string]int)
+			start := -1
+			for j := op.BStart; j <= op.BEnd; j++ {
+				var key followKey
+				if j < op.BEnd {
+					key = followKey{insert: true, pos: op.AStart, text: lines[j], n: count[lines[j]]}
+					count[lines[j]]++
+					seen[key] = true
+				}
+				if j < op.BEnd && !f.seen[key] {
+					if start < 0 {
+						start = j
+					}
+					continue
+				}
+				if start >= 0 {
+					u.New = append(u.New, DiffOp{Type: Insert, AStart: op.AStart, AEnd: op.AStart, BStart: start, BEnd: j})
+					start = -1
+				}
+			}
+		}
+	}
+	f.seen = seen
+	return u
+}
+
+// Follow watches the file at path and calls fn with an update each time
+// its diff against baseline gains a change, until ctx is done or fn
+// returns an error. The file is checked when Follow starts and then every
+// interval, or every DefaultFollowInterval if interval is not positive,
+// and read again when its size or modification time changed. It is
+// decoded as DiffDirs decodes files and split into lines that keep their
+// terminators, so baseline should be split the same way. The options
+// configure the line diff, as for NewFollower.
+//
+// A file that is missing when Follow starts is an error. A file that goes
+// missing later, as when a generator replaces it, is checked again at the
+// next interval. Follow returns the error from fn, or ctx.Err().
+func Follow(ctx context.Context, path string, baseline []string, interval time.Duration, fn func(FollowUpdate) error, opts ...Option) error {
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	f := NewFollower(baseline, opts...)
+	var size int64 = -1
+	var modTime time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && (info.Size() != size || !info.ModTime().Equal(modTime)):
+			data, err := os.ReadFile(path)
+			if err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				break
+			}
+			size, modTime = info.Size(), info.ModTime()
+			text, _ := DecodeText(data)
+			if u := f.Update(splitLinesKeepEOL(text)); len(u.New) > 0 {
+				if err := fn(u); err != nil {
+					return err
+				}
+			}
+		case err != nil && !os.IsNotExist(err):
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
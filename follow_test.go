@@ -0,0 +1,137 @@
+package diffx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFollower_Growing(t *testing.T) {
+	baseline := []string{"start\n", "ok\n"}
+	f := NewFollower(baseline)
+
+	u := f.Update([]string{"start\n", "ok\n", "warn 1\n"})
+	want := []DiffOp{{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3}}
+	if !reflect.DeepEqual(u.New, want) {
+		t.Fatalf("first update: New = %v, want %v", u.New, want)
+	}
+
+	// Only the appended lines are new
+	u = f.Update([]string{"start\n", "ok\n", "warn 1\n", "warn 2\n", "warn 1\n"})
+	want = []DiffOp{{Type: Insert, AStart: 2, AEnd: 2, BStart: 3, BEnd: 5}}
+	if !reflect.DeepEqual(u.New, want) {
+		t.Errorf("second update: New = %v, want %v", u.New, want)
+	}
+	if s := Stat(u.Ops); s.Insertions != 3 {
+		t.Errorf("second update: Ops = %v, want the whole diff", u.Ops)
+	}
+
+	// Nothing changed
+	u = f.Update(u.Lines)
+	if u.New != nil {
+		t.Errorf("same version: New = %v, want nil", u.New)
+	}
+}
+
+func TestFollower_Edits(t *testing.T) {
+	baseline := []string{"a\n", "b\n", "c\n", "d\n"}
+	f := NewFollower(baseline)
+
+	f.Update([]string{"a\n", "B\n", "c\n", "d\n"})
+
+	// b is still replaced, but by another line; c is newly deleted
+	u := f.Update([]string{"a\n", "B2\n", "d\n"})
+	want := []DiffOp{
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 1, BEnd: 2},
+	}
+	if len(u.New) != 2 {
+		t.Fatalf("New = %v, want %v", u.New, want)
+	}
+	for _, op := range u.New {
+		switch op.Type {
+		case Delete:
+			if op.AStart != 2 || op.AEnd != 3 {
+				t.Errorf("new deletion = %v, want line c", op)
+			}
+		case Insert:
+			if got := u.Lines[op.BStart:op.BEnd]; !reflect.DeepEqual(got, []string{"B2\n"}) {
+				t.Errorf("new insertion = %q, want B2", got)
+			}
+		}
+	}
+
+	// Reverting and reapplying a change reports it again
+	f.Update(baseline)
+	u = f.Update([]string{"a\n", "b\n", "c\n"})
+	want = []DiffOp{{Type: Delete, AStart: 3, AEnd: 4, BStart: 3, BEnd: 3}}
+	if !reflect.DeepEqual(u.New, want) {
+		t.Errorf("reapplied: New = %v, want %v", u.New, want)
+	}
+}
+
+func TestFollower_Options(t *testing.T) {
+	f := NewFollower([]string{"x = 1\n"}, WithIgnoreAllSpace(true))
+	if u := f.Update([]string{"x=1\n"}); u.New != nil {
+		t.Errorf("New = %v, want nil when only white space changed", u.New)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file matches the baseline, so there is nothing to report
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls := 0
+	err := Follow(ctx, path, []string{"one\n"}, 10*time.Millisecond, func(FollowUpdate) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 0 {
+		t.Fatalf("unchanged: Follow() = %v after %d updates, want deadline and none", err, calls)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	baseline := []string{"ONE\n", "zero\n"}
+	var got []string
+	errDone := errors.New("done")
+	err = Follow(ctx, path, baseline, 10*time.Millisecond, func(u FollowUpdate) error {
+		for _, op := range u.New {
+			switch op.Type {
+			case Delete:
+				got = append(got, "-"+strings.Join(baseline[op.AStart:op.AEnd], ""))
+			case Insert:
+				got = append(got, "+"+strings.Join(u.Lines[op.BStart:op.BEnd], ""))
+			}
+		}
+		if len(got) > 1 {
+			return errDone
+		}
+		// Grow the file; the change is seen at the next check
+		return os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644)
+	}, WithIgnoreCase(true))
+	if !errors.Is(err, errDone) {
+		t.Fatalf("Follow() = %v, want the callback's error", err)
+	}
+	want := []string{"-zero\n", "+two\nthree\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("updates = %q, want %q", got, want)
+	}
+}
+
+func TestFollow_Missing(t *testing.T) {
+	err := Follow(context.Background(), filepath.Join(t.TempDir(), "nope"), nil, 0, func(FollowUpdate) error { return nil })
+	if !os.IsNotExist(err) {
+		t.Errorf("Follow() = %v, want a not-exist error", err)
+	}
+}
@@ -0,0 +1,137 @@
+package diffx
+
+import (
+	"strings"
+	"testing"
+)
+
+// Fuzz targets. Run one with, for example:
+//
+//	go test -fuzz=FuzzDiff -fuzztime=30s
+//
+// Without -fuzz, go test runs only the seed corpus.
+
+// fuzzSeeds are input pairs that exercise repetition, blank lines, and
+// changes at the edges, where boundary shifting is most involved.
+var fuzzSeeds = [][2]string{
+	{"", ""},
+	{"abc", ""},
+	{"", "abc"},
+	{"abcabba", "cbabac"},
+	{"aaaa", "aa"},
+	{"a\n\nb\n\nc", "a\n\nc\n\nb"},
+	{"x{\n}\ny{\n}\n", "x{\n}\nz{\n}\ny{\n}\n"},
+	{"the cat sat on the mat", "the dog sat on a mat"},
+}
+
+// fuzzOptions decodes bits of flags into an option set, so that the
+// fuzzer explores combinations of the algorithm's stages.
+func fuzzOptions(flags uint8) []Option {
+	return []Option{
+		WithMinimal(flags&1 != 0),
+		WithPreprocessing(flags&2 == 0),
+		WithPostprocessing(flags&4 == 0),
+		WithAnchorElimination(flags&8 == 0),
+		WithIndentHeuristic(flags&16 != 0),
+		WithWeakAnchorElimination(flags&32 != 0),
+		WithEditCost(int(flags>>6) * 2),
+	}
+}
+
+// fuzzTokens splits s into single-character elements. Bytes are folded
+// into a small alphabet so that inputs share many elements, which is where
+// the interesting alignments are.
+func fuzzTokens(s string) []string {
+	tokens := make([]string, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\n' && c != ' ' {
+			c = 'a' + c%6
+		}
+		tokens[i] = string(c)
+	}
+	return tokens
+}
+
+// checkScript fails the test unless ops is a well-formed script for a and
+// b: operations are contiguous and non-empty, Equal operations cover equal
+// elements, and the script rebuilds both sequences.
+func checkScript(t *testing.T, a, b []string, ops []DiffOp) {
+	t.Helper()
+	i, j := 0, 0
+	var gotA, gotB []string
+	for k, op := range ops {
+		if op.AStart != i || op.BStart != j || op.AEnd < op.AStart || op.BEnd < op.BStart ||
+			op.AEnd > len(a) || op.BEnd > len(b) {
+			t.Fatalf("op %d %v does not continue at (%d, %d)\nops: %v", k, op, i, j, ops)
+		}
+		if op.AEnd == op.AStart && op.BEnd == op.BStart {
+			t.Fatalf("op %d %v is empty\nops: %v", k, op, ops)
+		}
+		switch op.Type {
+		case Equal:
+			if op.AEnd-op.AStart != op.BEnd-op.BStart {
+				t.Fatalf("op %d %v has unequal lengths\nops: %v", k, op, ops)
+			}
+			gotA = append(gotA, a[op.AStart:op.AEnd]...)
+			gotB = append(gotB, b[op.BStart:op.BEnd]...)
+		case Delete:
+			if op.BEnd != op.BStart {
+				t.Fatalf("op %d %v covers B\nops: %v", k, op, ops)
+			}
+			gotA = append(gotA, a[op.AStart:op.AEnd]...)
+		case Insert:
+			if op.AEnd != op.AStart {
+				t.Fatalf("op %d %v covers A\nops: %v", k, op, ops)
+			}
+			gotB = append(gotB, b[op.BStart:op.BEnd]...)
+		default:
+			t.Fatalf("op %d has type %v\nops: %v", k, op.Type, ops)
+		}
+		i, j = op.AEnd, op.BEnd
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("script ends at (%d, %d), want (%d, %d)\nops: %v", i, j, len(a), len(b), ops)
+	}
+	if strings.Join(gotA, "\x00") != strings.Join(a, "\x00") {
+		t.Fatalf("script does not rebuild A\nops: %v", ops)
+	}
+	if strings.Join(gotB, "\x00") != strings.Join(b, "\x00") {
+		t.Fatalf("script does not rebuild B\nops: %v", ops)
+	}
+}
+
+func FuzzDiff(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s[0], s[1], uint8(0))
+	}
+	f.Fuzz(func(t *testing.T, textA, textB string, flags uint8) {
+		a, b := fuzzTokens(textA), fuzzTokens(textB)
+		checkScript(t, a, b, Diff(a, b, fuzzOptions(flags)...))
+	})
+}
+
+func FuzzDiffHistogram(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s[0], s[1], uint8(0))
+	}
+	f.Fuzz(func(t *testing.T, textA, textB string, flags uint8) {
+		a, b := fuzzTokens(textA), fuzzTokens(textB)
+		checkScript(t, a, b, DiffHistogram(a, b, fuzzOptions(flags)...))
+	})
+}
+
+// FuzzApply diffs arbitrary text line by line, as the command-line tool
+// does, and checks that the script and its canonical form both rebuild
+// the inputs.
+func FuzzApply(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s[0], s[1])
+	}
+	f.Fuzz(func(t *testing.T, textA, textB string) {
+		a, b := strings.SplitAfter(textA, "\n"), strings.SplitAfter(textB, "\n")
+		ops := Diff(a, b)
+		checkScript(t, a, b, ops)
+		checkScript(t, a, b, Normalize(ops, toElements(a), toElements(b)))
+	})
+}
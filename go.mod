@@ -1,3 +1,12 @@
 module github.com/dacharyc/diffx
 
 go 1.22.5
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/klauspost/compress v1.18.0
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/sergi/go-diff v1.4.0
+	google.golang.org/protobuf v1.34.2
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,267 @@
+// Package godifflib renders diffx diffs with the types and output formats
+// of github.com/pmezard/go-difflib, so that programs formatting diffs with
+// go-difflib can switch to diffx's alignment without rewriting their
+// rendering code.
+//
+// go-difflib's UnifiedDiff and ContextDiff structs hold only the inputs
+// and the formatting settings; its WriteUnifiedDiff and WriteContextDiff
+// align the lines themselves with difflib.SequenceMatcher. This package
+// provides functions of the same names that take the same structs and
+// produce the same output format from a diffx diff, and the *Ops variants
+// render an edit script the caller already has. OpCodes and GroupedOpCodes
+// convert an edit script to go-difflib opcodes for code that renders them
+// itself.
+package godifflib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/dacharyc/diffx"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// OpCodes converts an edit script to go-difflib opcodes, as
+// SequenceMatcher.GetOpCodes returns them: a Delete followed by an Insert
+// becomes one 'r' opcode, and every other change a 'd' or 'i' opcode.
+// Adjacent Equal operations are merged into one 'e' opcode.
+func OpCodes(ops []diffx.DiffOp) []difflib.OpCode {
+	var codes []difflib.OpCode
+	for k := 0; k < len(ops); k++ {
+		op := ops[k]
+		c := difflib.OpCode{I1: op.AStart, I2: op.AEnd, J1: op.BStart, J2: op.BEnd}
+		if op.Type == diffx.Equal {
+			if n := len(codes) - 1; n >= 0 && codes[n].Tag == 'e' {
+				codes[n].I2, codes[n].J2 = op.AEnd, op.BEnd
+				continue
+			}
+			c.Tag = 'e'
+			codes = append(codes, c)
+			continue
+		}
+
+		// A change region runs up to the next Equal operation
+		for k+1 < len(ops) && ops[k+1].Type != diffx.Equal {
+			k++
+			c.I2, c.J2 = max(c.I2, ops[k].AEnd), max(c.J2, ops[k].BEnd)
+		}
+		switch {
+		case c.I1 < c.I2 && c.J1 < c.J2:
+			c.Tag = 'r'
+		case c.I1 < c.I2:
+			c.Tag = 'd'
+		case c.J1 < c.J2:
+			c.Tag = 'i'
+		default:
+			continue
+		}
+		codes = append(codes, c)
+	}
+	return codes
+}
+
+// GroupedOpCodes converts an edit script to go-difflib opcodes grouped
+// into hunks with up to n lines of context, as
+// SequenceMatcher.GetGroupedOpCodes(n) returns them. A negative n means 3.
+// A script without changes has no groups.
+func GroupedOpCodes(ops []diffx.DiffOp, n int) [][]difflib.OpCode {
+	if n < 0 {
+		n = 3
+	}
+	codes := OpCodes(ops)
+	if len(codes) == 0 {
+		codes = []difflib.OpCode{{Tag: 'e', I1: 0, I2: 1, J1: 0, J2: 1}}
+	}
+
+	// Trim the context before the first change and after the last
+	if c := &codes[0]; c.Tag == 'e' {
+		c.I1, c.J1 = max(c.I1, c.I2-n), max(c.J1, c.J2-n)
+	}
+	if c := &codes[len(codes)-1]; c.Tag == 'e' {
+		c.I2, c.J2 = min(c.I2, c.I1+n), min(c.J2, c.J1+n)
+	}
+
+	// Start a new group at each equal run longer than twice the context
+	var groups [][]difflib.OpCode
+	var group []difflib.OpCode
+	for _, c := range codes {
+		if c.Tag == 'e' && c.I2-c.I1 > 2*n {
+			group = append(group, difflib.OpCode{Tag: 'e', I1: c.I1, I2: min(c.I2, c.I1+n), J1: c.J1, J2: min(c.J2, c.J1+n)})
+			groups = append(groups, group)
+			group = nil
+			c.I1, c.J1 = max(c.I1, c.I2-n), max(c.J1, c.J2-n)
+		}
+		group = append(group, c)
+	}
+	if len(group) > 0 && !(len(group) == 1 && group[0].Tag == 'e') {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// WriteUnifiedDiff writes a unified diff of diff.A and diff.B to w in the
+// format of difflib.WriteUnifiedDiff, aligning the lines with diffx.Diff
+// configured by opts.
+func WriteUnifiedDiff(w io.Writer, diff difflib.UnifiedDiff, opts ...diffx.Option) error {
+	return WriteUnifiedDiffOps(w, diff, diffx.Diff(diff.A, diff.B, opts...))
+}
+
+// GetUnifiedDiffString is like WriteUnifiedDiff but returns the diff as a
+// string.
+func GetUnifiedDiffString(diff difflib.UnifiedDiff, opts ...diffx.Option) (string, error) {
+	var buf bytes.Buffer
+	err := WriteUnifiedDiff(&buf, diff, opts...)
+	return buf.String(), err
+}
+
+// WriteUnifiedDiffOps writes ops, an edit script for diff.A and diff.B, to
+// w in the format of difflib.WriteUnifiedDiff.
+func WriteUnifiedDiffOps(w io.Writer, diff difflib.UnifiedDiff, ops []diffx.DiffOp) error {
+	bw := bufio.NewWriter(w)
+	eol := diff.Eol
+	if eol == "" {
+		eol = "\n"
+	}
+
+	for k, g := range GroupedOpCodes(ops, diff.Context) {
+		if k == 0 && (diff.FromFile != "" || diff.ToFile != "") {
+			fmt.Fprintf(bw, "--- %s%s%s", diff.FromFile, dateSuffix(diff.FromDate), eol)
+			fmt.Fprintf(bw, "+++ %s%s%s", diff.ToFile, dateSuffix(diff.ToDate), eol)
+		}
+		first, last := g[0], g[len(g)-1]
+		fmt.Fprintf(bw, "@@ -%s +%s @@%s", unifiedRange(first.I1, last.I2), unifiedRange(first.J1, last.J2), eol)
+		for _, c := range g {
+			if c.Tag == 'e' {
+				writeLines(bw, " ", diff.A[c.I1:c.I2])
+				continue
+			}
+			if c.Tag == 'r' || c.Tag == 'd' {
+				writeLines(bw, "-", diff.A[c.I1:c.I2])
+			}
+			if c.Tag == 'r' || c.Tag == 'i' {
+				writeLines(bw, "+", diff.B[c.J1:c.J2])
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteContextDiff writes a context diff of diff.A and diff.B to w in the
+// format of difflib.WriteContextDiff, aligning the lines with diffx.Diff
+// configured by opts.
+func WriteContextDiff(w io.Writer, diff difflib.ContextDiff, opts ...diffx.Option) error {
+	return WriteContextDiffOps(w, diff, diffx.Diff(diff.A, diff.B, opts...))
+}
+
+// GetContextDiffString is like WriteContextDiff but returns the diff as a
+// string.
+func GetContextDiffString(diff difflib.ContextDiff, opts ...diffx.Option) (string, error) {
+	var buf bytes.Buffer
+	err := WriteContextDiff(&buf, diff, opts...)
+	return buf.String(), err
+}
+
+// contextPrefix is the line prefix of each opcode tag in a context diff.
+var contextPrefix = map[byte]string{
+	'i': "+ ",
+	'd': "- ",
+	'r': "! ",
+	'e': "  ",
+}
+
+// WriteContextDiffOps writes ops, an edit script for diff.A and diff.B, to
+// w in the format of difflib.WriteContextDiff.
+func WriteContextDiffOps(w io.Writer, diff difflib.ContextDiff, ops []diffx.DiffOp) error {
+	bw := bufio.NewWriter(w)
+	eol := diff.Eol
+	if eol == "" {
+		eol = "\n"
+	}
+
+	for k, g := range GroupedOpCodes(ops, diff.Context) {
+		if k == 0 && (diff.FromFile != "" || diff.ToFile != "") {
+			fmt.Fprintf(bw, "*** %s%s%s", diff.FromFile, dateSuffix(diff.FromDate), eol)
+			fmt.Fprintf(bw, "--- %s%s%s", diff.ToFile, dateSuffix(diff.ToDate), eol)
+		}
+		first, last := g[0], g[len(g)-1]
+		bw.WriteString("***************" + eol)
+
+		// Each side is listed only if it has changes
+		fmt.Fprintf(bw, "*** %s ****%s", contextRange(first.I1, last.I2), eol)
+		if hasTag(g, 'r', 'd') {
+			for _, c := range g {
+				if c.Tag != 'i' {
+					writeLines(bw, contextPrefix[c.Tag], diff.A[c.I1:c.I2])
+				}
+			}
+		}
+		fmt.Fprintf(bw, "--- %s ----%s", contextRange(first.J1, last.J2), eol)
+		if hasTag(g, 'r', 'i') {
+			for _, c := range g {
+				if c.Tag != 'd' {
+					writeLines(bw, contextPrefix[c.Tag], diff.B[c.J1:c.J2])
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// hasTag reports whether any opcode of g has one of the tags.
+func hasTag(g []difflib.OpCode, tags ...byte) bool {
+	for _, c := range g {
+		for _, t := range tags {
+			if c.Tag == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeLines writes each line with prefix. The lines keep their own
+// terminators, as in go-difflib.
+func writeLines(w *bufio.Writer, prefix string, lines []string) {
+	for _, line := range lines {
+		w.WriteString(prefix)
+		w.WriteString(line)
+	}
+}
+
+// dateSuffix returns the tab-separated date of a file header, if any.
+func dateSuffix(date string) string {
+	if date == "" {
+		return ""
+	}
+	return "\t" + date
+}
+
+// unifiedRange formats the lines [start, stop) as a unified diff range:
+// "start,length", or "start" for a single line, with 1-based line numbers.
+// An empty range starts at the line before it.
+func unifiedRange(start, stop int) string {
+	beginning, length := start+1, stop-start
+	if length == 1 {
+		return fmt.Sprintf("%d", beginning)
+	}
+	if length == 0 {
+		beginning--
+	}
+	return fmt.Sprintf("%d,%d", beginning, length)
+}
+
+// contextRange formats the lines [start, stop) as a context diff range:
+// "first,last", or a single line number for ranges of at most one line.
+// An empty range starts at the line before it.
+func contextRange(start, stop int) string {
+	beginning, length := start+1, stop-start
+	if length == 0 {
+		beginning--
+	}
+	if length <= 1 {
+		return fmt.Sprintf("%d", beginning)
+	}
+	return fmt.Sprintf("%d,%d", beginning, beginning+length-1)
+}
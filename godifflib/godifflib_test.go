@@ -0,0 +1,220 @@
+package godifflib
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dacharyc/diffx"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// difflibOps returns go-difflib's own alignment of a and b as an edit
+// script, so that rendering it must reproduce go-difflib's output exactly.
+func difflibOps(a, b []string) []diffx.DiffOp {
+	var ops []diffx.DiffOp
+	for _, c := range difflib.NewMatcher(a, b).GetOpCodes() {
+		switch c.Tag {
+		case 'e':
+			ops = append(ops, diffx.DiffOp{Type: diffx.Equal, AStart: c.I1, AEnd: c.I2, BStart: c.J1, BEnd: c.J2})
+		case 'd':
+			ops = append(ops, diffx.DiffOp{Type: diffx.Delete, AStart: c.I1, AEnd: c.I2, BStart: c.J1, BEnd: c.J1})
+		case 'i':
+			ops = append(ops, diffx.DiffOp{Type: diffx.Insert, AStart: c.I1, AEnd: c.I1, BStart: c.J1, BEnd: c.J2})
+		case 'r':
+			ops = append(ops,
+				diffx.DiffOp{Type: diffx.Delete, AStart: c.I1, AEnd: c.I2, BStart: c.J1, BEnd: c.J1},
+				diffx.DiffOp{Type: diffx.Insert, AStart: c.I2, AEnd: c.I2, BStart: c.J1, BEnd: c.J2})
+		}
+	}
+	return ops
+}
+
+// lines splits a space-separated list into lines with terminators.
+func lines(s string) []string {
+	var l []string
+	for _, f := range strings.Fields(s) {
+		l = append(l, f+"\n")
+	}
+	return l
+}
+
+var formatTests = []struct {
+	name string
+	diff difflib.UnifiedDiff
+}{
+	{"files", difflib.UnifiedDiff{
+		A: lines("one two three four"), B: lines("zero one tree four"),
+		FromFile: "Original", ToFile: "Current", Context: 3,
+	}},
+	{"dates", difflib.UnifiedDiff{
+		A: lines("a b c"), B: lines("a c d"),
+		FromFile: "a.txt", FromDate: "2005-01-26 23:30:50", ToFile: "b.txt", ToDate: "2010-04-02 10:20:52", Context: 1,
+	}},
+	{"no header", difflib.UnifiedDiff{
+		A: lines("a b c d e f g h i j k l m"), B: lines("a B c d e f g h i j k L m n"), Context: 2,
+	}},
+	{"no context", difflib.UnifiedDiff{
+		A: lines("a b c d"), B: lines("a x c"), Context: 0,
+	}},
+	{"eol", difflib.UnifiedDiff{
+		A: []string{"a", "b", "c"}, B: []string{"a", "c"}, FromFile: "x", Eol: "\r\n",
+	}},
+	{"deletion only", difflib.UnifiedDiff{
+		A: lines("a b c"), B: nil, ToFile: "empty", Context: 3,
+	}},
+	{"insertion only", difflib.UnifiedDiff{
+		A: nil, B: lines("a b"), Context: 3,
+	}},
+	{"identical", difflib.UnifiedDiff{
+		A: lines("a b c"), B: lines("a b c"), FromFile: "a", ToFile: "b", Context: 3,
+	}},
+	{"empty", difflib.UnifiedDiff{FromFile: "a", ToFile: "b"}},
+}
+
+func TestWriteUnifiedDiffOps(t *testing.T) {
+	for _, tt := range formatTests {
+		want, err := difflib.GetUnifiedDiffString(tt.diff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := WriteUnifiedDiffOps(&buf, tt.diff, difflibOps(tt.diff.A, tt.diff.B)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("%s: WriteUnifiedDiffOps =\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestWriteContextDiffOps(t *testing.T) {
+	for _, tt := range formatTests {
+		diff := difflib.ContextDiff(tt.diff)
+		want, err := difflib.GetContextDiffString(diff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := WriteContextDiffOps(&buf, diff, difflibOps(diff.A, diff.B)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("%s: WriteContextDiffOps =\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestGroupedOpCodes(t *testing.T) {
+	for _, tt := range formatTests {
+		for _, n := range []int{-1, 0, 1, 3} {
+			want := difflib.NewMatcher(tt.diff.A, tt.diff.B).GetGroupedOpCodes(n)
+			got := GroupedOpCodes(difflibOps(tt.diff.A, tt.diff.B), n)
+			if len(want) == 0 {
+				want = nil
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: GroupedOpCodes(%d) = %v, want %v", tt.name, n, got, want)
+			}
+		}
+	}
+}
+
+func TestOpCodes(t *testing.T) {
+	tests := []struct {
+		ops  []diffx.DiffOp
+		want []difflib.OpCode
+	}{
+		{nil, nil},
+		{
+			// Adjacent equal runs merge, and a deletion with an insertion
+			// is a replacement
+			[]diffx.DiffOp{
+				{Type: diffx.Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: diffx.Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+				{Type: diffx.Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+				{Type: diffx.Insert, AStart: 4, AEnd: 4, BStart: 2, BEnd: 3},
+				{Type: diffx.Equal, AStart: 4, AEnd: 5, BStart: 3, BEnd: 4},
+				{Type: diffx.Insert, AStart: 5, AEnd: 5, BStart: 4, BEnd: 6},
+			},
+			[]difflib.OpCode{
+				{Tag: 'e', I1: 0, I2: 2, J1: 0, J2: 2},
+				{Tag: 'r', I1: 2, I2: 4, J1: 2, J2: 3},
+				{Tag: 'e', I1: 4, I2: 5, J1: 3, J2: 4},
+				{Tag: 'i', I1: 5, I2: 5, J1: 4, J2: 6},
+			},
+		},
+		{
+			[]diffx.DiffOp{{Type: diffx.Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0}},
+			[]difflib.OpCode{{Tag: 'd', I1: 0, I2: 3, J1: 0, J2: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := OpCodes(tt.ops); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("OpCodes(%v) = %v, want %v", tt.ops, got, tt.want)
+		}
+	}
+}
+
+func TestGetUnifiedDiffString(t *testing.T) {
+	// go-difflib inserts the new function's lines starting with the old
+	// function's closing brace; diffx inserts the whole function
+	diff := difflib.UnifiedDiff{
+		A: lines("func_a() { x } func_b() { y }"), B: lines("func_a() { x } func_c() { z } func_b() { y }"),
+		FromFile: "a.go", ToFile: "b.go", Context: 1,
+	}
+	wantUnified := `--- a.go
++++ b.go
+@@ -4,2 +4,6 @@
+ }
++func_c()
++{
++z
++}
+ func_b()
+`
+	wantContext := `*** a.go
+--- b.go
+***************
+*** 4,5 ****
+--- 4,9 ----
+  }
++ func_c()
++ {
++ z
++ }
+  func_b()
+`
+
+	got, err := GetUnifiedDiffString(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != wantUnified {
+		t.Errorf("GetUnifiedDiffString =\n%s\nwant\n%s", got, wantUnified)
+	}
+	got, err = GetContextDiffString(difflib.ContextDiff(diff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != wantContext {
+		t.Errorf("GetContextDiffString =\n%s\nwant\n%s", got, wantContext)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestWriteUnifiedDiff_Error(t *testing.T) {
+	diff := difflib.UnifiedDiff{A: lines("a"), B: lines("b")}
+	if err := WriteUnifiedDiff(failWriter{}, diff); err == nil {
+		t.Error("WriteUnifiedDiff returned no error for a failing writer")
+	}
+	if err := WriteContextDiff(failWriter{}, difflib.ContextDiff(diff)); err == nil {
+		t.Error("WriteContextDiff returned no error for a failing writer")
+	}
+}
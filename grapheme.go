@@ -0,0 +1,174 @@
+package diffx
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Grapheme cluster segmentation.
+//
+// A character diff over runes can split what a reader sees as one
+// character: an accented letter written with a combining mark, an emoji
+// with a skin tone modifier, a flag made of two regional indicators, or a
+// family emoji joined with zero-width joiners. Diffing such text rune by
+// rune reports a change inside the character, and rendering the halves
+// separately shows broken glyphs. SplitGraphemes segments text into
+// extended grapheme clusters, following the rules of Unicode Standard Annex
+// #29, so a character diff can treat each visible character as one element.
+//
+// The property tables are approximated from the unicode package, which has
+// no grapheme properties: combining marks stand in for Extend and
+// SpacingMark, and Extended_Pictographic is approximated by the emoji and
+// symbol blocks. Prepend characters, used by a few Indic scripts, are not
+// recognized.
+
+// graphemeClass is the Grapheme_Cluster_Break property of a rune.
+type graphemeClass int
+
+const (
+	gcOther graphemeClass = iota
+	gcCR
+	gcLF
+	gcControl
+	gcExtend
+	gcZWJ
+	gcRegionalIndicator
+	gcSpacingMark
+	gcL   // Hangul leading consonant
+	gcV   // Hangul vowel
+	gcT   // Hangul trailing consonant
+	gcLV  // Hangul syllable without trailing consonant
+	gcLVT // Hangul syllable with trailing consonant
+	gcPictographic
+)
+
+// Hangul syllable block, for computing LV and LVT.
+const (
+	hangulBase   = 0xAC00
+	hangulCount  = 11172
+	hangulTCount = 28
+)
+
+// classifyGrapheme returns the grapheme break class of r.
+func classifyGrapheme(r rune) graphemeClass {
+	switch {
+	case r == '\r':
+		return gcCR
+	case r == '\n':
+		return gcLF
+	case r == 0x200D:
+		return gcZWJ
+	case r == 0x200C, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F,
+		unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r):
+		// Non-joiner, emoji modifiers, tags, and nonspacing marks,
+		// which include the variation selectors
+		return gcExtend
+	case unicode.Is(unicode.Mc, r):
+		return gcSpacingMark
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gcRegionalIndicator
+	case unicode.Is(unicode.Cc, r), unicode.Is(unicode.Zl, r), unicode.Is(unicode.Zp, r),
+		unicode.Is(unicode.Cf, r) && r != 0x200C:
+		return gcControl
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gcL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gcV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gcT
+	case r >= hangulBase && r < hangulBase+hangulCount:
+		if (r-hangulBase)%hangulTCount == 0 {
+			return gcLV
+		}
+		return gcLVT
+	case isPictographic(r):
+		return gcPictographic
+	default:
+		return gcOther
+	}
+}
+
+// isPictographic approximates the Extended_Pictographic property.
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139,
+		r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	case r >= 0x2194 && r <= 0x21AA, r >= 0x2300 && r <= 0x23FF, r >= 0x25A0 && r <= 0x27BF,
+		r >= 0x2900 && r <= 0x297F, r >= 0x2B00 && r <= 0x2BFF, r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	}
+	return false
+}
+
+// SplitGraphemes splits s into extended grapheme clusters: user-perceived
+// characters such as "é" written as "e" plus a combining accent, a flag,
+// or an emoji sequence joined with zero-width joiners. Concatenating the
+// clusters reproduces s. Invalid UTF-8 bytes form clusters of their own.
+func SplitGraphemes(s string) []string {
+	var clusters []string
+	start := 0
+	prev := gcOther
+	riCount := 0          // regional indicators in a row before the current rune
+	pictSequence := false // prev ends Extended_Pictographic Extend*, or ZWJ after it
+	for i, r := range s {
+		class := classifyGrapheme(r)
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				class = gcControl
+			}
+		}
+		if i > start && graphemeBreak(prev, class, riCount, pictSequence) {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+
+		if class == gcRegionalIndicator {
+			riCount++
+		} else {
+			riCount = 0
+		}
+		switch {
+		case class == gcPictographic:
+			pictSequence = true
+		case class == gcExtend && pictSequence && prev != gcZWJ:
+		case class == gcZWJ && pictSequence && prev != gcZWJ:
+		default:
+			pictSequence = false
+		}
+		prev = class
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// graphemeBreak reports whether there is a cluster boundary between a rune
+// of class prev and one of class next. riCount is the number of regional
+// indicators ending at prev, and pictSequence whether prev ends an
+// Extended_Pictographic character followed by Extend characters and
+// possibly a ZWJ.
+func graphemeBreak(prev, next graphemeClass, riCount int, pictSequence bool) bool {
+	switch {
+	case prev == gcCR && next == gcLF: // GB3
+		return false
+	case prev == gcCR || prev == gcLF || prev == gcControl: // GB4
+		return true
+	case next == gcCR || next == gcLF || next == gcControl: // GB5
+		return true
+	case prev == gcL && (next == gcL || next == gcV || next == gcLV || next == gcLVT): // GB6
+		return false
+	case (prev == gcLV || prev == gcV) && (next == gcV || next == gcT): // GB7
+		return false
+	case (prev == gcLVT || prev == gcT) && next == gcT: // GB8
+		return false
+	case next == gcExtend || next == gcZWJ || next == gcSpacingMark: // GB9, GB9a
+		return false
+	case prev == gcZWJ && next == gcPictographic && pictSequence: // GB11
+		return false
+	case prev == gcRegionalIndicator && next == gcRegionalIndicator: // GB12, GB13
+		return riCount%2 == 0
+	}
+	return true // GB999
+}
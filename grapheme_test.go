@@ -0,0 +1,54 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"crlf", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"combining accent", "cafe\u0301!", []string{"c", "a", "f", "e\u0301", "!"}},
+		{"skin tone", "\U0001F44B\U0001F3FD.", []string{"\U0001F44B\U0001F3FD", "."}},
+		{"zwj family", "\U0001F468\u200d\U0001F469\u200d\U0001F467x", []string{"\U0001F468\u200d\U0001F469\u200d\U0001F467", "x"}},
+		{"variation selector", "\u2764\ufe0f\u2764", []string{"\u2764\ufe0f", "\u2764"}},
+		{"flags", "\U0001F1EB\U0001F1F7\U0001F1E9\U0001F1EA\U0001F1EE", []string{"\U0001F1EB\U0001F1F7", "\U0001F1E9\U0001F1EA", "\U0001F1EE"}},
+		{"hangul jamo", "\u1100\u1161\u11a8\uac00", []string{"\u1100\u1161\u11a8", "\uac00"}},
+		{"spacing mark", "\u0915\u093f\u0915", []string{"\u0915\u093f", "\u0915"}},
+		{"zwj without emoji", "a\u200db", []string{"a\u200d", "b"}},
+		{"invalid utf-8", "a\xffb", []string{"a", "\xff", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitGraphemes(tt.s)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitGraphemes(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+			if joined := strings.Join(got, ""); joined != tt.s {
+				t.Errorf("clusters join to %q, want %q", joined, tt.s)
+			}
+		})
+	}
+}
+
+func TestSplitGraphemes_Diff(t *testing.T) {
+	// Changing the skin tone replaces the whole emoji, not just the modifier
+	a := SplitGraphemes("hi \U0001F44B\U0001F3FB")
+	b := SplitGraphemes("hi \U0001F44B\U0001F3FF")
+	ops := Diff(a, b)
+	if got := countChangeRegions(ops); got != 1 {
+		t.Fatalf("change regions = %d, want 1 (ops %v)", got, ops)
+	}
+	for _, op := range ops {
+		if op.Type == Delete && op.AEnd-op.AStart != 1 {
+			t.Errorf("delete %v covers %d clusters, want 1", op, op.AEnd-op.AStart)
+		}
+	}
+}
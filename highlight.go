@@ -0,0 +1,198 @@
+package diffx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Intra-replace highlighting.
+//
+// When a deleted region and an inserted region are paired (adjacent, or via
+// PairChanges), most of their text is usually identical. Highlighting the
+// entire regions hides the actual edit; highlighting only the words that
+// differ, as GitHub does within changed lines, makes it obvious.
+
+// Segment is a run of text within a highlighted region.
+type Segment struct {
+	Text    string
+	Changed bool // the text differs from the other side of the pair
+}
+
+// HighlightPair splits the text of a paired Delete and Insert into segments,
+// marking only the words that differ between them. The regions must consist
+// of StringElements; elements are joined with newlines. ok is false if the
+// pair refers to operations of the wrong type or to non-string elements.
+func HighlightPair(pair ChangePair, ops []DiffOp, a, b []Element) (del, ins []Segment, ok bool) {
+	if pair.DeleteOp < 0 || pair.DeleteOp >= len(ops) || pair.InsertOp < 0 || pair.InsertOp >= len(ops) {
+		return nil, nil, false
+	}
+	d, in := ops[pair.DeleteOp], ops[pair.InsertOp]
+	if d.Type != Delete || in.Type != Insert {
+		return nil, nil, false
+	}
+
+	oldText, okA := joinStringElements(a[d.AStart:d.AEnd])
+	newText, okB := joinStringElements(b[in.BStart:in.BEnd])
+	if !okA || !okB {
+		return nil, nil, false
+	}
+
+	del, ins = HighlightText(oldText, newText)
+	return del, ins, true
+}
+
+// HighlightText compares two strings word by word and returns each side
+// split into segments, marking the words that differ. Whitespace and
+// punctuation are kept, so concatenating the segments of each side
+// reproduces the input exactly. Texts longer than the WithMaxLineLen
+// default are first compared in chunks cut at content-defined rune
+// boundaries; only changed runs of chunks short enough for a word diff are
+// compared word by word, and longer ones are marked changed as a whole.
+func HighlightText(oldText, newText string) (del, ins []Segment) {
+	if len(oldText) > defaultMaxLineLen || len(newText) > defaultMaxLineLen {
+		return highlightChunks(oldText, newText)
+	}
+	return highlightWords(nil, nil, oldText, newText)
+}
+
+// highlightWords appends the word diff of oldText and newText to del and
+// ins.
+func highlightWords(del, ins []Segment, oldText, newText string) ([]Segment, []Segment) {
+	oldTokens := SplitWords(oldText)
+	newTokens := SplitWords(newText)
+
+	for _, op := range Diff(oldTokens, newTokens, WithPreprocessing(false)) {
+		switch op.Type {
+		case Equal:
+			del = appendSegment(del, strings.Join(oldTokens[op.AStart:op.AEnd], ""), false)
+			ins = appendSegment(ins, strings.Join(newTokens[op.BStart:op.BEnd], ""), false)
+		case Delete:
+			del = appendSegment(del, strings.Join(oldTokens[op.AStart:op.AEnd], ""), true)
+		case Insert:
+			ins = appendSegment(ins, strings.Join(newTokens[op.BStart:op.BEnd], ""), true)
+		}
+	}
+
+	return del, ins
+}
+
+// highlightChunks highlights long texts by diffing their splitChunks
+// chunks. Equal chunks are unchanged; each run of changed chunks between
+// them is word-diffed if both sides fit within defaultMaxLineLen, and
+// marked changed otherwise.
+func highlightChunks(oldText, newText string) (del, ins []Segment) {
+	oldChunks := splitChunks(oldText)
+	newChunks := splitChunks(newText)
+	ops := Diff(oldChunks, newChunks, WithPreprocessing(false))
+
+	for i := 0; i < len(ops); {
+		if op := ops[i]; op.Type == Equal {
+			del = appendSegment(del, strings.Join(oldChunks[op.AStart:op.AEnd], ""), false)
+			ins = appendSegment(ins, strings.Join(newChunks[op.BStart:op.BEnd], ""), false)
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].Type != Equal {
+			j++
+		}
+		oldPart := strings.Join(oldChunks[ops[i].AStart:ops[j-1].AEnd], "")
+		newPart := strings.Join(newChunks[ops[i].BStart:ops[j-1].BEnd], "")
+		if len(oldPart) <= defaultMaxLineLen && len(newPart) <= defaultMaxLineLen {
+			del, ins = highlightWords(del, ins, oldPart, newPart)
+		} else {
+			del = appendSegment(del, oldPart, true)
+			ins = appendSegment(ins, newPart, true)
+		}
+		i = j
+	}
+
+	return del, ins
+}
+
+// FormatSegments renders segments as text, wrapping changed segments in
+// open and close markers, for example "[-" and "-]".
+func FormatSegments(segs []Segment, open, close string) string {
+	var sb strings.Builder
+	for _, s := range segs {
+		if s.Changed {
+			sb.WriteString(open)
+			sb.WriteString(s.Text)
+			sb.WriteString(close)
+		} else {
+			sb.WriteString(s.Text)
+		}
+	}
+	return sb.String()
+}
+
+// appendSegment appends text to segs, extending the last segment when it
+// has the same Changed state.
+func appendSegment(segs []Segment, text string, changed bool) []Segment {
+	if text == "" {
+		return segs
+	}
+	if n := len(segs); n > 0 && segs[n-1].Changed == changed {
+		segs[n-1].Text += text
+		return segs
+	}
+	return append(segs, Segment{Text: text, Changed: changed})
+}
+
+// SplitWords splits s into words (runs of letters, digits, and
+// underscores), runs of whitespace, and single punctuation characters.
+// Concatenating the tokens reproduces s. It is the tokenizer of
+// HighlightText and RewrapDiff, exported so that word diffs elsewhere
+// split text the same way.
+func SplitWords(s string) []string {
+	var tokens []string
+	start := 0
+	prevClass := -1
+
+	for i, r := range s {
+		class := runeClass(r)
+		// Punctuation is always a token of its own
+		if i > start && (class != prevClass || class == classPunct) {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		prevClass = class
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// Rune classes used by SplitWords.
+const (
+	classWord = iota
+	classSpace
+	classPunct
+)
+
+// runeClass classifies r for SplitWords.
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return classWord
+	case unicode.IsSpace(r):
+		return classSpace
+	default:
+		return classPunct
+	}
+}
+
+// joinStringElements joins the text of StringElements with newlines.
+// It reports false if any element is not a StringElement.
+func joinStringElements(elems []Element) (string, bool) {
+	parts := make([]string, len(elems))
+	for i, e := range elems {
+		s, ok := e.(StringElement)
+		if !ok {
+			return "", false
+		}
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, "\n"), true
+}
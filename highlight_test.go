@@ -0,0 +1,139 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"hello", []string{"hello"}},
+		{"hello, world!", []string{"hello", ",", " ", "world", "!"}},
+		{"a  b\n", []string{"a", "  ", "b", "\n"}},
+		{"foo_bar(x)", []string{"foo_bar", "(", "x", ")"}},
+		{"...", []string{".", ".", "."}},
+		{"héllo wörld", []string{"héllo", " ", "wörld"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitWords(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitWords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightText(t *testing.T) {
+	del, ins := HighlightText("The quick brown fox.", "The quick red fox.")
+
+	if got := FormatSegments(del, "[-", "-]"); got != "The quick [-brown-] fox." {
+		t.Errorf("deleted side = %q", got)
+	}
+	if got := FormatSegments(ins, "{+", "+}"); got != "The quick {+red+} fox." {
+		t.Errorf("inserted side = %q", got)
+	}
+}
+
+func TestHighlightText_Reconstructs(t *testing.T) {
+	oldText := "line one\n  indented,  spaced"
+	newText := "line two\n\tindented; spaced"
+
+	del, ins := HighlightText(oldText, newText)
+	if got := FormatSegments(del, "", ""); got != oldText {
+		t.Errorf("deleted segments = %q, want %q", got, oldText)
+	}
+	if got := FormatSegments(ins, "", ""); got != newText {
+		t.Errorf("inserted segments = %q, want %q", got, newText)
+	}
+}
+
+func TestHighlightText_LongLine(t *testing.T) {
+	long := minified(defaultMaxLineLen)
+	tests := []struct {
+		name     string
+		old, new string
+		wantDel  string
+		wantIns  string
+	}{
+		{"edit at the end", long + "x", long + "y", "[-x-]", "{+y+}"},
+		{"edit in the middle", long + "old " + long, long + "new " + long, "[-old-]", "{+new+}"},
+		{"insertion", long + long, long + "added " + long, "", "{+added +}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			del, ins := HighlightText(tt.old, tt.new)
+			if got := FormatSegments(del, "", ""); got != tt.old {
+				t.Error("deleted segments do not reproduce the input")
+			}
+			if got := FormatSegments(ins, "", ""); got != tt.new {
+				t.Error("inserted segments do not reproduce the input")
+			}
+			if got := changedText(del, "[-", "-]"); got != tt.wantDel {
+				t.Errorf("deleted changes = %q, want %q", TruncateLine(got, 100), tt.wantDel)
+			}
+			if got := changedText(ins, "{+", "+}"); got != tt.wantIns {
+				t.Errorf("inserted changes = %q, want %q", TruncateLine(got, 100), tt.wantIns)
+			}
+		})
+	}
+
+	// Texts that share no chunks are marked changed as a whole
+	del, ins := HighlightText(strings.Repeat("a ", defaultMaxLineLen), strings.Repeat("b ", defaultMaxLineLen))
+	if len(del) != 1 || !del[0].Changed || len(ins) != 1 || !ins[0].Changed {
+		t.Errorf("unrelated texts: %d and %d segments, want one changed segment each", len(del), len(ins))
+	}
+}
+
+// changedText returns the changed segments of segs, each wrapped in open
+// and close.
+func changedText(segs []Segment, open, close string) string {
+	var changed []Segment
+	for _, s := range segs {
+		if s.Changed {
+			changed = append(changed, s)
+		}
+	}
+	return FormatSegments(changed, open, close)
+}
+
+func TestHighlightPair(t *testing.T) {
+	a := toElements([]string{"Install with go get.", "same"})
+	b := toElements([]string{"same", "Install with go install."})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 0, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+	}
+
+	pairs := PairChanges(ops, a, b)
+	if len(pairs) != 1 {
+		t.Fatalf("expected 1 pair, got %+v", pairs)
+	}
+
+	del, ins, ok := HighlightPair(pairs[0], ops, a, b)
+	if !ok {
+		t.Fatal("HighlightPair() not ok")
+	}
+	if got := FormatSegments(del, "[-", "-]"); got != "Install with go [-get-]." {
+		t.Errorf("deleted side = %q", got)
+	}
+	if got := FormatSegments(ins, "{+", "+}"); got != "Install with go {+install+}." {
+		t.Errorf("inserted side = %q", got)
+	}
+}
+
+func TestHighlightPair_Invalid(t *testing.T) {
+	ops := []DiffOp{{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1}}
+	a := toElements([]string{"x"})
+
+	if _, _, ok := HighlightPair(ChangePair{DeleteOp: 0, InsertOp: 0}, ops, a, a); ok {
+		t.Error("expected not ok for Equal ops")
+	}
+	if _, _, ok := HighlightPair(ChangePair{DeleteOp: 5, InsertOp: 0}, ops, a, a); ok {
+		t.Error("expected not ok for out-of-range op index")
+	}
+}
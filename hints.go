@@ -0,0 +1,84 @@
+package diffx
+
+// Known common prefix and suffix.
+//
+// An editor diffing a buffer against its saved version after an edit
+// already knows which lines the edit cannot have touched. Every diff
+// otherwise compares the sequences from both ends to find the common
+// prefix and suffix, and preprocessing hashes every element, which for
+// large buffers costs more than diffing the few changed lines. With
+// WithCommonPrefix and WithCommonSuffix the algorithms only look at the
+// window between them.
+
+// WithCommonPrefix tells the diff that the first n elements of A and B are
+// equal, so that only the elements after them need to be compared. The
+// hint is trusted, not checked: if the elements differ, the result reports
+// them as equal. Values larger than either sequence are reduced to its
+// length; negative values are treated as 0.
+// Default: 0.
+func WithCommonPrefix(n int) Option {
+	n = max(n, 0)
+	return func(o *options) {
+		o.commonPrefix = n
+	}
+}
+
+// WithCommonSuffix is like WithCommonPrefix for the last n elements of A and
+// B. Where the hinted prefix and suffix would overlap in the shorter
+// sequence, the suffix is reduced.
+// Default: 0.
+func WithCommonSuffix(n int) Option {
+	n = max(n, 0)
+	return func(o *options) {
+		o.commonSuffix = n
+	}
+}
+
+// hintedWindow returns the lengths of the common prefix and suffix hinted
+// by o, reduced to fit sequences of lengths n and m.
+func (o *options) hintedWindow(n, m int) (prefix, suffix int) {
+	prefix = min(o.commonPrefix, n, m)
+	suffix = min(o.commonSuffix, n-prefix, m-prefix)
+	return prefix, suffix
+}
+
+// diffWindow runs core on the elements of a and b between the common
+// prefix and suffix hinted by o and returns its ops in the indices of a and
+// b, with Equal ops for the prefix and suffix. core reports trace events
+// in the indices of the window, and they are passed on in those of a and b.
+func diffWindow(a, b []Element, o *options, core func(a, b []Element, o *options) []DiffOp) []DiffOp {
+	prefix, suffix := o.hintedWindow(len(a), len(b))
+	if prefix == 0 && suffix == 0 {
+		return core(a, b, o)
+	}
+
+	wo := o
+	if trace := o.trace; trace != nil && prefix > 0 {
+		c := *o
+		c.trace = func(e TraceEvent) {
+			e.AStart, e.AEnd, e.X = e.AStart+prefix, e.AEnd+prefix, e.X+prefix
+			e.BStart, e.BEnd, e.Y = e.BStart+prefix, e.BEnd+prefix, e.Y+prefix
+			trace(e)
+		}
+		wo = &c
+	}
+
+	n, m := len(a)-suffix, len(b)-suffix
+	var ops []DiffOp
+	if prefix > 0 {
+		ops = append(ops, DiffOp{Type: Equal, AStart: 0, AEnd: prefix, BStart: 0, BEnd: prefix})
+	}
+	switch wa, wb := a[prefix:n], b[prefix:m]; {
+	case len(wa) == 0 && len(wb) == 0:
+	case len(wa) == 0:
+		ops = append(ops, DiffOp{Type: Insert, AStart: prefix, AEnd: prefix, BStart: prefix, BEnd: m})
+	case len(wb) == 0:
+		ops = append(ops, DiffOp{Type: Delete, AStart: prefix, AEnd: n, BStart: prefix, BEnd: prefix})
+	default:
+		ops = append(ops, OffsetOps(core(wa, wb, wo), prefix, prefix)...)
+	}
+	if suffix > 0 {
+		ops = append(ops, DiffOp{Type: Equal, AStart: n, AEnd: len(a), BStart: m, BEnd: len(b)})
+	}
+	return mergeAdjacentOps(ops)
+}
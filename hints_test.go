@@ -0,0 +1,136 @@
+package diffx
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestWithCommonPrefix(t *testing.T) {
+	// A long buffer with one edited line in the middle
+	var a, b []string
+	for i := 0; i < 1000; i++ {
+		line := "line " + strconv.Itoa(i)
+		a = append(a, line)
+		if i == 500 {
+			line = "edited"
+		}
+		b = append(b, line)
+	}
+
+	for name, diff := range map[string]func(a, b []string, opts ...Option) []DiffOp{"Diff": Diff, "DiffHistogram": DiffHistogram} {
+		var plain, hinted CallCounts
+		want := diff(a, b, WithPreprocessing(false), WithCallCounts(&plain))
+		got := diff(a, b, WithPreprocessing(false), WithCommonPrefix(490), WithCommonSuffix(495), WithCallCounts(&hinted))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: hinted = %v, want %v", name, got, want)
+		}
+		if hinted.Equal+hinted.Hash >= (plain.Equal+plain.Hash)/10 {
+			t.Errorf("%s: hinted diff made %+v calls, want far fewer than %+v", name, hinted, plain)
+		}
+
+		// Preprocessing only sees the window, but the script stays valid
+		checkScript(t, a, b, diff(a, b, WithCommonPrefix(490), WithCommonSuffix(495)))
+	}
+}
+
+func TestWithCommonPrefix_Window(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           []string
+		prefix, suffix int
+		want           []DiffOp
+	}{
+		{
+			name:   "insertion between hints",
+			a:      []string{"a", "b"},
+			b:      []string{"a", "x", "b"},
+			prefix: 1, suffix: 1,
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Insert, AStart: 1, AEnd: 1, BStart: 1, BEnd: 2},
+				{Type: Equal, AStart: 1, AEnd: 2, BStart: 2, BEnd: 3},
+			},
+		},
+		{
+			name:   "deletion between hints",
+			a:      []string{"a", "x", "y", "b"},
+			b:      []string{"a", "b"},
+			prefix: 1, suffix: 1,
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+				{Type: Equal, AStart: 3, AEnd: 4, BStart: 1, BEnd: 2},
+			},
+		},
+		{
+			name:   "hints beyond the sequences",
+			a:      []string{"a", "b"},
+			b:      []string{"a", "b", "c"},
+			prefix: 5, suffix: 5,
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3},
+			},
+		},
+		{
+			name:   "overlapping hints",
+			a:      []string{"a", "a"},
+			b:      []string{"a", "a", "a"},
+			prefix: 2, suffix: 2,
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3},
+			},
+		},
+		{
+			name:   "negative hints",
+			a:      []string{"a", "b"},
+			b:      []string{"a", "c"},
+			prefix: -1, suffix: -1,
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+			},
+		},
+		{
+			// Hints are trusted, not checked
+			name:   "wrong hint",
+			a:      []string{"a", "b"},
+			b:      []string{"x", "b"},
+			prefix: 1,
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithCommonPrefix(tt.prefix), WithCommonSuffix(tt.suffix)}
+			if got := Diff(tt.a, tt.b, opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+			if got := DiffHistogram(tt.a, tt.b, opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffHistogram() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCommonPrefix_Trace(t *testing.T) {
+	// Events of the window are reported in the indices of the inputs
+	a := []string{"p", "p", "x", "y", "z", "s"}
+	b := []string{"p", "p", "x", "w", "z", "s"}
+
+	var events []TraceEvent
+	Diff(a, b, WithCommonPrefix(2), WithPreprocessing(false), collectTrace(&events))
+	trims := eventsOf(events, TraceTrim)
+	if len(trims) == 0 {
+		t.Fatalf("no trim events: %+v", events)
+	}
+	if e := trims[0]; e.AStart != 2 || e.AEnd != 6 || e.BStart != 2 || e.BEnd != 6 {
+		t.Errorf("first trim = %+v, want the window [2,6) x [2,6)", e)
+	}
+}
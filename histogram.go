@@ -24,16 +24,35 @@ type histogramOptions struct {
 	// Git uses 64 by default, but for word-level diff we use a lower value.
 	maxChainLength int
 
+	// chainFallback makes an element above maxChainLength send its whole
+	// region to the Myers fallback, as in Git, instead of only being
+	// skipped as an anchor.
+	chainFallback bool
+
 	// fallbackToMyers controls whether to use Myers when no good anchors exist.
 	fallbackToMyers bool
 
 	// filterStopwords prevents common words from being used as anchors.
 	filterStopwords bool
+
+	// stats records anchor choices and Myers fallbacks, if not nil.
+	stats *Stats
+
+	// trace receives trim and anchor events, if not nil.
+	trace func(TraceEvent)
+
+	// myers holds the caller's options for the Myers fallback: its
+	// heuristics, cost limit, minimality, and preprocessing. Nil means
+	// the defaults.
+	myers *options
 }
 
+// defaultChainLimit is the default maxChainLength. It matches Git's.
+const defaultChainLimit = 64
+
 func defaultHistogramOptions() *histogramOptions {
 	return &histogramOptions{
-		maxChainLength:  64, // Match Git's default; allow higher-frequency anchors
+		maxChainLength:  defaultChainLimit, // Match Git's default; allow higher-frequency anchors
 		fallbackToMyers: true,
 		filterStopwords: true, // Filter stopwords for histogram anchors; Myers fallback finds others
 	}
@@ -54,13 +73,16 @@ var stopwords = map[string]bool{
 	"is": true, "are": true, "be": true,
 }
 
-// isStopword checks if a string element is a stopword.
+// isStopword checks if a string element is a stopword. An element compared
+// by a normalized key is checked by its key.
 func isStopword(e Element) bool {
-	s, ok := e.(StringElement)
-	if !ok {
-		return false
+	switch v := unwrapElement(e).(type) {
+	case StringElement:
+		return stopwords[string(v)]
+	case keyedElement:
+		return stopwords[v.key]
 	}
-	return stopwords[string(s)]
+	return false
 }
 
 // histogramDiff performs histogram-style diff on two element sequences.
@@ -98,6 +120,8 @@ func histogramDiff(a, b []Element, opts *histogramOptions) []DiffOp {
 		return []DiffOp{{Type: Equal, AStart: 0, AEnd: len(a), BStart: 0, BEnd: len(b)}}
 	}
 
+	traceTrim(opts.trace, 0, len(a), 0, len(b), prefixLen, suffixLen)
+
 	// Work on the middle section
 	aStart, aEnd := prefixLen, len(a)-suffixLen
 	bStart, bEnd := prefixLen, len(b)-suffixLen
@@ -126,6 +150,45 @@ func histogramDiff(a, b []Element, opts *histogramOptions) []DiffOp {
 	return mergeAdjacentOps(result)
 }
 
+// balancedMatch returns the index among candidates of the element of a
+// equal to b[bIdx] whose relative position in a is closest to bIdx's
+// relative position in b, or -1 if none is equal (a hash collision).
+func balancedMatch(a, b []Element, candidates []int, bIdx int) int {
+	best := -1
+	var bestImbalance int64
+	for _, aIdx := range candidates {
+		// Verify hash collision
+		if !a[aIdx].Equal(b[bIdx]) {
+			continue
+		}
+		if imb := imbalance(aIdx, bIdx, len(a), len(b)); best == -1 || imb < bestImbalance {
+			best = aIdx
+			bestImbalance = imb
+		}
+	}
+	return best
+}
+
+// imbalance returns |aIdx/lenA - bIdx/lenB| scaled by lenA*lenB, so that
+// relative positions can be compared exactly. Both products fit in int64
+// for inputs of up to MaxElements elements.
+func imbalance(aIdx, bIdx, lenA, lenB int) int64 {
+	d := int64(aIdx)*int64(lenB) - int64(bIdx)*int64(lenA)
+	if d < 0 {
+		d = -d
+	}
+	return d
+}
+
+// anchorScore combines the frequency of a candidate anchor in a section of
+// lenA by lenB elements and its position imbalance. Lower frequency is
+// better, lower imbalance is better. The product overflows int64 for
+// sections of a few hundred million elements, so it is computed in 128
+// bits.
+func anchorScore(freq, lenA, lenB int, imb int64) score128 {
+	return mul128(uint64(freq), uint64(lenA)*uint64(lenB)+2*uint64(imb))
+}
+
 // histogramDiffRecursive performs the core histogram algorithm on a section.
 func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogramOptions) []DiffOp {
 	if len(a) == 0 && len(b) == 0 {
@@ -144,14 +207,26 @@ func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogra
 	for i, e := range a {
 		h := e.Hash()
 		aFreq[h]++
+		if opts.chainFallback && opts.fallbackToMyers && aFreq[h] > opts.maxChainLength {
+			// Too repetitive to anchor reliably: give up on the whole
+			// region rather than scan B against the long chains
+			opts.stats.recordAnchor(false)
+			return myersFallback(a, b, aOffset, bOffset, opts)
+		}
 		aIndices[h] = append(aIndices[h], i)
 	}
 
 	// Find the best anchor: consider both frequency AND position balance.
 	// We want low-frequency tokens, but also tokens that create balanced splits.
-	// Score = frequency * (1 + positionImbalance), lower is better.
+	// Score = frequency * (1 + 2*positionImbalance), lower is better.
+	//
+	// Positions are compared as fractions of the sequence lengths. To keep
+	// the choice exact and identical on every platform, they are scaled by
+	// len(a)*len(b) and compared as integers rather than as floats, whose
+	// rounding (including fused multiply-add) can differ between
+	// architectures. Ties keep the earliest candidate.
 	bestIdx := -1
-	bestScore := float64(opts.maxChainLength+1) * 3 // Initialize to impossible value
+	var bestScore score128
 	var bestHash uint64
 
 	for i, e := range b {
@@ -167,32 +242,13 @@ func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogra
 		}
 
 		// Find the best matching position in A for this potential anchor
-		bRatio := float64(i) / float64(len(b))
-		bestPosImbalance := 2.0
-
-		for _, aIdx := range aIndices[h] {
-			if !a[aIdx].Equal(e) {
-				continue
-			}
-			aRatio := float64(aIdx) / float64(len(a))
-			imbalance := aRatio - bRatio
-			if imbalance < 0 {
-				imbalance = -imbalance
-			}
-			if imbalance < bestPosImbalance {
-				bestPosImbalance = imbalance
-			}
-		}
-
-		if bestPosImbalance > 1.5 {
+		aMatch := balancedMatch(a, b, aIndices[h], i)
+		if aMatch == -1 {
 			continue // No valid match position found
 		}
 
-		// Score combines frequency and position imbalance
-		// Lower frequency is better, lower imbalance is better
-		score := float64(freq) * (1.0 + bestPosImbalance*2)
-
-		if score < bestScore {
+		score := anchorScore(freq, len(a), len(b), imbalance(aMatch, i, len(a), len(b)))
+		if bestIdx == -1 || score.less(bestScore) {
 			bestScore = score
 			bestIdx = i
 			bestHash = h
@@ -202,9 +258,10 @@ func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogra
 	// No good anchor found - fall back to Myers to find more anchors.
 	// Myers will find common subsequences that histogram missed.
 	// The anchor elimination post-processing will clean up bad stopword matches.
+	opts.stats.recordAnchor(bestIdx != -1)
 	if bestIdx == -1 {
 		if opts.fallbackToMyers {
-			return myersFallback(a, b, aOffset, bOffset)
+			return myersFallback(a, b, aOffset, bOffset, opts)
 		}
 		// Only use delete+insert if Myers fallback is disabled
 		return []DiffOp{
@@ -216,36 +273,7 @@ func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogra
 	// Find the best matching position in A for this anchor.
 	// Instead of picking the first occurrence, pick the one that creates
 	// the most balanced split (position ratio in A closest to position ratio in B).
-	bRatio := float64(bestIdx) / float64(len(b))
-	aMatchIdx := -1
-	bestRatioDiff := 2.0 // Initialize to impossible value
-
-	for _, idx := range aIndices[bestHash] {
-		// Verify hash collision
-		if !a[idx].Equal(b[bestIdx]) {
-			continue
-		}
-		aRatio := float64(idx) / float64(len(a))
-		ratioDiff := aRatio - bRatio
-		if ratioDiff < 0 {
-			ratioDiff = -ratioDiff
-		}
-		if ratioDiff < bestRatioDiff {
-			bestRatioDiff = ratioDiff
-			aMatchIdx = idx
-		}
-	}
-
-	if aMatchIdx == -1 {
-		// No valid match found - fall back
-		if opts.fallbackToMyers {
-			return myersFallback(a, b, aOffset, bOffset)
-		}
-		return []DiffOp{
-			{Type: Delete, AStart: aOffset, AEnd: aOffset + len(a), BStart: bOffset, BEnd: bOffset},
-			{Type: Insert, AStart: aOffset + len(a), AEnd: aOffset + len(a), BStart: bOffset, BEnd: bOffset + len(b)},
-		}
-	}
+	aMatchIdx := balancedMatch(a, b, aIndices[bestHash], bestIdx)
 
 	// Extend the match forward and backward to find the full matching region
 	matchStartA, matchStartB := aMatchIdx, bestIdx
@@ -263,6 +291,17 @@ func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogra
 		matchEndB++
 	}
 
+	if opts.trace != nil {
+		opts.trace(TraceEvent{
+			Kind:   TraceAnchor,
+			AStart: aOffset, AEnd: aOffset + len(a),
+			BStart: bOffset, BEnd: bOffset + len(b),
+			X: aOffset + matchStartA, Y: bOffset + matchStartB,
+			Len:       matchEndA - matchStartA,
+			Frequency: aFreq[bestHash],
+		})
+	}
+
 	// Recursively diff the sections before and after the match
 	var result []DiffOp
 
@@ -298,57 +337,147 @@ func histogramDiffRecursive(a, b []Element, aOffset, bOffset int, opts *histogra
 	return result
 }
 
-// myersFallback uses the standard Myers algorithm for a section.
-func myersFallback(a, b []Element, aOffset, bOffset int) []DiffOp {
+// myersFallback uses the standard Myers algorithm for a section, with the
+// preprocessing, gap refinement, and core options of opts.myers.
+func myersFallback(a, b []Element, aOffset, bOffset int, opts *histogramOptions) []DiffOp {
 	// Create a temporary context for Myers diff
 	o := defaultOptions()
-	o.preprocessing = false  // Already preprocessed
+	if m := opts.myers; m != nil {
+		o.useHeuristic, o.forceMinimal, o.costLimit = m.useHeuristic, m.forceMinimal, m.costLimit
+		o.preprocessing, o.gapRefinement = m.preprocessing, m.gapRefinement
+		o.budget = m.budget
+	}
 	o.postprocessing = false // Will be done after
 	o.anchorElimination = false
+	o.stats = opts.stats
+
+	origA, origB := a, b
+	var mapping *indexMapping
+	if o.preprocessing {
+		filteredA, filteredB, m := filterConfusingElements(a, b)
+		o.stats.recordFilter(a, b, len(filteredA), len(filteredB))
+		a, b, mapping = filteredA, filteredB, m
+	}
+	if trace := opts.trace; trace != nil {
+		// Report the section's events in the indices of the whole input
+		o.trace = func(e TraceEvent) {
+			e.AStart, e.BStart = mapping.orig(e.AStart, e.BStart)
+			e.AEnd, e.BEnd = mapping.orig(e.AEnd, e.BEnd)
+			e.X, e.Y = mapping.orig(e.X, e.Y)
+			e.AStart, e.AEnd, e.X = e.AStart+aOffset, e.AEnd+aOffset, e.X+aOffset
+			e.BStart, e.BEnd, e.Y = e.BStart+bOffset, e.BEnd+bOffset, e.Y+bOffset
+			trace(e)
+		}
+	}
 
 	ctx := newDiffContext(a, b, o)
-	ctx.compareSeq(0, len(a), 0, len(b), false)
+	ctx.compareSeq(0, len(a), 0, len(b), o.forceMinimal)
 	ops := ctx.buildOps()
+	if mapping != nil {
+		ops = mapping.mapOps(ops)
+		if o.gapRefinement {
+			ops = mapping.refineGaps(ops, origA, origB, o)
+		}
+	}
+	return OffsetOps(ops, aOffset, bOffset)
+}
 
-	// Adjust offsets
-	for i := range ops {
-		ops[i].AStart += aOffset
-		ops[i].AEnd += aOffset
-		ops[i].BStart += bOffset
-		ops[i].BEnd += bOffset
+// WithHistogramChainLimit sets how often an element may occur in a region
+// of A and still be chosen as a histogram anchor. Elements that occur more
+// often are skipped, or with WithHistogramChainFallback end the anchor
+// search for the region. 0 or less means the default. Only affects
+// histogram diff.
+// Default: 64.
+func WithHistogramChainLimit(n int) Option {
+	if n <= 0 {
+		n = defaultChainLimit
 	}
+	return func(o *options) {
+		o.chainLimit = n
+	}
+}
 
-	return ops
+// WithHistogramChainFallback makes histogram diff behave like Git's when an
+// element occurs in a region of A more often than the chain limit: the whole
+// region is diffed with the Myers algorithm instead of only skipping that
+// element as an anchor. Highly repetitive regions then cost one Myers diff
+// instead of an anchor search over every element, and get a Myers
+// alignment. Only affects histogram diff.
+// Default: false.
+func WithHistogramChainFallback(enabled bool) Option {
+	return func(o *options) {
+		o.chainFallback = enabled
+	}
 }
 
 // DiffHistogram performs histogram-style diff on string slices.
 func DiffHistogram(a, b []string, opts ...Option) []DiffOp {
-	return DiffElementsHistogram(toElements(a), toElements(b), opts...)
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return DiffElementsHistogram(o.ignoreOpts.elements(a), o.ignoreOpts.elements(b), opts...)
 }
 
 // DiffElementsHistogram performs histogram-style diff on Element slices.
+//
+// It accepts the same options as DiffElements. Regions without a usable
+// anchor are diffed with the Myers algorithm, which WithPreprocessing,
+// WithHeuristic, WithCostLimit, and WithMinimal configure as they do for
+// DiffElements. The anchors themselves do not depend on those options, so
+// WithMinimal makes the fallback regions minimal, not the whole result.
 func DiffElementsHistogram(a, b []Element, opts ...Option) []DiffOp {
 	// Apply options
 	o := defaultOptions()
 	for _, opt := range opts {
 		opt(o)
 	}
+	if err := checkSize(len(a), len(b)); err != nil {
+		panic(err)
+	}
+
+	if o.annotations != nil {
+		return annotate(a, b, opts, o, false, DiffElementsHistogram)
+	}
+	if o.metrics != nil {
+		return observe("histogram", a, b, opts, o, DiffElementsHistogram)
+	}
+	if o.contractErr != nil {
+		return checkedDiff(a, b, opts, o, DiffElementsHistogram)
+	}
+	if o.callCounts != nil {
+		a, b = countCalls(a, o.callCounts), countCalls(b, o.callCounts)
+	}
 
 	origA, origB := a, b
 
 	histOpts := defaultHistogramOptions()
-
-	// Run histogram diff
-	ops := histogramDiff(a, b, histOpts)
+	histOpts.stats = o.stats
+	histOpts.myers = o
+	histOpts.maxChainLength = o.chainLimit
+	histOpts.chainFallback = o.chainFallback
+	o.stats.recordCall(a, b)
+
+	// Run histogram diff between any common prefix and suffix the caller
+	// hinted at, within the work limit
+	ops := diffWindow(a, b, o, withWorkLimit(func(a, b []Element, o *options) []DiffOp {
+		histOpts.trace, histOpts.myers = o.trace, o
+		return histogramDiff(a, b, histOpts)
+	}))
 
 	// Apply anchor elimination if enabled
 	if o.anchorElimination {
-		ops = eliminateWeakAnchors(ops, origA, origB)
+		ops = eliminateWeakAnchors(ops, origA, origB, o.anchorOpts)
+	}
+
+	// Apply efficiency cleanup if enabled
+	if o.editCost > 0 {
+		ops = cleanupEfficiency(ops, o.editCost)
 	}
 
 	// Apply boundary shifting if enabled
 	if o.postprocessing {
-		ops = shiftBoundaries(ops, origA, origB)
+		ops = shiftBoundaries(ops, origA, origB, o)
 	}
 
 	return ops
@@ -2,6 +2,7 @@ package diffx
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -233,6 +234,103 @@ func TestHistogramDiff_MyersFallback(t *testing.T) {
 	}
 }
 
+func TestDiffHistogram_MyersOptions(t *testing.T) {
+	// Stopwords and elements of one side only are no anchors, so histogram
+	// diff hands the whole input to Myers, which must see the same options
+	// as Diff. The shared run of stopwords lets the heuristics cut the
+	// search short, and the one-sided elements give preprocessing
+	// something to filter.
+	words := []string{"the", "a", "an", "in", "on", "to", "for", "of", "and", "or"}
+	seq := func(side string, seed uint32, n int) []string {
+		s := make([]string, n)
+		for i := range s {
+			seed = seed*1103515245 + 12345
+			switch s[i] = words[int(seed>>16)%len(words)]; {
+			case side == "":
+			case i%2 == 0:
+				s[i] = side + strconv.Itoa(i)
+			case i%50 == 1:
+				s[i] = "is" // rare enough to survive preprocessing
+			}
+		}
+		return s
+	}
+	common := seq("", 3, 40)[1:]
+	a := append(append(seq("a", 1, 200), common...), seq("a", 4, 200)...)
+	b := append(append(seq("b", 2, 200), common...), seq("b", 5, 200)...)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"defaults", nil},
+		{"no preprocessing", []Option{WithPreprocessing(false)}},
+		{"minimal", []Option{WithMinimal(true), WithPreprocessing(false)}},
+		{"cost limit", []Option{WithCostLimit(8), WithPreprocessing(false)}},
+		{"no heuristic", []Option{WithHeuristic(false), WithPreprocessing(false)}},
+		{"gap refinement", []Option{WithGapRefinement(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var myers, hist Stats
+			want := Diff(a, b, append(tt.opts, WithStats(&myers))...)
+			got := DiffHistogram(a, b, append(tt.opts, WithStats(&hist))...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("DiffHistogram() differs from Diff():\n got %v\nwant %v", got, want)
+			}
+			if hist.MyersFallbacks != 1 || hist.HeuristicFallbacks != myers.HeuristicFallbacks || hist.FilteredA != myers.FilteredA {
+				t.Errorf("histogram stats = %+v, want one fallback matching %+v", hist, myers)
+			}
+		})
+	}
+}
+
+func TestDiffHistogram_ChainLimit(t *testing.T) {
+	// "x" occurs 70 times, above the default limit of 64; "mid" is a
+	// unique anchor among them
+	var a, b []string
+	for i := 0; i < 70; i++ {
+		a = append(a, "x")
+		b = append(b, "x", "y")
+		if i == 35 {
+			a = append(a, "mid")
+			b = append(b, "mid")
+		}
+	}
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantAnchors   bool
+		wantFallbacks int
+	}{
+		{"skip repetitive elements", nil, true, 0},
+		{"fall back for the region", []Option{WithHistogramChainFallback(true)}, false, 1},
+		{"higher limit", []Option{WithHistogramChainFallback(true), WithHistogramChainLimit(100)}, true, 0},
+		{"non-positive limit is the default", []Option{WithHistogramChainFallback(true), WithHistogramChainLimit(0)}, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Stats
+			ops := DiffHistogram(a, b, append(tt.opts, WithStats(&s))...)
+			if got := applyHistogramDiff(a, b, ops); !reflect.DeepEqual(got, b) {
+				t.Fatalf("applying diff = %v, want %v", got, b)
+			}
+			if (s.HistogramAnchors > 0) != tt.wantAnchors || s.MyersFallbacks < tt.wantFallbacks {
+				t.Errorf("HistogramAnchors = %d, MyersFallbacks = %d, want anchors %v and at least %d fallbacks",
+					s.HistogramAnchors, s.MyersFallbacks, tt.wantAnchors, tt.wantFallbacks)
+			}
+		})
+	}
+
+	// The fallback gives the region a plain Myers alignment
+	want := Diff(a, b, WithPreprocessing(false))
+	got := DiffHistogram(a, b, WithHistogramChainFallback(true), WithPreprocessing(false))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("with fallback: DiffHistogram() = %v, want Diff() = %v", got, want)
+	}
+}
+
 func TestHistogramDiff_BalancedSplit(t *testing.T) {
 	// Test that histogram prefers balanced splits
 	a := toElements([]string{"a", "b", "anchor", "c", "d"})
@@ -373,3 +471,43 @@ func BenchmarkHistogramDiff_Large(b *testing.B) {
 		histogramDiff(a, bSeq, nil)
 	}
 }
+
+func TestImbalance(t *testing.T) {
+	tests := []struct {
+		aIdx, bIdx, lenA, lenB int
+		want                   int64
+	}{
+		{0, 0, 5, 5, 0},
+		{2, 2, 5, 5, 0},
+		{1, 2, 2, 4, 0},  // 1/2 == 2/4
+		{3, 1, 5, 5, 10}, // |3*5 - 1*5|
+		{1, 3, 5, 5, 10},
+		{1, 1, 3, 7, 4}, // |1*7 - 1*3|
+	}
+	for _, tt := range tests {
+		if got := imbalance(tt.aIdx, tt.bIdx, tt.lenA, tt.lenB); got != tt.want {
+			t.Errorf("imbalance(%d, %d, %d, %d) = %d, want %d", tt.aIdx, tt.bIdx, tt.lenA, tt.lenB, got, tt.want)
+		}
+	}
+}
+
+func TestBalancedMatch(t *testing.T) {
+	a := toElements([]string{"x", "p", "x", "q", "x", "r"})
+	b := toElements([]string{"s", "t", "x", "u", "v", "w"})
+
+	// x at index 2 of 6 in b; x at index 2 of 6 in a is exactly balanced
+	if got := balancedMatch(a, b, []int{0, 2, 4}, 2); got != 2 {
+		t.Errorf("balancedMatch = %d, want 2", got)
+	}
+
+	// Equally balanced candidates resolve to the first one
+	b = toElements([]string{"s", "t", "u", "x", "v", "w"})
+	if got := balancedMatch(a, b, []int{0, 2, 4}, 3); got != 2 {
+		t.Errorf("balancedMatch on tie = %d, want 2", got)
+	}
+
+	// Candidates that are not equal (hash collisions) are skipped
+	if got := balancedMatch(a, b, []int{1, 3}, 3); got != -1 {
+		t.Errorf("balancedMatch without equal candidates = %d, want -1", got)
+	}
+}
@@ -0,0 +1,301 @@
+// Package htmlreport renders line diffs as a single self-contained HTML
+// page, for attaching to CI artifacts or sharing with reviewers.
+//
+// The page shows each file side by side, with the words that changed
+// within a replaced line highlighted. Long unchanged regions are collapsed
+// and can be expanded in place, and a navigation bar (or the n and p keys)
+// steps through the hunks of all files. All styles and scripts are inline,
+// so the page works offline and from a file:// URL.
+package htmlreport
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// File is one compared file of a report. A and B hold the lines of each
+// version; a trailing "\n" or "\r\n" on a line is not displayed. Ops is an
+// edit script for A and B, as returned by diffx.Diff.
+type File struct {
+	NameA, NameB string
+	A, B         []string
+	Ops          []diffx.DiffOp
+
+	// Binary reports that the files differ but are not text. A, B, and
+	// Ops are ignored.
+	Binary bool
+}
+
+// Option configures a report.
+type Option func(*options)
+
+type options struct {
+	title   string
+	context int
+}
+
+// WithTitle sets the page title.
+// Default: "diffx report".
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithContext sets how many unchanged lines stay visible around each
+// change; longer unchanged regions are collapsed.
+// Default: 3.
+func WithContext(n int) Option {
+	return func(o *options) {
+		o.context = n
+	}
+}
+
+// Write writes a report of files to w as a complete HTML document.
+func Write(w io.Writer, files []File, opts ...Option) error {
+	o := &options{title: "diffx report", context: 3}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.context < 0 {
+		o.context = 0
+	}
+
+	bw := bufio.NewWriter(w)
+	title := html.EscapeString(o.title)
+	fmt.Fprintf(bw, pageHeader, title, title)
+
+	// Index of files with their change counts
+	bw.WriteString(`<ul class="index">` + "\n")
+	for i, f := range files {
+		if f.Binary {
+			fmt.Fprintf(bw, `<li><a href="#file-%d">%s</a> binary</li>`+"\n", i, html.EscapeString(fileName(f)))
+			continue
+		}
+		s := diffx.Stat(f.Ops)
+		fmt.Fprintf(bw, `<li><a href="#file-%d">%s</a> <span class="ins">+%d</span> <span class="del">-%d</span></li>`+"\n",
+			i, html.EscapeString(fileName(f)), s.Insertions, s.Deletions)
+	}
+	bw.WriteString("</ul>\n")
+
+	for i, f := range files {
+		writeFile(bw, i, f, o.context)
+	}
+
+	bw.WriteString(pageFooter)
+	return bw.Flush()
+}
+
+// fileName returns the name shown for f.
+func fileName(f File) string {
+	if f.NameA == f.NameB || f.NameA == "" {
+		return f.NameB
+	}
+	if f.NameB == "" {
+		return f.NameA
+	}
+	return f.NameA + " → " + f.NameB
+}
+
+// writeFile writes the side-by-side table of one file.
+func writeFile(w *bufio.Writer, idx int, f File, context int) {
+	fmt.Fprintf(w, `<section class="file" id="file-%d">`+"\n", idx)
+	fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(fileName(f)))
+	if f.Binary {
+		w.WriteString("<p>Binary files differ</p>\n</section>\n")
+		return
+	}
+	w.WriteString(`<table class="sbs"><colgroup><col class="ln"><col><col class="ln"><col></colgroup>` + "\n")
+
+	hunk := 0
+	ops := f.Ops
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		if op.Type == diffx.Equal {
+			writeEqual(w, f, op, context, i == 0, i == len(ops)-1)
+			continue
+		}
+
+		// Gather the run of changes up to the next Equal
+		var del, ins []int
+		for ; i < len(ops) && ops[i].Type != diffx.Equal; i++ {
+			for k := ops[i].AStart; k < ops[i].AEnd && ops[i].Type == diffx.Delete; k++ {
+				del = append(del, k)
+			}
+			for k := ops[i].BStart; k < ops[i].BEnd && ops[i].Type == diffx.Insert; k++ {
+				ins = append(ins, k)
+			}
+		}
+		i--
+
+		fmt.Fprintf(w, `<tbody class="hunk" id="file-%d-hunk-%d">`+"\n", idx, hunk)
+		hunk++
+		for k := 0; k < len(del) || k < len(ins); k++ {
+			switch {
+			case k < len(del) && k < len(ins):
+				left, right := diffx.HighlightText(lineText(f.A[del[k]]), lineText(f.B[ins[k]]))
+				writeRow(w, "del", del[k]+1, segments(left, "del"), "ins", ins[k]+1, segments(right, "ins"))
+			case k < len(del):
+				writeRow(w, "del", del[k]+1, html.EscapeString(lineText(f.A[del[k]])), "empty", 0, "")
+			default:
+				writeRow(w, "empty", 0, "", "ins", ins[k]+1, html.EscapeString(lineText(f.B[ins[k]])))
+			}
+		}
+		w.WriteString("</tbody>\n")
+	}
+
+	w.WriteString("</table>\n</section>\n")
+}
+
+// writeEqual writes an unchanged region, collapsing all but context lines
+// next to changes. first and last report whether the region starts or ends
+// the file, where no change needs context.
+func writeEqual(w *bufio.Writer, f File, op diffx.DiffOp, context int, first, last bool) {
+	n := op.AEnd - op.AStart
+	head, tail := context, context
+	if first {
+		head = 0
+	}
+	if last {
+		tail = 0
+	}
+	if head+tail >= n {
+		head, tail = n, 0
+	}
+
+	row := func(k int) {
+		writeRow(w, "", op.AStart+k+1, html.EscapeString(lineText(f.A[op.AStart+k])),
+			"", op.BStart+k+1, html.EscapeString(lineText(f.B[op.BStart+k])))
+	}
+
+	w.WriteString("<tbody>\n")
+	for k := 0; k < head; k++ {
+		row(k)
+	}
+	w.WriteString("</tbody>\n")
+	if hidden := n - head - tail; hidden > 0 {
+		fmt.Fprintf(w, `<tbody class="toggle"><tr><td colspan="4"><button type="button">%d unchanged lines</button></td></tr></tbody>`+"\n", hidden)
+		w.WriteString(`<tbody class="fold" hidden>` + "\n")
+		for k := head; k < n-tail; k++ {
+			row(k)
+		}
+		w.WriteString("</tbody>\n<tbody>\n")
+		for k := n - tail; k < n; k++ {
+			row(k)
+		}
+		w.WriteString("</tbody>\n")
+	}
+}
+
+// writeRow writes one table row. A line number of 0 leaves its cell empty.
+// The text arguments are HTML.
+func writeRow(w *bufio.Writer, classA string, lineA int, textA string, classB string, lineB int, textB string) {
+	num := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return fmt.Sprint(n)
+	}
+	fmt.Fprintf(w, `<tr><td class="ln">%s</td><td class="%s">%s</td><td class="ln">%s</td><td class="%s">%s</td></tr>`+"\n",
+		num(lineA), classA, textA, num(lineB), classB, textB)
+}
+
+// segments renders highlighted segments as HTML, marking changed ones with
+// the given element.
+func segments(segs []diffx.Segment, tag string) string {
+	var sb strings.Builder
+	for _, s := range segs {
+		text := html.EscapeString(s.Text)
+		if s.Changed && s.Text != "" {
+			sb.WriteString("<" + tag + ">" + text + "</" + tag + ">")
+		} else {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+// lineText returns line without its terminator.
+func lineText(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
+
+// pageHeader and pageFooter enclose the report. pageHeader takes the title
+// twice, for the <title> and the heading.
+const (
+	pageHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { margin: 0; font-family: sans-serif; }
+main { padding: 3em 1em 1em; }
+nav { position: fixed; top: 0; left: 0; right: 0; padding: 0.4em 1em; background: #f6f8fa; border-bottom: 1px solid #d0d7de; }
+nav button { margin-right: 0.5em; }
+.index { font-family: monospace; }
+.index .ins { color: #1a7f37; }
+.index .del { color: #cf222e; }
+.file h2 { font-size: 1em; font-family: monospace; background: #f6f8fa; border: 1px solid #d0d7de; padding: 0.4em; margin: 1.5em 0 0; }
+table.sbs { border-collapse: collapse; width: 100%%; table-layout: fixed; font-family: monospace; border: 1px solid #d0d7de; }
+table.sbs col.ln { width: 4em; }
+table.sbs td { padding: 0 0.5em; white-space: pre-wrap; word-break: break-all; vertical-align: top; }
+table.sbs td.ln { color: #6e7781; text-align: right; user-select: none; }
+td.del { background: #ffebe9; }
+td.ins { background: #dafbe1; }
+td.empty { background: #f6f8fa; }
+td.del del { background: #ff8182; text-decoration: none; }
+td.ins ins { background: #4ac26b; text-decoration: none; }
+tbody.toggle td { background: #ddf4ff; text-align: center; }
+tbody.toggle button { border: none; background: none; color: #0550ae; cursor: pointer; font: inherit; }
+tbody.hunk.current { outline: 2px solid #0969da; }
+</style>
+</head>
+<body>
+<nav><button type="button" id="prev">&larr; Previous hunk</button><button type="button" id="next">Next hunk &rarr;</button><span id="position"></span>
+<button type="button" id="expand">Expand all</button></nav>
+<main>
+<h1>%s</h1>
+`
+	pageFooter = `</main>
+<script>
+(function () {
+  var hunks = document.querySelectorAll("tbody.hunk");
+  var current = -1;
+  var position = document.getElementById("position");
+  function show(i) {
+    if (hunks.length === 0) return;
+    if (current >= 0) hunks[current].classList.remove("current");
+    current = (i + hunks.length) % hunks.length;
+    hunks[current].classList.add("current");
+    hunks[current].scrollIntoView({block: "center"});
+    position.textContent = "Hunk " + (current + 1) + " of " + hunks.length;
+  }
+  position.textContent = hunks.length + " hunks";
+  document.getElementById("next").onclick = function () { show(current + 1); };
+  document.getElementById("prev").onclick = function () { show(current - 1); };
+  document.addEventListener("keydown", function (e) {
+    if (e.target.tagName === "INPUT" || e.ctrlKey || e.metaKey || e.altKey) return;
+    if (e.key === "n" || e.key === "j") show(current + 1);
+    if (e.key === "p" || e.key === "k") show(current - 1);
+  });
+  document.querySelectorAll("tbody.toggle button").forEach(function (b) {
+    b.onclick = function () {
+      var fold = b.closest("tbody").nextElementSibling;
+      fold.hidden = !fold.hidden;
+    };
+  });
+  document.getElementById("expand").onclick = function () {
+    document.querySelectorAll("tbody.fold").forEach(function (f) { f.hidden = false; });
+  };
+})();
+</script>
+</body>
+</html>
+`
+)
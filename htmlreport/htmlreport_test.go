@@ -0,0 +1,159 @@
+package htmlreport
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dacharyc/diffx"
+)
+
+func report(t *testing.T, files []File, opts ...Option) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := Write(&sb, files, opts...); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func newFile(name string, a, b []string) File {
+	return File{NameA: name, NameB: name, A: a, B: b, Ops: diffx.Diff(a, b)}
+}
+
+func TestWrite_Document(t *testing.T) {
+	a := []string{"one\n", "two\n", "three\n"}
+	b := []string{"one\n", "too\n", "three\n"}
+	out := report(t, []File{newFile("a.txt", a, b)}, WithTitle("CI <run>"))
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>CI &lt;run&gt;</title>",
+		`<a href="#file-0">a.txt</a> <span class="ins">+1</span> <span class="del">-1</span>`,
+		`<section class="file" id="file-0">`,
+		`<tbody class="hunk" id="file-0-hunk-0">`,
+		"<script>",
+		"</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	if strings.Contains(out, "<script src") || strings.Contains(out, `<link rel="stylesheet"`) {
+		t.Error("report is not self-contained")
+	}
+}
+
+func TestWrite_IntraLineHighlight(t *testing.T) {
+	a := []string{"the quick brown fox"}
+	b := []string{"the quick red fox"}
+	out := report(t, []File{newFile("f", a, b)})
+
+	if !strings.Contains(out, "<del>brown</del>") || !strings.Contains(out, "<ins>red</ins>") {
+		t.Errorf("changed words are not highlighted:\n%s", out)
+	}
+	if strings.Contains(out, "<del>the") {
+		t.Error("unchanged words are highlighted")
+	}
+}
+
+func TestWrite_UnpairedLines(t *testing.T) {
+	a := []string{"keep", "gone"}
+	b := []string{"keep", "new", "extra"}
+	out := report(t, []File{newFile("f", a, b)})
+
+	for _, want := range []string{
+		`<td class="ln">2</td><td class="del"><del>gone</del></td><td class="ln">2</td><td class="ins"><ins>new</ins></td>`,
+		`<td class="ln"></td><td class="empty"></td><td class="ln">3</td><td class="ins">extra</td>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks row %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWrite_CollapsesUnchanged(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		a = append(a, fmt.Sprintf("line %d", i))
+	}
+	b = append(b, a...)
+	b[10] = "changed"
+
+	out := report(t, []File{newFile("f", a, b)}, WithContext(2))
+
+	// 8 lines before the change and 7 after are hidden, 2 of each shown
+	for _, want := range []string{
+		`<button type="button">8 unchanged lines</button>`,
+		`<button type="button">7 unchanged lines</button>`,
+		`<tbody class="fold" hidden>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+
+	// Every line is still in the page
+	for _, line := range a {
+		if !strings.Contains(out, ">"+line+"<") {
+			t.Errorf("report lacks %q", line)
+		}
+	}
+}
+
+func TestWrite_ShortEqualNotCollapsed(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "b", "x", "d"}
+	out := report(t, []File{newFile("f", a, b)})
+	if strings.Contains(out, "unchanged lines") {
+		t.Error("short unchanged regions are collapsed")
+	}
+}
+
+func TestWrite_MultipleFiles(t *testing.T) {
+	files := []File{
+		newFile("one", []string{"a"}, []string{"b"}),
+		{NameA: "old", NameB: "new", A: []string{"x", "y"}, B: []string{"x", "z"}},
+	}
+	files[1].Ops = diffx.Diff(files[1].A, files[1].B)
+	out := report(t, files)
+
+	for _, want := range []string{
+		`id="file-0-hunk-0"`,
+		`id="file-1-hunk-0"`,
+		"old → new",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+}
+
+func TestLineText(t *testing.T) {
+	tests := map[string]string{
+		"a\n":   "a",
+		"a\r\n": "a",
+		"a":     "a",
+		"":      "",
+	}
+	for in, want := range tests {
+		if got := lineText(in); got != want {
+			t.Errorf("lineText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWrite_Binary(t *testing.T) {
+	out := report(t, []File{{NameA: "a.png", NameB: "b.png", Binary: true}})
+	for _, want := range []string{
+		`<a href="#file-0">a.png → b.png</a> binary`,
+		"<p>Binary files differ</p>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q", want)
+		}
+	}
+	if strings.Contains(out, `class="hunk"`) {
+		t.Error("binary file has hunks")
+	}
+}
@@ -0,0 +1,314 @@
+package diffx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Whitespace-, case-, and line-ending-insensitive comparison.
+//
+// Like diff -i, -w, -b, -Z, -E, and --strip-trailing-cr, these options make Diff and DiffHistogram
+// compare normalized keys instead of the strings themselves, so that
+// reindented or recapitalized lines are kept as unchanged. The keys have
+// the same length as the input, so the returned operations still index
+// into the original slices. Only equality uses the keys: boundary shifting
+// and the indent heuristic score positions by the strings as written.
+// DiffElements and DiffElementsHistogram are not affected: Element
+// implementations define their own equality.
+
+// ignoreOptions configures how strings are normalized before comparison.
+type ignoreOptions struct {
+	caseInsensitive bool
+	allSpace        bool
+	spaceChange     bool
+	trailingSpace   bool
+	eol             bool
+	tabWidth        int
+	references      bool
+	markup          bool
+	placeholders    bool
+	punctuation     bool
+	logs            bool
+	maxLineLen      int // see WithMaxLineLen
+}
+
+// defaultIgnoreOptions returns options that compare strings exactly.
+func defaultIgnoreOptions() *ignoreOptions {
+	return &ignoreOptions{maxLineLen: defaultMaxLineLen}
+}
+
+// WithIgnoreCase treats strings that differ only in case as equal.
+// Default: false.
+func WithIgnoreCase(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.caseInsensitive = enabled
+	}
+}
+
+// WithIgnoreAllSpace ignores all white space when comparing strings, so
+// "a b" equals "ab".
+// Default: false.
+func WithIgnoreAllSpace(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.allSpace = enabled
+	}
+}
+
+// WithIgnoreSpaceChange ignores changes in the amount of white space:
+// runs of white space compare equal to a single space, and trailing white
+// space is ignored. Unlike WithIgnoreAllSpace, "a b" does not equal "ab".
+// Default: false.
+func WithIgnoreSpaceChange(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.spaceChange = enabled
+	}
+}
+
+// WithIgnoreTrailingSpace ignores white space at the end of each string,
+// before its final line feed, like diff -Z, so that trailing spaces added
+// or removed by an editor are not a change. Leading and inner white space
+// still count, and a carriage return before the line feed counts as
+// trailing white space.
+// Default: false.
+func WithIgnoreTrailingSpace(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.trailingSpace = enabled
+	}
+}
+
+// WithNormalizeEOL treats CRLF, LF, and CR line endings as equal, so that
+// a file checked out with Windows line endings does not differ on every
+// line. LineEndingChanges counts the lines that differ only this way, for
+// reporting a summary such as "line endings changed".
+// Default: false.
+func WithNormalizeEOL(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.eol = enabled
+	}
+}
+
+// WithExpandTabs compares strings with tabs expanded to spaces at tab stops
+// every width columns, so that indentation converted between tabs and
+// spaces is not a change, like diff -E. A width of 0 or less compares tabs
+// as they are. To display tabs consistently instead, render lines with
+// ExpandTabs or ShowInvisibles.
+// Default: 0.
+func WithExpandTabs(width int) Option {
+	return func(o *options) {
+		o.ignoreOpts.tabWidth = width
+	}
+}
+
+// elements converts strs to Elements for a diff. When the options
+// normalize strings, each element is compared by its normalized key but
+// keeps the original string, so that postprocessing places change
+// boundaries by the text as written; see keyedElement. Keys longer than
+// maxLineLen get a sampled hash, as longElements do. Without
+// normalization, strings longer than maxLineLen become longElements.
+func (ig *ignoreOptions) elements(strs []string) []Element {
+	if !ig.normalizes() {
+		return ig.keys(strs)
+	}
+	keys := ig.keyStrings(strs)
+	elems := make([]Element, len(strs))
+	for i, s := range strs {
+		e := keyedElement{key: keys[i], text: s}
+		if ig.maxLineLen > 0 && len(e.key) > ig.maxLineLen {
+			e.hash = hashSampled(e.key)
+		} else {
+			e.hash = hashString(e.key)
+		}
+		elems[i] = e
+	}
+	return elems
+}
+
+// keys converts strs to Elements of their normalized form, which are equal,
+// also as map keys, exactly when the strings are equal under the options.
+// Strings whose normalized form is longer than maxLineLen become
+// longElements.
+func (ig *ignoreOptions) keys(strs []string) []Element {
+	elems := make([]Element, len(strs))
+	for i, s := range ig.keyStrings(strs) {
+		if ig.maxLineLen > 0 && len(s) > ig.maxLineLen {
+			elems[i] = newLongElement(s)
+		} else {
+			elems[i] = StringElement(s)
+		}
+	}
+	return elems
+}
+
+// keyStrings returns the normalized form of each string in strs.
+func (ig *ignoreOptions) keyStrings(strs []string) []string {
+	if ig.references {
+		strs = normalizeReferences(strs)
+	}
+	if !ig.normalizesLines() {
+		return strs
+	}
+	keys := make([]string, len(strs))
+	for i, s := range strs {
+		keys[i] = ig.key(s)
+	}
+	return keys
+}
+
+// normalizes reports whether the options compare strings by a normalized
+// form rather than exactly.
+func (ig *ignoreOptions) normalizes() bool {
+	return ig.references || ig.normalizesLines()
+}
+
+// normalizesLines reports whether key can change a string.
+func (ig *ignoreOptions) normalizesLines() bool {
+	return ig.caseInsensitive || ig.allSpace || ig.spaceChange || ig.trailingSpace || ig.eol || ig.tabWidth > 0 || ig.markup || ig.placeholders || ig.punctuation || ig.logs
+}
+
+// keyedElement is a string compared by its normalized key, with the hash of
+// the key computed once. Heuristics that look at the layout of lines, such
+// as blank line detection and the indent heuristic, see the original text
+// through elementText.
+type keyedElement struct {
+	key, text string
+	hash      uint64
+}
+
+// Equal reports whether e and other have the same key.
+func (e keyedElement) Equal(other Element) bool {
+	o, ok := other.(keyedElement)
+	return ok && e.hash == o.hash && e.key == o.key
+}
+
+// Hash returns the hash of the key.
+func (e keyedElement) Hash() uint64 {
+	return e.hash
+}
+
+// String returns the original text, for annotations.
+func (e keyedElement) String() string {
+	return e.text
+}
+
+// key returns the normalized form of s.
+func (ig *ignoreOptions) key(s string) string {
+	if ig.eol {
+		s = normalizeEOL(s)
+	}
+	if ig.tabWidth > 0 {
+		s = ExpandTabs(s, ig.tabWidth)
+	}
+	if ig.logs {
+		s = MaskLogLine(s)
+	}
+	if ig.placeholders {
+		s = normalizePlaceholders(s)
+	}
+	if ig.punctuation {
+		s = normalizePunctuation(s)
+	}
+	if ig.markup {
+		s = stripMarkup(s)
+	}
+	switch {
+	case ig.allSpace:
+		s = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, s)
+	case ig.spaceChange:
+		s = collapseSpace(s)
+	case ig.trailingSpace:
+		s = trimTrailingSpace(s)
+	}
+	if ig.caseInsensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// normalizeEOL replaces CRLF and CR line endings in s with LF.
+func normalizeEOL(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// LineEndingChanges returns the number of lines that ops keeps as unchanged
+// although they differ in a and b, and differ only in their line endings.
+// It is nonzero only for edit scripts computed with WithNormalizeEOL.
+func LineEndingChanges(ops []DiffOp, a, b []string) int {
+	n := 0
+	for _, op := range ops {
+		if op.Type != Equal {
+			continue
+		}
+		for i, j := op.AStart, op.BStart; i < op.AEnd; i, j = i+1, j+1 {
+			if a[i] != b[j] && normalizeEOL(a[i]) == normalizeEOL(b[j]) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// ExpandTabs returns s with each tab replaced by the spaces that reach the
+// next tab stop, with stops every width columns. Columns count runes and
+// restart after each line feed. A width of 0 or less returns s unchanged.
+func ExpandTabs(s string, width int) string {
+	if width <= 0 || !strings.Contains(s, "\t") {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s) + 4*width)
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			n := width - col%width
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+		case '\n':
+			sb.WriteRune(r)
+			col = 0
+		default:
+			sb.WriteRune(r)
+			col++
+		}
+	}
+	return sb.String()
+}
+
+// trimTrailingSpace removes white space at the end of s, keeping a final
+// line feed.
+func trimTrailingSpace(s string) string {
+	body, eol := strings.CutSuffix(s, "\n")
+	body = strings.TrimRightFunc(body, unicode.IsSpace)
+	if eol {
+		return body + "\n"
+	}
+	return body
+}
+
+// collapseSpace replaces each run of white space in s with a single space
+// and removes trailing white space.
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
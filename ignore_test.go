@@ -0,0 +1,117 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIgnoreOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		opts    []Option
+		changed bool
+	}{
+		{"exact", "Hello  world", "hello world", nil, true},
+		{"case", "Hello World", "hello world", []Option{WithIgnoreCase(true)}, false},
+		{"all space", "a b\tc", "abc", []Option{WithIgnoreAllSpace(true)}, false},
+		{"space change", "a  b\t", "a b", []Option{WithIgnoreSpaceChange(true)}, false},
+		{"space change keeps word breaks", "a b", "ab", []Option{WithIgnoreSpaceChange(true)}, true},
+		{"space change keeps leading space", "  a", "a", []Option{WithIgnoreSpaceChange(true)}, true},
+		{"combined", "Hello  World", "hello world", []Option{WithIgnoreCase(true), WithIgnoreSpaceChange(true)}, false},
+		{"trailing space", "a b \t\n", "a b\n", []Option{WithIgnoreTrailingSpace(true)}, false},
+		{"trailing space without newline", "a b  ", "a b", []Option{WithIgnoreTrailingSpace(true)}, false},
+		{"trailing space keeps inner space", "a  b", "a b", []Option{WithIgnoreTrailingSpace(true)}, true},
+		{"trailing space keeps leading space", "  a\n", "a\n", []Option{WithIgnoreTrailingSpace(true)}, true},
+		{"trailing space keeps missing newline", "a \n", "a", []Option{WithIgnoreTrailingSpace(true)}, true},
+		{"crlf", "line\r\n", "line\n", nil, true},
+		{"normalize crlf", "line\r\n", "line\n", []Option{WithNormalizeEOL(true)}, false},
+		{"normalize cr", "line\r", "line\n", []Option{WithNormalizeEOL(true)}, false},
+		{"tabs", "\tx", "    x", nil, true},
+		{"expand tabs", "\tx", "    x", []Option{WithExpandTabs(4)}, false},
+		{"expand tabs to stop", "ab\tx", "ab  x", []Option{WithExpandTabs(4)}, false},
+		{"expand tabs keeps width", "\tx", "  x", []Option{WithExpandTabs(4)}, true},
+		{"normalize keeps missing newline", "line", "line\r\n", []Option{WithNormalizeEOL(true)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := []string{"same", tt.a, "end"}
+			b := []string{"same", tt.b, "end"}
+			for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+				ops := diff(a, b, tt.opts...)
+				if got := countChangeRegions(ops) > 0; got != tt.changed {
+					t.Errorf("changed = %v, want %v (ops %v)", got, tt.changed, ops)
+				}
+			}
+		})
+	}
+}
+
+func TestIgnoreOptions_IndexOriginal(t *testing.T) {
+	a := []string{"  x", "y"}
+	b := []string{"x", "Y", "z"}
+
+	ops := Diff(a, b, WithIgnoreAllSpace(true), WithIgnoreCase(true))
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3},
+	}
+	if len(ops) != len(want) {
+		t.Fatalf("Diff() = %v, want %v", ops, want)
+	}
+	for i := range ops {
+		if ops[i] != want[i] {
+			t.Errorf("op %d = %v, want %v", i, ops[i], want[i])
+		}
+	}
+}
+
+func TestIgnoreOptions_IndentHeuristic(t *testing.T) {
+	// The indent heuristic places the inserted block by the indentation as
+	// written, even when white space is ignored for comparison
+	a := []string{"server:", "    port: 80", "", "  debug: true"}
+	b := []string{"server:", "    port: 80", "server:", "    port: 80", "", "  debug: true"}
+	want := Diff(a, b, WithIndentHeuristic(true))
+
+	for _, opt := range []Option{WithIgnoreAllSpace(true), WithIgnoreSpaceChange(true), WithIgnoreCase(true)} {
+		for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+			if got := diff(a, b, WithIndentHeuristic(true), opt); !reflect.DeepEqual(got, want) {
+				t.Errorf("ops = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestLineEndingChanges(t *testing.T) {
+	a := []string{"one\r\n", "two\r\n", "three\n", "four\r\n"}
+	b := []string{"one\n", "two\n", "three\n", "4\n"}
+
+	ops := Diff(a, b, WithNormalizeEOL(true))
+	if got := LineEndingChanges(ops, a, b); got != 2 {
+		t.Errorf("LineEndingChanges() = %d, want 2 (ops %v)", got, ops)
+	}
+	if got := LineEndingChanges(Diff(a, b), a, b); got != 0 {
+		t.Errorf("without WithNormalizeEOL: LineEndingChanges() = %d, want 0", got)
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"\tx", 4, "    x"},
+		{"ab\tc\td", 4, "ab  c   d"},
+		{"abcd\te", 4, "abcd    e"},
+		{"a\n\tb", 2, "a\n  b"},
+		{"é\tx", 4, "é   x"},
+		{"\tx", 0, "\tx"},
+		{"no tabs", 8, "no tabs"},
+	}
+	for _, tt := range tests {
+		if got := ExpandTabs(tt.s, tt.width); got != tt.want {
+			t.Errorf("ExpandTabs(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package diffx
+
+// Indent heuristic for boundary sliding.
+//
+// This follows the approach of Git's indent heuristic (xdiff/xdiffi.c,
+// introduced in Git 2.11 and enabled by default since 2.14). When a change
+// region can slide up or down because the elements at its edges repeat,
+// each candidate position is scored by looking at the indentation and blank
+// lines around the two split points it would create. Positions that split at
+// shallow indentation and just before blank-line-separated blocks win, which
+// keeps whole functions and blocks together in code diffs.
+
+// Indent heuristic weights. These are Git's tuned values; lower scores
+// indicate better split positions.
+const (
+	startOfFilePenalty              = 1
+	endOfFilePenalty                = 21
+	totalBlankWeight                = -30
+	postBlankWeight                 = 6
+	relativeIndentPenalty           = -4
+	relativeIndentWithBlankPenalty  = 10
+	relativeOutdentPenalty          = 24
+	relativeOutdentWithBlankPenalty = 17
+	relativeDedentPenalty           = 23
+	relativeDedentWithBlankPenalty  = 17
+	indentWeight                    = 60
+
+	// indentMaxSliding limits how many positions are scored for one region.
+	indentMaxSliding = 100
+	// maxIndent caps the measured indentation of a line.
+	maxIndent = 200
+	// maxBlanks caps how many blank lines are scanned around a split.
+	maxBlanks = 20
+)
+
+// splitMeasurement describes the surroundings of a split point located
+// just before elems[split].
+type splitMeasurement struct {
+	endOfFile  bool // the split is at the end of the sequence
+	indent     int  // indent of the line after the split, -1 if blank
+	preBlank   int  // blank lines immediately before the split
+	preIndent  int  // indent of the first non-blank line before the split, -1 if none
+	postBlank  int  // blank lines following the line after the split
+	postIndent int  // indent of the next non-blank line after that, -1 if none
+}
+
+// splitScore accumulates the score for one candidate slide position.
+type splitScore struct {
+	effectiveIndent int
+	penalty         int
+}
+
+// elementIndent returns the indentation width of e, expanding tabs to
+// 8-column stops. It returns -1 for blank elements. Non-string elements
+// are treated as unindented content.
+func elementIndent(e Element) int {
+	s, ok := elementText(e)
+	if !ok {
+		return 0
+	}
+
+	indent := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 8 - indent%8
+		case '\n', '\r', '\f', '\v':
+			// Ignore other whitespace
+		default:
+			return indent
+		}
+		if indent >= maxIndent {
+			return maxIndent
+		}
+	}
+
+	// The element consists only of whitespace
+	return -1
+}
+
+// measureSplit measures the context of a split just before elems[split].
+func measureSplit(elems []Element, split int) splitMeasurement {
+	var m splitMeasurement
+
+	if split >= len(elems) {
+		m.endOfFile = true
+		m.indent = -1
+	} else {
+		m.indent = elementIndent(elems[split])
+	}
+
+	m.preIndent = -1
+	for i := split - 1; i >= 0; i-- {
+		m.preIndent = elementIndent(elems[i])
+		if m.preIndent != -1 {
+			break
+		}
+		m.preBlank++
+		if m.preBlank == maxBlanks {
+			m.preIndent = 0
+			break
+		}
+	}
+
+	m.postIndent = -1
+	for i := split + 1; i < len(elems); i++ {
+		m.postIndent = elementIndent(elems[i])
+		if m.postIndent != -1 {
+			break
+		}
+		m.postBlank++
+		if m.postBlank == maxBlanks {
+			m.postIndent = 0
+			break
+		}
+	}
+
+	return m
+}
+
+// add folds a split measurement into the score.
+func (s *splitScore) add(m splitMeasurement) {
+	if m.preIndent == -1 && m.preBlank == 0 {
+		s.penalty += startOfFilePenalty
+	}
+	if m.endOfFile {
+		s.penalty += endOfFilePenalty
+	}
+
+	// Blank lines following the split count as if they were before it
+	postBlank := 0
+	if m.indent == -1 {
+		postBlank = 1 + m.postBlank
+	}
+	totalBlank := m.preBlank + postBlank
+
+	s.penalty += totalBlankWeight * totalBlank
+	s.penalty += postBlankWeight * postBlank
+
+	indent := m.indent
+	if indent == -1 {
+		indent = m.postIndent
+	}
+	anyBlanks := totalBlank != 0
+
+	s.effectiveIndent += indent
+
+	switch {
+	case indent == -1, m.preIndent == -1:
+		// No adjustment when either side has no content
+	case indent > m.preIndent:
+		// The line after the split is indented more than the line before it
+		if anyBlanks {
+			s.penalty += relativeIndentWithBlankPenalty
+		} else {
+			s.penalty += relativeIndentPenalty
+		}
+	case indent == m.preIndent:
+		// Same indentation: no adjustment
+	case m.postIndent != -1 && m.postIndent > indent:
+		// Outdented, but the block continues at a deeper level afterwards
+		if anyBlanks {
+			s.penalty += relativeOutdentWithBlankPenalty
+		} else {
+			s.penalty += relativeOutdentPenalty
+		}
+	default:
+		// Dedented: the split ends a block
+		if anyBlanks {
+			s.penalty += relativeDedentWithBlankPenalty
+		} else {
+			s.penalty += relativeDedentPenalty
+		}
+	}
+}
+
+// compare returns a negative value if s is a better split than other,
+// positive if it is worse, and zero if they are equivalent.
+func (s splitScore) compare(other splitScore) int {
+	cmpIndents := 0
+	if s.effectiveIndent > other.effectiveIndent {
+		cmpIndents = 1
+	} else if s.effectiveIndent < other.effectiveIndent {
+		cmpIndents = -1
+	}
+	return indentWeight*cmpIndents + (s.penalty - other.penalty)
+}
+
+// scoreIndentSlide scores placing a change region at elems[start:end].
+func scoreIndentSlide(start, end int, elems []Element) splitScore {
+	var s splitScore
+	s.add(measureSplit(elems, start))
+	s.add(measureSplit(elems, end))
+	return s
+}
+
+// slideIndentHeuristic slides every pure Delete or pure Insert region that
+// sits between Equal regions, or at either end of the script, to the
+// position the indent heuristic prefers. Neighboring Equal regions are
+// adjusted so the script stays consistent.
+// Each slide is reported to trace, if not nil.
+func slideIndentHeuristic(ops []DiffOp, a, b []Element, trace func(TraceEvent)) []DiffOp {
+	if len(ops) < 2 {
+		return ops
+	}
+
+	result := padWithEqual(ops)
+
+	for i, op := range result {
+		if op.Type == Equal {
+			continue
+		}
+		// Only regions bounded by Equal ops (or the sequence edges) can slide
+		if i > 0 && result[i-1].Type != Equal {
+			continue
+		}
+		if i+1 < len(result) && result[i+1].Type != Equal {
+			continue
+		}
+
+		elems, start, end := a, op.AStart, op.AEnd
+		if op.Type == Insert {
+			elems, start, end = b, op.BStart, op.BEnd
+		}
+		if end == start {
+			continue
+		}
+
+		prevLen, nextLen := 0, 0
+		if i > 0 {
+			prevLen = result[i-1].AEnd - result[i-1].AStart
+		}
+		if i+1 < len(result) {
+			nextLen = result[i+1].AEnd - result[i+1].AStart
+		}
+
+		// Determine how far the region can slide in each direction
+		up := 0
+		for up < prevLen && elems[start-up-1].Equal(elems[end-up-1]) {
+			up++
+		}
+		down := 0
+		for down < nextLen && elems[start+down].Equal(elems[end+down]) {
+			down++
+		}
+		if up == 0 && down == 0 {
+			continue
+		}
+
+		// Only consider the lowest positions when the range is very long
+		lowest := -up
+		if down-lowest > indentMaxSliding {
+			lowest = down - indentMaxSliding
+		}
+
+		// Score every candidate; ties prefer the lower position, like Git
+		bestShift := lowest
+		bestScore := scoreIndentSlide(start+lowest, end+lowest, elems)
+		for shift := lowest + 1; shift <= down; shift++ {
+			score := scoreIndentSlide(start+shift, end+shift, elems)
+			if score.compare(bestScore) <= 0 {
+				bestScore = score
+				bestShift = shift
+			}
+		}
+
+		if bestShift != 0 {
+			slideRun(result, i, i+1, bestShift)
+			traceShift(trace, result, i, i+1, bestShift)
+		}
+	}
+
+	return mergeAdjacentOps(dropEmptyOps(result))
+}
+
+// slideRun moves the change region ops[i:j] by shift positions, growing and
+// shrinking the surrounding Equal regions accordingly.
+func slideRun(ops []DiffOp, i, j, shift int) {
+	for k := i; k < j; k++ {
+		ops[k].AStart += shift
+		ops[k].AEnd += shift
+		ops[k].BStart += shift
+		ops[k].BEnd += shift
+	}
+	if i > 0 {
+		ops[i-1].AEnd += shift
+		ops[i-1].BEnd += shift
+	}
+	if j < len(ops) {
+		ops[j].AStart += shift
+		ops[j].BStart += shift
+	}
+}
+
+// padWithEqual returns a copy of ops with empty Equal operations added at
+// the start and end when the script begins or ends with a change. Change
+// regions at the edges can then slide by growing those Equal operations;
+// dropEmptyOps removes any that stay empty.
+func padWithEqual(ops []DiffOp) []DiffOp {
+	result := make([]DiffOp, 0, len(ops)+2)
+	if len(ops) == 0 {
+		return result
+	}
+
+	if first := ops[0]; first.Type != Equal {
+		result = append(result, DiffOp{Type: Equal, AStart: first.AStart, AEnd: first.AStart, BStart: first.BStart, BEnd: first.BStart})
+	}
+	result = append(result, ops...)
+	if last := ops[len(ops)-1]; last.Type != Equal {
+		result = append(result, DiffOp{Type: Equal, AStart: last.AEnd, AEnd: last.AEnd, BStart: last.BEnd, BEnd: last.BEnd})
+	}
+	return result
+}
+
+// dropEmptyOps removes operations that cover no elements in either sequence.
+func dropEmptyOps(ops []DiffOp) []DiffOp {
+	result := ops[:0]
+	for _, op := range ops {
+		if op.AEnd > op.AStart || op.BEnd > op.BStart {
+			result = append(result, op)
+		}
+	}
+	return result
+}
+
+// WithIndentHeuristic enables Git's indent heuristic for boundary sliding.
+// Change regions that can slide are placed so that they split at lines with
+// less indentation and before blank-line-separated blocks, which produces
+// more readable line-level diffs of source code. Requires postprocessing.
+// Default: false.
+func WithIndentHeuristic(enabled bool) Option {
+	return func(o *options) {
+		o.indentHeuristic = enabled
+	}
+}
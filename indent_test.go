@@ -0,0 +1,228 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestElementIndent(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"code", 0},
+		{"  code", 2},
+		{"\tcode", 8},
+		{"  \tcode", 8},
+		{"\t  code", 10},
+		{"", -1},
+		{"   ", -1},
+		{"\t\n", -1},
+	}
+
+	for _, tt := range tests {
+		if got := elementIndent(StringElement(tt.input)); got != tt.want {
+			t.Errorf("elementIndent(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMeasureSplit(t *testing.T) {
+	elems := toElements([]string{"a {", "    b", "", "", "c"})
+
+	m := measureSplit(elems, 4)
+	if m.indent != 0 || m.preBlank != 2 || m.preIndent != 4 || m.endOfFile {
+		t.Errorf("measureSplit(4) = %+v", m)
+	}
+
+	m = measureSplit(elems, 5)
+	if !m.endOfFile || m.indent != -1 {
+		t.Errorf("measureSplit(5) = %+v, want end of file", m)
+	}
+
+	m = measureSplit(elems, 0)
+	if m.preIndent != -1 || m.preBlank != 0 {
+		t.Errorf("measureSplit(0) = %+v, want start of file", m)
+	}
+}
+
+func TestSplitScore_PrefersSplitAfterBlankLine(t *testing.T) {
+	elems := toElements([]string{"x", "y", "", "z"})
+
+	midBlock := scoreIndentSlide(1, 1, elems)
+	afterBlank := scoreIndentSlide(3, 3, elems)
+
+	if afterBlank.compare(midBlock) >= 0 {
+		t.Errorf("expected split after blank line to win: %+v vs %+v", afterBlank, midBlock)
+	}
+}
+
+func TestSlideIndentHeuristic_InsertedFunction(t *testing.T) {
+	a := []string{
+		"func a() {",
+		"    x",
+		"}",
+		"",
+		"func c() {",
+		"    z",
+		"}",
+	}
+	b := []string{
+		"func a() {",
+		"    x",
+		"}",
+		"",
+		"func b() {",
+		"    y",
+		"}",
+		"",
+		"func c() {",
+		"    z",
+		"}",
+	}
+
+	// The insertion is ambiguous: it can be placed anywhere between
+	// "}" + "" + "func b() {" ... and "func b() {" ... + "}" + "".
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 6},
+		{Type: Equal, AStart: 2, AEnd: 7, BStart: 6, BEnd: 11},
+	}
+
+	got := slideIndentHeuristic(ops, toElements(a), toElements(b), nil)
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 4, BStart: 0, BEnd: 4},
+		{Type: Insert, AStart: 4, AEnd: 4, BStart: 4, BEnd: 8},
+		{Type: Equal, AStart: 4, AEnd: 7, BStart: 8, BEnd: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slideIndentHeuristic() = %v, want %v", got, want)
+	}
+}
+
+func TestSlideIndentHeuristic_NotSlidable(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 1, BEnd: 2},
+	}
+	a := toElements([]string{"a", "b", "c"})
+	b := toElements([]string{"a", "c"})
+
+	got := slideIndentHeuristic(ops, a, b, nil)
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("expected ops unchanged, got %v", got)
+	}
+}
+
+func TestWithIndentHeuristic(t *testing.T) {
+	a := []string{
+		"if x {",
+		"    one()",
+		"}",
+		"",
+		"if y {",
+		"    two()",
+		"}",
+	}
+	b := []string{
+		"if x {",
+		"    one()",
+		"}",
+		"",
+		"if z {",
+		"    three()",
+		"}",
+		"",
+		"if y {",
+		"    two()",
+		"}",
+	}
+
+	ops := Diff(a, b, WithPreprocessing(false), WithIndentHeuristic(true))
+	if got := applyDiff(a, b, ops); !reflect.DeepEqual(got, b) {
+		t.Fatalf("applying diff = %v, want %v", got, b)
+	}
+
+	var inserted []string
+	for _, op := range ops {
+		if op.Type == Insert {
+			inserted = append(inserted, b[op.BStart:op.BEnd]...)
+		}
+	}
+	want := []string{"if z {", "    three()", "}", ""}
+	if !reflect.DeepEqual(inserted, want) {
+		t.Errorf("inserted = %q, want %q", inserted, want)
+	}
+}
+
+func TestSlideIndentHeuristic_RegionAtStart(t *testing.T) {
+	// The leading insertion can slide down past the repeated blank line
+	a := toElements([]string{"", "x"})
+	b := toElements([]string{"", "", "x"})
+	ops := []DiffOp{
+		{Type: Insert, AStart: 0, AEnd: 0, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 1, BEnd: 3},
+	}
+
+	got := slideIndentHeuristic(ops, a, b, nil)
+	aPos, bPos := 0, 0
+	for _, op := range got {
+		if op.AStart != aPos || op.BStart != bPos {
+			t.Fatalf("op %v does not continue from (%d, %d): %v", op, aPos, bPos, got)
+		}
+		aPos, bPos = op.AEnd, op.BEnd
+	}
+	if aPos != len(a) || bPos != len(b) {
+		t.Errorf("script ends at (%d, %d), want (%d, %d): %v", aPos, bPos, len(a), len(b), got)
+	}
+}
+
+func TestSlideIndentHeuristic_EdgeRegions(t *testing.T) {
+	// Regions at either end of the script slide by growing an Equal region
+	// on the open side
+	tests := []struct {
+		name string
+		a, b []string
+		ops  []DiffOp
+		want []DiffOp
+	}{
+		{
+			name: "insert at the start",
+			a:    []string{"", "x"},
+			b:    []string{"", "", "x"},
+			ops: []DiffOp{
+				{Type: Insert, AStart: 0, AEnd: 0, BStart: 0, BEnd: 1},
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 1, BEnd: 3},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Insert, AStart: 1, AEnd: 1, BStart: 1, BEnd: 2},
+				{Type: Equal, AStart: 1, AEnd: 2, BStart: 2, BEnd: 3},
+			},
+		},
+		{
+			name: "delete at the end",
+			a:    []string{"f {", "  a", "}", "  b", "}"},
+			b:    []string{"f {", "  a", "}"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+				{Type: Delete, AStart: 3, AEnd: 5, BStart: 3, BEnd: 3},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+				{Type: Equal, AStart: 4, AEnd: 5, BStart: 2, BEnd: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slideIndentHeuristic(tt.ops, toElements(tt.a), toElements(tt.b), nil)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("slideIndentHeuristic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package diffx
+
+// Alignment inspection.
+//
+// Two decisions shape an alignment more than any other: which elements
+// preprocessing hides from the Myers algorithm, and which anchors the
+// histogram algorithm splits on. Inspect reports both, so that tools can
+// visualize them and explain why a diff aligned the way it did.
+
+// ElementClass is the preprocessing classification of an element.
+type ElementClass int
+
+const (
+	// ClassKeep means the element occurs in both sequences, rarely enough
+	// to be a useful anchor.
+	ClassKeep ElementClass = iota
+	// ClassDiscard means the element does not occur in the other sequence,
+	// so it is certainly changed.
+	ClassDiscard
+	// ClassProvisional means the element occurs too often to be a useful
+	// anchor. It is hidden unless it borders a ClassKeep element.
+	ClassProvisional
+)
+
+// String returns a string representation of the ElementClass.
+func (c ElementClass) String() string {
+	switch c {
+	case ClassKeep:
+		return "Keep"
+	case ClassDiscard:
+		return "Discard"
+	case ClassProvisional:
+		return "Provisional"
+	default:
+		return "Unknown"
+	}
+}
+
+// Anchor is a matched run chosen by the histogram algorithm to split the
+// sequences around.
+type Anchor struct {
+	A, B      int // start of the run in A and in B
+	Len       int // length of the run
+	Frequency int // occurrences of the anchor element in the region of A searched
+}
+
+// Inspection reports the alignment decisions made for two sequences.
+type Inspection struct {
+	// ClassA and ClassB hold the preprocessing class of each element.
+	ClassA, ClassB []ElementClass
+
+	// Filtered reports whether preprocessing hides elements from the Myers
+	// algorithm. It is false when preprocessing is disabled, and when so
+	// many elements are ClassKeep that filtering would not help.
+	Filtered bool
+
+	// HiddenA and HiddenB report, for each element, whether preprocessing
+	// hides it. Hidden elements are always reported as changed or
+	// interleaved with the alignment of their neighbors.
+	HiddenA, HiddenB []bool
+
+	// Anchors lists the anchors chosen by DiffElementsHistogram, in the
+	// order it chose them.
+	Anchors []Anchor
+}
+
+// Inspect reports how DiffElements and DiffElementsHistogram, called with
+// opts, would treat a and b: the preprocessing class of every element,
+// which elements preprocessing hides, and the histogram anchors.
+func Inspect(a, b []Element, opts ...Option) *Inspection {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	in := &Inspection{
+		ClassA:  make([]ElementClass, len(a)),
+		ClassB:  make([]ElementClass, len(b)),
+		HiddenA: make([]bool, len(a)),
+		HiddenB: make([]bool, len(b)),
+	}
+
+	aClass, bClass := classifyElements(a, b)
+	for i, c := range aClass {
+		in.ClassA[i] = ElementClass(c)
+	}
+	for i, c := range bClass {
+		in.ClassB[i] = ElementClass(c)
+	}
+
+	if o.preprocessing {
+		if _, _, mapping := filterConfusingElements(a, b); mapping != nil {
+			in.Filtered = true
+			markHidden(in.HiddenA, mapping.aToOrig)
+			markHidden(in.HiddenB, mapping.bToOrig)
+		}
+	}
+
+	trace := o.trace
+	recordAnchor := func(e TraceEvent) {
+		if e.Kind == TraceAnchor {
+			in.Anchors = append(in.Anchors, Anchor{A: e.X, B: e.Y, Len: e.Len, Frequency: e.Frequency})
+		}
+		if trace != nil {
+			trace(e)
+		}
+	}
+	DiffElementsHistogram(a, b, append(opts[:len(opts):len(opts)], WithTrace(recordAnchor))...)
+
+	return in
+}
+
+// markHidden sets hidden[i] for every index not listed in kept, which is
+// sorted.
+func markHidden(hidden []bool, kept []int) {
+	k := 0
+	for i := range hidden {
+		if k < len(kept) && kept[k] == i {
+			k++
+			continue
+		}
+		hidden[i] = true
+	}
+}
@@ -0,0 +1,105 @@
+package diffx
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestElementClass_String(t *testing.T) {
+	tests := []struct {
+		c    ElementClass
+		want string
+	}{
+		{ClassKeep, "Keep"},
+		{ClassDiscard, "Discard"},
+		{ClassProvisional, "Provisional"},
+		{ElementClass(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("ElementClass(%d).String() = %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestInspect_Classes(t *testing.T) {
+	a := toElements([]string{"a", "b", "c"})
+	b := toElements([]string{"a", "x", "c"})
+	in := Inspect(a, b)
+
+	if want := []ElementClass{ClassKeep, ClassDiscard, ClassKeep}; !reflect.DeepEqual(in.ClassA, want) {
+		t.Errorf("ClassA = %v, want %v", in.ClassA, want)
+	}
+	if want := []ElementClass{ClassKeep, ClassDiscard, ClassKeep}; !reflect.DeepEqual(in.ClassB, want) {
+		t.Errorf("ClassB = %v, want %v", in.ClassB, want)
+	}
+}
+
+func TestInspect_Hidden(t *testing.T) {
+	// Mostly unique lines: preprocessing hides the lines found on one
+	// side only, and the frequent "}" where it borders none of the shared
+	// lines
+	var sa, sb []string
+	for i := 0; i < 50; i++ {
+		sa = append(sa, "}", fmt.Sprintf("k%d", i), fmt.Sprintf("a%d", i))
+		sb = append(sb, "}", fmt.Sprintf("k%d", i), fmt.Sprintf("b%d", i))
+	}
+	a, b := toElements(sa), toElements(sb)
+
+	in := Inspect(a, b)
+	if !in.Filtered {
+		t.Fatal("Filtered = false, want true")
+	}
+	if in.ClassA[0] != ClassProvisional || in.ClassA[1] != ClassKeep || in.ClassA[2] != ClassDiscard {
+		t.Errorf("ClassA[:3] = %v, want [Provisional Keep Discard]", in.ClassA[:3])
+	}
+	if in.HiddenA[0] || in.HiddenA[1] || !in.HiddenA[2] {
+		t.Errorf("HiddenA[:3] = %v, want [false false true]", in.HiddenA[:3])
+	}
+
+	// The hidden elements are exactly those the statistics count
+	var s Stats
+	DiffElements(a, b, WithStats(&s))
+	if n := countTrue(in.HiddenA...); n != s.FilteredA {
+		t.Errorf("%d hidden in A, Stats.FilteredA = %d", n, s.FilteredA)
+	}
+
+	in = Inspect(a, b, WithPreprocessing(false))
+	if in.Filtered || countTrue(in.HiddenA...)+countTrue(in.HiddenB...) != 0 {
+		t.Error("elements hidden with preprocessing disabled")
+	}
+}
+
+func TestInspect_NotWorthFiltering(t *testing.T) {
+	a := toElements([]string{"a", "b", "c", "d", "e", "f", "g", "h"})
+	b := toElements([]string{"a", "b", "c", "d", "e", "f", "g", "x"})
+	in := Inspect(a, b)
+	if in.Filtered {
+		t.Error("Filtered = true for inputs that are mostly kept")
+	}
+}
+
+func TestInspect_Anchors(t *testing.T) {
+	a := toElements([]string{"a", "b", "anchor", "c", "d"})
+	b := toElements([]string{"x", "y", "anchor", "z", "w"})
+
+	var traced int
+	in := Inspect(a, b, WithTrace(func(TraceEvent) { traced++ }))
+
+	want := []Anchor{{A: 2, B: 2, Len: 1, Frequency: 1}}
+	if !reflect.DeepEqual(in.Anchors, want) {
+		t.Errorf("Anchors = %+v, want %+v", in.Anchors, want)
+	}
+	if traced == 0 {
+		t.Error("caller's trace function was not called")
+	}
+}
+
+func TestMarkHidden(t *testing.T) {
+	hidden := make([]bool, 5)
+	markHidden(hidden, []int{0, 2, 3})
+	if want := []bool{false, true, false, false, true}; !reflect.DeepEqual(hidden, want) {
+		t.Errorf("markHidden = %v, want %v", hidden, want)
+	}
+}
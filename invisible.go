@@ -0,0 +1,66 @@
+package diffx
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Invisible character rendering.
+//
+// The most confusing diffs are the ones where both sides look identical:
+// a line that gained a trailing space, a CRLF line ending, a tab that
+// replaced spaces, or a no-break space pasted from a web page. Rendering
+// those characters as visible markers makes the difference explainable from
+// the output alone.
+
+// ShowInvisibles returns s with invisible characters replaced by visible
+// markers, for display only:
+//
+//   - a tab becomes "→"
+//   - a carriage return becomes "^M", and other control characters use the
+//     same caret notation, such as "^[" for escape and "^?" for delete
+//   - a space at the end of the line becomes "·"
+//   - other space and format characters, such as a no-break space or a
+//     zero-width space, become their code point, such as "<U+00A0>"
+//
+// Line feeds are kept as they are, so a line stays one line, and a space
+// counts as trailing when only white space follows it up to the next line
+// feed or the end of s.
+func ShowInvisibles(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + len(s)/4)
+	for _, line := range strings.SplitAfter(s, "\n") {
+		showLineInvisibles(&sb, line)
+	}
+	return sb.String()
+}
+
+// showLineInvisibles writes line, which contains at most a final "\n",
+// to sb with invisible characters replaced.
+func showLineInvisibles(sb *strings.Builder, line string) {
+	body, eol := strings.CutSuffix(line, "\n")
+	end := len(strings.TrimRight(body, " \t\r"))
+	for i, r := range body {
+		switch {
+		case r == '\t':
+			sb.WriteString("→")
+		case r == ' ' && i >= end:
+			sb.WriteString("·")
+		case r == ' ':
+			sb.WriteByte(' ')
+		case r < 0x20:
+			sb.WriteByte('^')
+			sb.WriteByte(byte(r) + '@')
+		case r == 0x7F:
+			sb.WriteString("^?")
+		case unicode.Is(unicode.Cf, r) || unicode.IsSpace(r):
+			fmt.Fprintf(sb, "<U+%04X>", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if eol {
+		sb.WriteByte('\n')
+	}
+}
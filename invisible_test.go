@@ -0,0 +1,29 @@
+package diffx
+
+import "testing"
+
+func TestShowInvisibles(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"plain", "a b\n", "a b\n"},
+		{"trailing spaces", "a b  \n", "a b··\n"},
+		{"crlf", "line\r\n", "line^M\n"},
+		{"trailing space before cr", "x \r\n", "x·^M\n"},
+		{"tabs", "\tx\ty\t", "→x→y→"},
+		{"controls", "a\x1b[0m\x7f\x00", "a^[[0m^?^@"},
+		{"no-break and zero-width space", "a\u00a0b\u200bc\ufeff", "a<U+00A0>b<U+200B>c<U+FEFF>"},
+		{"several lines", "a \nb\n  c \n", "a·\nb\n  c·\n"},
+		{"whitespace token", "  \n  ", "··\n··"},
+		{"unicode text", "café ✓", "café ✓"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShowInvisibles(tt.s); got != tt.want {
+				t.Errorf("ShowInvisibles(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package jsondiff compares JSON documents using diffx sequence alignment.
+//
+// Arrays of objects, such as lists of records exported from an API, are
+// aligned by a caller-specified identity field rather than by whole-value
+// equality. A record whose fields changed is then reported as modified
+// instead of as a removal and an unrelated addition, and a record that only
+// moved is reported as moved, not as changed.
+//
+// Arbitrary documents can be compared structurally with Diff, which
+// produces JSON Patch (RFC 6902) operations.
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+
+	"github.com/dacharyc/diffx"
+)
+
+// ChangeType identifies how a record changed. It is an alias of
+// diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the record is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the record is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the record is present in both with different fields.
+	Modified = diffx.ChangeModified
+	// Moved means the record is present in both with identical fields, but
+	// its position relative to the other records changed.
+	Moved = diffx.ChangeMoved
+)
+
+// Change describes a record that differs between two arrays.
+type Change struct {
+	Type ChangeType
+	Key  string // identity value, as JSON text
+	A    int    // index in array A, or -1 if added
+	B    int    // index in array B, or -1 if removed
+}
+
+// record is a diffx.Element that compares by identity value only.
+type record struct {
+	key   string
+	value map[string]any
+}
+
+// Equal reports whether other is a record with the same identity.
+func (r record) Equal(other diffx.Element) bool {
+	o, ok := other.(record)
+	return ok && r.key == o.key
+}
+
+// Hash returns a FNV-1a hash of the identity value.
+func (r record) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(r.key))
+	return h.Sum64()
+}
+
+// DiffArrays compares two JSON arrays of objects, matching objects by the
+// value of their key field. Unchanged records are not reported. Records
+// are aligned with diffx.DiffElements, and opts are passed to it; a record
+// that falls outside the alignment but is present in both arrays is
+// reported as Moved, or as Modified if its fields also changed.
+//
+// It returns an error if either input is not an array of objects, or if an
+// object lacks the key field or repeats another object's key.
+func DiffArrays(a, b []byte, key string, opts ...diffx.Option) ([]Change, error) {
+	va, err := decodeArray(a)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: array A: %w", err)
+	}
+	vb, err := decodeArray(b)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: array B: %w", err)
+	}
+	return DiffValues(va, vb, key, opts...)
+}
+
+// DiffValues is like DiffArrays but takes arrays that have already been
+// decoded with encoding/json.
+func DiffValues(a, b []any, key string, opts ...diffx.Option) ([]Change, error) {
+	ra, err := toRecords(a, key)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: array A: %w", err)
+	}
+	rb, err := toRecords(b, key)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: array B: %w", err)
+	}
+
+	ea := make([]diffx.Element, len(ra))
+	for i, r := range ra {
+		ea[i] = r
+	}
+	eb := make([]diffx.Element, len(rb))
+	for i, r := range rb {
+		eb[i] = r
+	}
+	ops := diffx.DiffElements(ea, eb, opts...)
+
+	// Records deleted and inserted elsewhere were reordered
+	deleted := make(map[string]bool)
+	inserted := make(map[string]int)
+	for _, op := range ops {
+		switch op.Type {
+		case diffx.Delete:
+			for i := op.AStart; i < op.AEnd; i++ {
+				deleted[ra[i].key] = true
+			}
+		case diffx.Insert:
+			for j := op.BStart; j < op.BEnd; j++ {
+				inserted[rb[j].key] = j
+			}
+		}
+	}
+
+	var changes []Change
+	for _, op := range ops {
+		switch op.Type {
+		case diffx.Equal:
+			for k := 0; k < op.AEnd-op.AStart; k++ {
+				i, j := op.AStart+k, op.BStart+k
+				if !reflect.DeepEqual(ra[i].value, rb[j].value) {
+					changes = append(changes, Change{Type: Modified, Key: ra[i].key, A: i, B: j})
+				}
+			}
+		case diffx.Delete:
+			for i := op.AStart; i < op.AEnd; i++ {
+				j, ok := inserted[ra[i].key]
+				if !ok {
+					changes = append(changes, Change{Type: Removed, Key: ra[i].key, A: i, B: -1})
+					continue
+				}
+				typ := Moved
+				if !reflect.DeepEqual(ra[i].value, rb[j].value) {
+					typ = Modified
+				}
+				changes = append(changes, Change{Type: typ, Key: ra[i].key, A: i, B: j})
+			}
+		case diffx.Insert:
+			for j := op.BStart; j < op.BEnd; j++ {
+				if !deleted[rb[j].key] {
+					changes = append(changes, Change{Type: Added, Key: rb[j].key, A: -1, B: j})
+				}
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// decodeArray decodes data as a JSON array, keeping numbers exact.
+func decodeArray(data []byte) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v []any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// toRecords converts decoded array members to records keyed by field.
+func toRecords(values []any, field string) ([]record, error) {
+	records := make([]record, len(values))
+	seen := make(map[string]int, len(values))
+	for i, v := range values {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("element %d is not an object", i)
+		}
+		id, ok := obj[field]
+		if !ok {
+			return nil, fmt.Errorf("element %d has no %q field", i, field)
+		}
+		key, err := json.Marshal(id)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		if prev, dup := seen[string(key)]; dup {
+			return nil, fmt.Errorf("elements %d and %d share %s %s", prev, i, field, key)
+		}
+		seen[string(key)] = i
+		records[i] = record{key: string(key), value: obj}
+	}
+	return records, nil
+}
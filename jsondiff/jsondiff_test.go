@@ -0,0 +1,84 @@
+package jsondiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffArrays(t *testing.T) {
+	a := `[
+		{"id": 1, "name": "alpha"},
+		{"id": 2, "name": "beta"},
+		{"id": 3, "name": "gamma"}
+	]`
+	b := `[
+		{"name": "alpha", "id": 1},
+		{"id": 2, "name": "BETA"},
+		{"id": 4, "name": "delta"}
+	]`
+
+	got, err := DiffArrays([]byte(a), []byte(b), "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Type: Modified, Key: "2", A: 1, B: 1},
+		{Type: Removed, Key: "3", A: 2, B: -1},
+		{Type: Added, Key: "4", A: -1, B: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffArrays() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffArrays_Reordered(t *testing.T) {
+	a := `[{"id": "a", "v": 1}, {"id": "b", "v": 2}, {"id": "c", "v": 3}]`
+	b := `[{"id": "c", "v": 3}, {"id": "a", "v": 1}, {"id": "b", "v": 2}]`
+
+	got, err := DiffArrays([]byte(a), []byte(b), "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 change, got %+v", got)
+	}
+	if got[0].Type != Moved || got[0].Key != `"c"` {
+		t.Errorf("got %+v, want record \"c\" moved", got[0])
+	}
+}
+
+func TestDiffArrays_Identical(t *testing.T) {
+	a := `[{"id": 1, "tags": ["x", "y"]}]`
+	got, err := DiffArrays([]byte(a), []byte(a), "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no changes, got %+v", got)
+	}
+}
+
+func TestDiffArrays_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		want string
+	}{
+		{"not an array", `{"id": 1}`, "array A"},
+		{"not objects", `[1, 2]`, "not an object"},
+		{"missing key", `[{"name": "x"}]`, `no "id" field`},
+		{"duplicate key", `[{"id": 1}, {"id": 1}]`, "share id 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DiffArrays([]byte(tt.a), []byte(`[]`), "id")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// Structural diff.
+//
+// Diff compares two arbitrary JSON values and describes the difference as
+// a JSON Patch (RFC 6902): a list of add, remove, and replace operations
+// addressed by JSON Pointer (RFC 6901). Object members are compared by
+// name. Array members are aligned with diffx so that an insertion in the
+// middle of an array produces one add rather than a replace of every
+// following member.
+
+// Operation is a single JSON Patch operation.
+type Operation struct {
+	Op    string // "add", "remove", or "replace"
+	Path  string // JSON Pointer to the target location
+	Value any    // new value; unused for "remove"
+}
+
+// MarshalJSON encodes the operation in RFC 6902 form. The value member is
+// omitted for "remove" and kept, even when null, for other operations.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{o.Op, o.Path, o.Value})
+}
+
+// Diff compares two JSON documents and returns the patch operations that
+// transform a into b, in the order they must be applied. opts are passed
+// to diffx.DiffElements when aligning arrays.
+func Diff(a, b []byte, opts ...diffx.Option) ([]Operation, error) {
+	va, err := decodeValue(a)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: document A: %w", err)
+	}
+	vb, err := decodeValue(b)
+	if err != nil {
+		return nil, fmt.Errorf("jsondiff: document B: %w", err)
+	}
+	return DiffValue(va, vb, opts...), nil
+}
+
+// DiffValue is like Diff but takes values that have already been decoded
+// with encoding/json.
+func DiffValue(a, b any, opts ...diffx.Option) []Operation {
+	return diffValue(nil, "", a, b, opts)
+}
+
+// diffValue appends the operations that transform a into b at path.
+func diffValue(patch []Operation, path string, a, b any, opts []diffx.Option) []Operation {
+	switch va := a.(type) {
+	case map[string]any:
+		if vb, ok := b.(map[string]any); ok {
+			return diffObject(patch, path, va, vb, opts)
+		}
+	case []any:
+		if vb, ok := b.([]any); ok {
+			return diffArray(patch, path, va, vb, opts)
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return patch
+	}
+	return append(patch, Operation{Op: "replace", Path: path, Value: b})
+}
+
+// diffObject compares object members by name, in sorted order.
+func diffObject(patch []Operation, path string, a, b map[string]any, opts []diffx.Option) []Operation {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := path + "/" + escapePointer(name)
+		va, inA := a[name]
+		vb, inB := b[name]
+		switch {
+		case !inB:
+			patch = append(patch, Operation{Op: "remove", Path: p})
+		case !inA:
+			patch = append(patch, Operation{Op: "add", Path: p, Value: vb})
+		default:
+			patch = diffValue(patch, p, va, vb, opts)
+		}
+	}
+	return patch
+}
+
+// diffArray aligns array members with diffx. Within each change region,
+// members are compared pairwise by position, and any surplus is removed or
+// added. Indices account for the operations already applied.
+func diffArray(patch []Operation, path string, a, b []any, opts []diffx.Option) []Operation {
+	ea, eb := toValues(a), toValues(b)
+	ops := diffx.DiffElements(ea, eb, opts...)
+
+	for k := 0; k < len(ops); {
+		if ops[k].Type == diffx.Equal {
+			k++
+			continue
+		}
+
+		// Gather the change region
+		aStart, bStart := ops[k].AStart, ops[k].BStart
+		aEnd, bEnd := ops[k].AEnd, ops[k].BEnd
+		for k < len(ops) && ops[k].Type != diffx.Equal {
+			aEnd = max(aEnd, ops[k].AEnd)
+			bEnd = max(bEnd, ops[k].BEnd)
+			k++
+		}
+
+		dels, ins := aEnd-aStart, bEnd-bStart
+		common := min(dels, ins)
+		for n := 0; n < common; n++ {
+			patch = diffValue(patch, path+"/"+strconv.Itoa(bStart+n), a[aStart+n], b[bStart+n], opts)
+		}
+		for n := common; n < dels; n++ {
+			patch = append(patch, Operation{Op: "remove", Path: path + "/" + strconv.Itoa(bStart+common)})
+		}
+		for n := common; n < ins; n++ {
+			patch = append(patch, Operation{Op: "add", Path: path + "/" + strconv.Itoa(bStart+n), Value: b[bStart+n]})
+		}
+	}
+	return patch
+}
+
+// value is a diffx.Element comparing JSON values by canonical encoding.
+type value string
+
+// Equal reports whether other encodes the same JSON value.
+func (v value) Equal(other diffx.Element) bool {
+	o, ok := other.(value)
+	return ok && v == o
+}
+
+// Hash returns a FNV-1a hash of the canonical encoding.
+func (v value) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(v))
+	return h.Sum64()
+}
+
+// toValues converts array members to elements. encoding/json sorts object
+// keys, so equal values have equal encodings.
+func toValues(members []any) []diffx.Element {
+	elems := make([]diffx.Element, len(members))
+	for i, m := range members {
+		data, err := json.Marshal(m)
+		if err != nil {
+			// Decoded JSON always re-encodes; fall back to a unique value
+			data = []byte(fmt.Sprintf("\x00%d", i))
+		}
+		elems[i] = value(data)
+	}
+	return elems
+}
+
+// escapePointer escapes a member name for use in a JSON Pointer.
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// decodeValue decodes data as a single JSON value, keeping numbers exact.
+func decodeValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,135 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := `{"name": "svc", "port": 80, "tags": ["a", "b", "c"], "old": true}`
+	b := `{"name": "svc", "port": 8080, "tags": ["a", "x", "b", "c"], "new": null}`
+
+	got, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"op":"add","path":"/new","value":null},` +
+		`{"op":"remove","path":"/old"},` +
+		`{"op":"replace","path":"/port","value":8080},` +
+		`{"op":"add","path":"/tags/1","value":"x"}]`
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestDiff_Nested(t *testing.T) {
+	a := `{"items": [{"id": 1, "v": "a"}, {"id": 2, "v": "b"}]}`
+	b := `{"items": [{"id": 1, "v": "a"}, {"id": 2, "v": "B"}]}`
+
+	got, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Operation{{Op: "replace", Path: "/items/1/v", Value: "B"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiff_EscapesPointer(t *testing.T) {
+	got, err := Diff([]byte(`{}`), []byte(`{"a/b~c": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Path != "/a~1b~0c" {
+		t.Errorf("Diff() = %+v, want path /a~1b~0c", got)
+	}
+}
+
+func TestDiff_AppliesCleanly(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{`[1, 2, 3, 4, 5]`, `[1, 3, 4, 6, 7, 8]`},
+		{`[1, 2, 3]`, `[]`},
+		{`[]`, `["x", "y"]`},
+		{`{"a": [1, {"b": [true, false]}]}`, `{"a": [0, 1, {"b": [false]}], "c": "d"}`},
+		{`"scalar"`, `{"now": "object"}`},
+	}
+
+	for _, tt := range tests {
+		patch, err := Diff([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, _ := decodeValue([]byte(tt.a))
+		for _, op := range patch {
+			doc = applyOperation(t, doc, op)
+		}
+
+		want, _ := decodeValue([]byte(tt.b))
+		if !reflect.DeepEqual(doc, want) {
+			t.Errorf("applying Diff(%s, %s) = %v, want %v (patch %+v)", tt.a, tt.b, doc, want, patch)
+		}
+	}
+}
+
+// applyOperation applies a single patch operation to doc. It supports
+// only the pointers Diff produces.
+func applyOperation(t *testing.T, doc any, op Operation) any {
+	t.Helper()
+	if op.Path == "" {
+		return op.Value
+	}
+
+	tokens := strings.Split(op.Path[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return applyAt(t, doc, tokens, op)
+}
+
+func applyAt(t *testing.T, node any, tokens []string, op Operation) any {
+	t.Helper()
+	last := len(tokens) == 1
+	switch n := node.(type) {
+	case map[string]any:
+		if !last {
+			n[tokens[0]] = applyAt(t, n[tokens[0]], tokens[1:], op)
+			return n
+		}
+		if op.Op == "remove" {
+			delete(n, tokens[0])
+		} else {
+			n[tokens[0]] = op.Value
+		}
+		return n
+	case []any:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil {
+			t.Fatalf("bad array index in %q", op.Path)
+		}
+		if !last {
+			n[i] = applyAt(t, n[i], tokens[1:], op)
+			return n
+		}
+		switch op.Op {
+		case "add":
+			n = append(n[:i], append([]any{op.Value}, n[i:]...)...)
+		case "remove":
+			n = append(n[:i], n[i+1:]...)
+		case "replace":
+			n[i] = op.Value
+		}
+		return n
+	}
+	t.Fatalf("cannot apply %+v", op)
+	return nil
+}
@@ -0,0 +1,322 @@
+// Package kvdiff compares key=value configuration files, such as Java
+// .properties and .env files, by key.
+//
+// A line diff of two such files reports a reordered key as a removal and
+// an addition, and an edited comment as a change, although neither affects
+// the configuration. kvdiff parses both files in the syntax of their
+// format, with comments, quoting, escapes, and continuation lines, and
+// reports the keys that were added or removed and the values that changed.
+package kvdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// Format identifies the syntax of a key=value file.
+type Format int
+
+const (
+	// Env is the .env syntax read by dotenv libraries and Docker Compose:
+	// KEY=value lines with an optional "export" prefix, "#" comments,
+	// single-quoted literal values, and double-quoted values with
+	// backslash escapes, which may span lines.
+	Env Format = iota
+	// Properties is the Java .properties syntax: "key=value", "key:
+	// value", or "key value", "#" and "!" comments, backslash escapes
+	// including \uXXXX, and lines continued with a trailing backslash.
+	Properties
+)
+
+// String returns a string representation of the Format.
+func (f Format) String() string {
+	switch f {
+	case Env:
+		return "Env"
+	case Properties:
+		return "Properties"
+	default:
+		return "Unknown"
+	}
+}
+
+// ChangeType identifies how a key changed. It is an alias of
+// diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the key is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the key is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the key has a different value in B.
+	Modified = diffx.ChangeModified
+)
+
+// Entry is a key and its value as parsed from a file.
+type Entry struct {
+	Key   string
+	Value string // with quotes removed and escapes decoded
+	Line  int    // 1-based line where the entry starts
+}
+
+// Change describes a key whose value differs between two files.
+type Change struct {
+	Type         ChangeType
+	Key          string
+	A, B         string // value in each file; empty if absent
+	LineA, LineB int    // 1-based line of the key in each file, or 0 if absent
+}
+
+// String returns a one-line description such as "~ PORT: 80 -> 8080".
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Key, c.B)
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Key, c.A)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Key, c.A, c.B)
+	}
+}
+
+// Diff parses two files in format and returns the keys whose values
+// differ, sorted by key. When a file sets a key more than once, the last
+// value counts, as it does for the programs that read these files.
+func Diff(a, b []byte, format Format) ([]Change, error) {
+	ea, err := Parse(a, format)
+	if err != nil {
+		return nil, fmt.Errorf("kvdiff: file A: %w", err)
+	}
+	eb, err := Parse(b, format)
+	if err != nil {
+		return nil, fmt.Errorf("kvdiff: file B: %w", err)
+	}
+	return DiffEntries(ea, eb), nil
+}
+
+// DiffEntries is like Diff but takes entries that have already been
+// parsed.
+func DiffEntries(a, b []Entry) []Change {
+	ma, mb := entryMap(a), entryMap(b)
+	var changes []Change
+	for _, mc := range diffx.DiffMaps(ma, mb, func(x, y Entry) bool { return x.Value == y.Value }) {
+		c := Change{Type: Modified, Key: mc.Key, A: mc.A.Value, B: mc.B.Value, LineA: mc.A.Line, LineB: mc.B.Line}
+		switch {
+		case mc.Added():
+			c.Type = Added
+		case mc.Removed():
+			c.Type = Removed
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// entryMap indexes entries by key, keeping the last entry for each key.
+func entryMap(entries []Entry) map[string]Entry {
+	m := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		m[e.Key] = e
+	}
+	return m
+}
+
+// Parse returns the entries of a file in format, in file order.
+// Properties files always parse; an Env file fails to parse if a line
+// has no "=" or a quoted value is not closed.
+func Parse(data []byte, format Format) ([]Entry, error) {
+	text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\r", "\n")
+	switch format {
+	case Env:
+		return parseEnv(text)
+	case Properties:
+		return parseProperties(text), nil
+	default:
+		return nil, fmt.Errorf("unknown format %d", int(format))
+	}
+}
+
+// parseEnv parses .env syntax.
+func parseEnv(text string) ([]Entry, error) {
+	var entries []Entry
+	line := 1
+	for len(text) > 0 {
+		var raw string
+		raw, text, _ = strings.Cut(text, "\n")
+		start := line
+		line++
+
+		s := strings.TrimSpace(raw)
+		if s == "" || s[0] == '#' {
+			continue
+		}
+		s = strings.TrimPrefix(s, "export ")
+		key, value, ok := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", start)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			// A quoted value may continue on the following lines
+			quote := value[0]
+			rest := value[1:] + "\n" + text
+			end := closingQuote(rest, quote)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated %c quote", start, quote)
+			}
+			value = rest[:end]
+			if quote == '"' {
+				value = unescapeEnv(value)
+			}
+			consumed := strings.Count(rest[:end], "\n")
+			line += consumed
+			if consumed > 0 {
+				// Drop the lines the value spans, and the rest of its last line
+				_, after, _ := strings.Cut(rest[end:], "\n")
+				text = after
+			}
+		} else {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimRight(value, " \t")
+		}
+		entries = append(entries, Entry{Key: key, Value: value, Line: start})
+	}
+	return entries, nil
+}
+
+// closingQuote returns the index of the quote that closes a value in s,
+// skipping backslash-escaped double quotes, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeEnv decodes the backslash escapes of a double-quoted .env value.
+func unescapeEnv(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// parseProperties parses Java .properties syntax.
+func parseProperties(text string) []Entry {
+	lines := strings.Split(text, "\n")
+	var entries []Entry
+	for n := 0; n < len(lines); n++ {
+		start := n + 1
+		s := strings.TrimLeft(lines[n], " \t\f")
+		if s == "" || s[0] == '#' || s[0] == '!' {
+			continue
+		}
+		// Join continuation lines, dropping their leading white space
+		for continued(s) && n+1 < len(lines) {
+			n++
+			s = s[:len(s)-1] + strings.TrimLeft(lines[n], " \t\f")
+		}
+		if continued(s) {
+			s = s[:len(s)-1]
+		}
+
+		// The key ends at the first unescaped '=', ':', or white space
+		end := len(s)
+		for i := 0; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '=' || s[i] == ':' || s[i] == ' ' || s[i] == '\t' || s[i] == '\f' {
+				end = i
+				break
+			}
+		}
+		key := s[:end]
+		value := strings.TrimLeft(s[end:], " \t\f")
+		if value != "" && (value[0] == '=' || value[0] == ':') {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+		entries = append(entries, Entry{Key: unescapeProperties(key), Value: unescapeProperties(value), Line: start})
+	}
+	return entries
+}
+
+// continued reports whether a .properties line ends with an odd number of
+// backslashes, which continues it on the next line.
+func continued(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// unescapeProperties decodes the backslash escapes of .properties keys and
+// values.
+func unescapeProperties(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 <= len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteByte('u')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package kvdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func changeStrings(changes []Change) string {
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiff_Env(t *testing.T) {
+	a := `# Service configuration
+PORT=80
+HOST=localhost
+export DEBUG=false
+GREETING="Hello,\nworld"
+`
+	// Reordered, comments changed, quoting changed, and real changes
+	b := `DEBUG=false   # off in production
+HOST='localhost'
+
+# Network
+PORT=8080
+GREETING="Hello,
+world"
+CERT="-----BEGIN-----
+abc
+-----END-----"
+`
+	changes, err := Diff([]byte(a), []byte(b), Env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "+ CERT: -----BEGIN-----\nabc\n-----END-----\n~ PORT: 80 -> 8080"
+	if got := changeStrings(changes); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if changes[1].LineA != 2 || changes[1].LineB != 5 {
+		t.Errorf("PORT lines = %d, %d, want 2, 5", changes[1].LineA, changes[1].LineB)
+	}
+	if changes[0].LineB != 8 {
+		t.Errorf("CERT line = %d, want 8", changes[0].LineB)
+	}
+}
+
+func TestDiff_Properties(t *testing.T) {
+	a := `# Application
+app.name = Demo
+app.greeting=Hello \
+    world
+! legacy
+db.url: jdbc:h2:mem
+path\ with\ spaces value
+`
+	b := "db.url=jdbc:h2:mem\r\napp.name Demo\r\napp.greeting = Hello world\r\nunicode = caf\\u00e9\r\n"
+
+	changes, err := Diff([]byte(a), []byte(b), Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- path with spaces: value\n+ unicode: café"
+	if got := changeStrings(changes); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParse_Env(t *testing.T) {
+	entries, err := Parse([]byte("A=1\nB=x # note\nC='a # b '\nD=\"q\\\"t\"\nA=2\n"), Env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Key: "A", Value: "1", Line: 1},
+		{Key: "B", Value: "x", Line: 2},
+		{Key: "C", Value: "a # b ", Line: 3},
+		{Key: "D", Value: `q"t`, Line: 4},
+		{Key: "A", Value: "2", Line: 5},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Parse() = %+v\nwant %+v", entries, want)
+	}
+
+	// The last value of a repeated key counts
+	changes, err := Diff([]byte("A=1\nA=2\n"), []byte("A=2\n"), Env)
+	if err != nil || changes != nil {
+		t.Errorf("Diff() = %v, %v, want no changes", changes, err)
+	}
+}
+
+func TestDiff_Invalid(t *testing.T) {
+	tests := []string{"NOEQUALS\n", "A=\"unterminated\n", "=value\n"}
+	for _, in := range tests {
+		if _, err := Diff([]byte(in), nil, Env); err == nil || !strings.HasPrefix(err.Error(), "kvdiff: file A: line 1:") {
+			t.Errorf("Diff(%q) error = %v", in, err)
+		}
+	}
+	if _, err := Parse(nil, Format(9)); err == nil {
+		t.Error("unknown format: expected error")
+	}
+}
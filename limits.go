@@ -0,0 +1,59 @@
+package diffx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Size limits.
+//
+// Element indices, diagonals of the edit graph, and the lengths of the
+// diagonal vectors are ints, so on a 32-bit platform a diff of a few
+// hundred million elements is close to overflowing them. The diff
+// functions accept inputs up to MaxElements elements in total on every
+// platform, so that a diff that works on a 64-bit server also works on a
+// 32-bit device, and compute the quantities that grow faster than n+m,
+// such as the products of the lengths, in 64 or 128 bits.
+//
+// Memory is usually the practical limit long before MaxElements: the
+// Myers search allocates two diagonal vectors of n+m+4 ints and a change
+// mark per element, about 17 bytes per element on a 64-bit platform,
+// besides the elements themselves. Estimate reports the memory a diff
+// needs before running it.
+
+// MaxElements is the largest total length, len(a)+len(b), of the
+// sequences the diff functions accept. Diff, DiffElements, and
+// DiffHistogram panic with an error wrapping ErrTooLarge for longer
+// inputs; DiffE and DiffElementsE return it.
+const MaxElements = math.MaxInt32 - 4
+
+// ErrTooLarge is reported for inputs of more than MaxElements elements.
+var ErrTooLarge = errors.New("diffx: input too large")
+
+// checkSize returns an error wrapping ErrTooLarge if sequences of n and m
+// elements are too long to diff together.
+func checkSize(n, m int) error {
+	if n > MaxElements || m > MaxElements-n {
+		return fmt.Errorf("%w: %d + %d elements, more than %d", ErrTooLarge, n, m, MaxElements)
+	}
+	return nil
+}
+
+// score128 is an unsigned 128-bit number, for products of lengths that
+// overflow int64.
+type score128 struct {
+	hi, lo uint64
+}
+
+// mul128 returns x*y.
+func mul128(x, y uint64) score128 {
+	hi, lo := bits.Mul64(x, y)
+	return score128{hi, lo}
+}
+
+// less reports whether s < t.
+func (s score128) less(t score128) bool {
+	return s.hi < t.hi || s.hi == t.hi && s.lo < t.lo
+}
@@ -0,0 +1,56 @@
+package diffx
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCheckSize(t *testing.T) {
+	tests := []struct {
+		n, m int
+		ok   bool
+	}{
+		{0, 0, true},
+		{MaxElements, 0, true},
+		{0, MaxElements, true},
+		{MaxElements / 2, MaxElements - MaxElements/2, true},
+		{MaxElements, 1, false},
+		{1, MaxElements, false},
+		{MaxElements + 1, 0, false},
+		// The sum would overflow a 32-bit int
+		{math.MaxInt32, math.MaxInt32, false},
+		{math.MaxInt, math.MaxInt, false},
+	}
+
+	for _, tt := range tests {
+		err := checkSize(tt.n, tt.m)
+		if tt.ok && err != nil {
+			t.Errorf("checkSize(%d, %d) = %v, want nil", tt.n, tt.m, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrTooLarge) {
+			t.Errorf("checkSize(%d, %d) = %v, want ErrTooLarge", tt.n, tt.m, err)
+		}
+	}
+}
+
+func TestAnchorScore(t *testing.T) {
+	// Sections this large overflow an int64 score: 64 * 3 * 4e16
+	const half = MaxElements / 2
+	maxImb := imbalance(half, 0, half, half)
+	tests := []struct {
+		name          string
+		better, worse score128
+	}{
+		{"frequency", anchorScore(1, half, half, maxImb), anchorScore(64, half, half, 0)},
+		{"imbalance", anchorScore(64, half, half, 0), anchorScore(64, half, half, maxImb)},
+		{"both", anchorScore(63, half, half, maxImb), anchorScore(64, half, half, maxImb)},
+		{"small", anchorScore(1, 10, 10, 0), anchorScore(1, 10, 10, 1)},
+	}
+
+	for _, tt := range tests {
+		if !tt.better.less(tt.worse) || tt.worse.less(tt.better) {
+			t.Errorf("%s: %v does not rank before %v", tt.name, tt.better, tt.worse)
+		}
+	}
+}
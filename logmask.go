@@ -0,0 +1,65 @@
+package diffx
+
+import "regexp"
+
+// Volatile-token masking for logs.
+//
+// Two captures of the same program's log differ on nearly every line:
+// timestamps, process IDs, request and trace IDs, and measured durations
+// change from run to run even when the program behaves the same. With
+// WithMaskLogs, these tokens are replaced by placeholders such as "<time>"
+// before comparison, so a diff of two log captures shows the lines where
+// behavior differs. MaskLogLine applies the same masking for display.
+
+// logMask replaces the matches of pattern with replacement, which may
+// refer to submatches as in regexp.Regexp.ReplaceAllString.
+type logMask struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// logMasks are applied in order; timestamps come first so that their parts
+// are not taken for durations or numeric IDs.
+var logMasks = []logMask{
+	// ISO 8601 and RFC 3339, "2024-05-01T12:00:00.123Z", "2024-05-01 12:00:00,5"
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	// Common Log Format, "01/May/2024:12:00:00 +0000"
+	{regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2}(?: [+-]\d{4})?`), "<time>"},
+	// syslog, "May  1 12:00:00"
+	{regexp.MustCompile(`\b(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) [ \d]\d \d{2}:\d{2}:\d{2}(?:\.\d+)?`), "<time>"},
+	// Dates and times of day on their own
+	{regexp.MustCompile(`\b\d{4}[-/]\d{2}[-/]\d{2}\b`), "<time>"},
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`), "<time>"},
+	// Unix timestamps in seconds or milliseconds, 2017 to 2033
+	{regexp.MustCompile(`\b1[5-9]\d{8}(?:\d{3}|\.\d+)?\b`), "<time>"},
+	// UUIDs and long hexadecimal IDs such as trace IDs
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<id>"},
+	{regexp.MustCompile(`\b[0-9a-f]{16,}\b`), "<id>"},
+	// Request, trace, span, correlation, and session IDs, "request_id=abc123"
+	{regexp.MustCompile(`(?i)\b((?:request|req|trace|span|correlation|session)[-_]?id"?\s*[=:]\s*"?)[\w.:-]+`), "${1}<id>"},
+	// Process and thread IDs, "sshd[1234]:", "pid=1234", "PID 1234"
+	{regexp.MustCompile(`\b([\w.-]+)\[\d+\]`), "${1}[<pid>]"},
+	{regexp.MustCompile(`(?i)\b((?:pid|tid|thread)\s*[=: ]\s*)\d+\b`), "${1}<pid>"},
+	// Durations, "15ms", "1.5 s", "2m30.5s"
+	{regexp.MustCompile(`\b(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h))+\b|\b\d+(?:\.\d+)? ?(?:ns|us|µs|ms|s|sec|secs|seconds|milliseconds)\b`), "<duration>"},
+}
+
+// WithMaskLogs compares log lines with their volatile tokens masked, as
+// MaskLogLine does: timestamps, UUIDs and long hexadecimal IDs, request
+// and trace IDs given as key=value, process and thread IDs, and durations.
+// Lines that differ only in these tokens are kept as unchanged.
+// Default: false.
+func WithMaskLogs(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.logs = enabled
+	}
+}
+
+// MaskLogLine returns s with timestamps, IDs, process IDs, and durations
+// replaced by "<time>", "<id>", "<pid>", and "<duration>".
+func MaskLogLine(s string) string {
+	for _, m := range logMasks {
+		s = m.pattern.ReplaceAllString(s, m.replacement)
+	}
+	return s
+}
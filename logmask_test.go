@@ -0,0 +1,56 @@
+package diffx
+
+import "testing"
+
+func TestMaskLogLine(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-05-01T12:00:00.123Z INFO started", "<time> INFO started"},
+		{"2024-05-01 12:00:00,5 +02:00 WARN slow", "<time> +02:00 WARN slow"},
+		{`127.0.0.1 - - [01/May/2024:12:00:00 +0000] "GET / HTTP/1.1" 200`, `127.0.0.1 - - [<time>] "GET / HTTP/1.1" 200`},
+		{"May  1 12:00:00 host sshd[1234]: Accepted key", "<time> host sshd[<pid>]: Accepted key"},
+		{"ts=1714564800123 level=info", "ts=<time> level=info"},
+		{"request_id=abc-123 user=42 done", "request_id=<id> user=42 done"},
+		{`{"traceId": "4bf92f3577b34da6a3ce929d0e0e4736"}`, `{"traceId": "<id>"}`},
+		{"job 123e4567-e89b-12d3-a456-426614174000 queued", "job <id> queued"},
+		{"worker pid=4242 exited", "worker pid=<pid> exited"},
+		{"handled in 15ms (db 1.5 s, total 2m30.5s)", "handled in <duration> (db <duration>, total <duration>)"},
+		{"retry 3 of 5 for user 42", "retry 3 of 5 for user 42"},
+	}
+	for _, tt := range tests {
+		if got := MaskLogLine(tt.in); got != tt.want {
+			t.Errorf("MaskLogLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithMaskLogs(t *testing.T) {
+	a := []string{
+		"2024-05-01T12:00:00Z server[100]: listening on :8080",
+		"2024-05-01T12:00:01Z server[100]: request_id=a1 GET /health 200 in 3ms",
+		"2024-05-01T12:00:02Z server[100]: request_id=a2 GET /users 200 in 40ms",
+	}
+	b := []string{
+		"2024-06-11T08:30:00Z server[977]: listening on :8080",
+		"2024-06-11T08:30:01Z server[977]: request_id=b7 GET /health 200 in 2ms",
+		"2024-06-11T08:30:02Z server[977]: request_id=b8 GET /users 500 in 12ms",
+	}
+
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+	for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+		ops := diff(a, b, WithMaskLogs(true))
+		if len(ops) != len(want) {
+			t.Fatalf("ops = %v, want %v", ops, want)
+		}
+		for i := range want {
+			if ops[i] != want[i] {
+				t.Errorf("ops[%d] = %v, want %v", i, ops[i], want[i])
+			}
+		}
+	}
+}
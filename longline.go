@@ -0,0 +1,170 @@
+package diffx
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Long line protection.
+//
+// Minified JavaScript, generated JSON, and base64 blobs put megabytes on a
+// single line. StringElement copies and rehashes its string on every Hash
+// call, highlighting a changed line runs a word diff over it, and printing
+// it floods the terminal, so one such line can dominate the time and memory
+// of a whole diff. Diff and DiffHistogram instead compare lines longer than
+// a limit by a hash of a fixed number of sampled blocks, computed once;
+// HighlightText splits such lines into chunks at content-defined rune
+// boundaries and word-diffs only the chunks that changed; and TruncateLine
+// shortens them for display.
+
+// defaultMaxLineLen is the default limit set by WithMaxLineLen.
+const defaultMaxLineLen = 1 << 16
+
+// WithMaxLineLen sets the length in bytes above which Diff and
+// DiffHistogram treat a line as long. A long line is hashed once, over its
+// length and a fixed number of sampled blocks rather than all of its bytes,
+// and compared by that hash before its bytes; results are the same as
+// without the limit. A limit of 0 or less treats no line as long.
+// Default: 65536.
+func WithMaxLineLen(n int) Option {
+	return func(o *options) {
+		o.ignoreOpts.maxLineLen = n
+	}
+}
+
+// longElement is a string longer than the WithMaxLineLen limit, with its
+// sampled hash computed once. It is not a StringElement, so heuristics that
+// look inside lines, such as blank line detection, skip it.
+type longElement struct {
+	s    string
+	hash uint64
+}
+
+// newLongElement returns a longElement for s.
+func newLongElement(s string) longElement {
+	return longElement{s: s, hash: hashSampled(s)}
+}
+
+// Equal reports whether e and other hold the same string, comparing the
+// cached hashes first. Strings that differ only outside the sampled blocks
+// have the same hash and are told apart by their bytes.
+func (e longElement) Equal(other Element) bool {
+	o, ok := other.(longElement)
+	return ok && e.hash == o.hash && e.s == o.s
+}
+
+// Hash returns the cached sampled hash.
+func (e longElement) Hash() uint64 {
+	return e.hash
+}
+
+// TruncateLine shortens s for display to at most n bytes, cut at a rune
+// boundary and followed by a note of how many bytes were left out. A final
+// line feed is kept. Lines of at most n bytes, not counting the line feed,
+// and any line when n is 0 or less, are returned unchanged.
+func TruncateLine(s string, n int) string {
+	body, eol := strings.CutSuffix(s, "\n")
+	if n <= 0 || len(body) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	s = fmt.Sprintf("%s… [%d more bytes]", body[:cut], len(body)-cut)
+	if eol {
+		s += "\n"
+	}
+	return s
+}
+
+// FNV-1a parameters, as used by hash/fnv.
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// hashString returns the 64-bit FNV-1a hash of s without copying it.
+func hashString(s string) uint64 {
+	return fnvAdd(fnvOffset64, s)
+}
+
+// fnvAdd continues the FNV-1a hash h over the bytes of s.
+func fnvAdd(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// Sampling of long lines by hashSampled.
+const (
+	sampleBlocks   = 32
+	sampleBlockLen = 256
+)
+
+// hashSampled returns the FNV-1a hash of the length of s and of
+// sampleBlocks blocks of sampleBlockLen bytes spread evenly over s, so that
+// its cost does not grow with the length of s. Strings too short to sample
+// are hashed whole.
+func hashSampled(s string) uint64 {
+	h := uint64(fnvOffset64)
+	for n := uint64(len(s)); n > 0; n >>= 8 {
+		h ^= n & 0xff
+		h *= fnvPrime64
+	}
+	if len(s) <= sampleBlocks*sampleBlockLen {
+		return fnvAdd(h, s)
+	}
+	step := (len(s) - sampleBlockLen) / (sampleBlocks - 1)
+	for i := 0; i < sampleBlocks; i++ {
+		h = fnvAdd(h, s[i*step:i*step+sampleBlockLen])
+	}
+	return h
+}
+
+// Chunk sizes of splitChunks, in bytes. A cut happens where the rolling
+// hash matches chunkCutMask, which gives chunks of about 2 KiB on average.
+const (
+	minChunkLen  = 256
+	maxChunkLen  = 8192
+	chunkCutMask = 0x7ff << 53
+)
+
+// gearTable holds the pseudo-random values the rolling hash of splitChunks
+// adds for each byte, generated by splitmix64 from a fixed seed.
+var gearTable = func() (t [256]uint64) {
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range t {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		t[i] = z ^ z>>31
+	}
+	return t
+}()
+
+// splitChunks splits s into chunks of minChunkLen to maxChunkLen bytes,
+// cut at rune boundaries. Cuts depend only on the 64 bytes before them
+// (a gear rolling hash), so an edit moves the cuts near it and leaves the
+// chunks elsewhere the same on both sides. Concatenating the chunks
+// reproduces s.
+func splitChunks(s string) []string {
+	var chunks []string
+	start := 0
+	var h uint64
+	for i := 0; i < len(s); i++ {
+		if n := i - start; n >= minChunkLen && utf8.RuneStart(s[i]) && (h&chunkCutMask == 0 || n >= maxChunkLen) {
+			chunks = append(chunks, s[start:i])
+			start = i
+		}
+		h = h<<1 + gearTable[s[i]]
+	}
+	if start < len(s) {
+		chunks = append(chunks, s[start:])
+	}
+	return chunks
+}
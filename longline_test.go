@@ -0,0 +1,131 @@
+package diffx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWithMaxLineLen(t *testing.T) {
+	long := strings.Repeat("minified();", 20)
+	a := []string{"header", long + "a", "middle", long, "footer"}
+	b := []string{"header", long + "b", "middle", long, "FOOTER"}
+
+	want := Diff(a, b, WithMaxLineLen(0))
+	for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+		if got := diff(a, b, WithMaxLineLen(50)); !reflect.DeepEqual(got, want) {
+			t.Errorf("with a limit: ops = %v, want %v", got, want)
+		}
+	}
+
+	// Normalization applies before the limit
+	got := Diff([]string{long + "\r\n"}, []string{long + "\n"}, WithMaxLineLen(50), WithNormalizeEOL(true))
+	if countChangeRegions(got) != 0 {
+		t.Errorf("normalized long lines: ops = %v, want no changes", got)
+	}
+
+	// Keys of long lines under ignore options are hashed by samples too
+	spaced := []string{"header", long + " a", "middle", long, "footer"}
+	want = Diff(spaced, b, WithMaxLineLen(0), WithIgnoreAllSpace(true))
+	for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+		if got := diff(spaced, b, WithMaxLineLen(50), WithIgnoreAllSpace(true)); !reflect.DeepEqual(got, want) {
+			t.Errorf("with a limit and WithIgnoreAllSpace: ops = %v, want %v", got, want)
+		}
+	}
+	ig := defaultIgnoreOptions()
+	ig.maxLineLen = 50
+	ig.allSpace = true
+	keyed := ig.elements([]string{"a b", long + " a"})
+	if h, want := keyed[0].Hash(), hashString("ab"); h != want {
+		t.Errorf("short key: hash %x, want the full hash %x", h, want)
+	}
+	if h, want := keyed[1].Hash(), hashSampled(long+"a"); h != want {
+		t.Errorf("long key: hash %x, want the sampled hash %x", h, want)
+	}
+
+	ig = defaultIgnoreOptions()
+	ig.maxLineLen = 50
+	elems := ig.elements([]string{"short", long})
+	if _, ok := elems[0].(StringElement); !ok {
+		t.Errorf("short line: got %T, want StringElement", elems[0])
+	}
+	if _, ok := elems[1].(longElement); !ok {
+		t.Errorf("long line: got %T, want longElement", elems[1])
+	}
+
+	// Strings that differ outside the sampled blocks share a hash but are
+	// not equal
+	huge := strings.Repeat("x", 1<<20)
+	x, y := newLongElement(huge+"a"+huge), newLongElement(huge+"b"+huge)
+	if x.Hash() != y.Hash() {
+		t.Fatal("the edit falls inside a sampled block")
+	}
+	if x.Equal(y) {
+		t.Error("long lines with the same sampled hash are equal")
+	}
+	if !x.Equal(newLongElement(huge + "a" + huge)) {
+		t.Error("identical long lines are not equal")
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	s := minified(200000)
+	edited := s[:100000] + "EDIT" + s[100000:]
+
+	chunks := splitChunks(s)
+	if got := strings.Join(chunks, ""); got != s {
+		t.Fatal("chunks do not reproduce the input")
+	}
+	for i, c := range chunks {
+		if !utf8.ValidString(c) {
+			t.Errorf("chunk %d is not cut at rune boundaries", i)
+		}
+		if len(c) > maxChunkLen || (len(c) < minChunkLen && i < len(chunks)-1) {
+			t.Errorf("chunk %d has %d bytes, want %d to %d", i, len(c), minChunkLen, maxChunkLen)
+		}
+	}
+
+	// The edit changes only the chunks around it
+	ops := Diff(chunks, splitChunks(edited))
+	changed := 0
+	for _, op := range ops {
+		changed += op.LenA()
+		if op.Type == Equal {
+			changed -= op.LenA()
+		}
+	}
+	if changed > 2 {
+		t.Errorf("%d of %d chunks changed by a small edit, want at most 2", changed, len(chunks))
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"abcdef\n", 3, "abc… [3 more bytes]\n"},
+		{"abcdef", 3, "abc… [3 more bytes]"},
+		{"abc\n", 3, "abc\n"},
+		{"héllo", 2, "h… [5 more bytes]"},
+		{"abcdef", 0, "abcdef"},
+	}
+	for _, tt := range tests {
+		if got := TruncateLine(tt.s, tt.n); got != tt.want {
+			t.Errorf("TruncateLine(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+// minified returns a line of at least n bytes of varied, minified-looking
+// code, with multi-byte runes.
+func minified(n int) string {
+	var sb strings.Builder
+	for i := 0; sb.Len() < n; i++ {
+		fmt.Fprintf(&sb, "var v%d=\"é%d\";", i, i*i)
+	}
+	return sb.String()
+}
@@ -0,0 +1,177 @@
+package diffx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Checksum manifest comparison.
+//
+// Release audits compare the file listings of two builds, with a checksum
+// and size per file, rather than the files themselves. A file moved to a
+// new directory shows up in such a listing as a removal and an addition
+// with the same checksum. DiffManifests pairs the entries by path, pairs
+// the leftovers by checksum to find renames, and reports what was added,
+// removed, modified, and renamed.
+
+// ManifestEntry is a file in a checksum manifest.
+type ManifestEntry struct {
+	Path string
+	Hash string // checksum of the content, in any consistent encoding
+	Size int64  // size in bytes, or -1 if unknown
+}
+
+// ManifestChange describes a file that differs between two manifests.
+type ManifestChange struct {
+	Status FileStatus // FileAdded, FileRemoved, FileModified, or FileRenamed
+	Path   string     // path in B, or in A for removed files
+	// OldPath is the path in A of a renamed file.
+	OldPath string
+	A, B    ManifestEntry // the entry in each manifest; zero if absent
+}
+
+// String returns a one-line description such as "R old/a.txt -> a.txt".
+func (c ManifestChange) String() string {
+	switch c.Status {
+	case FileAdded:
+		return "A " + c.Path
+	case FileRemoved:
+		return "D " + c.Path
+	case FileRenamed:
+		return "R " + c.OldPath + " -> " + c.Path
+	default:
+		return "M " + c.Path
+	}
+}
+
+// DiffManifests compares two manifests and returns the files that
+// differ, sorted by path. Entries with the same path are modified if
+// their hashes differ, or, when a hash is empty, their known sizes. A
+// removed and an added entry with the same non-empty hash, and the same
+// size if both are known, are reported as one rename; when several files
+// share a hash, they are paired in path order. A file that was moved and
+// changed has a new hash and is reported as removed and added.
+func DiffManifests(a, b []ManifestEntry) []ManifestChange {
+	byPathA := make(map[string]ManifestEntry, len(a))
+	for _, e := range a {
+		byPathA[e.Path] = e
+	}
+	byPathB := make(map[string]ManifestEntry, len(b))
+	for _, e := range b {
+		byPathB[e.Path] = e
+	}
+
+	var changes []ManifestChange
+	var removed []ManifestEntry
+	for _, p := range sortedKeys(byPathA) {
+		ea := byPathA[p]
+		eb, ok := byPathB[p]
+		switch {
+		case !ok:
+			removed = append(removed, ea)
+		case !sameContent(ea, eb):
+			changes = append(changes, ManifestChange{Status: FileModified, Path: p, A: ea, B: eb})
+		}
+	}
+
+	// Pair removed files with added files of the same content
+	candidates := make(map[string][]ManifestEntry) // hash -> removed entries, in path order
+	for _, e := range removed {
+		if e.Hash != "" {
+			candidates[e.Hash] = append(candidates[e.Hash], e)
+		}
+	}
+	renamed := make(map[string]bool) // paths in A
+	for _, p := range sortedKeys(byPathB) {
+		eb := byPathB[p]
+		if _, ok := byPathA[p]; ok {
+			continue
+		}
+		c := ManifestChange{Status: FileAdded, Path: p, B: eb}
+		for k, ea := range candidates[eb.Hash] {
+			if sameContent(ea, eb) {
+				c.Status, c.OldPath, c.A = FileRenamed, ea.Path, ea
+				candidates[eb.Hash] = append(candidates[eb.Hash][:k:k], candidates[eb.Hash][k+1:]...)
+				renamed[ea.Path] = true
+				break
+			}
+		}
+		changes = append(changes, c)
+	}
+	for _, e := range removed {
+		if !renamed[e.Path] {
+			changes = append(changes, ManifestChange{Status: FileRemoved, Path: e.Path, A: e})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// sameContent reports whether two entries describe the same content: equal
+// hashes, or equal sizes where a hash is missing, and equal sizes where
+// both are known.
+func sameContent(a, b ManifestEntry) bool {
+	if a.Size >= 0 && b.Size >= 0 && a.Size != b.Size {
+		return false
+	}
+	if a.Hash == "" || b.Hash == "" {
+		return a.Size >= 0 && b.Size >= 0
+	}
+	return a.Hash == b.Hash
+}
+
+// bsdChecksum matches the BSD "tagged" checksum format, as written by
+// "shasum --tag" and "openssl dgst": "SHA256 (path) = hash".
+var bsdChecksum = regexp.MustCompile(`^[A-Za-z0-9-]+ \((.*)\) = ([0-9A-Fa-f]+)$`)
+
+// ParseManifest reads a checksum manifest in the formats written by
+// sha256sum and similar tools: "hash  path" or "hash *path" lines, and
+// BSD-style "SHA256 (path) = hash" lines. A line of the form "hash size
+// path", where size is a decimal number, also records the size; in the
+// other formats Size is -1. Blank lines and lines starting with "#" are
+// skipped.
+func ParseManifest(r io.Reader) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := bsdChecksum.FindStringSubmatch(line); m != nil {
+			entries = append(entries, ManifestEntry{Path: m[1], Hash: m[2], Size: -1})
+			continue
+		}
+
+		hash, rest, ok := strings.Cut(line, " ")
+		if !ok || hash == "" || rest == "" {
+			return nil, fmt.Errorf("diffx: manifest line %d: expected hash and path", n)
+		}
+		e := ManifestEntry{Hash: hash, Size: -1}
+		switch {
+		case rest[0] == ' ' || rest[0] == '*':
+			e.Path = rest[1:]
+		default:
+			field, path, ok := strings.Cut(rest, " ")
+			size, err := strconv.ParseInt(field, 10, 64)
+			if !ok || err != nil || size < 0 {
+				return nil, fmt.Errorf("diffx: manifest line %d: expected hash and path", n)
+			}
+			e.Size, e.Path = size, path
+		}
+		if e.Path == "" {
+			return nil, fmt.Errorf("diffx: manifest line %d: expected hash and path", n)
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("diffx: manifest: %w", err)
+	}
+	return entries, nil
+}
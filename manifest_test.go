@@ -0,0 +1,95 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffManifests(t *testing.T) {
+	a := []ManifestEntry{
+		{Path: "bin/tool", Hash: "aa", Size: 100},
+		{Path: "docs/guide.md", Hash: "bb", Size: 20},
+		{Path: "lib/old.so", Hash: "cc", Size: 30},
+		{Path: "LICENSE", Hash: "dd", Size: 10},
+		{Path: "notes.txt", Hash: "ee", Size: 5},
+	}
+	b := []ManifestEntry{
+		{Path: "LICENSE", Hash: "dd", Size: 10},
+		{Path: "bin/tool", Hash: "a2", Size: 104},
+		{Path: "lib/v2/old.so", Hash: "cc", Size: 30},
+		{Path: "guide.md", Hash: "bb", Size: 20},
+		{Path: "CHANGELOG", Hash: "ff", Size: 7},
+	}
+
+	var got []string
+	for _, c := range DiffManifests(a, b) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"A CHANGELOG",
+		"M bin/tool",
+		"R docs/guide.md -> guide.md",
+		"R lib/old.so -> lib/v2/old.so",
+		"D notes.txt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffManifests() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDiffManifests_SharedHash(t *testing.T) {
+	// Two empty files were moved and a third copy added; each removed
+	// file is used for one rename only
+	a := []ManifestEntry{{"a/x", "e3", 0}, {"a/y", "e3", 0}, {"keep", "e3", 0}}
+	b := []ManifestEntry{{"b/x", "e3", 0}, {"b/y", "e3", 0}, {"b/z", "e3", 0}, {"keep", "e3", 0}}
+
+	var got []string
+	for _, c := range DiffManifests(a, b) {
+		got = append(got, c.String())
+	}
+	want := []string{"R a/x -> b/x", "R a/y -> b/y", "A b/z"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffManifests() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffManifests_SizeOnly(t *testing.T) {
+	a := []ManifestEntry{{"f", "", 10}, {"g", "", 3}, {"h", "11", 4}}
+	b := []ManifestEntry{{"f", "", 12}, {"g", "", 3}, {"h", "11", 5}}
+
+	changes := DiffManifests(a, b)
+	if len(changes) != 2 || changes[0].Path != "f" || changes[1].Path != "h" {
+		t.Errorf("DiffManifests() = %v, want f and h modified", changes)
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	input := strings.Join([]string{
+		"# release 1.2",
+		"aa  bin/tool",
+		"bb *docs/guide with spaces.md",
+		"SHA256 (lib/old.so) = cc",
+		"dd 1024 LICENSE",
+		"",
+	}, "\n")
+	got, err := ParseManifest(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ManifestEntry{
+		{Path: "bin/tool", Hash: "aa", Size: -1},
+		{Path: "docs/guide with spaces.md", Hash: "bb", Size: -1},
+		{Path: "lib/old.so", Hash: "cc", Size: -1},
+		{Path: "LICENSE", Hash: "dd", Size: 1024},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseManifest() = %+v\nwant %+v", got, want)
+	}
+
+	for _, bad := range []string{"justahash", "aa  ", "aa x y", "aa -1 f"} {
+		if _, err := ParseManifest(strings.NewReader(bad)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("ParseManifest(%q) error = %v", bad, err)
+		}
+	}
+}
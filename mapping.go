@@ -0,0 +1,101 @@
+package diffx
+
+import "sort"
+
+// Position mapping.
+//
+// Tools that carry positions across versions of a file, such as coverage
+// remappers or review comments anchored to a line, need to translate an
+// index in A to the corresponding index in B. The information is in the
+// edit script, but every caller would otherwise re-derive it.
+// MigrateMarks carries values attached to positions of A, such as review
+// comments, bookmarks, or coverage flags, over to B in one step.
+
+// PositionMap translates element positions between the two sequences of an
+// edit script. It is safe for concurrent use.
+type PositionMap struct {
+	ops        []DiffOp
+	lenA, lenB int
+}
+
+// Mapping returns a PositionMap for ops, which must be a complete script
+// such as one returned by Diff. The script is copied.
+func Mapping(ops []DiffOp) *PositionMap {
+	m := &PositionMap{ops: append([]DiffOp(nil), ops...)}
+	if n := len(ops); n > 0 {
+		m.lenA = ops[n-1].AEnd
+		m.lenB = ops[n-1].BEnd
+	}
+	return m
+}
+
+// AtoB returns the position in B corresponding to position i in A.
+// exact is true if element i is unchanged and j is its position in B. If
+// element i was deleted, j is the position in B where the deletion
+// occurred, that is, the index of the first element after it. Positions
+// outside A are shifted by the difference in length and are never exact.
+func (m *PositionMap) AtoB(i int) (j int, exact bool) {
+	if i < 0 {
+		return i, false
+	}
+	if i >= m.lenA {
+		return i - m.lenA + m.lenB, false
+	}
+
+	k := sort.Search(len(m.ops), func(k int) bool { return m.ops[k].AEnd > i })
+	op := m.ops[k]
+	if op.Type == Equal {
+		return op.BStart + i - op.AStart, true
+	}
+	return op.BStart, false
+}
+
+// BtoA returns the position in A corresponding to position j in B.
+// It is the inverse of AtoB: inserted elements map to the position in A
+// where the insertion occurred, with exact false.
+func (m *PositionMap) BtoA(j int) (i int, exact bool) {
+	if j < 0 {
+		return j, false
+	}
+	if j >= m.lenB {
+		return j - m.lenB + m.lenA, false
+	}
+
+	k := sort.Search(len(m.ops), func(k int) bool { return m.ops[k].BEnd > j })
+	op := m.ops[k]
+	if op.Type == Equal {
+		return op.AStart + j - op.BStart, true
+	}
+	return op.AStart, false
+}
+
+// Mark is a value attached to an element of A, carried over to B.
+type Mark[T any] struct {
+	Value T
+	A     int // position of the element in A
+	// B is the position of the element in B. If the element was deleted,
+	// B is the position where the deletion occurred and Deleted is true;
+	// the mark has no element to attach to and needs review.
+	B       int
+	Deleted bool
+}
+
+// MigrateMarks carries marks, keyed by position in A, over to B using
+// ops, which must be a complete script such as one returned by Diff. The
+// result is sorted by position in A. Marks outside A are shifted as AtoB
+// does and reported as Deleted.
+func MigrateMarks[T any](ops []DiffOp, marks map[int]T) []Mark[T] {
+	m := Mapping(ops)
+	positions := make([]int, 0, len(marks))
+	for i := range marks {
+		positions = append(positions, i)
+	}
+	sort.Ints(positions)
+
+	migrated := make([]Mark[T], len(positions))
+	for k, i := range positions {
+		j, exact := m.AtoB(i)
+		migrated[k] = Mark[T]{Value: marks[i], A: i, B: j, Deleted: !exact}
+	}
+	return migrated
+}
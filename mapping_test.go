@@ -0,0 +1,107 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapping(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e"}
+	b := []string{"a", "x", "c", "d", "y", "e"}
+	m := Mapping(Diff(a, b))
+
+	tests := []struct {
+		i     int
+		j     int
+		exact bool
+	}{
+		{0, 0, true},
+		{1, 1, false}, // "b" was deleted where "x" now is
+		{2, 2, true},
+		{3, 3, true},
+		{4, 5, true},
+		{5, 6, false}, // end of A maps to end of B
+		{-1, -1, false},
+	}
+
+	for _, tt := range tests {
+		j, exact := m.AtoB(tt.i)
+		if j != tt.j || exact != tt.exact {
+			t.Errorf("AtoB(%d) = (%d, %v), want (%d, %v)", tt.i, j, exact, tt.j, tt.exact)
+		}
+	}
+}
+
+func TestMapping_BtoA(t *testing.T) {
+	a := []string{"a", "b", "c"}
+	b := []string{"new", "a", "b", "c"}
+	m := Mapping(Diff(a, b))
+
+	tests := []struct {
+		j     int
+		i     int
+		exact bool
+	}{
+		{0, 0, false},
+		{1, 0, true},
+		{3, 2, true},
+		{4, 3, false},
+	}
+
+	for _, tt := range tests {
+		i, exact := m.BtoA(tt.j)
+		if i != tt.i || exact != tt.exact {
+			t.Errorf("BtoA(%d) = (%d, %v), want (%d, %v)", tt.j, i, exact, tt.i, tt.exact)
+		}
+	}
+}
+
+func TestMapping_RoundTrip(t *testing.T) {
+	a := []string{"1", "2", "3", "4", "5", "6"}
+	b := []string{"0", "1", "3", "4", "x", "6", "7"}
+	m := Mapping(Diff(a, b))
+
+	for i := range a {
+		j, exact := m.AtoB(i)
+		if !exact {
+			continue
+		}
+		if a[i] != b[j] {
+			t.Errorf("AtoB(%d) = %d maps %q to %q", i, j, a[i], b[j])
+		}
+		if back, ok := m.BtoA(j); !ok || back != i {
+			t.Errorf("BtoA(AtoB(%d)) = (%d, %v)", i, back, ok)
+		}
+	}
+}
+
+func TestMapping_Empty(t *testing.T) {
+	m := Mapping(nil)
+	if j, exact := m.AtoB(0); j != 0 || exact {
+		t.Errorf("AtoB(0) on empty mapping = (%d, %v)", j, exact)
+	}
+}
+
+func TestMigrateMarks(t *testing.T) {
+	a := []string{"func f() {", "\tx := 1", "\treturn x", "}"}
+	b := []string{"// f returns one.", "func f() {", "\treturn 1", "}"}
+	comments := map[int]string{
+		0: "rename f",
+		1: "use a constant",
+		3: "missing newline",
+	}
+
+	got := MigrateMarks(Diff(a, b), comments)
+	want := []Mark[string]{
+		{Value: "rename f", A: 0, B: 1},
+		{Value: "use a constant", A: 1, B: 2, Deleted: true},
+		{Value: "missing newline", A: 3, B: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MigrateMarks() = %+v\nwant %+v", got, want)
+	}
+
+	if got := MigrateMarks[bool](Diff(a, b), nil); len(got) != 0 {
+		t.Errorf("no marks: MigrateMarks() = %+v", got)
+	}
+}
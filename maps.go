@@ -0,0 +1,63 @@
+package diffx
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Map diff.
+//
+// Configuration, feature flags, and environment variables are maps, where
+// only the keys identify entries. DiffMaps reports the keys added to or
+// removed from a map and the keys whose value changed, in key order so the
+// result is stable across runs.
+
+// MapChange describes a key whose entry differs between two maps.
+type MapChange[V any] struct {
+	Key      string
+	A, B     V    // the value in each map, or the zero value where absent
+	InA, InB bool // whether the key is present in each map
+}
+
+// Added reports whether the key is only present in B.
+func (c MapChange[V]) Added() bool {
+	return !c.InA
+}
+
+// Removed reports whether the key is only present in A.
+func (c MapChange[V]) Removed() bool {
+	return !c.InB
+}
+
+// DiffMaps compares two maps and returns the keys added, removed, or
+// whose values differ, sorted by key. Values are compared with equal, or
+// with reflect.DeepEqual if equal is nil. The A and B values of a changed
+// key can be diffed further, for example with Diff for string slices or
+// with DiffMaps for nested maps.
+func DiffMaps[V any](a, b map[string]V, equal func(x, y V) bool) []MapChange[V] {
+	if equal == nil {
+		equal = func(x, y V) bool { return reflect.DeepEqual(x, y) }
+	}
+
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []MapChange[V]
+	for _, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		if inA && inB && equal(va, vb) {
+			continue
+		}
+		changes = append(changes, MapChange[V]{Key: k, A: va, B: vb, InA: inA, InB: inB})
+	}
+	return changes
+}
@@ -0,0 +1,48 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffMaps(t *testing.T) {
+	a := map[string]int{"timeout": 30, "retries": 3, "workers": 4}
+	b := map[string]int{"timeout": 60, "retries": 3, "verbose": 1}
+
+	got := DiffMaps(a, b, nil)
+	want := []MapChange[int]{
+		{Key: "timeout", A: 30, B: 60, InA: true, InB: true},
+		{Key: "verbose", B: 1, InB: true},
+		{Key: "workers", A: 4, InA: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffMaps() = %+v\nwant %+v", got, want)
+	}
+	if got[0].Added() || got[0].Removed() || !got[1].Added() || !got[2].Removed() {
+		t.Errorf("Added/Removed wrong for %+v", got)
+	}
+
+	if got := DiffMaps(a, a, nil); got != nil {
+		t.Errorf("identical: DiffMaps() = %+v, want nil", got)
+	}
+}
+
+func TestDiffMaps_Comparator(t *testing.T) {
+	a := map[string][]string{"hosts": {"a", "b"}, "Name": {"web"}}
+	b := map[string][]string{"hosts": {"a", "b", "c"}, "Name": {"WEB"}}
+
+	equalFold := func(x, y []string) bool {
+		return strings.EqualFold(strings.Join(x, "\n"), strings.Join(y, "\n"))
+	}
+	got := DiffMaps(a, b, equalFold)
+	if len(got) != 1 || got[0].Key != "hosts" {
+		t.Fatalf("DiffMaps() = %+v, want only hosts", got)
+	}
+
+	// The values of a changed key can be diffed further
+	ops := Diff(got[0].A, got[0].B)
+	if s := Stat(ops); s.Insertions != 1 || s.Deletions != 0 {
+		t.Errorf("Diff of hosts = %v, want one insertion", ops)
+	}
+}
@@ -0,0 +1,91 @@
+package diffx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Inline-markup-insensitive comparison.
+//
+// Docs edits often only change formatting: a term gains code backticks, a
+// warning becomes bold, emphasis switches from "*" to "_". Compared as
+// text, each such line is a change and hides the wording changes around
+// it. WithIgnoreMarkup compares text without its inline markup markers,
+// while the returned operations still index the original strings, so the
+// output keeps the markup; MarkupChanges then counts the formatting-only
+// edits for a separate summary.
+
+// WithIgnoreMarkup compares strings without Markdown and reStructuredText
+// inline markup markers: "*" and "**" emphasis, "_" and "__" emphasis at
+// word boundaries, "~~" strikethrough, and "`" code spans. Underscores
+// inside words, as in snake_case, a "*" list bullet, and a "*" between
+// spaces are kept.
+// Default: false.
+func WithIgnoreMarkup(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.markup = enabled
+	}
+}
+
+// MarkupChanges returns the number of strings that ops keeps as unchanged
+// although they differ in a and b, and differ only in inline markup. It is
+// nonzero only for edit scripts computed with WithIgnoreMarkup.
+func MarkupChanges(ops []DiffOp, a, b []string) int {
+	n := 0
+	for _, op := range ops {
+		if op.Type != Equal {
+			continue
+		}
+		for i, j := op.AStart, op.BStart; i < op.AEnd; i, j = i+1, j+1 {
+			if a[i] != b[j] && stripMarkup(a[i]) == stripMarkup(b[j]) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// stripMarkup removes inline markup markers from s.
+func stripMarkup(s string) string {
+	if !strings.ContainsAny(s, "*_~`") {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	lineStart := true
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		prev, _ := utf8.DecodeLastRuneInString(s[:i])
+		next, _ := utf8.DecodeRuneInString(s[i+size:])
+		keep := true
+		switch r {
+		case '`':
+			keep = false
+		case '~':
+			keep = next != '~' && prev != '~'
+		case '*':
+			bullet := lineStart && next == ' '
+			spaced := i > 0 && unicode.IsSpace(prev) && (i+size == len(s) || unicode.IsSpace(next))
+			keep = bullet || spaced
+		case '_':
+			keep = isWordRune(prev) && isWordRune(next) && i > 0 && i+size < len(s)
+		}
+		if keep {
+			sb.WriteRune(r)
+		}
+		switch {
+		case r == '\n':
+			lineStart = true
+		case !unicode.IsSpace(r):
+			lineStart = false
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// isWordRune reports whether r is a letter or digit.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
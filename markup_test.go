@@ -0,0 +1,53 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripMarkup(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"a **bold** and *em* word", "a bold and em word"},
+		{"__strong__ and _em_", "strong and em"},
+		{"run `go test` now", "run go test now"},
+		{"~~old~~ new", "old new"},
+		{"snake_case_name stays", "snake_case_name stays"},
+		{"* list item with *em*", "* list item with em"},
+		{"2 * 3 = 6", "2 * 3 = 6"},
+		{"a ~ b", "a ~ b"},
+		{"line\n* second *item*", "line\n* second item"},
+	}
+	for _, tt := range tests {
+		if got := stripMarkup(tt.in); got != tt.want {
+			t.Errorf("stripMarkup(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithIgnoreMarkup(t *testing.T) {
+	a := []string{"# Setup", "Run `make` to build.", "This is *important*.", "Then deploy."}
+	b := []string{"# Setup", "Run make to build.", "This is **important**.", "Then ship."}
+
+	if n := countChangeRegions(Diff(a, b)); n != 1 {
+		t.Fatalf("plain diff has %d change regions, want 1", n)
+	}
+
+	ops := Diff(a, b, WithIgnoreMarkup(true))
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+		{Type: Delete, AStart: 3, AEnd: 4, BStart: 3, BEnd: 3},
+		{Type: Insert, AStart: 4, AEnd: 4, BStart: 3, BEnd: 4},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops = %v, want %v", ops, want)
+	}
+	if got := MarkupChanges(ops, a, b); got != 2 {
+		t.Errorf("MarkupChanges() = %d, want 2", got)
+	}
+	if got := MarkupChanges(Diff(a, b), a, b); got != 0 {
+		t.Errorf("without WithIgnoreMarkup: MarkupChanges() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,96 @@
+package diffx
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics hooks.
+//
+// Services that diff untrusted inputs want the same numbers Stats records,
+// but aggregated over time in their monitoring system rather than per call.
+// The Metrics interface receives one observation per diff, which adapts
+// directly to Prometheus or OpenTelemetry counters and histograms without
+// this package depending on either.
+
+// Metrics receives an observation for every diff. Implementations must be
+// safe for concurrent use, since diffs on different goroutines report to
+// the same Metrics.
+type Metrics interface {
+	ObserveDiff(m DiffMetrics)
+}
+
+// DiffMetrics describes one diff.
+type DiffMetrics struct {
+	Algorithm string        // "myers" or "histogram"
+	Duration  time.Duration // wall time of the diff
+	LenA      int           // elements of A
+	LenB      int           // elements of B
+	Ops       int           // operations in the result
+
+	FilteredA          int // elements of A hidden by preprocessing
+	FilteredB          int // elements of B hidden by preprocessing
+	HeuristicFallbacks int // searches split by a heuristic, greedy fallbacks included
+	GreedyFallbacks    int // searches that found no snake and split greedily
+	MyersFallbacks     int // histogram sections without an anchor diffed with Myers
+	CoarseFallbacks    int // diffs over the work limit computed coarsely
+}
+
+// metricsBox holds the global Metrics, so that a nil Metrics can be stored.
+type metricsBox struct {
+	m Metrics
+}
+
+var globalMetrics atomic.Pointer[metricsBox]
+
+// SetMetrics registers m to observe every diff that does not override it
+// with WithMetrics. SetMetrics(nil) removes the registered Metrics. It is
+// safe to call concurrently with diffs, which use the Metrics registered
+// when they start.
+func SetMetrics(m Metrics) {
+	globalMetrics.Store(&metricsBox{m: m})
+}
+
+// registeredMetrics returns the Metrics registered with SetMetrics, or nil.
+func registeredMetrics() Metrics {
+	if box := globalMetrics.Load(); box != nil {
+		return box.m
+	}
+	return nil
+}
+
+// WithMetrics reports the diff to m instead of the Metrics registered with
+// SetMetrics. WithMetrics(nil) disables reporting for the call.
+// Default: the Metrics registered with SetMetrics.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// observe runs diff on a and b with opts, timing it and collecting its
+// statistics, and reports the result to o.metrics. Statistics requested
+// with WithStats are recorded as well.
+func observe(algorithm string, a, b []Element, opts []Option, o *options,
+	diff func(a, b []Element, opts ...Option) []DiffOp) []DiffOp {
+	var s Stats
+	start := time.Now()
+	ops := diff(a, b, append(opts[:len(opts):len(opts)], WithMetrics(nil), WithStats(&s))...)
+	duration := time.Since(start)
+
+	o.stats.add(&s)
+	o.metrics.ObserveDiff(DiffMetrics{
+		Algorithm:          algorithm,
+		Duration:           duration,
+		LenA:               len(a),
+		LenB:               len(b),
+		Ops:                len(ops),
+		FilteredA:          s.FilteredA,
+		FilteredB:          s.FilteredB,
+		HeuristicFallbacks: s.HeuristicFallbacks,
+		GreedyFallbacks:    s.GreedyFallbacks,
+		MyersFallbacks:     s.MyersFallbacks,
+		CoarseFallbacks:    s.CoarseFallbacks,
+	})
+	return ops
+}
@@ -0,0 +1,112 @@
+package diffx
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// recordingMetrics collects observations.
+type recordingMetrics struct {
+	mu  sync.Mutex
+	obs []DiffMetrics
+}
+
+func (r *recordingMetrics) ObserveDiff(m DiffMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.obs = append(r.obs, m)
+}
+
+func TestWithMetrics(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "c"}
+
+	var r recordingMetrics
+	ops := Diff(a, b, WithMetrics(&r))
+	DiffHistogram(a, b, WithMetrics(&r))
+
+	if len(r.obs) != 2 {
+		t.Fatalf("observations = %+v, want 2", r.obs)
+	}
+	m := r.obs[0]
+	if m.Algorithm != "myers" || m.LenA != 4 || m.LenB != 3 || m.Ops != len(ops) || m.Duration < 0 {
+		t.Errorf("myers observation = %+v", m)
+	}
+	if r.obs[1].Algorithm != "histogram" {
+		t.Errorf("histogram observation = %+v", r.obs[1])
+	}
+	if want := Diff(a, b); !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops with metrics = %v, want %v", ops, want)
+	}
+}
+
+func TestWithMetrics_Fallbacks(t *testing.T) {
+	// The same input as TestWithStats_HeuristicFallback
+	var a, b []string
+	a = append(a, "p")
+	b = append(b, "q")
+	for i := 0; i < 40; i++ {
+		a = append(a, fmt.Sprintf("run%d", i))
+		b = append(b, fmt.Sprintf("run%d", i))
+	}
+	for i := 0; i < 100; i++ {
+		a = append(a, fmt.Sprintf("x%d", i))
+		b = append(b, fmt.Sprintf("y%d", i))
+	}
+
+	var r recordingMetrics
+	var s Stats
+	Diff(a, b, WithCostLimit(5), WithPreprocessing(false), WithMetrics(&r), WithStats(&s))
+
+	if len(r.obs) != 1 || r.obs[0].HeuristicFallbacks == 0 {
+		t.Errorf("observations = %+v, want one with heuristic fallbacks", r.obs)
+	}
+	// The caller's Stats is still filled in
+	if s.Calls != 1 || s.HeuristicFallbacks != r.obs[0].HeuristicFallbacks {
+		t.Errorf("stats = %+v, want one call with the observed fallbacks", s)
+	}
+}
+
+func TestSetMetrics(t *testing.T) {
+	var global, local recordingMetrics
+	SetMetrics(&global)
+	t.Cleanup(func() { SetMetrics(nil) })
+
+	a := []string{"a", "b"}
+	b := []string{"a", "c"}
+	Diff(a, b)
+	Diff(a, b, WithMetrics(&local))
+	Diff(a, b, WithMetrics(nil))
+
+	if len(global.obs) != 1 || len(local.obs) != 1 {
+		t.Errorf("global %d, local %d observations, want 1 and 1", len(global.obs), len(local.obs))
+	}
+
+	SetMetrics(nil)
+	Diff(a, b)
+	if len(global.obs) != 1 {
+		t.Errorf("after SetMetrics(nil): %d observations, want 1", len(global.obs))
+	}
+}
+
+func TestSetMetrics_Concurrent(t *testing.T) {
+	var r recordingMetrics
+	SetMetrics(&r)
+	t.Cleanup(func() { SetMetrics(nil) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Diff([]string{"a", "b"}, []string{"a", "c"})
+		}()
+	}
+	wg.Wait()
+
+	if len(r.obs) != 8 {
+		t.Errorf("observations = %d, want 8", len(r.obs))
+	}
+}
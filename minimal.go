@@ -0,0 +1,101 @@
+package diffx
+
+// Minimality verification.
+//
+// The default heuristics trade edit-script size for speed and readability:
+// the cost limit stops the search early on large inputs, preprocessing
+// hides frequent elements from the core algorithm, and weak anchor
+// elimination deliberately gives up short matches. VerifyMinimality
+// measures what that costs on real inputs, so cost limits and options can
+// be tuned against numbers rather than guesses.
+
+// exhaustiveLimit is the largest len(a)*len(b) for which the minimal edit
+// distance is computed exhaustively by dynamic programming. Larger inputs
+// use Myers' algorithm in minimal mode, which is also exact but shares its
+// code with the algorithm under test.
+const exhaustiveLimit = 1 << 22
+
+// MinimalityReport compares the size of an edit script with the size of a
+// shortest edit script for the same inputs.
+type MinimalityReport struct {
+	Edits   int  // elements deleted plus elements inserted by the script
+	Minimal int  // elements deleted plus inserted by a shortest script
+	Exact   bool // Minimal was computed exhaustively, independently of Myers
+}
+
+// Excess returns how many more elements the script changes than a
+// shortest script does.
+func (r MinimalityReport) Excess() int {
+	return r.Edits - r.Minimal
+}
+
+// Ratio returns Edits divided by Minimal: 1 for a minimal script, and
+// larger the further the script is from minimal. It returns 1 when both
+// are zero.
+func (r MinimalityReport) Ratio() float64 {
+	if r.Minimal == 0 {
+		if r.Edits == 0 {
+			return 1
+		}
+		return float64(r.Edits)
+	}
+	return float64(r.Edits) / float64(r.Minimal)
+}
+
+// VerifyMinimality reports how far ops, an edit script for a and b, is
+// from minimal. The minimal edit distance is computed exhaustively for
+// small inputs and with Myers' algorithm in minimal mode for larger ones.
+func VerifyMinimality(ops []DiffOp, a, b []Element) MinimalityReport {
+	minimal, exact := minimalEditDistance(a, b)
+	return MinimalityReport{
+		Edits:   Stat(ops).Changes(),
+		Minimal: minimal,
+		Exact:   exact,
+	}
+}
+
+// MinimalEditDistance returns the number of elements deleted plus inserted
+// by a shortest edit script that turns a into b.
+func MinimalEditDistance(a, b []Element) int {
+	d, _ := minimalEditDistance(a, b)
+	return d
+}
+
+// minimalEditDistance computes the minimal edit distance and reports
+// whether it was computed exhaustively.
+func minimalEditDistance(a, b []Element) (int, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) + len(b), true
+	}
+	if int64(len(a))*int64(len(b)) <= exhaustiveLimit {
+		return len(a) + len(b) - 2*lcsLength(a, b), true
+	}
+	ops := DiffElements(a, b,
+		WithMinimal(true),
+		WithPreprocessing(false),
+		WithAnchorElimination(false),
+		WithPostprocessing(false),
+	)
+	return Stat(ops).Changes(), false
+}
+
+// lcsLength returns the length of a longest common subsequence of a and b
+// by dynamic programming, in O(len(a)*len(b)) time and O(len(b)) space.
+func lcsLength(a, b []Element) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i].Equal(b[j]):
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
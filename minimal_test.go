@@ -0,0 +1,100 @@
+package diffx
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMinimalEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "ab", 2},
+		{"abc", "abc", 0},
+		{"abcabba", "cbabac", 5},
+		{"kitten", "sitting", 5},
+	}
+
+	for _, tt := range tests {
+		a := toElements(strings.Split(tt.a, ""))
+		b := toElements(strings.Split(tt.b, ""))
+		if tt.a == "" {
+			a = nil
+		}
+		if tt.b == "" {
+			b = nil
+		}
+		if got := MinimalEditDistance(a, b); got != tt.want {
+			t.Errorf("MinimalEditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMinimalEditDistance_Large(t *testing.T) {
+	// 65536*65536 overflows a 32-bit int to 0, which must not select the
+	// quadratic exhaustive search
+	lines := make([]string, 1<<16)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+	a := toElements(lines)
+	d, exhaustive := minimalEditDistance(a, a)
+	if d != 0 || exhaustive {
+		t.Errorf("minimalEditDistance() = %d, %v, want 0, false", d, exhaustive)
+	}
+}
+
+func TestVerifyMinimality(t *testing.T) {
+	a := toElements([]string{"a", "b", "c"})
+	b := toElements([]string{"a", "x", "c"})
+
+	// Replacing everything is valid but changes four more elements than needed
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 0, BEnd: 3},
+	}
+	r := VerifyMinimality(ops, a, b)
+	want := MinimalityReport{Edits: 6, Minimal: 2, Exact: true}
+	if r != want {
+		t.Fatalf("VerifyMinimality() = %+v, want %+v", r, want)
+	}
+	if r.Excess() != 4 || r.Ratio() != 3 {
+		t.Errorf("Excess() = %d, Ratio() = %v, want 4 and 3", r.Excess(), r.Ratio())
+	}
+
+	if r := VerifyMinimality(nil, nil, nil); r.Excess() != 0 || r.Ratio() != 1 {
+		t.Errorf("empty inputs: Excess() = %d, Ratio() = %v", r.Excess(), r.Ratio())
+	}
+}
+
+// TestMinimalMode_MatchesExhaustive checks that Myers in minimal mode,
+// which VerifyMinimality relies on for large inputs, agrees with the
+// exhaustive computation.
+func TestMinimalMode_MatchesExhaustive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []Element {
+		elems := make([]Element, rng.Intn(40))
+		for i := range elems {
+			elems[i] = StringElement(string(rune('a' + rng.Intn(4))))
+		}
+		return elems
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := random(), random()
+		ops := DiffElements(a, b,
+			WithMinimal(true),
+			WithPreprocessing(false),
+			WithAnchorElimination(false),
+			WithPostprocessing(false),
+		)
+		if got, want := Stat(ops).Changes(), len(a)+len(b)-2*lcsLength(a, b); got != want {
+			t.Fatalf("minimal mode changed %d elements, want %d\na: %v\nb: %v", got, want, a, b)
+		}
+	}
+}
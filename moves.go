@@ -0,0 +1,198 @@
+package diffx
+
+// Moved-section detection.
+//
+// When a section of a structured document is moved, a line diff reports it
+// as a large deletion in one place and a large insertion in another, even if
+// only a few lines inside it changed. DetectMovedSections pairs sections by
+// heading, finds the ones whose relative order changed, and diffs each moved
+// section's contents against its original so the change can be reported as
+// "section moved + N internal edits".
+
+// SectionMove describes a section that changed position between documents.
+type SectionMove struct {
+	Heading string   // heading text shared by both sections
+	A       Section  // the section in document A
+	B       Section  // the section in document B
+	Ops     []DiffOp // diff of the section contents, indexed into the full documents
+	Edits   int      // number of change regions inside the section
+}
+
+// MoveOption configures DetectCopies, PairChanges, and DetectMovedSections.
+type MoveOption func(*moveOptions)
+
+// moveOptions configures move and copy detection.
+type moveOptions struct {
+	minBlockLen   int     // minimum block length; 0 uses each detector's default
+	minSimilarity float64 // minimum content similarity; 0 uses each detector's default
+}
+
+// newMoveOptions returns the move detection options set by opts.
+func newMoveOptions(opts []MoveOption) *moveOptions {
+	m := &moveOptions{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// blockLen returns the configured minimum block length, or def if unset.
+func (m *moveOptions) blockLen(def int) int {
+	if m.minBlockLen > 0 {
+		return m.minBlockLen
+	}
+	return def
+}
+
+// similarity returns the configured similarity floor, or def if unset.
+func (m *moveOptions) similarity(def float64) float64 {
+	if m.minSimilarity > 0 {
+		return m.minSimilarity
+	}
+	return def
+}
+
+// DetectMovedSections finds sections of a that appear in b at a different
+// position relative to the other sections. Sections are split with split
+// (for example MarkdownSections) and paired by heading level and text;
+// headings that are not unique in both documents are not paired.
+//
+// The largest set of paired sections that kept their relative order is
+// treated as stationary; every other paired section is reported as moved,
+// in the order it appears in b. A moved section is only reported if both
+// copies have at least the number of lines set with WithMinBlockLen and
+// their contents reach the similarity set with WithMinSimilarity; by
+// default every moved section is reported.
+func DetectMovedSections(a, b []string, split SectionSplitter, opts ...MoveOption) []SectionMove {
+	m := newMoveOptions(opts)
+	minLen := m.blockLen(1)
+	minSim := m.similarity(0)
+
+	sectionsA, sectionsB := split(a), split(b)
+	secA := uniqueSections(sectionsA)
+	secB := uniqueSections(sectionsB)
+
+	// Keep only headings present in both documents, in document order
+	paired := func(s Section) bool {
+		_, inA := secA[sectionKey(s)]
+		_, inB := secB[sectionKey(s)]
+		return inA && inB
+	}
+	var keysA, keysB []string
+	for _, s := range sectionsA {
+		if paired(s) {
+			keysA = append(keysA, sectionKey(s))
+		}
+	}
+	for _, s := range sectionsB {
+		if paired(s) {
+			keysB = append(keysB, sectionKey(s))
+		}
+	}
+
+	// Sections outside the longest common ordering have moved
+	stationary := make(map[string]bool)
+	for _, op := range Diff(keysA, keysB, WithMinimal(true), WithPreprocessing(false), WithPostprocessing(false)) {
+		if op.Type == Equal {
+			for _, key := range keysA[op.AStart:op.AEnd] {
+				stationary[key] = true
+			}
+		}
+	}
+
+	var moves []SectionMove
+	for _, key := range keysB {
+		if stationary[key] {
+			continue
+		}
+
+		sa, sb := secA[key], secB[key]
+		if sa.End-sa.Start < minLen || sb.End-sb.Start < minLen {
+			continue
+		}
+		ops := Diff(a[sa.Start:sa.End], b[sb.Start:sb.End])
+		if minSim > 0 && similarityOf(ops, sa.End-sa.Start, sb.End-sb.Start) < minSim {
+			continue
+		}
+		OffsetOps(ops, sa.Start, sb.Start)
+
+		moves = append(moves, SectionMove{
+			Heading: sb.Heading,
+			A:       sa,
+			B:       sb,
+			Ops:     ops,
+			Edits:   countChangeRegions(ops),
+		})
+	}
+
+	return moves
+}
+
+// uniqueSections indexes headed sections by key, dropping the preamble and
+// any key that occurs more than once.
+func uniqueSections(sections []Section) map[string]Section {
+	byKey := make(map[string]Section, len(sections))
+	dup := make(map[string]bool)
+	for _, s := range sections {
+		if s.Level == 0 {
+			continue
+		}
+		key := sectionKey(s)
+		if _, ok := byKey[key]; ok {
+			dup[key] = true
+		}
+		byKey[key] = s
+	}
+	for key := range dup {
+		delete(byKey, key)
+	}
+	return byKey
+}
+
+// sectionKey identifies a section by heading level and text.
+func sectionKey(s Section) string {
+	return string(rune('0'+s.Level)) + "\x00" + s.Heading
+}
+
+// countChangeRegions returns the number of runs of consecutive change
+// operations in ops.
+func countChangeRegions(ops []DiffOp) int {
+	regions := 0
+	inChange := false
+	for _, op := range ops {
+		if op.Type == Equal {
+			inChange = false
+		} else if !inChange {
+			regions++
+			inChange = true
+		}
+	}
+	return regions
+}
+
+// WithMinBlockLen sets the minimum number of elements in a block reported by
+// DetectCopies, PairChanges, and DetectMovedSections, so that short common
+// phrases are not reported as moves or copies. Values less than 1 restore
+// the default.
+// Default: 3 for DetectCopies, 1 otherwise.
+func WithMinBlockLen(n int) MoveOption {
+	if n < 1 {
+		n = 0
+	}
+	return func(m *moveOptions) {
+		m.minBlockLen = n
+	}
+}
+
+// WithMinSimilarity sets the similarity floor, in [0, 1], below which
+// PairChanges does not pair regions and DetectMovedSections does not report
+// a moved section. Values of zero or less restore the default.
+// Default: 0.5 for PairChanges, no floor for DetectMovedSections.
+func WithMinSimilarity(f float64) MoveOption {
+	if f < 0 {
+		f = 0
+	}
+	return func(m *moveOptions) {
+		m.minSimilarity = f
+	}
+}
@@ -0,0 +1,93 @@
+package diffx
+
+import "testing"
+
+func TestDetectMovedSections(t *testing.T) {
+	a := []string{
+		"# One", "alpha", "beta",
+		"# Two", "gamma", "delta", "epsilon",
+		"# Three", "zeta",
+	}
+	b := []string{
+		"# One", "alpha", "beta",
+		"# Three", "zeta",
+		"# Two", "gamma", "DELTA", "epsilon",
+	}
+
+	moves := DetectMovedSections(a, b, MarkdownSections)
+	if len(moves) != 1 {
+		t.Fatalf("expected 1 move, got %d: %+v", len(moves), moves)
+	}
+
+	m := moves[0]
+	if m.Heading != "Two" && m.Heading != "Three" {
+		t.Errorf("unexpected moved section %q", m.Heading)
+	}
+	if m.Heading == "Two" && m.Edits != 1 {
+		t.Errorf("Edits = %d, want 1", m.Edits)
+	}
+
+	// Ops index into the full documents
+	for _, op := range m.Ops {
+		if op.AStart < m.A.Start || op.AEnd > m.A.End || op.BStart < m.B.Start || op.BEnd > m.B.End {
+			t.Errorf("op %v outside sections %+v / %+v", op, m.A, m.B)
+		}
+	}
+}
+
+func TestDetectMovedSections_NoMoves(t *testing.T) {
+	a := []string{"# One", "x", "# Two", "y"}
+	b := []string{"# One", "x changed", "# Two", "y"}
+
+	if moves := DetectMovedSections(a, b, MarkdownSections); len(moves) != 0 {
+		t.Errorf("expected no moves, got %+v", moves)
+	}
+}
+
+func TestDetectMovedSections_UnchangedContent(t *testing.T) {
+	a := []string{"# A", "1", "# B", "2", "# C", "3"}
+	b := []string{"# C", "3", "# A", "1", "# B", "2"}
+
+	moves := DetectMovedSections(a, b, MarkdownSections)
+	if len(moves) != 1 || moves[0].Heading != "C" {
+		t.Fatalf("expected section C to move, got %+v", moves)
+	}
+	if moves[0].Edits != 0 {
+		t.Errorf("Edits = %d, want 0", moves[0].Edits)
+	}
+}
+
+func TestDetectMovedSections_DuplicateHeadingsIgnored(t *testing.T) {
+	a := []string{"# Notes", "1", "# Other", "x", "# Notes", "2"}
+	b := []string{"# Other", "x", "# Notes", "1", "# Notes", "2"}
+
+	for _, m := range DetectMovedSections(a, b, MarkdownSections) {
+		if m.Heading == "Notes" {
+			t.Errorf("duplicate heading should not be paired: %+v", m)
+		}
+	}
+}
+
+func TestCountChangeRegions(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Delete}, {Type: Insert}, {Type: Equal}, {Type: Insert}, {Type: Equal},
+	}
+	if got := countChangeRegions(ops); got != 2 {
+		t.Errorf("countChangeRegions() = %d, want 2", got)
+	}
+}
+
+func TestDetectMovedSections_Options(t *testing.T) {
+	a := []string{"# A", "one", "two", "three", "# B", "x", "# C", "y"}
+	b := []string{"# B", "x", "# C", "y", "# A", "uno", "dos", "tres"}
+
+	if moves := DetectMovedSections(a, b, MarkdownSections); len(moves) != 1 {
+		t.Fatalf("default: expected 1 move, got %+v", moves)
+	}
+	if moves := DetectMovedSections(a, b, MarkdownSections, WithMinSimilarity(0.5)); len(moves) != 0 {
+		t.Errorf("WithMinSimilarity(0.5): expected rewritten section to be skipped, got %+v", moves)
+	}
+	if moves := DetectMovedSections(a, b, MarkdownSections, WithMinBlockLen(5)); len(moves) != 0 {
+		t.Errorf("WithMinBlockLen(5): expected short section to be skipped, got %+v", moves)
+	}
+}
@@ -0,0 +1,389 @@
+// Package nbdiff compares Jupyter notebooks (.ipynb files) cell by cell.
+//
+// Notebooks are JSON documents, and a textual diff of two versions mixes
+// source edits with execution counts, re-rendered outputs, and base64
+// image data. nbdiff aligns the cells of two notebooks, pairing edited
+// cells by content similarity, and diffs each cell's source and outputs
+// separately. Execution counts and binary output data can be ignored.
+package nbdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// minCellSimilarity is the minimum source similarity for an edited cell to
+// be paired with its original.
+const minCellSimilarity = 0.5
+
+// ChangeType identifies how a cell changed. It is an alias of
+// diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the cell is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the cell is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the cell is present in both with different source,
+	// outputs, or execution count.
+	Modified = diffx.ChangeModified
+)
+
+// Cell is a notebook cell with its source and outputs as lines of text.
+type Cell struct {
+	Type           string   // "code", "markdown", or "raw"
+	Source         []string // source lines
+	Outputs        []string // outputs rendered as text lines
+	ExecutionCount *int     // nil if the cell was never executed
+}
+
+// CellDiff describes a cell that differs between two notebooks.
+type CellDiff struct {
+	Type ChangeType
+	A, B int // cell index in each notebook, or -1 if absent
+
+	// Source and Outputs are line diffs of the cell's source and rendered
+	// outputs. For added and removed cells they cover the whole cell.
+	Source  []diffx.DiffOp
+	Outputs []diffx.DiffOp
+
+	ExecutionCountChanged bool
+}
+
+// Option configures the comparison.
+type Option func(*options)
+
+type options struct {
+	ignoreExecutionCounts bool
+	ignoreOutputBlobs     bool
+	ignoreOutputs         bool
+}
+
+// WithIgnoreExecutionCounts ignores execution counts, which change every
+// time a notebook is re-run. Default: false.
+func WithIgnoreExecutionCounts(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreExecutionCounts = enabled
+	}
+}
+
+// WithIgnoreOutputBlobs ignores non-text output data such as images.
+// Otherwise each blob is rendered as a line giving its MIME type, size, and
+// hash. It applies to notebooks parsed by Diff. Default: false.
+func WithIgnoreOutputBlobs(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOutputBlobs = enabled
+	}
+}
+
+// WithIgnoreOutputs ignores cell outputs entirely. Default: false.
+func WithIgnoreOutputs(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOutputs = enabled
+	}
+}
+
+// Diff parses two notebooks and returns the cells that differ, in order.
+// Unchanged cells are not reported.
+func Diff(a, b []byte, opts ...Option) ([]CellDiff, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ca, err := parse(a, o)
+	if err != nil {
+		return nil, fmt.Errorf("nbdiff: notebook A: %w", err)
+	}
+	cb, err := parse(b, o)
+	if err != nil {
+		return nil, fmt.Errorf("nbdiff: notebook B: %w", err)
+	}
+	return DiffCells(ca, cb, opts...), nil
+}
+
+// Parse parses a notebook into cells. Outputs are rendered as text lines;
+// non-text data is summarized by MIME type and size.
+func Parse(data []byte) ([]Cell, error) {
+	return parse(data, &options{})
+}
+
+// DiffCells is like Diff but compares parsed cells.
+func DiffCells(a, b []Cell, opts ...Option) []CellDiff {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ops := diffx.DiffElements(cellKeys(a), cellKeys(b), diffx.WithPostprocessing(false))
+
+	var diffs []CellDiff
+	for k := 0; k < len(ops); {
+		if ops[k].Type == diffx.Equal {
+			for n := 0; n < ops[k].AEnd-ops[k].AStart; n++ {
+				i, j := ops[k].AStart+n, ops[k].BStart+n
+				if d, changed := compareCells(a[i], b[j], i, j, o); changed {
+					diffs = append(diffs, d)
+				}
+			}
+			k++
+			continue
+		}
+
+		aStart, bStart := ops[k].AStart, ops[k].BStart
+		aEnd, bEnd := ops[k].AEnd, ops[k].BEnd
+		for k < len(ops) && ops[k].Type != diffx.Equal {
+			aEnd = max(aEnd, ops[k].AEnd)
+			bEnd = max(bEnd, ops[k].BEnd)
+			k++
+		}
+		diffs = append(diffs, diffRegion(a, b, aStart, aEnd, bStart, bEnd, o)...)
+	}
+	return diffs
+}
+
+// diffRegion reports the cells of a change region, pairing edited cells
+// with their originals by source similarity. Pairs keep their relative
+// order; cells that cannot be paired are reported as removed or added.
+func diffRegion(a, b []Cell, aStart, aEnd, bStart, bEnd int, o *options) []CellDiff {
+	var diffs []CellDiff
+	j := bStart
+	for i := aStart; i < aEnd; i++ {
+		// Find the most similar remaining cell of the same type
+		best, bestSim := -1, minCellSimilarity
+		for jj := j; jj < bEnd; jj++ {
+			if b[jj].Type != a[i].Type {
+				continue
+			}
+			if sim := diffx.Similarity(lines(a[i].Source), lines(b[jj].Source)); sim >= bestSim && (best < 0 || sim > bestSim) {
+				best, bestSim = jj, sim
+			}
+		}
+		if best < 0 {
+			diffs = append(diffs, CellDiff{
+				Type:    Removed,
+				A:       i,
+				B:       -1,
+				Source:  wholeOps(diffx.Delete, len(a[i].Source)),
+				Outputs: wholeOps(diffx.Delete, len(a[i].Outputs)),
+			})
+			continue
+		}
+
+		for ; j < best; j++ {
+			diffs = append(diffs, added(b, j))
+		}
+		d, _ := compareCells(a[i], b[best], i, best, o)
+		diffs = append(diffs, d)
+		j = best + 1
+	}
+	for ; j < bEnd; j++ {
+		diffs = append(diffs, added(b, j))
+	}
+	return diffs
+}
+
+// added returns the CellDiff for cell j of b being added.
+func added(b []Cell, j int) CellDiff {
+	return CellDiff{
+		Type:    Added,
+		A:       -1,
+		B:       j,
+		Source:  wholeOps(diffx.Insert, len(b[j].Source)),
+		Outputs: wholeOps(diffx.Insert, len(b[j].Outputs)),
+	}
+}
+
+// compareCells diffs two paired cells and reports whether they differ.
+func compareCells(a, b Cell, i, j int, o *options) (CellDiff, bool) {
+	d := CellDiff{Type: Modified, A: i, B: j}
+	d.Source = diffx.Diff(a.Source, b.Source)
+	changed := hasChanges(d.Source)
+
+	if !o.ignoreOutputs {
+		d.Outputs = diffx.Diff(a.Outputs, b.Outputs)
+		changed = changed || hasChanges(d.Outputs)
+	}
+	if !o.ignoreExecutionCounts && !sameCount(a.ExecutionCount, b.ExecutionCount) {
+		d.ExecutionCountChanged = true
+		changed = true
+	}
+	return d, changed
+}
+
+// notebook is the subset of the nbformat 4 schema that nbdiff reads.
+type notebook struct {
+	Cells []struct {
+		CellType       string            `json:"cell_type"`
+		Source         multiline         `json:"source"`
+		Outputs        []json.RawMessage `json:"outputs"`
+		ExecutionCount *int              `json:"execution_count"`
+	} `json:"cells"`
+}
+
+// multiline is an nbformat string, stored either as one string or as a
+// list of lines.
+type multiline string
+
+// UnmarshalJSON accepts both forms.
+func (m *multiline) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = multiline(s)
+		return nil
+	}
+	var parts []string
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	*m = multiline(strings.Join(parts, ""))
+	return nil
+}
+
+// output is the subset of an nbformat output that nbdiff renders.
+type output struct {
+	OutputType string               `json:"output_type"`
+	Name       string               `json:"name"`
+	Text       multiline            `json:"text"`
+	Data       map[string]multiline `json:"data"`
+	EName      string               `json:"ename"`
+	EValue     string               `json:"evalue"`
+}
+
+// parse decodes a notebook and renders its cells.
+func parse(data []byte, o *options) ([]Cell, error) {
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, err
+	}
+
+	cells := make([]Cell, len(nb.Cells))
+	for i, c := range nb.Cells {
+		cells[i] = Cell{
+			Type:           c.CellType,
+			Source:         splitLines(string(c.Source)),
+			ExecutionCount: c.ExecutionCount,
+		}
+		for _, raw := range c.Outputs {
+			var out output
+			if err := json.Unmarshal(raw, &out); err != nil {
+				return nil, fmt.Errorf("cell %d: %w", i, err)
+			}
+			cells[i].Outputs = append(cells[i].Outputs, renderOutput(out, o)...)
+		}
+	}
+	return cells, nil
+}
+
+// renderOutput renders an output as text lines.
+func renderOutput(out output, o *options) []string {
+	switch out.OutputType {
+	case "stream":
+		return splitLines(string(out.Text))
+	case "error":
+		return []string{out.EName + ": " + out.EValue}
+	}
+
+	// execute_result and display_data: text/plain, then other MIME types
+	var rendered []string
+	if text, ok := out.Data["text/plain"]; ok {
+		rendered = append(rendered, splitLines(string(text))...)
+	}
+	if o.ignoreOutputBlobs {
+		return rendered
+	}
+	mimes := make([]string, 0, len(out.Data))
+	for mime := range out.Data {
+		if mime != "text/plain" {
+			mimes = append(mimes, mime)
+		}
+	}
+	sort.Strings(mimes)
+	for _, mime := range mimes {
+		rendered = append(rendered, fmt.Sprintf("[%s, %d bytes, %016x]", mime, len(out.Data[mime]), hashString(string(out.Data[mime]))))
+	}
+	return rendered
+}
+
+// cellKey is a diffx.Element identifying a cell by type and source.
+type cellKey string
+
+// Equal reports whether other has the same type and source.
+func (k cellKey) Equal(other diffx.Element) bool {
+	o, ok := other.(cellKey)
+	return ok && k == o
+}
+
+// Hash returns a FNV-1a hash of the key.
+func (k cellKey) Hash() uint64 {
+	return hashString(string(k))
+}
+
+// cellKeys returns the alignment key of each cell.
+func cellKeys(cells []Cell) []diffx.Element {
+	elems := make([]diffx.Element, len(cells))
+	for i, c := range cells {
+		elems[i] = cellKey(c.Type + "\x00" + strings.Join(c.Source, "\n"))
+	}
+	return elems
+}
+
+// hashString returns a FNV-1a hash of s.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// splitLines splits text into lines without their terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lines converts strings to diffx elements.
+func lines(s []string) []diffx.Element {
+	elems := make([]diffx.Element, len(s))
+	for i, line := range s {
+		elems[i] = diffx.StringElement(line)
+	}
+	return elems
+}
+
+// wholeOps returns a single operation covering n lines, or nil if n is 0.
+func wholeOps(t diffx.OpType, n int) []diffx.DiffOp {
+	switch {
+	case n == 0:
+		return nil
+	case t == diffx.Delete:
+		return []diffx.DiffOp{{Type: t, AStart: 0, AEnd: n}}
+	default:
+		return []diffx.DiffOp{{Type: t, BStart: 0, BEnd: n}}
+	}
+}
+
+// hasChanges reports whether ops contains a change.
+func hasChanges(ops []diffx.DiffOp) bool {
+	for _, op := range ops {
+		if op.Type != diffx.Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// sameCount reports whether two execution counts are equal.
+func sameCount(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
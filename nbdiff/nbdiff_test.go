@@ -0,0 +1,106 @@
+package nbdiff
+
+import (
+	"testing"
+)
+
+const notebookA = `{
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Analysis\n", "Load the data."]},
+    {"cell_type": "code", "execution_count": 1,
+     "source": ["import pandas as pd\n", "df = pd.read_csv('data.csv')\n", "df.head()"],
+     "outputs": [{"output_type": "execute_result", "execution_count": 1,
+                  "data": {"text/plain": ["   a  b\n", "0  1  2"]}}]},
+    {"cell_type": "code", "execution_count": 2, "source": "df.plot()",
+     "outputs": [{"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo="}}]}
+  ],
+  "metadata": {}, "nbformat": 4, "nbformat_minor": 5
+}`
+
+const notebookB = `{
+  "cells": [
+    {"cell_type": "markdown", "source": "# Analysis\nLoad the data."},
+    {"cell_type": "code", "execution_count": 5,
+     "source": ["import pandas as pd\n", "df = pd.read_csv('data.csv')\n", "df.head(10)"],
+     "outputs": [{"output_type": "execute_result", "execution_count": 5,
+                  "data": {"text/plain": ["   a  b\n", "0  1  2"]}}]},
+    {"cell_type": "code", "execution_count": 6, "source": "df.plot()",
+     "outputs": [{"output_type": "display_data", "data": {"image/png": "AAAAAAAAAAA="}}]},
+    {"cell_type": "markdown", "source": "Done."}
+  ],
+  "metadata": {}, "nbformat": 4, "nbformat_minor": 5
+}`
+
+func TestDiff(t *testing.T) {
+	diffs, err := Diff([]byte(notebookA), []byte(notebookB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 cell diffs, got %+v", diffs)
+	}
+
+	// The edited code cell is paired with its original
+	edited := diffs[0]
+	if edited.Type != Modified || edited.A != 1 || edited.B != 1 {
+		t.Errorf("diffs[0] = %+v, want cell 1 modified", edited)
+	}
+	if !hasChanges(edited.Source) || hasChanges(edited.Outputs) || !edited.ExecutionCountChanged {
+		t.Errorf("diffs[0] = %+v, want source and execution count changes only", edited)
+	}
+
+	// Same source, new image
+	if d := diffs[1]; d.Type != Modified || hasChanges(d.Source) || !hasChanges(d.Outputs) {
+		t.Errorf("diffs[1] = %+v, want output change only", d)
+	}
+
+	if d := diffs[2]; d.Type != Added || d.B != 3 {
+		t.Errorf("diffs[2] = %+v, want cell 3 added", d)
+	}
+}
+
+func TestDiff_IgnoreOptions(t *testing.T) {
+	diffs, err := Diff([]byte(notebookA), []byte(notebookB),
+		WithIgnoreExecutionCounts(true), WithIgnoreOutputBlobs(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 cell diffs, got %+v", diffs)
+	}
+	if diffs[0].ExecutionCountChanged {
+		t.Error("execution count should be ignored")
+	}
+	if diffs[1].Type != Added {
+		t.Errorf("diffs[1] = %+v, want added cell", diffs[1])
+	}
+}
+
+func TestDiffCells_Unpaired(t *testing.T) {
+	a := []Cell{{Type: "code", Source: []string{"x = 1"}}}
+	b := []Cell{{Type: "markdown", Source: []string{"x = 1"}}}
+
+	diffs := DiffCells(a, b)
+	if len(diffs) != 2 || diffs[0].Type != Removed || diffs[1].Type != Added {
+		t.Errorf("DiffCells() = %+v, want removed then added", diffs)
+	}
+}
+
+func TestParse(t *testing.T) {
+	cells, err := Parse([]byte(notebookA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 3 {
+		t.Fatalf("expected 3 cells, got %d", len(cells))
+	}
+	if got := cells[0].Source; len(got) != 2 || got[1] != "Load the data." {
+		t.Errorf("markdown source = %q", got)
+	}
+	if got := cells[1].Outputs; len(got) != 2 || got[1] != "0  1  2" {
+		t.Errorf("outputs = %q", got)
+	}
+	if _, err := Parse([]byte("not json")); err == nil {
+		t.Error("expected error for invalid notebook")
+	}
+}
@@ -0,0 +1,76 @@
+package diffx
+
+// Normalize returns the canonical form of an edit script for sequences a
+// and b. Two scripts that describe the same alignment up to sliding of
+// ambiguous change regions normalize to identical slices, which makes the
+// result suitable for caching and golden tests.
+//
+// The canonical form:
+//   - contains no empty operations
+//   - merges adjacent operations of the same type
+//   - represents each change region as a Delete followed by an Insert
+//   - slides every change region as far toward the end as the repeated
+//     elements around it allow
+//
+// Normalize does not modify ops. It assumes ops is a valid script for a
+// and b, such as one returned by Diff or DiffElements.
+func Normalize(ops []DiffOp, a, b []Element) []DiffOp {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	result := make([]DiffOp, len(ops))
+	copy(result, ops)
+	result = coalesceChanges(dropEmptyOps(result))
+	if len(result) == 0 {
+		return nil
+	}
+
+	for {
+		// A change at the start can only slide by growing a leading Equal
+		result = padWithEqual(result)
+
+		moved := false
+		for i := 0; i < len(result); i++ {
+			if result[i].Type == Equal {
+				continue
+			}
+
+			// A change region is one Delete and/or one Insert
+			j := i + 1
+			if j < len(result) && result[j].Type != Equal {
+				j++
+			}
+
+			if j < len(result) {
+				if down := slideDownLimit(result, i, j, a, b); down > 0 {
+					slideRun(result, i, j, down)
+					moved = true
+				}
+			}
+			i = j - 1
+		}
+
+		// Sliding may have emptied an Equal region and joined two changes
+		result = coalesceChanges(dropEmptyOps(result))
+		if !moved {
+			return result
+		}
+	}
+}
+
+// slideDownLimit returns how far the change region ops[i:j] can slide
+// toward the end into the Equal region ops[j].
+func slideDownLimit(ops []DiffOp, i, j int, a, b []Element) int {
+	aStart, aEnd := ops[i].AStart, ops[j-1].AEnd
+	bStart, bEnd := ops[i].BStart, ops[j-1].BEnd
+	limit := ops[j].AEnd - ops[j].AStart
+
+	down := 0
+	for down < limit &&
+		(aEnd == aStart || a[aStart+down].Equal(a[aEnd+down])) &&
+		(bEnd == bStart || b[bStart+down].Equal(b[bEnd+down])) {
+		down++
+	}
+	return down
+}
@@ -0,0 +1,133 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize_Empty(t *testing.T) {
+	if got := Normalize(nil, nil, nil); got != nil {
+		t.Errorf("Normalize(nil) = %v, want nil", got)
+	}
+}
+
+func TestNormalize_EquivalentScripts(t *testing.T) {
+	// Deleting either "y" produces the same result
+	a := toElements([]string{"x", "y", "y", "z"})
+	b := toElements([]string{"x", "y", "z"})
+
+	first := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Equal, AStart: 2, AEnd: 4, BStart: 1, BEnd: 3},
+	}
+	second := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 2, BEnd: 3},
+	}
+
+	n1 := Normalize(first, a, b)
+	n2 := Normalize(second, a, b)
+	if !reflect.DeepEqual(n1, n2) {
+		t.Errorf("normalized scripts differ:\n%v\n%v", n1, n2)
+	}
+	if !reflect.DeepEqual(n1, second) {
+		t.Errorf("Normalize() = %v, want %v", n1, second)
+	}
+}
+
+func TestNormalize_OrdersDeleteBeforeInsert(t *testing.T) {
+	a := toElements([]string{"a", "b", "c"})
+	b := toElements([]string{"a", "x", "c"})
+
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 1, BEnd: 2},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 2, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	got := Normalize(ops, a, b)
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalize_MergesAndDropsEmpty(t *testing.T) {
+	a := toElements([]string{"a", "b", "c"})
+	b := toElements([]string{"a", "b", "c"})
+
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 1, BStart: 1, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	got := Normalize(ops, a, b)
+	want := []DiffOp{{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalize_SlidesReplacePair(t *testing.T) {
+	a := toElements([]string{"p", "x", "p", "q"})
+	b := toElements([]string{"p", "y", "p", "q"})
+
+	// Replace "p x" with "p y", equivalent to replacing "x p" with "y p"
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 4, BStart: 2, BEnd: 4},
+	}
+
+	got := Normalize(ops, a, b)
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 3},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 3, BEnd: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalize_Idempotent(t *testing.T) {
+	a := []string{"a", "b", "b", "c", "", "d", "", "e"}
+	b := []string{"a", "b", "c", "", "x", "", "d", "", "e"}
+	ea, eb := toElements(a), toElements(b)
+
+	ops := Normalize(Diff(a, b), ea, eb)
+	if got := applyDiff(a, b, ops); !reflect.DeepEqual(got, b) {
+		t.Fatalf("applying normalized diff = %v, want %v", got, b)
+	}
+	if again := Normalize(ops, ea, eb); !reflect.DeepEqual(again, ops) {
+		t.Errorf("Normalize is not idempotent:\n%v\n%v", ops, again)
+	}
+}
+
+func TestNormalize_DoesNotModifyInput(t *testing.T) {
+	a := toElements([]string{"x", "y", "y"})
+	b := toElements([]string{"x", "y"})
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 1, BEnd: 2},
+	}
+	orig := make([]DiffOp, len(ops))
+	copy(orig, ops)
+
+	Normalize(ops, a, b)
+	if !reflect.DeepEqual(ops, orig) {
+		t.Errorf("input modified: %v", ops)
+	}
+}
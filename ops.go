@@ -0,0 +1,141 @@
+package diffx
+
+// Edit script utilities.
+//
+// These helpers work on the []DiffOp returned by every diff function, so
+// that callers do not have to walk the operations themselves for common
+// questions about a diff.
+
+// Materialize splits the elements of a diff of a and b by operation: kept
+// holds the unchanged elements, taken from a, deleted the elements
+// deleted from a, and inserted the elements inserted from b, each in
+// order. ops must be a diff of a and b. When options such as
+// WithIgnoreCase made different elements compare equal, kept shows them
+// as they are in a.
+func Materialize[T any](ops []DiffOp, a, b []T) (kept, deleted, inserted []T) {
+	for _, op := range ops {
+		switch op.Type {
+		case Equal:
+			kept = append(kept, SliceA(op, a)...)
+		case Delete:
+			deleted = append(deleted, SliceA(op, a)...)
+		case Insert:
+			inserted = append(inserted, SliceB(op, b)...)
+		}
+	}
+	return kept, deleted, inserted
+}
+
+// SubDiff returns the part of ops that covers a[aStart:aEnd], for showing
+// one section of a large diff at a time. Operations that straddle the
+// range are cut at its bounds, and the result keeps the indices of ops,
+// so it indexes the original sequences and covers a contiguous range of
+// b. An insertion that replaces deleted elements belongs to the range
+// holding the last of them; any other insertion belongs to the range
+// holding the element it is inserted before, or to a range that ends at
+// the end of a. The sub-diffs of adjacent ranges put together thus give
+// ops back, with operations cut at the boundaries.
+func SubDiff(ops []DiffOp, aStart, aEnd int) []DiffOp {
+	n := 0 // length of a
+	if len(ops) > 0 {
+		n = ops[len(ops)-1].AEnd
+	}
+	var sub []DiffOp
+	for i, op := range ops {
+		if op.Type == Insert {
+			at := op.AStart // the element the insertion goes with
+			if i > 0 && ops[i-1].Type == Delete {
+				at--
+			}
+			if at >= aStart && (at < aEnd || at == n && aEnd >= n) {
+				sub = append(sub, op)
+			}
+			continue
+		}
+		lo, hi := max(op.AStart, aStart), min(op.AEnd, aEnd)
+		if lo >= hi {
+			continue
+		}
+		cut := DiffOp{Type: op.Type, AStart: lo, AEnd: hi, BStart: op.BStart, BEnd: op.BEnd}
+		if op.Type == Equal {
+			cut.BStart = op.BStart + lo - op.AStart
+			cut.BEnd = cut.BStart + hi - lo
+		}
+		sub = append(sub, cut)
+	}
+	return sub
+}
+
+// OffsetOps shifts the A indices of ops by dA and the B indices by dB, so
+// that a diff of the sub-slices a[dA:] and b[dB:] indexes a and b. It
+// modifies ops in place and returns it.
+func OffsetOps(ops []DiffOp, dA, dB int) []DiffOp {
+	for i := range ops {
+		ops[i].AStart += dA
+		ops[i].AEnd += dA
+		ops[i].BStart += dB
+		ops[i].BEnd += dB
+	}
+	return ops
+}
+
+// ConcatDiffs joins diffs of the pairs (a1, b1), (a2, b2), ... into a
+// diff of the concatenations a1+a2+... and b1+b2+..., so that long inputs
+// can be cut into chunks and the chunks diffed in parallel. Each diff must
+// cover its whole pair. Operations that meet at a seam are merged, and a
+// change that spans a seam becomes one deletion followed by one insertion,
+// as in the result of Diff. The result is a valid diff, but it can be
+// longer than a diff of the whole sequences, since no element is matched
+// across a seam; cutting at elements known to be unchanged avoids that.
+func ConcatDiffs(diffs ...[]DiffOp) []DiffOp {
+	var out []DiffOp
+	da, db := 0, 0 // lengths of the sequences joined so far
+	for _, ops := range diffs {
+		for _, op := range ops {
+			if op.AStart == op.AEnd && op.BStart == op.BEnd {
+				continue
+			}
+			op.AStart += da
+			op.AEnd += da
+			op.BStart += db
+			op.BEnd += db
+			out = appendOp(out, op)
+		}
+		if n := len(ops); n > 0 {
+			da += ops[n-1].AEnd
+			db += ops[n-1].BEnd
+		}
+	}
+	return out
+}
+
+// appendOp appends op to ops, which it must directly follow, merging it
+// into the Equal or change region that ops ends with.
+func appendOp(ops []DiffOp, op DiffOp) []DiffOp {
+	n := len(ops)
+	if op.Type == Equal {
+		if n > 0 && ops[n-1].Type == Equal {
+			ops[n-1].AEnd, ops[n-1].BEnd = op.AEnd, op.BEnd
+			return ops
+		}
+		return append(ops, op)
+	}
+
+	// Rebuild the change region as a deletion and an insertion
+	r := n
+	for r > 0 && ops[r-1].Type != Equal {
+		r--
+	}
+	aStart, bStart := op.AStart, op.BStart
+	if r < n {
+		aStart, bStart = ops[r].AStart, ops[r].BStart
+	}
+	ops = ops[:r]
+	if op.AEnd > aStart {
+		ops = append(ops, DiffOp{Type: Delete, AStart: aStart, AEnd: op.AEnd, BStart: bStart, BEnd: bStart})
+	}
+	if op.BEnd > bStart {
+		ops = append(ops, DiffOp{Type: Insert, AStart: op.AEnd, AEnd: op.AEnd, BStart: bStart, BEnd: op.BEnd})
+	}
+	return ops
+}
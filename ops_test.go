@@ -0,0 +1,160 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaterialize(t *testing.T) {
+	a := strings.Fields("the quick brown fox jumps")
+	b := strings.Fields("the slow brown fox leaps high")
+
+	kept, deleted, inserted := Materialize(Diff(a, b), a, b)
+	if want := []string{"the", "brown", "fox"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %q, want %q", kept, want)
+	}
+	if want := []string{"quick", "jumps"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted = %q, want %q", deleted, want)
+	}
+	if want := []string{"slow", "leaps", "high"}; !reflect.DeepEqual(inserted, want) {
+		t.Errorf("inserted = %q, want %q", inserted, want)
+	}
+}
+
+func TestMaterialize_Elements(t *testing.T) {
+	a := []Element{StringElement("A"), StringElement("b")}
+	b := []Element{StringElement("a"), StringElement("c")}
+
+	// Unchanged elements are taken from A
+	kept, deleted, inserted := Materialize(Diff([]string{"A", "b"}, []string{"a", "c"}, WithIgnoreCase(true)), a, b)
+	if len(kept) != 1 || kept[0] != StringElement("A") {
+		t.Errorf("kept = %v, want [A]", kept)
+	}
+	if len(deleted) != 1 || len(inserted) != 1 {
+		t.Errorf("deleted = %v, inserted = %v", deleted, inserted)
+	}
+
+	k, d, i := Materialize[int](nil, nil, nil)
+	if k != nil || d != nil || i != nil {
+		t.Errorf("empty diff: %v %v %v, want nil", k, d, i)
+	}
+}
+
+func TestSubDiff(t *testing.T) {
+	a := strings.Split("a b c d e f g h", " ")
+	b := strings.Split("a x c d e y g h i", " ")
+	ops := Diff(a, b)
+
+	// The replacement of b by x stays with the deletion
+	got := SubDiff(ops, 1, 6)
+	want := []DiffOp{
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 5, BStart: 2, BEnd: 5},
+		{Type: Delete, AStart: 5, AEnd: 6, BStart: 5, BEnd: 5},
+		{Type: Insert, AStart: 6, AEnd: 6, BStart: 5, BEnd: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SubDiff(1, 6) = %v\nwant %v", got, want)
+	}
+
+	// The insertion at the end of A belongs to the last range only
+	if got := SubDiff(ops, 6, 8); Stat(got).Insertions != 1 {
+		t.Errorf("SubDiff(6, 8) = %v, want the insertion of i", got)
+	}
+	if got := SubDiff(ops, 8, 8); len(got) != 1 || got[0].Type != Insert {
+		t.Errorf("SubDiff(8, 8) = %v, want the insertion of i", got)
+	}
+	if got := SubDiff(ops, 3, 3); got != nil {
+		t.Errorf("SubDiff(3, 3) = %v, want nil", got)
+	}
+}
+
+func TestSubDiff_Partition(t *testing.T) {
+	a := strings.Split("p q r s t u v w x y z", " ")
+	b := strings.Split("new p r S t u extra v w z end", " ")
+	ops := Diff(a, b)
+
+	// Sections that cover A cover B once, in order, and are valid diffs
+	var stitched []string
+	for start := 0; start < len(a); start += 3 {
+		sub := SubDiff(ops, start, min(start+3, len(a)))
+		for i, op := range sub {
+			if i > 0 && (op.AStart != sub[i-1].AEnd || op.BStart != sub[i-1].BEnd) {
+				t.Fatalf("section %d: ops not contiguous: %v", start, sub)
+			}
+			switch op.Type {
+			case Equal:
+				if !reflect.DeepEqual(a[op.AStart:op.AEnd], b[op.BStart:op.BEnd]) {
+					t.Fatalf("section %d: %v is not equal", start, op)
+				}
+				stitched = append(stitched, b[op.BStart:op.BEnd]...)
+			case Insert:
+				stitched = append(stitched, b[op.BStart:op.BEnd]...)
+			}
+		}
+	}
+	if !reflect.DeepEqual(stitched, b) {
+		t.Errorf("sections give B = %q, want %q", stitched, b)
+	}
+}
+
+func TestOffsetOps(t *testing.T) {
+	a := strings.Split("header a b c footer", " ")
+	b := strings.Split("header a B c d footer", " ")
+
+	// Diff the middle and express the result in the indices of the whole
+	ops := OffsetOps(Diff(a[1:4], b[1:5]), 1, 1)
+	for _, op := range ops {
+		if op.Type == Equal && !reflect.DeepEqual(a[op.AStart:op.AEnd], b[op.BStart:op.BEnd]) {
+			t.Errorf("%v is not equal in the whole sequences", op)
+		}
+	}
+	if ops[0].AStart != 1 || ops[len(ops)-1].AEnd != 4 || ops[len(ops)-1].BEnd != 5 {
+		t.Errorf("OffsetOps() = %v, want A 1-4 and B 1-5", ops)
+	}
+
+	// Negative offsets undo the shift
+	back := OffsetOps(ops, -1, -1)
+	if !reflect.DeepEqual(back, Diff(a[1:4], b[1:5])) {
+		t.Errorf("OffsetOps(-1, -1) = %v", back)
+	}
+}
+
+func TestConcatDiffs(t *testing.T) {
+	a1, b1 := strings.Fields("a b c"), strings.Fields("a b x")
+	a2, b2 := strings.Fields("d e f"), strings.Fields("y e f")
+	a3, b3 := strings.Fields("g"), strings.Fields("g h")
+
+	got := ConcatDiffs(Diff(a1, b1), Diff(a2, b2), nil, Diff(a3, b3))
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 4, AEnd: 4, BStart: 2, BEnd: 4},
+		{Type: Equal, AStart: 4, AEnd: 7, BStart: 4, BEnd: 7},
+		{Type: Insert, AStart: 7, AEnd: 7, BStart: 7, BEnd: 8},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConcatDiffs() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestConcatDiffs_Valid(t *testing.T) {
+	a := strings.Fields("p q r s t u v w x y z")
+	b := strings.Fields("p Q r s T u v x y new z")
+
+	for cut := 0; cut <= len(a); cut++ {
+		cutB := min(cut, len(b))
+		ops := ConcatDiffs(Diff(a[:cut], b[:cutB]), Diff(a[cut:], b[cutB:]))
+		checkScript(t, a, b, ops)
+		for k := 1; k < len(ops); k++ {
+			if ops[k].Type == ops[k-1].Type || ops[k].Type == Delete && ops[k-1].Type == Insert {
+				t.Fatalf("cut %d: seam not merged: %v", cut, ops)
+			}
+		}
+	}
+	if got := ConcatDiffs(); got != nil {
+		t.Errorf("ConcatDiffs() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,143 @@
+package diffx
+
+import (
+	"sort"
+	"strings"
+)
+
+// Similarity-based pairing of deleted and inserted regions.
+//
+// An edit script only says that some elements were removed and others were
+// added. When a paragraph is rewritten and also moved, or when unrelated
+// edits separate a deletion from its replacement, renderers have no way to
+// show "this paragraph became that paragraph". PairChanges matches deleted
+// regions with inserted regions by content similarity so that they can be
+// presented as modifications of each other.
+
+// minPairSimilarity is the default minimum similarity for two regions to
+// be paired.
+const minPairSimilarity = 0.5
+
+// ChangePair links a deleted region of A with an inserted region of B that
+// has similar content.
+type ChangePair struct {
+	DeleteOp   int     // index of the Delete operation in the script
+	InsertOp   int     // index of the Insert operation in the script
+	Similarity float64 // content similarity in [0, 1]
+	Adjacent   bool    // the Insert directly follows the Delete
+}
+
+// PairChanges pairs Delete and Insert operations of ops whose content is
+// similar. Each operation is used in at most one pair. Candidates are
+// chosen greedily from the most similar down to a similarity of 0.5 (see
+// WithMinSimilarity); ties prefer earlier operations. Regions shorter than
+// the minimum block length set with WithMinBlockLen are not paired. The
+// result is sorted by DeleteOp.
+//
+// Regions made entirely of StringElements are compared word by word, so
+// that a rewritten line or paragraph is recognized even though no whole
+// element is shared. Other regions are compared element by element.
+func PairChanges(ops []DiffOp, a, b []Element, opts ...MoveOption) []ChangePair {
+	m := newMoveOptions(opts)
+	minLen := m.blockLen(1)
+	minSim := m.similarity(minPairSimilarity)
+
+	var dels, inss []int
+	for i, op := range ops {
+		switch {
+		case op.Type == Delete && op.AEnd-op.AStart >= minLen:
+			dels = append(dels, i)
+		case op.Type == Insert && op.BEnd-op.BStart >= minLen:
+			inss = append(inss, i)
+		}
+	}
+
+	var candidates []ChangePair
+	for _, d := range dels {
+		for _, in := range inss {
+			sim := regionSimilarity(a[ops[d].AStart:ops[d].AEnd], b[ops[in].BStart:ops[in].BEnd])
+			if sim < minSim {
+				continue
+			}
+			candidates = append(candidates, ChangePair{
+				DeleteOp:   d,
+				InsertOp:   in,
+				Similarity: sim,
+				Adjacent:   in == d+1,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+
+	usedDel := make(map[int]bool)
+	usedIns := make(map[int]bool)
+	var pairs []ChangePair
+	for _, c := range candidates {
+		if usedDel[c.DeleteOp] || usedIns[c.InsertOp] {
+			continue
+		}
+		usedDel[c.DeleteOp] = true
+		usedIns[c.InsertOp] = true
+		pairs = append(pairs, c)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].DeleteOp < pairs[j].DeleteOp
+	})
+	return pairs
+}
+
+// Similarity returns the similarity of two element sequences as 2*M/T,
+// where M is the number of elements in Equal regions of their diff and T
+// is the total number of elements in both sequences. Identical sequences
+// score 1 and sequences with nothing in common score 0. Two empty
+// sequences are considered identical.
+func Similarity(a, b []Element) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	return similarityOf(DiffElements(a, b, WithPostprocessing(false)), len(a), len(b))
+}
+
+// similarityOf computes the 2*M/T similarity from a script for sequences
+// of lengths n and m.
+func similarityOf(ops []DiffOp, n, m int) float64 {
+	if n+m == 0 {
+		return 1
+	}
+	matches := 0
+	for _, op := range ops {
+		if op.Type == Equal {
+			matches += op.AEnd - op.AStart
+		}
+	}
+	return 2 * float64(matches) / float64(n+m)
+}
+
+// regionSimilarity compares two regions, word by word when both consist
+// only of StringElements and element by element otherwise.
+func regionSimilarity(a, b []Element) float64 {
+	wordsA, okA := regionWords(a)
+	wordsB, okB := regionWords(b)
+	if okA && okB {
+		return Similarity(toElements(wordsA), toElements(wordsB))
+	}
+	return Similarity(a, b)
+}
+
+// regionWords splits the text of a region of StringElements into words.
+// It reports false if any element is not a StringElement.
+func regionWords(elems []Element) ([]string, bool) {
+	var words []string
+	for _, e := range elems {
+		s, ok := e.(StringElement)
+		if !ok {
+			return nil, false
+		}
+		words = append(words, strings.Fields(string(s))...)
+	}
+	return words, true
+}
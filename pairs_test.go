@@ -0,0 +1,125 @@
+package diffx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want float64
+	}{
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, 1},
+		{"disjoint", []string{"a", "b"}, []string{"c", "d"}, 0},
+		{"half", []string{"a", "b"}, []string{"a", "c"}, 0.5},
+		{"both empty", nil, nil, 1},
+		{"one empty", []string{"a"}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(toElements(tt.a), toElements(tt.b))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionSimilarity_WordLevel(t *testing.T) {
+	a := toElements([]string{"the quick brown fox jumps"})
+	b := toElements([]string{"the quick red fox jumps"})
+
+	if got := regionSimilarity(a, b); got < 0.7 {
+		t.Errorf("regionSimilarity() = %v, want at least 0.7", got)
+	}
+	if got := Similarity(a, b); got != 0 {
+		t.Errorf("element-level Similarity() = %v, want 0", got)
+	}
+}
+
+func TestPairChanges_NonAdjacent(t *testing.T) {
+	a := toElements([]string{
+		"The installer supports Linux and macOS.",
+		"keep",
+		"Old unrelated sentence here.",
+	})
+	b := toElements([]string{
+		"Completely new opening line.",
+		"keep",
+		"The installer supports Linux, macOS, and Windows.",
+	})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+
+	pairs := PairChanges(ops, a, b)
+	if len(pairs) != 1 {
+		t.Fatalf("expected 1 pair, got %d: %+v", len(pairs), pairs)
+	}
+
+	p := pairs[0]
+	if p.DeleteOp != 0 || p.InsertOp != 4 {
+		t.Errorf("pair = %+v, want delete 0 paired with insert 4", p)
+	}
+	if p.Adjacent {
+		t.Error("expected pair to be non-adjacent")
+	}
+}
+
+func TestPairChanges_EachOpUsedOnce(t *testing.T) {
+	a := toElements([]string{"alpha beta gamma", "x", "alpha beta delta"})
+	b := toElements([]string{"x", "alpha beta gamma delta"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Equal, AStart: 1, AEnd: 2, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 2},
+	}
+
+	pairs := PairChanges(ops, a, b)
+	if len(pairs) != 1 {
+		t.Fatalf("expected 1 pair, got %+v", pairs)
+	}
+	if pairs[0].InsertOp != 3 {
+		t.Errorf("unexpected pair %+v", pairs[0])
+	}
+}
+
+func TestPairChanges_BelowThreshold(t *testing.T) {
+	a := toElements([]string{"one two three"})
+	b := toElements([]string{"four five six"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+	}
+
+	if pairs := PairChanges(ops, a, b); len(pairs) != 0 {
+		t.Errorf("expected no pairs, got %+v", pairs)
+	}
+}
+
+func TestPairChanges_Options(t *testing.T) {
+	a := toElements([]string{"alpha beta gamma delta"})
+	b := toElements([]string{"alpha beta epsilon zeta"})
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+	}
+
+	if pairs := PairChanges(ops, a, b); len(pairs) != 1 {
+		t.Fatalf("default: expected 1 pair, got %+v", pairs)
+	}
+	if pairs := PairChanges(ops, a, b, WithMinSimilarity(0.8)); len(pairs) != 0 {
+		t.Errorf("WithMinSimilarity(0.8): expected no pairs, got %+v", pairs)
+	}
+	if pairs := PairChanges(ops, a, b, WithMinBlockLen(2)); len(pairs) != 0 {
+		t.Errorf("WithMinBlockLen(2): expected no pairs, got %+v", pairs)
+	}
+}
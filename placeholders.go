@@ -0,0 +1,75 @@
+package diffx
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Localization placeholder-aware comparison.
+//
+// Translated string catalogs are full of interpolation placeholders:
+// "%s", "%1$d", "%(count)d", "{name}", "{0}", "{{user}}", "${path}". When a
+// translation is revised, placeholders are often renamed, renumbered, or
+// converted between styles while the sentence around them is what the
+// translator actually changed. WithNormalizePlaceholders compares every
+// placeholder as the same token, so a review shows the wording changes,
+// and keeps each placeholder whole in word diffs instead of splitting it
+// at its punctuation.
+
+// placeholderPattern matches printf-style, Python, brace, Mustache, and
+// template-literal placeholders. "%%" is matched so that it is skipped as
+// a literal percent sign.
+var placeholderPattern = regexp.MustCompile(
+	`%%` +
+		`|%(?:\([\w.]+\)|\d+\$)?[-+#0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[a-zA-Z@]` +
+		`|\{\{\s*[\w.]+\s*\}\}` +
+		`|\$\{[\w.]+\}` +
+		`|\{[\w.]*(?:[,:][^{}]*)?\}`)
+
+// placeholderKey is what every placeholder compares as.
+const placeholderKey = "{}"
+
+// WithNormalizePlaceholders makes placeholders compare equal to each
+// other: printf verbs such as "%s" and "%1$d", Python's "%(name)s" and
+// "{name}", ICU and .NET "{0}" and "{price, number}", Mustache
+// "{{name}}", and "${name}". ICU plural and select messages, which nest
+// braces, are not recognized as a whole, only the placeholders inside
+// them. A placeholder that was added or removed is still a change.
+// ProseDiff and ParagraphDiff also keep each placeholder as one token in
+// their word diffs.
+// Default: false.
+func WithNormalizePlaceholders(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.placeholders = enabled
+	}
+}
+
+// normalizePlaceholders replaces every placeholder in s with
+// placeholderKey.
+func normalizePlaceholders(s string) string {
+	if !strings.ContainsAny(s, "%{") {
+		return s
+	}
+	return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "%%" {
+			return m
+		}
+		return placeholderKey
+	})
+}
+
+// splitPlaceholderTokens is like SplitWords, but keeps each
+// placeholder as a single token.
+func splitPlaceholderTokens(s string) []string {
+	var tokens []string
+	start := 0
+	for _, loc := range placeholderPattern.FindAllStringIndex(s, -1) {
+		if s[loc[0]:loc[1]] == "%%" {
+			continue
+		}
+		tokens = append(tokens, SplitWords(s[start:loc[0]])...)
+		tokens = append(tokens, s[loc[0]:loc[1]])
+		start = loc[1]
+	}
+	return append(tokens, SplitWords(s[start:])...)
+}
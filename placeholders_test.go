@@ -0,0 +1,67 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePlaceholders(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello, %s!", "Hello, {}!"},
+		{"%1$d of %2$d files", "{} of {} files"},
+		{"%(count)d items, %.2f%% done", "{} items, {}%% done"},
+		{"Hi {name}, you owe {0} {price, number}", "Hi {}, you owe {} {}"},
+		{"Welcome {{ user.name }} to ${app}", "Welcome {} to {}"},
+		{"100% sure", "100% sure"},
+		{"no placeholders", "no placeholders"},
+	}
+	for _, tt := range tests {
+		if got := normalizePlaceholders(tt.in); got != tt.want {
+			t.Errorf("normalizePlaceholders(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithNormalizePlaceholders(t *testing.T) {
+	a := []string{"greeting = Hello, %s!", "files = %d files", "bye = Goodbye"}
+	b := []string{"greeting = Hello, {name}!", "files = {count} files", "bye = See you"}
+
+	ops := Diff(a, b, WithNormalizePlaceholders(true))
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+	}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("ops = %v, want %v", ops, want)
+	}
+
+	// A removed placeholder is still a change
+	ops = Diff([]string{"Hello, %s!"}, []string{"Hello!"}, WithNormalizePlaceholders(true))
+	if countChangeRegions(ops) != 1 {
+		t.Errorf("removed placeholder: ops = %v, want a change", ops)
+	}
+}
+
+func TestSplitPlaceholderTokens(t *testing.T) {
+	s := "Delete %(count)d files from {folder}?"
+	got := splitPlaceholderTokens(s)
+	want := []string{"Delete", " ", "%(count)d", " ", "files", " ", "from", " ", "{folder}", "?"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitPlaceholderTokens(%q) = %q, want %q", s, got, want)
+	}
+	if strings.Join(got, "") != s {
+		t.Errorf("tokens do not reproduce %q", s)
+	}
+
+	r := ProseDiff("Delete %(count)d old files.", "Remove %(count)d old files.", WithNormalizePlaceholders(true))
+	if len(r.Words) != 1 {
+		t.Fatalf("Words = %+v, want one refined sentence", r.Words)
+	}
+	if del, ins := changedWords(r.Words[0]); del != "Delete" || ins != "Remove" {
+		t.Errorf("changed words = %q, %q, want Delete, Remove", del, ins)
+	}
+}
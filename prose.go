@@ -0,0 +1,203 @@
+package diffx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Two-level prose diff.
+//
+// Documentation reviewers read sentences, not lines: a line diff of a
+// reflowed paragraph marks every line, and a word diff of a whole document
+// loses track of which sentence an edit belongs to. ProseDiff first aligns
+// the sentences of two texts, so that added, removed, and untouched
+// sentences are reported whole, and then diffs each rewritten sentence
+// word by word against the sentence it replaced. ParagraphDiff does the
+// same with paragraphs, which keeps the result of a long document with a
+// few local edits to a handful of changed paragraphs.
+
+// ProseResult is a two-level diff of two texts.
+type ProseResult struct {
+	// A and B are the units of each text, sentences or paragraphs, each
+	// including the white space that follows it, so concatenating them
+	// reproduces the text. Ops is a diff of A and B that ignores the
+	// trailing white space of units.
+	A, B []string
+	Ops  []DiffOp
+
+	// Words refines the changes of Ops that rewrite units: each Delete
+	// paired with a similar Insert, in the order of the Delete.
+	Words []WordDiff
+}
+
+// WordDiff is a word-level diff of a rewritten run of units.
+type WordDiff struct {
+	Pair ChangePair // the Delete and Insert of the unit-level Ops
+	// A and B are the words, white space, and punctuation of the deleted
+	// and inserted units; concatenating them reproduces the text. Ops is
+	// a diff of A and B.
+	A, B []string
+	Ops  []DiffOp
+}
+
+// ProseDiff compares two texts sentence by sentence, as split by
+// SplitSentences, and then word by word within each deleted run of
+// sentences that PairChanges pairs with an inserted run. Options apply to
+// both levels.
+func ProseDiff(aText, bText string, opts ...Option) *ProseResult {
+	return proseDiff(SplitSentences(aText), SplitSentences(bText), opts)
+}
+
+// ParagraphDiff compares two texts paragraph by paragraph, as split by
+// SplitParagraphs, and then word by word within each deleted run of
+// paragraphs that PairChanges pairs with an inserted run. Unchanged
+// paragraphs are not split into words at all.
+func ParagraphDiff(aText, bText string, opts ...Option) *ProseResult {
+	return proseDiff(SplitParagraphs(aText), SplitParagraphs(bText), opts)
+}
+
+// proseDiff aligns the units a and b and refines paired changes word by
+// word.
+func proseDiff(a, b []string, opts []Option) *ProseResult {
+	r := &ProseResult{A: a, B: b}
+	keysA, keysB := proseKeys(a), proseKeys(b)
+	r.Ops = Diff(keysA, keysB, opts...)
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	split := SplitWords
+	if o.ignoreOpts.placeholders {
+		split = splitPlaceholderTokens
+	}
+
+	wordOpts := append(opts[:len(opts):len(opts)], WithPreprocessing(false))
+	for _, pair := range PairChanges(r.Ops, toElements(keysA), toElements(keysB)) {
+		d, in := r.Ops[pair.DeleteOp], r.Ops[pair.InsertOp]
+		wa := split(strings.Join(a[d.AStart:d.AEnd], ""))
+		wb := split(strings.Join(b[in.BStart:in.BEnd], ""))
+		r.Words = append(r.Words, WordDiff{Pair: pair, A: wa, B: wb, Ops: Diff(wa, wb, wordOpts...)})
+	}
+	return r
+}
+
+// proseKeys returns units without their trailing white space.
+func proseKeys(units []string) []string {
+	keys := make([]string, len(units))
+	for i, u := range units {
+		keys[i] = strings.TrimRightFunc(u, unicode.IsSpace)
+	}
+	return keys
+}
+
+// SplitSentences splits text into sentences, each including the white
+// space that follows it, so that concatenating them reproduces text. A
+// sentence ends at ".", "!", "?", or an ellipsis, with any closing quotes
+// or brackets, when white space and a character that is not a lowercase
+// letter follow; so "e.g. this" and "3.14" do not end a sentence. The
+// ideographic full stop and full-width marks end a sentence without white
+// space. A blank line also ends a sentence, so headings and list items
+// without final punctuation stand on their own.
+func SplitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+
+		end := -1
+		switch {
+		case isSentenceEnd(r):
+			ideographic := r >= 0x3000
+			for i < len(text) {
+				next, size := utf8.DecodeRuneInString(text[i:])
+				if !isSentenceEnd(next) && !isClosingPunct(next) {
+					break
+				}
+				i += size
+			}
+			j := skipSpace(text, i)
+			if j == i && !ideographic && i < len(text) {
+				break
+			}
+			if next, _ := utf8.DecodeRuneInString(text[j:]); j < len(text) && unicode.IsLower(next) {
+				break
+			}
+			end = j
+		case r == '\n':
+			if j := skipSpace(text, i); strings.Contains(text[i:j], "\n") {
+				end = j
+			}
+		}
+
+		if end > start && end < len(text) {
+			sentences = append(sentences, text[start:end])
+			start, i = end, end
+		}
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// SplitParagraphs splits text into paragraphs at blank lines. Each
+// paragraph includes the blank lines that follow it, so that concatenating
+// them reproduces text; blank lines at the start belong to the first
+// paragraph. A line of only white space counts as blank.
+func SplitParagraphs(text string) []string {
+	var paragraphs []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\n' {
+			continue
+		}
+		j := skipSpace(text, i+1)
+		if !strings.Contains(text[i+1:j], "\n") {
+			continue
+		}
+		if skipSpace(text, start) < i && j < len(text) {
+			paragraphs = append(paragraphs, text[start:j])
+			start = j
+		}
+		i = j - 1
+	}
+	if start < len(text) {
+		paragraphs = append(paragraphs, text[start:])
+	}
+	return paragraphs
+}
+
+// isSentenceEnd reports whether r can end a sentence.
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+// isClosingPunct reports whether r is a closing quote or bracket that may
+// follow the end of a sentence.
+func isClosingPunct(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '”', '’', '»', '」', '』', '）':
+		return true
+	}
+	return false
+}
+
+// skipSpace returns the index of the first non-space character of s at or
+// after i.
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
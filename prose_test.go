@@ -0,0 +1,133 @@
+package diffx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"One. Two! Three?", []string{"One. ", "Two! ", "Three?"}},
+		{"He said \"stop.\" Then left.\n", []string{"He said \"stop.\" ", "Then left.\n"}},
+		{"Use e.g. this one. Pi is 3.14 here.", []string{"Use e.g. this one. ", "Pi is 3.14 here."}},
+		{"Wait... What?! Yes.", []string{"Wait... ", "What?! ", "Yes."}},
+		{"A line\nwrapped here. Next.", []string{"A line\nwrapped here. ", "Next."}},
+		{"# Heading\n\nBody text\n", []string{"# Heading\n\n", "Body text\n"}},
+		{"最初。次！", []string{"最初。", "次！"}},
+	}
+	for _, tt := range tests {
+		got := SplitSentences(tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if strings.Join(got, "") != tt.text {
+			t.Errorf("SplitSentences(%q) does not reproduce the text", tt.text)
+		}
+	}
+}
+
+func TestSplitParagraphs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"one\ntwo\n", []string{"one\ntwo\n"}},
+		{"one\n\ntwo\n", []string{"one\n\n", "two\n"}},
+		{"\n\none\n \n\t\ntwo", []string{"\n\none\n \n\t\n", "two"}},
+		{"one\n\n", []string{"one\n\n"}},
+		{"a\r\n\r\nb", []string{"a\r\n\r\n", "b"}},
+	}
+	for _, tt := range tests {
+		got := SplitParagraphs(tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitParagraphs(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if strings.Join(got, "") != tt.text {
+			t.Errorf("SplitParagraphs(%q) does not reproduce the text", tt.text)
+		}
+	}
+}
+
+func TestProseDiff(t *testing.T) {
+	a := "The tool reads files. It writes a report to standard output. Errors go to the log."
+	b := "The tool reads files. It writes a short report to a file. Errors go to the log. Exit codes are documented."
+
+	r := ProseDiff(a, b)
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 3, BEnd: 4},
+	}
+	if !reflect.DeepEqual(r.Ops, want) {
+		t.Fatalf("Ops = %v, want %v", r.Ops, want)
+	}
+
+	// Only the rewritten sentence is refined; the new one stays whole
+	if len(r.Words) != 1 {
+		t.Fatalf("Words = %+v, want one refined change", r.Words)
+	}
+	w := r.Words[0]
+	if w.Pair.DeleteOp != 1 || w.Pair.InsertOp != 2 {
+		t.Errorf("Pair = %+v, want ops 1 and 2", w.Pair)
+	}
+	deleted, inserted := changedWords(w)
+	if deleted != "standard output" || inserted != "short a file" {
+		t.Errorf("changed words = %q, %q, want %q, %q", deleted, inserted, "standard output", "short a file")
+	}
+}
+
+func TestProseDiff_TrailingSpace(t *testing.T) {
+	// A sentence that ends a paragraph in one version is still equal
+	r := ProseDiff("One. Two.\n\nThree.", "One. Two. Three.")
+	if countChangeRegions(r.Ops) != 0 {
+		t.Errorf("Ops = %v, want no changes", r.Ops)
+	}
+}
+
+func TestParagraphDiff(t *testing.T) {
+	var paras []string
+	for i := 0; i < 20; i++ {
+		paras = append(paras, fmt.Sprintf("Paragraph %d has\nseveral words on two lines.", i))
+	}
+	a := strings.Join(paras, "\n\n")
+	paras[7] = "Paragraph 7 has\nseveral new words on two lines."
+	b := strings.Join(paras, "\n\n")
+
+	r := ParagraphDiff(a, b)
+	if len(r.A) != 20 || len(r.B) != 20 || countChangeRegions(r.Ops) != 1 {
+		t.Fatalf("got %d and %d paragraphs with ops %v, want one changed paragraph of 20", len(r.A), len(r.B), r.Ops)
+	}
+	if len(r.Words) != 1 {
+		t.Fatalf("Words = %+v, want one refined paragraph", r.Words)
+	}
+	if del, ins := changedWords(r.Words[0]); del != "" || ins != "new" {
+		t.Errorf("changed words = %q, %q, want %q, %q", del, ins, "", "new")
+	}
+	if got := strings.Join(r.Words[0].B, ""); got != paras[7]+"\n\n" {
+		t.Errorf("refined text = %q, want %q", got, paras[7])
+	}
+}
+
+// changedWords returns the deleted and inserted words of w, without white
+// space, joined with spaces.
+func changedWords(w WordDiff) (deleted, inserted string) {
+	var del, ins []string
+	for _, op := range w.Ops {
+		switch op.Type {
+		case Delete:
+			del = append(del, w.A[op.AStart:op.AEnd]...)
+		case Insert:
+			ins = append(ins, w.B[op.BStart:op.BEnd]...)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(del, " ")), " "), strings.Join(strings.Fields(strings.Join(ins, " ")), " ")
+}
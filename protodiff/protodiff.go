@@ -0,0 +1,141 @@
+// Package protodiff compares repeated message fields of protocol buffer
+// messages.
+//
+// Services that store configuration or state as protobuf messages often
+// need a human-readable change log: which entries of a repeated field were
+// added, removed, or changed. Comparing the lists position by position
+// reports every entry after an insertion as changed. protodiff instead
+// aligns the entries with diffx, using one field of the entry message (such
+// as "name" or "id") as its identity.
+package protodiff
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/dacharyc/diffx"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ChangeType identifies how an entry changed. It is an alias of
+// diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the entry is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the entry is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means an entry with the same identity has different fields.
+	Modified = diffx.ChangeModified
+)
+
+// Change describes an entry of a repeated field that differs.
+type Change struct {
+	Type ChangeType
+	Key  string // identity value of the entry
+	A    int    // index in list A, or -1 if added
+	B    int    // index in list B, or -1 if removed
+}
+
+// Result is the comparison of two repeated fields.
+type Result struct {
+	// Ops aligns the entries by identity. Equal operations cover entries
+	// with the same identity, whether or not their other fields changed.
+	Ops []diffx.DiffOp
+	// Changes lists the added, removed, and modified entries in order.
+	Changes []Change
+}
+
+// DiffRepeated compares the repeated message field named field in messages
+// a and b, which must have the same type. Entries are identified by their
+// key field, which must be a singular scalar field of the entry message.
+// opts are passed to diffx.DiffElements.
+func DiffRepeated(a, b proto.Message, field, key protoreflect.Name, opts ...diffx.Option) (*Result, error) {
+	ma, mb := a.ProtoReflect(), b.ProtoReflect()
+	desc := ma.Descriptor()
+	if desc.FullName() != mb.Descriptor().FullName() {
+		return nil, fmt.Errorf("protodiff: messages have different types %s and %s", desc.FullName(), mb.Descriptor().FullName())
+	}
+
+	fd := desc.Fields().ByName(field)
+	if fd == nil {
+		return nil, fmt.Errorf("protodiff: %s has no field %q", desc.FullName(), field)
+	}
+	if !fd.IsList() || fd.Message() == nil {
+		return nil, fmt.Errorf("protodiff: field %s is not a repeated message field", fd.FullName())
+	}
+
+	kd := fd.Message().Fields().ByName(key)
+	if kd == nil {
+		return nil, fmt.Errorf("protodiff: %s has no field %q", fd.Message().FullName(), key)
+	}
+
+	return DiffList(ma.Get(fd).List(), mb.Get(fd).List(), kd, opts...)
+}
+
+// DiffList compares two lists of messages, identifying entries by the key
+// field. It is the building block for DiffRepeated when the lists have
+// already been obtained through protoreflect.
+func DiffList(a, b protoreflect.List, key protoreflect.FieldDescriptor, opts ...diffx.Option) (*Result, error) {
+	if key.IsList() || key.IsMap() || key.Message() != nil {
+		return nil, fmt.Errorf("protodiff: key field %s is not a singular scalar", key.FullName())
+	}
+
+	ea, eb := toEntries(a, key), toEntries(b, key)
+	ops := diffx.DiffElements(ea, eb, opts...)
+
+	res := &Result{Ops: ops}
+	for _, op := range ops {
+		switch op.Type {
+		case diffx.Equal:
+			for n := 0; n < op.AEnd-op.AStart; n++ {
+				i, j := op.AStart+n, op.BStart+n
+				if !proto.Equal(a.Get(i).Message().Interface(), b.Get(j).Message().Interface()) {
+					res.Changes = append(res.Changes, Change{Type: Modified, Key: keyOf(ea[i]), A: i, B: j})
+				}
+			}
+		case diffx.Delete:
+			for i := op.AStart; i < op.AEnd; i++ {
+				res.Changes = append(res.Changes, Change{Type: Removed, Key: keyOf(ea[i]), A: i, B: -1})
+			}
+		case diffx.Insert:
+			for j := op.BStart; j < op.BEnd; j++ {
+				res.Changes = append(res.Changes, Change{Type: Added, Key: keyOf(eb[j]), A: -1, B: j})
+			}
+		}
+	}
+	return res, nil
+}
+
+// entry is a diffx.Element comparing list entries by identity.
+type entry string
+
+// Equal reports whether other has the same identity.
+func (e entry) Equal(other diffx.Element) bool {
+	o, ok := other.(entry)
+	return ok && e == o
+}
+
+// Hash returns a FNV-1a hash of the identity.
+func (e entry) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(e))
+	return h.Sum64()
+}
+
+// toEntries returns the identity of each list entry.
+func toEntries(list protoreflect.List, key protoreflect.FieldDescriptor) []diffx.Element {
+	elems := make([]diffx.Element, list.Len())
+	for i := range elems {
+		v := list.Get(i).Message().Get(key)
+		elems[i] = entry(fmt.Sprint(v.Interface()))
+	}
+	return elems
+}
+
+// keyOf returns the identity of an entry element.
+func keyOf(e diffx.Element) string {
+	return string(e.(entry))
+}
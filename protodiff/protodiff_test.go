@@ -0,0 +1,83 @@
+package protodiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// file builds a FileDescriptorProto, whose repeated message_type field
+// serves as a convenient repeated message field keyed by name.
+func file(messages ...*descriptorpb.DescriptorProto) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{MessageType: messages}
+}
+
+func message(name string, fields ...string) *descriptorpb.DescriptorProto {
+	m := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	for _, f := range fields {
+		m.Field = append(m.Field, &descriptorpb.FieldDescriptorProto{Name: proto.String(f)})
+	}
+	return m
+}
+
+func TestDiffRepeated(t *testing.T) {
+	a := file(message("User", "id"), message("Group", "id"), message("Role"))
+	b := file(message("Account"), message("User", "id"), message("Group", "id", "owner"))
+
+	res, err := DiffRepeated(a, b, "message_type", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Type: Added, Key: "Account", A: -1, B: 0},
+		{Type: Modified, Key: "Group", A: 1, B: 2},
+		{Type: Removed, Key: "Role", A: 2, B: -1},
+	}
+	if !reflect.DeepEqual(res.Changes, want) {
+		t.Errorf("Changes = %+v, want %+v", res.Changes, want)
+	}
+}
+
+func TestDiffRepeated_Unchanged(t *testing.T) {
+	a := file(message("A", "x"), message("B"))
+	res, err := DiffRepeated(a, proto.Clone(a), "message_type", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Changes) != 0 {
+		t.Errorf("expected no changes, got %+v", res.Changes)
+	}
+	if len(res.Ops) != 1 {
+		t.Errorf("expected a single Equal op, got %v", res.Ops)
+	}
+}
+
+func TestDiffRepeated_Errors(t *testing.T) {
+	a := file()
+	tests := []struct {
+		name         string
+		b            proto.Message
+		field, key   protoreflect.Name
+		errSubstring string
+	}{
+		{"different types", &descriptorpb.DescriptorProto{}, "message_type", "name", "different types"},
+		{"unknown field", a, "nope", "name", `no field "nope"`},
+		{"not repeated", a, "package", "name", "not a repeated message field"},
+		{"unknown key", a, "message_type", "nope", `no field "nope"`},
+		{"message key", a, "message_type", "options", "not a singular scalar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DiffRepeated(a, tt.b, tt.field, tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.errSubstring) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.errSubstring)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package diffx
+
+import "strings"
+
+// Smart punctuation normalization.
+//
+// Editors, static site generators, and copy-paste from word processors
+// turn straight quotes into curly ones, "--" into dashes, and "..." into
+// an ellipsis character, or back. A typographic conversion touches nearly
+// every line of a document while the words stay the same.
+// WithNormalizePunctuation compares text with typographic punctuation
+// folded to its ASCII form, so only real edits remain.
+
+// punctuationReplacer folds typographic punctuation to ASCII.
+var punctuationReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`,
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-", "―", "-", "−", "-",
+	"…", "...",
+)
+
+// WithNormalizePunctuation treats typographic punctuation as equal to its
+// ASCII form: curly single and double quotes and primes as straight
+// quotes, hyphen, en dash, em dash, and minus characters as "-", and "…"
+// as "...". A "--" or "---" between other characters also compares equal
+// to a dash, as the SmartyPants conventions write dashes; runs of hyphens
+// at the start of a line, such as a Markdown rule or heading underline,
+// are kept.
+// Default: false.
+func WithNormalizePunctuation(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.punctuation = enabled
+	}
+}
+
+// normalizePunctuation folds typographic punctuation in s to ASCII.
+func normalizePunctuation(s string) string {
+	s = punctuationReplacer.Replace(s)
+	if !strings.Contains(s, "--") {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	lineStart := true
+	for i := 0; i < len(s); {
+		if s[i] != '-' {
+			lineStart = s[i] == '\n' || (lineStart && (s[i] == ' ' || s[i] == '\t'))
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && s[j] == '-' {
+			j++
+		}
+		if n := j - i; !lineStart && (n == 2 || n == 3) && j < len(s) {
+			sb.WriteByte('-')
+		} else {
+			sb.WriteString(s[i:j])
+		}
+		lineStart = false
+		i = j
+	}
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package diffx
+
+import "testing"
+
+func TestNormalizePunctuation(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"“Quoted” and ‘single’", `"Quoted" and 'single'`},
+		{"It’s done", "It's done"},
+		{"pages 10–20 — see", "pages 10-20 - see"},
+		{"wait…", "wait..."},
+		{"pages 10--20 --- see", "pages 10-20 - see"},
+		{"---\ntitle\n-----", "---\ntitle\n-----"},
+		{"  -- indented", "  -- indented"},
+		{"a ---- b", "a ---- b"},
+		{"trailing--", "trailing--"},
+	}
+	for _, tt := range tests {
+		if got := normalizePunctuation(tt.in); got != tt.want {
+			t.Errorf("normalizePunctuation(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithNormalizePunctuation(t *testing.T) {
+	a := []string{"\"Hello,\" she said -- quietly...", "It's 5-10 minutes.", "The end."}
+	b := []string{"“Hello,” she said — quietly…", "It’s 5–10 minutes.", "The End."}
+
+	for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+		ops := diff(a, b, WithNormalizePunctuation(true))
+		if len(ops) != 3 || ops[0].Type != Equal || ops[0].AEnd != 2 {
+			t.Errorf("ops = %v, want only the last line changed", ops)
+		}
+		if countChangeRegions(diff(a, b)) != 1 || diff(a, b)[0].Type == Equal {
+			t.Errorf("without normalization: ops = %v, want every line changed", diff(a, b))
+		}
+	}
+}
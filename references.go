@@ -0,0 +1,118 @@
+package diffx
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Reference-label normalization.
+//
+// Markdown reference links ("[text][3]" with "[3]: https://...") and
+// footnotes ("[^2]" with "[^2]: Note."), and reStructuredText footnotes
+// ("[2]_" with ".. [2] Note."), are usually numbered. Adding one reference
+// near the top of a document renumbers every reference after it, and a
+// diff reports each renumbered line. With WithNormalizeReferences, labels
+// are replaced by what they refer to before comparing, so a renumbered
+// reference compares equal to the original and only real changes to
+// links and notes remain.
+
+// Reference definitions and uses. Definitions start a line, with up to
+// three spaces of indentation in Markdown.
+var (
+	mdLinkDef      = regexp.MustCompile(`(?m)^ {0,3}\[([^\]^][^\]]*)\]:[ \t]*(\S+)`)
+	mdFootnoteDef  = regexp.MustCompile(`(?m)^ {0,3}\[\^([^\]]+)\]:[ \t]*(\S.*)$`)
+	rstFootnoteDef = regexp.MustCompile(`(?m)^\.\. \[(#?[\w-]+|\d+)\][ \t]+(\S.*)$`)
+	mdLinkRef      = regexp.MustCompile(`\[([^\]]*)\]\[([^\]]*)\]`)
+	mdFootnoteRef  = regexp.MustCompile(`\[\^([^\]]+)\]`)
+	rstFootnoteRef = regexp.MustCompile(`\[(#?[\w-]+|\d+)\]_`)
+)
+
+// WithNormalizeReferences makes Diff and DiffHistogram compare Markdown
+// reference links and footnotes, and reStructuredText footnotes, by what
+// they refer to instead of by label. A full or collapsed reference link,
+// "[text][label]" or "[label][]", and its definition compare as if the
+// label were the link destination; a footnote reference and its
+// definition compare as if the label were the first line of the note.
+// Labels are resolved within each sequence, whose strings may hold single
+// lines or longer runs of text; labels without a definition are compared
+// as they are.
+// Default: false.
+func WithNormalizeReferences(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreOpts.references = enabled
+	}
+}
+
+// normalizeReferences returns strs with reference labels replaced by their
+// definitions, as described for WithNormalizeReferences.
+func normalizeReferences(strs []string) []string {
+	links := make(map[string]string) // lowercase label -> destination
+	notes := make(map[string]string) // Markdown footnote label -> text
+	rst := make(map[string]string)   // reStructuredText footnote label -> text
+	for _, s := range strs {
+		if !strings.Contains(s, "[") {
+			continue
+		}
+		for _, m := range mdLinkDef.FindAllStringSubmatch(s, -1) {
+			addDefinition(links, strings.ToLower(m[1]), m[2])
+		}
+		for _, m := range mdFootnoteDef.FindAllStringSubmatch(s, -1) {
+			addDefinition(notes, m[1], strings.TrimSpace(m[2]))
+		}
+		for _, m := range rstFootnoteDef.FindAllStringSubmatch(s, -1) {
+			addDefinition(rst, m[1], strings.TrimSpace(m[2]))
+		}
+	}
+	if len(links)+len(notes)+len(rst) == 0 {
+		return strs
+	}
+
+	keys := make([]string, len(strs))
+	for i, s := range strs {
+		if !strings.Contains(s, "[") {
+			keys[i] = s
+			continue
+		}
+		s = mdLinkDef.ReplaceAllStringFunc(s, func(def string) string {
+			m := mdLinkDef.FindStringSubmatch(def)
+			return strings.Replace(def, "["+m[1]+"]", "[→"+links[strings.ToLower(m[1])]+"]", 1)
+		})
+		s = rstFootnoteDef.ReplaceAllStringFunc(s, func(def string) string {
+			m := rstFootnoteDef.FindStringSubmatch(def)
+			return strings.Replace(def, "["+m[1]+"]", "[→"+rst[m[1]]+"]", 1)
+		})
+		s = mdLinkRef.ReplaceAllStringFunc(s, func(ref string) string {
+			m := mdLinkRef.FindStringSubmatch(ref)
+			label := m[2]
+			if label == "" {
+				label = m[1]
+			}
+			if dest, ok := links[strings.ToLower(label)]; ok {
+				return "[" + m[1] + "][→" + dest + "]"
+			}
+			return ref
+		})
+		s = mdFootnoteRef.ReplaceAllStringFunc(s, func(ref string) string {
+			if text, ok := notes[ref[2:len(ref)-1]]; ok {
+				return "[^→" + text + "]"
+			}
+			return ref
+		})
+		s = rstFootnoteRef.ReplaceAllStringFunc(s, func(ref string) string {
+			if text, ok := rst[ref[1:len(ref)-2]]; ok {
+				return "[→" + text + "]_"
+			}
+			return ref
+		})
+		keys[i] = s
+	}
+	return keys
+}
+
+// addDefinition records the definition of label, keeping the first one as
+// Markdown does.
+func addDefinition(defs map[string]string, label, value string) {
+	if _, ok := defs[label]; !ok {
+		defs[label] = value
+	}
+}
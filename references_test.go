@@ -0,0 +1,80 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeReferences(t *testing.T) {
+	a := []string{
+		"See the [guide][1] and the [API][2].",
+		"A claim.[^1]",
+		"",
+		"[1]: https://example.com/guide",
+		"[2]: https://example.com/api",
+		"[^1]: Source: the survey.",
+	}
+	b := []string{
+		"Read [this][1] first.",
+		"See the [guide][2] and the [API][3].",
+		"A claim.[^2]",
+		"",
+		"[1]: https://example.com/intro",
+		"[2]: https://example.com/guide",
+		"[3]: https://example.com/api",
+		"[^2]: Source: the survey.",
+	}
+
+	// Without normalization every renumbered line differs
+	if n := countChangeRegions(Diff(a, b)); n < 2 {
+		t.Fatalf("plain diff has %d change regions, want several", n)
+	}
+
+	want := []DiffOp{
+		{Type: Insert, AStart: 0, AEnd: 0, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 0, AEnd: 3, BStart: 1, BEnd: 4},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 4, BEnd: 5},
+		{Type: Equal, AStart: 3, AEnd: 6, BStart: 5, BEnd: 8},
+	}
+	for _, diff := range []func([]string, []string, ...Option) []DiffOp{Diff, DiffHistogram} {
+		if got := diff(a, b, WithNormalizeReferences(true)); !reflect.DeepEqual(got, want) {
+			t.Errorf("ops = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNormalizeReferences_Keys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{
+			"collapsed and case-insensitive",
+			[]string{"[Docs][] and [x][DOCS]", "[docs]: /d"},
+			[]string{"[Docs][→/d] and [x][→/d]", "[→/d]: /d"},
+		},
+		{
+			"undefined labels kept",
+			[]string{"[a][nope] [^x] [9]_", "[b]: /b"},
+			[]string{"[a][nope] [^x] [9]_", "[→/b]: /b"},
+		},
+		{
+			"rst footnotes",
+			[]string{"Fact [3]_.", ".. [3] Citation."},
+			[]string{"Fact [→Citation.]_.", ".. [→Citation.] Citation."},
+		},
+		{
+			"multi-line strings",
+			[]string{"Text [^n] here.\n\n[^n]: Note."},
+			[]string{"Text [^→Note.] here.\n\n[^→Note.]: Note."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeReferences(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeReferences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
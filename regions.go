@@ -0,0 +1,75 @@
+package diffx
+
+import "fmt"
+
+// Compact change regions.
+//
+// An edit script of two large, mostly equal inputs is mostly Equal
+// operations, and each change usually takes a Delete and an Insert. A
+// ChangeRegion records a change in one value and leaves the unchanged
+// runs between changes implicit, which takes much less memory to hold
+// and to serialize. CompactOps and ExpandRegions convert between the two
+// forms.
+
+// ChangeRegion is a maximal run of changes: A[AStart:AEnd] was replaced
+// by B[BStart:BEnd]. Either range may be empty, for a pure insertion or
+// deletion.
+type ChangeRegion struct {
+	AStart, AEnd int
+	BStart, BEnd int
+}
+
+// CompactOps returns the change regions of ops, in order. Consecutive
+// Delete and Insert operations form one region.
+func CompactOps(ops []DiffOp) []ChangeRegion {
+	var regions []ChangeRegion
+	inChange := false
+	for _, op := range ops {
+		if op.Type == Equal {
+			inChange = false
+			continue
+		}
+		if !inChange {
+			regions = append(regions, ChangeRegion{AStart: op.AStart, BStart: op.BStart})
+			inChange = true
+		}
+		r := &regions[len(regions)-1]
+		r.AEnd, r.BEnd = op.AEnd, op.BEnd
+	}
+	return regions
+}
+
+// ExpandRegions returns the edit script of sequences of lengths lenA and
+// lenB whose changes are regions, with the unchanged runs between them as
+// Equal operations. It reverses CompactOps. An error is returned if the
+// regions are out of order, overlap, or leave unchanged runs of different
+// lengths in A and B.
+func ExpandRegions(regions []ChangeRegion, lenA, lenB int) ([]DiffOp, error) {
+	var ops []DiffOp
+	i, j := 0, 0 // end of the previous region
+	for k, r := range regions {
+		if r.AStart < i || r.BStart < j || r.AEnd < r.AStart || r.BEnd < r.BStart || r.AEnd > lenA || r.BEnd > lenB {
+			return nil, fmt.Errorf("diffx: region %d %v out of order or out of range", k, r)
+		}
+		if r.AStart-i != r.BStart-j {
+			return nil, fmt.Errorf("diffx: unchanged run before region %d has lengths %d and %d", k, r.AStart-i, r.BStart-j)
+		}
+		if r.AStart > i {
+			ops = append(ops, DiffOp{Type: Equal, AStart: i, AEnd: r.AStart, BStart: j, BEnd: r.BStart})
+		}
+		if r.AEnd > r.AStart {
+			ops = append(ops, DiffOp{Type: Delete, AStart: r.AStart, AEnd: r.AEnd, BStart: r.BStart, BEnd: r.BStart})
+		}
+		if r.BEnd > r.BStart {
+			ops = append(ops, DiffOp{Type: Insert, AStart: r.AEnd, AEnd: r.AEnd, BStart: r.BStart, BEnd: r.BEnd})
+		}
+		i, j = r.AEnd, r.BEnd
+	}
+	if lenA-i != lenB-j {
+		return nil, fmt.Errorf("diffx: unchanged run at the end has lengths %d and %d", lenA-i, lenB-j)
+	}
+	if lenA > i {
+		ops = append(ops, DiffOp{Type: Equal, AStart: i, AEnd: lenA, BStart: j, BEnd: lenB})
+	}
+	return ops, nil
+}
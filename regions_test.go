@@ -0,0 +1,70 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompactOps(t *testing.T) {
+	a := strings.Fields("a b c d e f g")
+	b := strings.Fields("a X c d e g h")
+	ops := Diff(a, b)
+
+	got := CompactOps(ops)
+	want := []ChangeRegion{
+		{AStart: 1, AEnd: 2, BStart: 1, BEnd: 2},
+		{AStart: 5, AEnd: 6, BStart: 5, BEnd: 5},
+		{AStart: 7, AEnd: 7, BStart: 6, BEnd: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CompactOps() = %v, want %v", got, want)
+	}
+
+	back, err := ExpandRegions(got, len(a), len(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, ops) {
+		t.Errorf("ExpandRegions() = %v, want %v", back, ops)
+	}
+
+	if got := CompactOps(Diff(a, a)); got != nil {
+		t.Errorf("identical: CompactOps() = %v, want nil", got)
+	}
+}
+
+func TestExpandRegions_RoundTrip(t *testing.T) {
+	for _, s := range fuzzSeeds {
+		a, b := fuzzTokens(s[0]), fuzzTokens(s[1])
+		ops := Diff(a, b)
+		back, err := ExpandRegions(CompactOps(ops), len(a), len(b))
+		if err != nil {
+			t.Fatalf("%q, %q: %v", s[0], s[1], err)
+		}
+		checkScript(t, a, b, back)
+		if Stat(back) != Stat(ops) {
+			t.Errorf("%q, %q: ExpandRegions() = %v, want %v", s[0], s[1], back, ops)
+		}
+	}
+}
+
+func TestExpandRegions_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		regions    []ChangeRegion
+		lenA, lenB int
+	}{
+		{"overlap", []ChangeRegion{{2, 4, 2, 3}, {3, 5, 3, 3}}, 6, 5},
+		{"unequal gap", []ChangeRegion{{2, 3, 1, 2}}, 4, 4},
+		{"unequal end", []ChangeRegion{{0, 1, 0, 1}}, 3, 4},
+		{"out of range", []ChangeRegion{{0, 5, 0, 0}}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ops, err := ExpandRegions(tt.regions, tt.lenA, tt.lenB); err == nil {
+				t.Errorf("ExpandRegions() = %v, want an error", ops)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package diffx
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Rename detection.
+//
+// When a multi-file diff sees a file disappear from one path and a file
+// appear at another, reporting a full removal and a full addition hides the
+// fact that the file was moved, possibly with small edits. Like Git,
+// DetectRenames pairs removed and added files by content similarity and
+// reports the pairs above a threshold as renames.
+
+// DefaultRenameThreshold is the similarity at or above which a removed and
+// an added file are considered a rename. It matches Git's default of 50%.
+const DefaultRenameThreshold = 0.5
+
+// Rename describes a file that moved from one path to another.
+type Rename struct {
+	From       string  // path of the removed file
+	To         string  // path of the added file
+	Similarity float64 // content similarity in [0, 1]
+}
+
+// String returns a description such as "a.txt => b.txt (87% similarity)".
+func (r Rename) String() string {
+	return fmt.Sprintf("%s => %s (%d%% similarity)", r.From, r.To, r.Percent())
+}
+
+// Percent returns the similarity as a whole percentage, rounded down as Git
+// does so that only identical files report 100%.
+func (r Rename) Percent() int {
+	return int(math.Floor(r.Similarity*100 + 1e-9))
+}
+
+// DetectRenames pairs removed files with added files whose line content is
+// at least threshold similar (see Similarity). Both maps are keyed by path
+// and hold each file's lines. A threshold of zero or less uses
+// DefaultRenameThreshold.
+//
+// Each file is used in at most one rename. Candidates are chosen greedily
+// from the most similar down; ties prefer the lexically smallest paths, so
+// the result is deterministic. The result is sorted by From.
+func DetectRenames(removed, added map[string][]string, threshold float64) []Rename {
+	if threshold <= 0 {
+		threshold = DefaultRenameThreshold
+	}
+
+	fromPaths := sortedKeys(removed)
+	toPaths := sortedKeys(added)
+
+	var candidates []Rename
+	for _, from := range fromPaths {
+		a := toElements(removed[from])
+		for _, to := range toPaths {
+			b := toElements(added[to])
+			// Similarity can be at most 2*min/(len(a)+len(b)); skip pairs
+			// whose sizes alone rule them out
+			if n := len(a) + len(b); n > 0 && 2*float64(min(len(a), len(b)))/float64(n) < threshold {
+				continue
+			}
+			sim := Similarity(a, b)
+			if sim < threshold {
+				continue
+			}
+			candidates = append(candidates, Rename{From: from, To: to, Similarity: sim})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+
+	usedFrom := make(map[string]bool)
+	usedTo := make(map[string]bool)
+	var renames []Rename
+	for _, c := range candidates {
+		if usedFrom[c.From] || usedTo[c.To] {
+			continue
+		}
+		usedFrom[c.From] = true
+		usedTo[c.To] = true
+		renames = append(renames, c)
+	}
+
+	sort.Slice(renames, func(i, j int) bool {
+		return renames[i].From < renames[j].From
+	})
+	return renames
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,63 @@
+package diffx
+
+import (
+	"testing"
+)
+
+func TestDetectRenames(t *testing.T) {
+	removed := map[string][]string{
+		"docs/old.md":   {"# Title", "one", "two", "three", "four"},
+		"docs/gone.md":  {"unrelated", "content"},
+		"src/helper.go": {"package src", "func a() {}", "func b() {}"},
+	}
+	added := map[string][]string{
+		"docs/new.md":     {"# Title", "one", "two", "three", "five"},
+		"pkg/helper.go":   {"package src", "func a() {}", "func b() {}"},
+		"docs/created.md": {"brand", "new", "file"},
+	}
+
+	renames := DetectRenames(removed, added, 0)
+	if len(renames) != 2 {
+		t.Fatalf("expected 2 renames, got %+v", renames)
+	}
+
+	if r := renames[0]; r.From != "docs/old.md" || r.To != "docs/new.md" || r.Percent() != 80 {
+		t.Errorf("renames[0] = %+v, want docs/old.md => docs/new.md at 80%%", r)
+	}
+	if r := renames[1]; r.From != "src/helper.go" || r.To != "pkg/helper.go" || r.Similarity != 1 {
+		t.Errorf("renames[1] = %+v, want exact rename of src/helper.go", r)
+	}
+}
+
+func TestDetectRenames_Threshold(t *testing.T) {
+	removed := map[string][]string{"a": {"1", "2", "3", "4"}}
+	added := map[string][]string{"b": {"1", "2", "x", "y"}}
+
+	if got := DetectRenames(removed, added, 0.5); len(got) != 1 {
+		t.Errorf("threshold 0.5: expected a rename, got %+v", got)
+	}
+	if got := DetectRenames(removed, added, 0.9); len(got) != 0 {
+		t.Errorf("threshold 0.9: expected no rename, got %+v", got)
+	}
+}
+
+func TestDetectRenames_EachFileUsedOnce(t *testing.T) {
+	content := []string{"same", "content", "here"}
+	removed := map[string][]string{"a": content}
+	added := map[string][]string{"b": content, "c": content}
+
+	renames := DetectRenames(removed, added, 0)
+	if len(renames) != 1 {
+		t.Fatalf("expected 1 rename, got %+v", renames)
+	}
+	if renames[0].To != "b" {
+		t.Errorf("tie should prefer smallest path, got %+v", renames[0])
+	}
+}
+
+func TestRename_String(t *testing.T) {
+	r := Rename{From: "a.txt", To: "b.txt", Similarity: 0.876}
+	if got, want := r.String(), "a.txt => b.txt (87% similarity)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
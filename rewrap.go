@@ -0,0 +1,96 @@
+package diffx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Re-wrap tolerant prose diff.
+//
+// Markdown and reStructuredText paragraphs are hard-wrapped, and changing
+// one word near the start of a paragraph often makes an editor re-flow all
+// the lines after it. A line diff then reports the whole paragraph, and a
+// plain word diff reports every moved line break. RewrapDiff compares the
+// words of both texts with line breaks inside a paragraph treated as plain
+// spaces, then maps the result back to the original lines, so a re-flowed
+// paragraph shows only the words that changed.
+
+// RewrapResult is a word diff of two texts that ignores line wrapping.
+type RewrapResult struct {
+	// A and B are the words, white space, and punctuation of each text;
+	// concatenating them reproduces the text. Ops is a diff of A and B.
+	A, B []string
+	Ops  []DiffOp
+
+	// LineA and LineB are the 0-based line of the first byte of each
+	// token of A and B.
+	LineA, LineB []int
+}
+
+// RewrapDiff compares two texts word by word, ignoring differences in
+// where lines are wrapped: white space that contains a single line break,
+// together with the indentation of the next line, compares equal to a
+// single space. A blank line still separates paragraphs, so joining or
+// splitting paragraphs is a change. Options configure the word diff.
+func RewrapDiff(aText, bText string, opts ...Option) *RewrapResult {
+	r := &RewrapResult{A: SplitWords(aText), B: SplitWords(bText)}
+	r.LineA, r.LineB = tokenLines(r.A), tokenLines(r.B)
+	r.Ops = Diff(rewrapKeys(r.A), rewrapKeys(r.B), opts...)
+	return r
+}
+
+// ChangedLines returns the lines of A and B, ascending and without
+// duplicates, that contain a deleted or inserted token. Lines whose words
+// only moved because of re-wrapping are not included.
+func (r *RewrapResult) ChangedLines() (a, b []int) {
+	for _, op := range r.Ops {
+		switch op.Type {
+		case Delete:
+			a = appendLines(a, r.LineA[op.AStart:op.AEnd])
+		case Insert:
+			b = appendLines(b, r.LineB[op.BStart:op.BEnd])
+		}
+	}
+	return a, b
+}
+
+// appendLines appends the lines not already at the end of dst. The lines
+// of successive tokens never decrease.
+func appendLines(dst, lines []int) []int {
+	for _, l := range lines {
+		if len(dst) == 0 || dst[len(dst)-1] < l {
+			dst = append(dst, l)
+		}
+	}
+	return dst
+}
+
+// tokenLines returns the 0-based line of the start of each token.
+func tokenLines(tokens []string) []int {
+	lines := make([]int, len(tokens))
+	line := 0
+	for i, t := range tokens {
+		lines[i] = line
+		line += strings.Count(t, "\n")
+	}
+	return lines
+}
+
+// rewrapKeys returns tokens with white space normalized: a paragraph break
+// becomes "\n\n", and any other white space containing a line break
+// becomes " ".
+func rewrapKeys(tokens []string) []string {
+	keys := make([]string, len(tokens))
+	for i, t := range tokens {
+		keys[i] = t
+		if !strings.Contains(t, "\n") || strings.TrimFunc(t, unicode.IsSpace) != "" {
+			continue
+		}
+		if strings.Count(t, "\n") > 1 {
+			keys[i] = "\n\n"
+		} else {
+			keys[i] = " "
+		}
+	}
+	return keys
+}
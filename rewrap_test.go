@@ -0,0 +1,48 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRewrapDiff(t *testing.T) {
+	a := "The quick brown fox jumps\nover the lazy dog and\nruns into the forest.\n\nSecond paragraph.\n"
+	b := "The quick red fox jumps over\nthe lazy dog and runs into\nthe forest.\n\nSecond paragraph.\n"
+
+	r := RewrapDiff(a, b)
+	if strings.Join(r.A, "") != a || strings.Join(r.B, "") != b {
+		t.Fatal("tokens do not reproduce the texts")
+	}
+
+	var deleted, inserted []string
+	for _, op := range r.Ops {
+		switch op.Type {
+		case Delete:
+			deleted = append(deleted, r.A[op.AStart:op.AEnd]...)
+		case Insert:
+			inserted = append(inserted, r.B[op.BStart:op.BEnd]...)
+		}
+	}
+	if !reflect.DeepEqual(deleted, []string{"brown"}) || !reflect.DeepEqual(inserted, []string{"red"}) {
+		t.Errorf("changed tokens = %q, %q, want only brown -> red", deleted, inserted)
+	}
+
+	la, lb := r.ChangedLines()
+	if !reflect.DeepEqual(la, []int{0}) || !reflect.DeepEqual(lb, []int{0}) {
+		t.Errorf("ChangedLines() = %v, %v, want [0], [0]", la, lb)
+	}
+}
+
+func TestRewrapDiff_ParagraphBreak(t *testing.T) {
+	// Splitting a paragraph is a change, and indentation after a wrapped
+	// line is not
+	r := RewrapDiff("One two\n   three four.", "One two three\n\nfour.")
+	la, lb := r.ChangedLines()
+	if len(la) != 1 || !reflect.DeepEqual(lb, []int{0}) {
+		t.Errorf("ChangedLines() = %v, %v, want one line of A and [0]", la, lb)
+	}
+	if countChangeRegions(r.Ops) != 1 {
+		t.Errorf("Ops = %v, want one change", r.Ops)
+	}
+}
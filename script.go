@@ -0,0 +1,74 @@
+package diffx
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// EditScript is an edit script as returned by Diff and the other diff
+// functions, with methods for logging and inspecting it. Convert a result
+// with EditScript(ops).
+type EditScript []DiffOp
+
+// maxLoggedOps is how many operations LogValue writes before eliding the
+// rest.
+const maxLoggedOps = 10
+
+// LogValue implements slog.LogValuer. It logs the script as a group with
+// its number of operations, insertions, deletions, and hunks, and its
+// change operations in their String form, up to ten of them, so that a
+// large script does not flood the log.
+func (s EditScript) LogValue() slog.Value {
+	st := Stat(s)
+	var changes []string
+	for _, op := range s {
+		if op.Type == Equal {
+			continue
+		}
+		if len(changes) == maxLoggedOps {
+			changes = append(changes, "...")
+			break
+		}
+		changes = append(changes, op.String())
+	}
+	return slog.GroupValue(
+		slog.Int("ops", len(s)),
+		slog.Int("insertions", st.Insertions),
+		slog.Int("deletions", st.Deletions),
+		slog.Int("hunks", st.Hunks),
+		slog.String("changes", strings.Join(changes, ", ")),
+	)
+}
+
+// TotalEqual returns the number of unchanged elements.
+func (s EditScript) TotalEqual() int {
+	n := 0
+	for _, op := range s {
+		if op.Type == Equal {
+			n += op.LenA()
+		}
+	}
+	return n
+}
+
+// TotalInserted returns the number of elements inserted from B.
+func (s EditScript) TotalInserted() int {
+	n := 0
+	for _, op := range s {
+		if op.Type == Insert {
+			n += op.LenB()
+		}
+	}
+	return n
+}
+
+// TotalDeleted returns the number of elements deleted from A.
+func (s EditScript) TotalDeleted() int {
+	n := 0
+	for _, op := range s {
+		if op.Type == Delete {
+			n += op.LenA()
+		}
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package diffx
+
+import "iter"
+
+// All returns an iterator over the operations of the script.
+func (s EditScript) All() iter.Seq[DiffOp] {
+	return func(yield func(DiffOp) bool) {
+		for _, op := range s {
+			if !yield(op) {
+				return
+			}
+		}
+	}
+}
+
+// Changes returns an iterator over the Delete and Insert operations.
+func (s EditScript) Changes() iter.Seq[DiffOp] {
+	return func(yield func(DiffOp) bool) {
+		for _, op := range s {
+			if op.IsChange() && !yield(op) {
+				return
+			}
+		}
+	}
+}
+
+// Matched returns an iterator over the index pairs of the unchanged
+// elements: i in A and j in B.
+func (s EditScript) Matched() iter.Seq2[int, int] {
+	return func(yield func(i, j int) bool) {
+		for _, op := range s {
+			if op.Type != Equal {
+				continue
+			}
+			for k := 0; k < op.LenA(); k++ {
+				if !yield(op.AStart+k, op.BStart+k) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Deleted returns an iterator over the indices in A of the deleted
+// elements.
+func (s EditScript) Deleted() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for _, op := range s {
+			if op.Type != Delete {
+				continue
+			}
+			for i := op.AStart; i < op.AEnd; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Inserted returns an iterator over the indices in B of the inserted
+// elements.
+func (s EditScript) Inserted() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for _, op := range s {
+			if op.Type != Insert {
+				continue
+			}
+			for j := op.BStart; j < op.BEnd; j++ {
+				if !yield(j) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//go:build go1.23
+
+package diffx
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEditScript_Iterators(t *testing.T) {
+	a := strings.Fields("a b c d")
+	b := strings.Fields("a x c d e")
+	s := EditScript(Diff(a, b))
+
+	if got := slices.Collect(s.All()); !reflect.DeepEqual(got, []DiffOp(s)) {
+		t.Errorf("All() = %v, want %v", got, s)
+	}
+
+	var changes []string
+	for op := range s.Changes() {
+		changes = append(changes, op.String())
+	}
+	if want := []string{"D a[1:2]", "I b[1:2]", "I b[4:5]"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes() = %q, want %q", changes, want)
+	}
+
+	var matched [][2]int
+	for i, j := range s.Matched() {
+		if a[i] != b[j] {
+			t.Errorf("Matched() pairs %q and %q", a[i], b[j])
+		}
+		matched = append(matched, [2]int{i, j})
+	}
+	if want := [][2]int{{0, 0}, {2, 2}, {3, 3}}; !reflect.DeepEqual(matched, want) {
+		t.Errorf("Matched() = %v, want %v", matched, want)
+	}
+
+	if got := slices.Collect(s.Deleted()); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Deleted() = %v, want [1]", got)
+	}
+	if got := slices.Collect(s.Inserted()); !reflect.DeepEqual(got, []int{1, 4}) {
+		t.Errorf("Inserted() = %v, want [1 4]", got)
+	}
+}
+
+func TestEditScript_IteratorsStop(t *testing.T) {
+	s := EditScript(Diff(strings.Fields("a b c d e"), strings.Fields("v w x y z")))
+
+	n := 0
+	for range s.Deleted() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	for i := range s.Inserted() {
+		if i == 1 {
+			break
+		}
+	}
+	for range s.All() {
+		break
+	}
+	for range s.Changes() {
+		break
+	}
+	for range s.Matched() {
+		break
+	}
+	if n != 2 {
+		t.Errorf("Deleted() yielded %d elements before break, want 2", n)
+	}
+}
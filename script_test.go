@@ -0,0 +1,59 @@
+package diffx
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEditScript_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	ops := Diff(strings.Fields("a b c d"), strings.Fields("a x c d e"))
+	logger.Info("diffed", "diff", EditScript(ops))
+
+	want := `level=INFO msg=diffed diff.ops=5 diff.insertions=2 diff.deletions=1 diff.hunks=2 diff.changes="D a[1:2], I b[1:2], I b[4:5]"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("log =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEditScript_LogValueElided(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		a = append(a, fmt.Sprint("same", i), fmt.Sprint("old", i))
+		b = append(b, fmt.Sprint("same", i))
+	}
+	v := EditScript(Diff(a, b)).LogValue()
+
+	var changes string
+	for _, attr := range v.Group() {
+		if attr.Key == "changes" {
+			changes = attr.Value.String()
+		}
+	}
+	if n := strings.Count(changes, "D "); n != maxLoggedOps || !strings.HasSuffix(changes, ", ...") {
+		t.Errorf("changes = %q, want %d deletions and an ellipsis", changes, maxLoggedOps)
+	}
+}
+
+func TestEditScript_Totals(t *testing.T) {
+	s := EditScript(Diff([]string{"a", "b", "c", "d"}, []string{"a", "x", "y", "c", "d", "e"}))
+	if s.TotalEqual() != 3 || s.TotalDeleted() != 1 || s.TotalInserted() != 3 {
+		t.Errorf("totals = %d equal, %d deleted, %d inserted; want 3, 1, 3",
+			s.TotalEqual(), s.TotalDeleted(), s.TotalInserted())
+	}
+	if s := EditScript(nil); s.TotalEqual() != 0 || s.TotalDeleted() != 0 || s.TotalInserted() != 0 {
+		t.Errorf("empty script has non-zero totals")
+	}
+}
@@ -0,0 +1,185 @@
+package diffx
+
+import "sort"
+
+// Section-scoped diff.
+//
+// Documentation teams review changes section by section: "Installation:
+// two steps reworded; Troubleshooting: new entry". A line diff of a whole
+// document splits hunks without regard to headings and shows a few lines
+// of context instead of the section an edit belongs to. DiffSections pairs
+// the sections of two documents, first by heading and then by content for
+// renamed headings, and diffs each pair on its own.
+
+// minSectionSimilarity is the minimum similarity for two sections with
+// different headings to be paired.
+const minSectionSimilarity = 0.5
+
+// SectionDiff is the diff of a section that changed between two documents.
+type SectionDiff struct {
+	// Path is the heading of the section and of the sections enclosing
+	// it, outermost first, in document B for sections present there and
+	// in document A for removed sections. The preamble has an empty path.
+	Path []string
+
+	// A and B are the section in each document; A is nil for an added
+	// section and B is nil for a removed one.
+	A, B *Section
+
+	Ops   []DiffOp // diff of the section contents, indexed into the full documents
+	Edits int      // number of change regions inside the section
+}
+
+// DiffSections splits a and b into sections with split (for example
+// MarkdownSections) and returns the sections that were added, removed, or
+// changed, in the order of document B with removed sections after the
+// section that preceded them in A.
+//
+// Sections are paired in order by heading level and text. Sections left
+// over are paired by the similarity of their lines, so that a section
+// whose heading was reworded is diffed against its old version; pairs
+// less than half similar are reported as a removal and an addition
+// instead. Options configure the diff of each section.
+func DiffSections(a, b []string, split SectionSplitter, opts ...Option) []SectionDiff {
+	sectionsA, sectionsB := split(a), split(b)
+	pathsA, pathsB := sectionPaths(sectionsA), sectionPaths(sectionsB)
+
+	// Pair sections with the same heading, in order
+	pairA := make([]int, len(sectionsA)) // index of the paired section of B, or -1
+	pairB := make([]int, len(sectionsB))
+	for i := range pairA {
+		pairA[i] = -1
+	}
+	for j := range pairB {
+		pairB[j] = -1
+	}
+	keysA := make([]string, len(sectionsA))
+	keysB := make([]string, len(sectionsB))
+	for i, s := range sectionsA {
+		keysA[i] = sectionKey(s)
+	}
+	for j, s := range sectionsB {
+		keysB[j] = sectionKey(s)
+	}
+	for _, op := range Diff(keysA, keysB, WithPreprocessing(false)) {
+		if op.Type != Equal {
+			continue
+		}
+		for k := 0; k < op.AEnd-op.AStart; k++ {
+			pairA[op.AStart+k], pairB[op.BStart+k] = op.BStart+k, op.AStart+k
+		}
+	}
+
+	// Pair moved sections by heading, then reworded ones by content
+	for i := range sectionsA {
+		if pairA[i] >= 0 {
+			continue
+		}
+		for j := range sectionsB {
+			if pairB[j] < 0 && keysB[j] == keysA[i] {
+				pairA[i], pairB[j] = j, i
+				break
+			}
+		}
+	}
+	type candidate struct {
+		i, j int
+		sim  float64
+	}
+	var candidates []candidate
+	for i, sa := range sectionsA {
+		if pairA[i] >= 0 {
+			continue
+		}
+		for j, sb := range sectionsB {
+			if pairB[j] >= 0 || (sa.Level == 0) != (sb.Level == 0) {
+				continue
+			}
+			ops := Diff(a[sa.Start:sa.End], b[sb.Start:sb.End], opts...)
+			if sim := similarityOf(ops, sa.End-sa.Start, sb.End-sb.Start); sim >= minSectionSimilarity {
+				candidates = append(candidates, candidate{i, j, sim})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(x, y int) bool { return candidates[x].sim > candidates[y].sim })
+	for _, c := range candidates {
+		if pairA[c.i] < 0 && pairB[c.j] < 0 {
+			pairA[c.i], pairB[c.j] = c.j, c.i
+		}
+	}
+
+	// Order by position in B; a removed section follows the section of B
+	// paired with the nearest paired section before it in A
+	type entry struct {
+		pos, sub int
+		diff     SectionDiff
+	}
+	var entries []entry
+	aStart := 0 // end in A of the section paired with the last paired section of B
+	for j := range sectionsB {
+		sb := &sectionsB[j]
+		d := SectionDiff{Path: pathsB[j], B: sb}
+		if i := pairB[j]; i >= 0 {
+			sa := &sectionsA[i]
+			d.A = sa
+			d.Ops = OffsetOps(Diff(a[sa.Start:sa.End], b[sb.Start:sb.End], opts...), sa.Start, sb.Start)
+			aStart = sa.End
+		} else {
+			d.Ops = []DiffOp{{Type: Insert, AStart: aStart, AEnd: aStart, BStart: sb.Start, BEnd: sb.End}}
+		}
+		d.Edits = countChangeRegions(d.Ops)
+		if d.Edits > 0 {
+			entries = append(entries, entry{pos: j, diff: d})
+		}
+	}
+	prev := -1
+	for i := range sectionsA {
+		if pairA[i] >= 0 {
+			prev = pairA[i]
+			continue
+		}
+		sa := &sectionsA[i]
+		bStart := 0
+		if prev >= 0 {
+			bStart = sectionsB[prev].End
+		}
+		d := SectionDiff{Path: pathsA[i], A: sa}
+		d.Ops = []DiffOp{{Type: Delete, AStart: sa.Start, AEnd: sa.End, BStart: bStart, BEnd: bStart}}
+		d.Edits = 1
+		entries = append(entries, entry{pos: prev, sub: i + 1, diff: d})
+	}
+	sort.SliceStable(entries, func(x, y int) bool {
+		if entries[x].pos != entries[y].pos {
+			return entries[x].pos < entries[y].pos
+		}
+		return entries[x].sub < entries[y].sub
+	})
+
+	diffs := make([]SectionDiff, len(entries))
+	for k, e := range entries {
+		diffs[k] = e.diff
+	}
+	return diffs
+}
+
+// sectionPaths returns the heading path of each section: the headings of
+// the nearest enclosing sections of lower level, then its own heading.
+func sectionPaths(sections []Section) [][]string {
+	paths := make([][]string, len(sections))
+	var stack []Section
+	for i, s := range sections {
+		if s.Level == 0 {
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= s.Level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, s)
+		path := make([]string, len(stack))
+		for k, p := range stack {
+			path[k] = p.Heading
+		}
+		paths[i] = path
+	}
+	return paths
+}
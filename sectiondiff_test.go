@@ -0,0 +1,86 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffSections(t *testing.T) {
+	a := strings.Split(strings.Join([]string{
+		"Intro text",
+		"# Guide",
+		"Welcome.",
+		"## Install",
+		"Download the archive.",
+		"Unpack it.",
+		"Run the installer.",
+		"## Old Notes",
+		"Nothing here.",
+		"## Configure",
+		"Edit the file.",
+		"Restart the service.",
+		"Check the log.",
+	}, "\n"), "\n")
+	b := strings.Split(strings.Join([]string{
+		"Intro text",
+		"# Guide",
+		"Welcome.",
+		"## Install",
+		"Download the archive.",
+		"Extract it.",
+		"Run the installer.",
+		"## Configuration",
+		"Edit the file.",
+		"Restart the service.",
+		"Check the log.",
+		"## FAQ",
+		"Ask away.",
+	}, "\n"), "\n")
+
+	diffs := DiffSections(a, b, MarkdownSections)
+
+	type summary struct {
+		path  string
+		inA   bool
+		inB   bool
+		edits int
+	}
+	var got []summary
+	for _, d := range diffs {
+		got = append(got, summary{strings.Join(d.Path, " > "), d.A != nil, d.B != nil, d.Edits})
+	}
+	want := []summary{
+		{"Guide > Install", true, true, 1},
+		{"Guide > Old Notes", true, false, 1},
+		{"Guide > Configuration", true, true, 1}, // heading reworded, content kept
+		{"Guide > FAQ", false, true, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiffSections() = %+v, want %+v", got, want)
+	}
+
+	// Ops index the full documents
+	install := diffs[0]
+	for _, op := range install.Ops {
+		if op.Type == Delete && a[op.AStart] != "Unpack it." {
+			t.Errorf("deleted line = %q, want %q", a[op.AStart], "Unpack it.")
+		}
+		if op.Type == Insert && b[op.BStart] != "Extract it." {
+			t.Errorf("inserted line = %q, want %q", b[op.BStart], "Extract it.")
+		}
+	}
+	if d := diffs[2]; d.A.Heading != "Configure" || d.B.Heading != "Configuration" {
+		t.Errorf("reworded section paired %q with %q", d.A.Heading, d.B.Heading)
+	}
+	if op := diffs[3].Ops[0]; op.Type != Insert || op.AStart != len(a) || op.BStart != 11 || op.BEnd != 13 {
+		t.Errorf("added section op = %+v", op)
+	}
+}
+
+func TestDiffSections_Unchanged(t *testing.T) {
+	doc := []string{"# A", "one", "# B", "two"}
+	if diffs := DiffSections(doc, doc, MarkdownSections); len(diffs) != 0 {
+		t.Errorf("DiffSections() = %+v, want none", diffs)
+	}
+}
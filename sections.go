@@ -0,0 +1,188 @@
+package diffx
+
+import "strings"
+
+// Section splitting for structured documents.
+//
+// Markdown and reStructuredText documents are organized as heading-delimited
+// sections. Splitting lines into sections lets higher-level analyses work on
+// whole sections (detecting moves, scoping diffs) instead of raw lines.
+
+// Section is a heading-delimited range of lines in a document.
+// Sections are flat: a subsection starts a new section.
+type Section struct {
+	Heading string // heading text without markup, "" for the preamble
+	Level   int    // heading level starting at 1, 0 for the preamble
+	Start   int    // index of the first line, including the heading (inclusive)
+	End     int    // index after the last line (exclusive)
+}
+
+// SectionSplitter splits document lines into sections.
+// Every line must belong to exactly one section, in order.
+type SectionSplitter func(lines []string) []Section
+
+// MarkdownSections splits Markdown lines into sections at ATX headings
+// ("## Title") and setext headings (a line underlined with === or ---).
+// Headings inside fenced code blocks are ignored. Lines before the first
+// heading form a preamble section, which is omitted when empty.
+func MarkdownSections(lines []string) []Section {
+	var headings []Section
+	inFence := false
+	fence := ""
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if level, text, ok := atxHeading(trimmed); ok {
+			headings = append(headings, Section{Heading: text, Level: level, Start: i})
+			continue
+		}
+
+		if trimmed != "" && i+1 < len(lines) {
+			underline := strings.TrimSpace(lines[i+1])
+			if isRepeated(underline, '=') {
+				headings = append(headings, Section{Heading: trimmed, Level: 1, Start: i})
+				i++
+			} else if isRepeated(underline, '-') && !strings.HasPrefix(trimmed, "-") {
+				headings = append(headings, Section{Heading: trimmed, Level: 2, Start: i})
+				i++
+			}
+		}
+	}
+
+	return closeSections(headings, len(lines))
+}
+
+// RSTSections splits reStructuredText lines into sections. A heading is a
+// line of text underlined (and optionally overlined) with a repeated
+// punctuation character at least as long as the text. Levels are assigned
+// in order of first appearance of each underline style, as in docutils.
+func RSTSections(lines []string) []Section {
+	var headings []Section
+	styles := make(map[string]int)
+
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimSpace(lines[i])
+		if text == "" || i+1 >= len(lines) {
+			continue
+		}
+
+		// Overlined heading: punctuation, text, punctuation
+		if isRSTAdornment(text) && i+2 < len(lines) {
+			title := strings.TrimSpace(lines[i+1])
+			under := strings.TrimSpace(lines[i+2])
+			if title != "" && under == text && len(text) >= len(title) {
+				style := "over" + text[:1]
+				headings = append(headings, Section{Heading: title, Level: rstLevel(styles, style), Start: i})
+				i += 2
+				continue
+			}
+		}
+
+		under := strings.TrimSpace(lines[i+1])
+		if !isRSTAdornment(text) && isRSTAdornment(under) && len(under) >= len(text) {
+			style := under[:1]
+			headings = append(headings, Section{Heading: text, Level: rstLevel(styles, style), Start: i})
+			i++
+		}
+	}
+
+	return closeSections(headings, len(lines))
+}
+
+// closeSections fills in the End of each heading section and adds the
+// preamble section when there is content before the first heading.
+func closeSections(headings []Section, n int) []Section {
+	if n == 0 {
+		return nil
+	}
+
+	var sections []Section
+	first := n
+	if len(headings) > 0 {
+		first = headings[0].Start
+	}
+	if first > 0 {
+		sections = append(sections, Section{Start: 0, End: first})
+	}
+
+	for i, h := range headings {
+		h.End = n
+		if i+1 < len(headings) {
+			h.End = headings[i+1].Start
+		}
+		sections = append(sections, h)
+	}
+	return sections
+}
+
+// atxHeading parses an ATX heading such as "## Title ##".
+func atxHeading(line string) (level int, text string, ok bool) {
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	if level < len(line) && line[level] != ' ' && line[level] != '\t' {
+		return 0, "", false
+	}
+	text = strings.TrimSpace(line[level:])
+	text = strings.TrimSpace(strings.TrimRight(text, "#"))
+	return level, text, true
+}
+
+// fenceMarker returns the fence ("```" or "~~~") that line opens or
+// closes, or "" if line is not a code fence.
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// isRepeated reports whether s is non-empty and consists only of c.
+func isRepeated(s string, c byte) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			return false
+		}
+	}
+	return true
+}
+
+// isRSTAdornment reports whether s is a valid reStructuredText section
+// adornment: at least two repetitions of one punctuation character.
+func isRSTAdornment(s string) bool {
+	if len(s) < 2 || !strings.ContainsRune("=-`:'\"~^_*+#<>.", rune(s[0])) {
+		return false
+	}
+	return isRepeated(s, s[0])
+}
+
+// rstLevel returns the level for an adornment style, assigning the next
+// level the first time a style is seen.
+func rstLevel(styles map[string]int, style string) int {
+	if level, ok := styles[style]; ok {
+		return level
+	}
+	styles[style] = len(styles) + 1
+	return styles[style]
+}
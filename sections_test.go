@@ -0,0 +1,88 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarkdownSections(t *testing.T) {
+	lines := []string{
+		"Preamble text",
+		"",
+		"# Title",
+		"Intro",
+		"## Install ##",
+		"```",
+		"# not a heading",
+		"```",
+		"Usage",
+		"-----",
+		"Run it.",
+	}
+
+	got := MarkdownSections(lines)
+	want := []Section{
+		{Heading: "", Level: 0, Start: 0, End: 2},
+		{Heading: "Title", Level: 1, Start: 2, End: 4},
+		{Heading: "Install", Level: 2, Start: 4, End: 8},
+		{Heading: "Usage", Level: 2, Start: 8, End: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarkdownSections() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestMarkdownSections_NoHeadings(t *testing.T) {
+	got := MarkdownSections([]string{"just", "text"})
+	want := []Section{{Start: 0, End: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarkdownSections() = %v, want %v", got, want)
+	}
+
+	if got := MarkdownSections(nil); got != nil {
+		t.Errorf("MarkdownSections(nil) = %v, want nil", got)
+	}
+}
+
+func TestMarkdownSections_NotHeadings(t *testing.T) {
+	lines := []string{"#hashtag", "####### seven", "- item", "---"}
+	got := MarkdownSections(lines)
+	if len(got) != 1 || got[0].Level != 0 {
+		t.Errorf("expected only a preamble, got %v", got)
+	}
+}
+
+func TestRSTSections(t *testing.T) {
+	lines := []string{
+		"=====",
+		"Title",
+		"=====",
+		"",
+		"Intro",
+		"-----",
+		"text",
+		"Details",
+		"~~~~~~~",
+		"Other",
+		"-----",
+	}
+
+	got := RSTSections(lines)
+	want := []Section{
+		{Heading: "Title", Level: 1, Start: 0, End: 4},
+		{Heading: "Intro", Level: 2, Start: 4, End: 7},
+		{Heading: "Details", Level: 3, Start: 7, End: 9},
+		{Heading: "Other", Level: 2, Start: 9, End: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RSTSections() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestRSTSections_ShortUnderline(t *testing.T) {
+	// The underline must be at least as long as the title
+	got := RSTSections([]string{"Long title", "---"})
+	if len(got) != 1 || got[0].Level != 0 {
+		t.Errorf("expected only a preamble, got %v", got)
+	}
+}
@@ -0,0 +1,155 @@
+package diffx
+
+import "sync"
+
+// Python difflib compatibility.
+//
+// Projects ported from Python often build on difflib.SequenceMatcher:
+// ratio() as a similarity score, get_matching_blocks() to find shared
+// runs, and get_opcodes() to render changes. SequenceMatcher offers the
+// same three methods, with the same result shapes, backed by a diffx
+// diff, so ported code keeps its structure while gaining diffx's
+// alignment. Because the alignment is diffx's rather than difflib's
+// longest-block search, the blocks and ratios can differ from Python's
+// for the same inputs.
+
+// Match is a run of equal elements, a[A:A+Size] and b[B:B+Size], as
+// returned by Python's get_matching_blocks().
+type Match struct {
+	A, B, Size int
+}
+
+// Opcode describes how to turn a[I1:I2] into b[J1:J2], as returned by
+// Python's get_opcodes(). Tag is "replace", "delete", "insert", or
+// "equal".
+type Opcode struct {
+	Tag            string
+	I1, I2, J1, J2 int
+}
+
+// SequenceMatcher compares two sequences with the interface of Python's
+// difflib.SequenceMatcher. The diff is computed on first use and cached;
+// a SequenceMatcher is safe for concurrent use.
+//
+// difflib's isjunk and autojunk arguments have no equivalent: diffx
+// preprocessing already keeps frequent elements from anchoring the
+// alignment, and options such as WithIgnoreCase and WithIgnoreAllSpace
+// control which elements compare equal.
+type SequenceMatcher struct {
+	a, b []Element
+	opts []Option
+
+	once   sync.Once
+	blocks []Match
+}
+
+// NewSequenceMatcher returns a SequenceMatcher for the string slices a
+// and b. The options configure the diff, as for Diff.
+func NewSequenceMatcher(a, b []string, opts ...Option) *SequenceMatcher {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return NewSequenceMatcherElements(o.ignoreOpts.elements(a), o.ignoreOpts.elements(b), opts...)
+}
+
+// NewSequenceMatcherElements returns a SequenceMatcher for the Element
+// slices a and b. The options configure the diff, as for DiffElements.
+func NewSequenceMatcherElements(a, b []Element, opts ...Option) *SequenceMatcher {
+	return &SequenceMatcher{a: a, b: b, opts: opts}
+}
+
+// GetMatchingBlocks returns the runs of equal elements, in increasing
+// order of both indices, followed by the sentinel {len(a), len(b), 0}.
+// Adjacent runs are merged, so no run directly follows another in both
+// sequences.
+func (m *SequenceMatcher) GetMatchingBlocks() []Match {
+	m.once.Do(m.match)
+	return append([]Match(nil), m.blocks...)
+}
+
+// match diffs the sequences and records the matching blocks.
+func (m *SequenceMatcher) match() {
+	for _, op := range DiffElements(m.a, m.b, m.opts...) {
+		if op.Type != Equal {
+			continue
+		}
+		if k := len(m.blocks) - 1; k >= 0 && m.blocks[k].A+m.blocks[k].Size == op.AStart && m.blocks[k].B+m.blocks[k].Size == op.BStart {
+			m.blocks[k].Size += op.AEnd - op.AStart
+			continue
+		}
+		m.blocks = append(m.blocks, Match{A: op.AStart, B: op.BStart, Size: op.AEnd - op.AStart})
+	}
+	m.blocks = append(m.blocks, Match{A: len(m.a), B: len(m.b)})
+}
+
+// GetOpcodes returns the operations that turn a into b, derived from the
+// matching blocks as Python derives them: each gap between blocks is a
+// replace, delete, or insert, and each block an equal. Together they
+// cover both sequences in order. Two empty sequences have no opcodes.
+func (m *SequenceMatcher) GetOpcodes() []Opcode {
+	var codes []Opcode
+	i, j := 0, 0
+	for _, blk := range m.GetMatchingBlocks() {
+		tag := ""
+		switch {
+		case i < blk.A && j < blk.B:
+			tag = "replace"
+		case i < blk.A:
+			tag = "delete"
+		case j < blk.B:
+			tag = "insert"
+		}
+		if tag != "" {
+			codes = append(codes, Opcode{Tag: tag, I1: i, I2: blk.A, J1: j, J2: blk.B})
+		}
+		i, j = blk.A+blk.Size, blk.B+blk.Size
+		if blk.Size > 0 {
+			codes = append(codes, Opcode{Tag: "equal", I1: blk.A, I2: i, J1: blk.B, J2: j})
+		}
+	}
+	return codes
+}
+
+// Ratio returns a measure of the sequences' similarity in [0, 1]: 2*M/T,
+// where M is the number of matched elements and T the total number of
+// elements in both sequences. It is 1 for two empty sequences.
+func (m *SequenceMatcher) Ratio() float64 {
+	matched := 0
+	for _, blk := range m.GetMatchingBlocks() {
+		matched += blk.Size
+	}
+	return seqRatio(matched, len(m.a)+len(m.b))
+}
+
+// QuickRatio returns an upper bound on Ratio without diffing, from the
+// number of elements the sequences share regardless of order. Elements
+// are counted by hash.
+func (m *SequenceMatcher) QuickRatio() float64 {
+	counts := make(map[uint64]int, len(m.b))
+	for _, e := range m.b {
+		counts[e.Hash()]++
+	}
+	shared := 0
+	for _, e := range m.a {
+		if h := e.Hash(); counts[h] > 0 {
+			counts[h]--
+			shared++
+		}
+	}
+	return seqRatio(shared, len(m.a)+len(m.b))
+}
+
+// RealQuickRatio returns an upper bound on Ratio from the lengths of the
+// sequences alone.
+func (m *SequenceMatcher) RealQuickRatio() float64 {
+	return seqRatio(min(len(m.a), len(m.b)), len(m.a)+len(m.b))
+}
+
+// seqRatio returns 2*matches/total, or 1 if total is 0.
+func seqRatio(matches, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return 2 * float64(matches) / float64(total)
+}
@@ -0,0 +1,102 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Expected results match Python's difflib; the first case of each test is
+// an example from its documentation.
+
+func TestSequenceMatcher_GetMatchingBlocks(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Match
+	}{
+		{"abxcd", "abcd", []Match{{0, 0, 2}, {3, 2, 2}, {5, 4, 0}}},
+		{"", "", []Match{{0, 0, 0}}},
+		{"abc", "", []Match{{3, 0, 0}}},
+		{"abc", "abc", []Match{{0, 0, 3}, {3, 3, 0}}},
+	}
+
+	for _, tt := range tests {
+		m := NewSequenceMatcher(chars(tt.a), chars(tt.b))
+		if got := m.GetMatchingBlocks(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetMatchingBlocks(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSequenceMatcher_GetOpcodes(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []Opcode
+	}{
+		{"qabxcd", "abycdf", []Opcode{
+			{"delete", 0, 1, 0, 0},
+			{"equal", 1, 3, 0, 2},
+			{"replace", 3, 4, 2, 3},
+			{"equal", 4, 6, 3, 5},
+			{"insert", 6, 6, 5, 6},
+		}},
+		{"", "", nil},
+		{"", "ab", []Opcode{{"insert", 0, 0, 0, 2}}},
+		{"ab", "ab", []Opcode{{"equal", 0, 2, 0, 2}}},
+		{"ab", "xy", []Opcode{{"replace", 0, 2, 0, 2}}},
+	}
+
+	for _, tt := range tests {
+		m := NewSequenceMatcher(chars(tt.a), chars(tt.b))
+		if got := m.GetOpcodes(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetOpcodes(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSequenceMatcher_Ratio(t *testing.T) {
+	tests := []struct {
+		a, b                    string
+		ratio, quick, realQuick float64
+	}{
+		{"abcd", "bcde", 0.75, 0.75, 1},
+		{"", "", 1, 1, 1},
+		{"abc", "", 0, 0, 0},
+		{"ab", "ba", 0.5, 1, 1},
+	}
+
+	for _, tt := range tests {
+		m := NewSequenceMatcher(chars(tt.a), chars(tt.b))
+		if got := m.Ratio(); got != tt.ratio {
+			t.Errorf("Ratio(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.ratio)
+		}
+		if got := m.QuickRatio(); got != tt.quick {
+			t.Errorf("QuickRatio(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.quick)
+		}
+		if got := m.RealQuickRatio(); got != tt.realQuick {
+			t.Errorf("RealQuickRatio(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.realQuick)
+		}
+	}
+}
+
+func TestSequenceMatcher_Options(t *testing.T) {
+	m := NewSequenceMatcher([]string{"Hello", "World"}, []string{"hello", "world"}, WithIgnoreCase(true))
+	if got := m.Ratio(); got != 1 {
+		t.Errorf("Ratio() = %v, want 1 ignoring case", got)
+	}
+
+	// Results are copies
+	blocks := m.GetMatchingBlocks()
+	blocks[0].Size = 0
+	if m.GetMatchingBlocks()[0].Size != 2 {
+		t.Error("GetMatchingBlocks() returned the cached slice")
+	}
+}
+
+// chars splits s into one-character strings, as Python iterates a string.
+func chars(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "")
+}
@@ -0,0 +1,124 @@
+package diffx
+
+import "fmt"
+
+// Unordered comparison.
+//
+// Dependency lists, tag sets, and allow lists are sets: their order carries
+// no meaning, and an aligned diff of two sorted or unsorted copies reports
+// moves and spurious replacements. DiffSet compares the contents only, in
+// linear time, by hashing. DiffCounts does the same for multisets, such as
+// word frequencies or inventories, and reports how often each string
+// occurs on each side.
+
+// SetDiff is the difference between two sets of strings.
+type SetDiff struct {
+	Added   []string // strings of B not in A, in order of first appearance in B
+	Removed []string // strings of A not in B, in order of first appearance in A
+}
+
+// Empty reports whether the sets are equal.
+func (d SetDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DiffSet compares a and b as sets, ignoring order and repetition, and
+// returns the strings only one of them contains. It runs in O(len(a) +
+// len(b)) time. Options that normalize strings for comparison, such as
+// WithIgnoreCase or WithIgnoreAllSpace, apply; a string that matches one
+// of the other set after normalization is not reported. The other options
+// have no effect.
+func DiffSet(a, b []string, opts ...Option) SetDiff {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	keysA, keysB := o.ignoreOpts.keys(a), o.ignoreOpts.keys(b)
+
+	inA := make(map[Element]bool, len(keysA))
+	for _, k := range keysA {
+		inA[k] = true
+	}
+	inB := make(map[Element]bool, len(keysB))
+	for _, k := range keysB {
+		inB[k] = true
+	}
+
+	var d SetDiff
+	for j, k := range keysB {
+		if !inA[k] {
+			d.Added = append(d.Added, b[j])
+			inA[k] = true // report each string once
+		}
+	}
+	for i, k := range keysA {
+		if !inB[k] {
+			d.Removed = append(d.Removed, a[i])
+			inB[k] = true
+		}
+	}
+	return d
+}
+
+// CountChange is a string that occurs a different number of times in two
+// sequences.
+type CountChange struct {
+	Value string // the string, as it first occurs in A, or in B if absent from A
+	A, B  int    // number of occurrences in each sequence
+}
+
+// String returns a description such as "apple: 3 -> 1".
+func (c CountChange) String() string {
+	return fmt.Sprintf("%s: %d -> %d", c.Value, c.A, c.B)
+}
+
+// Delta returns the change in the number of occurrences, B minus A.
+func (c CountChange) Delta() int {
+	return c.B - c.A
+}
+
+// DiffCounts compares a and b as multisets and returns the strings whose
+// number of occurrences differs, in order of first appearance in a, then
+// in b. Unlike Diff, it ignores order: a string that moved is not reported,
+// while one repeated more or less often is, even if Diff would match the
+// copies elsewhere. Options that normalize strings apply as for DiffSet.
+// It runs in O(len(a) + len(b)) time.
+func DiffCounts(a, b []string, opts ...Option) []CountChange {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	keysA, keysB := o.ignoreOpts.keys(a), o.ignoreOpts.keys(b)
+
+	index := make(map[Element]int) // position in counts
+	var counts []CountChange
+	for i, k := range keysA {
+		n, ok := index[k]
+		if !ok {
+			n = len(counts)
+			index[k] = n
+			counts = append(counts, CountChange{Value: a[i]})
+		}
+		counts[n].A++
+	}
+	for j, k := range keysB {
+		n, ok := index[k]
+		if !ok {
+			n = len(counts)
+			index[k] = n
+			counts = append(counts, CountChange{Value: b[j]})
+		}
+		counts[n].B++
+	}
+
+	changes := counts[:0]
+	for _, c := range counts {
+		if c.A != c.B {
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
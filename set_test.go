@@ -0,0 +1,92 @@
+package diffx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSet(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           []string
+		opts           []Option
+		added, removed []string
+	}{
+		{
+			name:    "reordered",
+			a:       []string{"x", "y", "z"},
+			b:       []string{"z", "x", "y"},
+			added:   nil,
+			removed: nil,
+		},
+		{
+			name:    "added and removed",
+			a:       []string{"cobra", "viper", "testify"},
+			b:       []string{"testify", "cobra", "zap", "pflag"},
+			added:   []string{"zap", "pflag"},
+			removed: []string{"viper"},
+		},
+		{
+			name:    "repetition ignored",
+			a:       []string{"a", "a", "b"},
+			b:       []string{"b", "c", "c", "b"},
+			added:   []string{"c"},
+			removed: []string{"a"},
+		},
+		{
+			name:    "normalized",
+			a:       []string{"Go", "Rust "},
+			b:       []string{"rust", "go", "Zig"},
+			opts:    []Option{WithIgnoreCase(true), WithIgnoreAllSpace(true)},
+			added:   []string{"Zig"},
+			removed: nil,
+		},
+		{
+			name:    "empty",
+			a:       nil,
+			b:       []string{"a"},
+			added:   []string{"a"},
+			removed: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiffSet(tt.a, tt.b, tt.opts...)
+			if !reflect.DeepEqual(d.Added, tt.added) || !reflect.DeepEqual(d.Removed, tt.removed) {
+				t.Errorf("DiffSet() = %+v, want added %v, removed %v", d, tt.added, tt.removed)
+			}
+			if d.Empty() != (tt.added == nil && tt.removed == nil) {
+				t.Errorf("Empty() = %v", d.Empty())
+			}
+		})
+	}
+}
+
+func TestDiffCounts(t *testing.T) {
+	a := []string{"apple", "pear", "apple", "fig", "apple"}
+	b := []string{"fig", "apple", "plum", "pear", "pear"}
+
+	got := DiffCounts(a, b)
+	want := []CountChange{
+		{Value: "apple", A: 3, B: 1},
+		{Value: "pear", A: 1, B: 2},
+		{Value: "plum", A: 0, B: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffCounts() = %v, want %v", got, want)
+	}
+	if s := got[0].String(); s != "apple: 3 -> 1" {
+		t.Errorf("String() = %q", s)
+	}
+	if d := got[0].Delta(); d != -2 {
+		t.Errorf("Delta() = %d, want -2", d)
+	}
+
+	if got := DiffCounts([]string{"x", "y", "x"}, []string{"x", "x", "y"}); got != nil {
+		t.Errorf("reordered: DiffCounts() = %v, want nil", got)
+	}
+	got = DiffCounts([]string{"The", "the"}, []string{"THE"}, WithIgnoreCase(true))
+	if want := []CountChange{{Value: "The", A: 2, B: 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WithIgnoreCase: DiffCounts() = %v, want %v", got, want)
+	}
+}
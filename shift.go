@@ -2,6 +2,8 @@ package diffx
 
 import (
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Boundary shifting preferences (higher = more preferred)
@@ -22,25 +24,44 @@ const (
 //   - Keeping blank lines as separators (not part of changes)
 //   - Aligning with logical block boundaries
 //   - Grouping related changes together
-func shiftBoundaries(ops []DiffOp, a, b []Element) []DiffOp {
+//
+// When opts enables the indent heuristic, slidable regions are placed using
+// Git-style indentation scoring instead of the readability scores below.
+func shiftBoundaries(ops []DiffOp, a, b []Element, opts *options) []DiffOp {
 	if len(ops) == 0 {
 		return ops
 	}
+	if opts == nil {
+		opts = defaultOptions()
+	}
 
 	// First pass: shift individual operations
-	result := make([]DiffOp, 0, len(ops))
-	for i, op := range ops {
-		if op.Type == Equal {
-			result = append(result, op)
-			continue
+	var result []DiffOp
+	if opts.indentHeuristic {
+		result = slideIndentHeuristic(ops, a, b, opts.trace)
+	} else {
+		result = padWithEqual(ops)
+		for i, op := range result {
+			// Paired replace regions are shifted jointly below; longer
+			// runs of changes stay in place
+			if !isIsolatedChange(result, i) {
+				continue
+			}
+			shifted := shiftOp(op, result, i, a, b)
+			if shift := shifted.AStart - op.AStart; shift != 0 {
+				slideRun(result, i, i+1, shift)
+				traceShift(opts.trace, result, i, i+1, shift)
+			}
 		}
-		shifted := shiftOp(op, ops, i, a, b)
-		result = append(result, shifted)
+		result = dropEmptyOps(result)
 	}
 
 	// Second pass: merge adjacent operations of the same type
 	result = mergeAdjacentOps(result)
 
+	// Shift Delete+Insert pairs together so they stay adjacent
+	result = shiftPairedChanges(result, a, b, opts)
+
 	// Third pass: try to improve boundaries between change regions
 	result = optimizeBoundaries(result, a, b)
 
@@ -48,6 +69,8 @@ func shiftBoundaries(ops []DiffOp, a, b []Element) []DiffOp {
 }
 
 // shiftOp attempts to shift a single operation's boundaries for readability.
+// The operation slides only over the Equal operations next to it in ops, so
+// that the shifted operation can be applied with slideRun.
 func shiftOp(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	switch op.Type {
 	case Delete:
@@ -70,7 +93,7 @@ func shiftDelete(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	maxShiftBackward := 0
 
 	// Check forward shifting potential
-	for i := 0; op.AEnd+i < len(a); i++ {
+	for i := 0; i < equalLen(ops, idx+1); i++ {
 		if !a[op.AStart+i].Equal(a[op.AEnd+i]) {
 			break
 		}
@@ -78,7 +101,7 @@ func shiftDelete(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	}
 
 	// Check backward shifting potential
-	for i := 0; op.AStart-i-1 >= 0; i++ {
+	for i := 0; i < equalLen(ops, idx-1); i++ {
 		if !a[op.AEnd-i-1].Equal(a[op.AStart-i-1]) {
 			break
 		}
@@ -96,7 +119,7 @@ func shiftDelete(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	// Try forward shifts
 	for shift := 1; shift <= maxShiftForward; shift++ {
 		score := scoreBoundary(op.AStart+shift, op.AEnd+shift, a)
-		if score > bestScore {
+		if score > bestScore || (score == bestScore && joinsNeighbor(ops, idx, shift) && !joinsNeighbor(ops, idx, bestShift)) {
 			bestScore = score
 			bestShift = shift
 		}
@@ -105,7 +128,7 @@ func shiftDelete(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	// Try backward shifts
 	for shift := 1; shift <= maxShiftBackward; shift++ {
 		score := scoreBoundary(op.AStart-shift, op.AEnd-shift, a)
-		if score > bestScore {
+		if score > bestScore || (score == bestScore && joinsNeighbor(ops, idx, -shift) && !joinsNeighbor(ops, idx, bestShift)) {
 			bestScore = score
 			bestShift = -shift
 		}
@@ -119,8 +142,8 @@ func shiftDelete(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 		Type:   Delete,
 		AStart: op.AStart + bestShift,
 		AEnd:   op.AEnd + bestShift,
-		BStart: op.BStart,
-		BEnd:   op.BEnd,
+		BStart: op.BStart + bestShift,
+		BEnd:   op.BEnd + bestShift,
 	}
 }
 
@@ -135,7 +158,7 @@ func shiftInsert(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	maxShiftBackward := 0
 
 	// Check forward shifting potential
-	for i := 0; op.BEnd+i < len(b); i++ {
+	for i := 0; i < equalLen(ops, idx+1); i++ {
 		if !b[op.BStart+i].Equal(b[op.BEnd+i]) {
 			break
 		}
@@ -143,7 +166,7 @@ func shiftInsert(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	}
 
 	// Check backward shifting potential
-	for i := 0; op.BStart-i-1 >= 0; i++ {
+	for i := 0; i < equalLen(ops, idx-1); i++ {
 		if !b[op.BEnd-i-1].Equal(b[op.BStart-i-1]) {
 			break
 		}
@@ -161,7 +184,7 @@ func shiftInsert(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	// Try forward shifts
 	for shift := 1; shift <= maxShiftForward; shift++ {
 		score := scoreBoundary(op.BStart+shift, op.BEnd+shift, b)
-		if score > bestScore {
+		if score > bestScore || (score == bestScore && joinsNeighbor(ops, idx, shift) && !joinsNeighbor(ops, idx, bestShift)) {
 			bestScore = score
 			bestShift = shift
 		}
@@ -170,7 +193,7 @@ func shiftInsert(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 	// Try backward shifts
 	for shift := 1; shift <= maxShiftBackward; shift++ {
 		score := scoreBoundary(op.BStart-shift, op.BEnd-shift, b)
-		if score > bestScore {
+		if score > bestScore || (score == bestScore && joinsNeighbor(ops, idx, -shift) && !joinsNeighbor(ops, idx, bestShift)) {
 			bestScore = score
 			bestShift = -shift
 		}
@@ -182,13 +205,165 @@ func shiftInsert(op DiffOp, ops []DiffOp, idx int, a, b []Element) DiffOp {
 
 	return DiffOp{
 		Type:   Insert,
-		AStart: op.AStart,
-		AEnd:   op.AEnd,
+		AStart: op.AStart + bestShift,
+		AEnd:   op.AEnd + bestShift,
 		BStart: op.BStart + bestShift,
 		BEnd:   op.BEnd + bestShift,
 	}
 }
 
+// equalLen returns the length of ops[i] if it is an Equal operation, and 0
+// if it is a change or out of range.
+func equalLen(ops []DiffOp, i int) int {
+	if i < 0 || i >= len(ops) || ops[i].Type != Equal {
+		return 0
+	}
+	return ops[i].AEnd - ops[i].AStart
+}
+
+// joinsNeighbor reports whether sliding the change ops[idx] by shift
+// consumes the whole Equal operation on that side and so joins the change
+// with the one beyond it. Among equally readable positions, shiftDelete
+// and shiftInsert prefer one that joins, as one hunk reads better than two.
+func joinsNeighbor(ops []DiffOp, idx, shift int) bool {
+	switch {
+	case shift < 0:
+		return idx >= 2 && ops[idx-2].Type != Equal && equalLen(ops, idx-1) == -shift
+	case shift > 0:
+		return idx+2 < len(ops) && ops[idx+2].Type != Equal && equalLen(ops, idx+1) == shift
+	}
+	return false
+}
+
+// isIsolatedChange reports whether ops[i] is a change with no other change
+// next to it, so that it can slide over the Equal operations around it.
+func isIsolatedChange(ops []DiffOp, i int) bool {
+	if ops[i].Type == Equal {
+		return false
+	}
+	return (i == 0 || ops[i-1].Type == Equal) && (i+1 == len(ops) || ops[i+1].Type == Equal)
+}
+
+// isPairedChange reports whether ops[i] is half of a replace pair: a Delete
+// and an Insert next to each other with no other change ops around them.
+func isPairedChange(ops []DiffOp, i int) bool {
+	if ops[i].Type == Equal {
+		return false
+	}
+	j := i
+	if i+1 < len(ops) && ops[i+1].Type != Equal && ops[i+1].Type != ops[i].Type {
+		j = i + 1
+	} else if i > 0 && ops[i-1].Type != Equal && ops[i-1].Type != ops[i].Type {
+		j = i - 1
+	} else {
+		return false
+	}
+
+	lo, hi := i, j
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if lo > 0 && ops[lo-1].Type != Equal {
+		return false
+	}
+	if hi+1 < len(ops) && ops[hi+1].Type != Equal {
+		return false
+	}
+	return true
+}
+
+// shiftPairedChanges slides each Delete+Insert pair as a unit. Sliding
+// the regions independently can leave the deleted text in one place and
+// its replacement in another; moving them together keeps the pair adjacent
+// and aligned with the same boundary in both sequences. Pairs at either end
+// of the script slide too.
+func shiftPairedChanges(ops []DiffOp, a, b []Element, opts *options) []DiffOp {
+	if len(ops) < 2 {
+		return ops
+	}
+
+	result := padWithEqual(ops)
+
+	for i := 0; i+1 < len(result); i++ {
+		if result[i].Type == Equal || !isPairedChange(result, i) || !isPairedChange(result, i+1) {
+			continue
+		}
+
+		del, ins := result[i], result[i+1]
+		if del.Type == Insert {
+			del, ins = ins, del
+		}
+		aStart, aEnd := del.AStart, del.AEnd
+		bStart, bEnd := ins.BStart, ins.BEnd
+
+		prevLen, nextLen := 0, 0
+		if i > 0 {
+			prevLen = result[i-1].AEnd - result[i-1].AStart
+		}
+		if i+2 < len(result) {
+			nextLen = result[i+2].AEnd - result[i+2].AStart
+		}
+
+		// Both regions must be able to slide by the same amount
+		up := 0
+		for up < prevLen &&
+			a[aStart-up-1].Equal(a[aEnd-up-1]) &&
+			b[bStart-up-1].Equal(b[bEnd-up-1]) {
+			up++
+		}
+		down := 0
+		for down < nextLen &&
+			a[aStart+down].Equal(a[aEnd+down]) &&
+			b[bStart+down].Equal(b[bEnd+down]) {
+			down++
+		}
+		if up == 0 && down == 0 {
+			continue
+		}
+
+		bestShift := 0
+		if opts.indentHeuristic {
+			bestScore := pairIndentScore(aStart, aEnd, bStart, bEnd, 0, a, b)
+			for shift := -up; shift <= down; shift++ {
+				score := pairIndentScore(aStart, aEnd, bStart, bEnd, shift, a, b)
+				if score.compare(bestScore) < 0 {
+					bestScore = score
+					bestShift = shift
+				}
+			}
+		} else {
+			bestScore := scoreBoundary(aStart, aEnd, a) + scoreBoundary(bStart, bEnd, b)
+			for shift := -up; shift <= down; shift++ {
+				score := scoreBoundary(aStart+shift, aEnd+shift, a) +
+					scoreBoundary(bStart+shift, bEnd+shift, b)
+				if score > bestScore {
+					bestScore = score
+					bestShift = shift
+				}
+			}
+		}
+
+		if bestShift != 0 {
+			slideRun(result, i, i+2, bestShift)
+			traceShift(opts.trace, result, i, i+2, bestShift)
+		}
+		i++
+	}
+
+	return mergeAdjacentOps(dropEmptyOps(result))
+}
+
+// pairIndentScore scores a replace pair slid by shift with the indent
+// heuristic, combining the splits in both sequences.
+func pairIndentScore(aStart, aEnd, bStart, bEnd, shift int, a, b []Element) splitScore {
+	sa := scoreIndentSlide(aStart+shift, aEnd+shift, a)
+	sb := scoreIndentSlide(bStart+shift, bEnd+shift, b)
+	return splitScore{
+		effectiveIndent: sa.effectiveIndent + sb.effectiveIndent,
+		penalty:         sa.penalty + sb.penalty,
+	}
+}
+
 // scoreBoundary scores a boundary position based on readability heuristics.
 // Higher scores indicate better boundary positions.
 func scoreBoundary(start, end int, elems []Element) int {
@@ -225,22 +400,51 @@ func scoreBoundary(start, end int, elems []Element) int {
 	return score
 }
 
+// elementText returns the text of a string element and true, or false if
+// e is not one. An element compared by a normalized key returns its
+// original text, so that boundaries are scored by the white space and
+// punctuation as written.
+func elementText(e Element) (string, bool) {
+	switch v := unwrapElement(e).(type) {
+	case StringElement:
+		return string(v), true
+	case keyedElement:
+		return v.text, true
+	}
+	return "", false
+}
+
 // isBlank checks if an element represents blank/whitespace content.
 func isBlank(e Element) bool {
-	s, ok := e.(StringElement)
+	s, ok := elementText(e)
+	if !ok {
+		return false
+	}
+	return strings.TrimSpace(s) == ""
+}
+
+// isSeparator reports whether an element separates content: a blank line
+// or white space token, or a token of a single punctuation character such
+// as "," or "}".
+func isSeparator(e Element) bool {
+	if isBlank(e) {
+		return true
+	}
+	s, ok := elementText(e)
 	if !ok {
 		return false
 	}
-	return strings.TrimSpace(string(s)) == ""
+	r, size := utf8.DecodeRuneInString(strings.TrimSpace(s))
+	return size == len(strings.TrimSpace(s)) && unicode.IsPunct(r)
 }
 
 // endsWithPunctuation checks if an element ends with sentence punctuation.
 func endsWithPunctuation(e Element) bool {
-	s, ok := e.(StringElement)
+	s, ok := elementText(e)
 	if !ok {
 		return false
 	}
-	str := strings.TrimSpace(string(s))
+	str := strings.TrimSpace(s)
 	if len(str) == 0 {
 		return false
 	}
@@ -250,11 +454,11 @@ func endsWithPunctuation(e Element) bool {
 
 // startsWithPunctuation checks if an element starts with punctuation.
 func startsWithPunctuation(e Element) bool {
-	s, ok := e.(StringElement)
+	s, ok := elementText(e)
 	if !ok {
 		return false
 	}
-	str := strings.TrimSpace(string(s))
+	str := strings.TrimSpace(s)
 	if len(str) == 0 {
 		return false
 	}
@@ -263,69 +467,64 @@ func startsWithPunctuation(e Element) bool {
 	return first == '-' || first == '*' || first == '#' || first == '>'
 }
 
-// optimizeBoundaries performs a second pass to improve boundaries between
-// adjacent Equal and change regions.
+// optimizeBoundaries performs a final pass over replace regions. Sliding
+// cannot move a blank line or separator out of a Delete+Insert pair when
+// both sides start or end with it, as happens when preprocessing maps
+// discarded blank lines into a change block. Such matching separators are
+// exchanged into the neighboring Equal regions, so hunks start and end at
+// them instead of carrying them as a removed and re-added line.
 func optimizeBoundaries(ops []DiffOp, a, b []Element) []DiffOp {
 	if len(ops) < 2 {
 		return ops
 	}
 
-	result := make([]DiffOp, len(ops))
-	copy(result, ops)
-
-	// Look for patterns where we can improve boundaries
-	for i := 0; i < len(result)-1; i++ {
-		curr := result[i]
-		next := result[i+1]
-
-		// Pattern: Equal followed by Delete/Insert
-		// Try to move blank lines from the Equal into the change if it improves things
-		if curr.Type == Equal && (next.Type == Delete || next.Type == Insert) {
-			result[i], result[i+1] = tryShiftEqualBoundary(curr, next, a, b)
-		}
-
-		// Pattern: Delete/Insert followed by Equal
-		// Try to move blank lines from the change into the Equal
-		if (curr.Type == Delete || curr.Type == Insert) && next.Type == Equal {
-			result[i], result[i+1] = tryShiftChangeBoundary(curr, next, a, b)
+	result := padWithEqual(ops)
+	for i := 1; i+1 < len(result); i++ {
+		if result[i].Type != Delete || result[i+1].Type != Insert || !isPairedChange(result, i) {
+			continue
 		}
+		// The pair is surrounded by Equal operations thanks to padding
+		result[i-1], result[i], result[i+1] = tryShiftEqualBoundary(result[i-1], result[i], result[i+1], a, b)
+		result[i], result[i+1], result[i+2] = tryShiftChangeBoundary(result[i], result[i+1], result[i+2], a, b)
+		i++
 	}
-
-	return result
+	return mergeAdjacentOps(dropEmptyOps(result))
 }
 
-// tryShiftEqualBoundary attempts to shift the boundary between an Equal
-// region and a following change region.
-func tryShiftEqualBoundary(eq, change DiffOp, a, b []Element) (DiffOp, DiffOp) {
-	// Check if the last element of Equal is blank
-	if eq.AEnd-eq.AStart == 0 {
-		return eq, change
-	}
-
-	lastEqA := eq.AEnd - 1
-	if lastEqA < 0 || lastEqA >= len(a) {
-		return eq, change
-	}
-
-	// Don't move blank lines into changes - keep them as separators
-	// This is actually the opposite of what we want, so just return unchanged
-	return eq, change
+// tryShiftEqualBoundary moves separators that open both the deletion and
+// the insertion of a replace pair to the end of the Equal before it.
+func tryShiftEqualBoundary(eq, del, ins DiffOp, a, b []Element) (DiffOp, DiffOp, DiffOp) {
+	for del.AStart < del.AEnd && ins.BStart < ins.BEnd {
+		x, y := a[del.AStart], b[ins.BStart]
+		if !isSeparator(x) || !x.Equal(y) {
+			break
+		}
+		eq.AEnd++
+		eq.BEnd++
+		del.AStart++
+		del.BStart++
+		del.BEnd++
+		ins.BStart++
+	}
+	return eq, del, ins
 }
 
-// tryShiftChangeBoundary attempts to shift the boundary between a change
-// region and a following Equal region.
-func tryShiftChangeBoundary(change, eq DiffOp, a, b []Element) (DiffOp, DiffOp) {
-	// Check if the first element of Equal is blank
-	if eq.AEnd-eq.AStart == 0 {
-		return change, eq
-	}
-
-	// If the change ends right before a blank line in the Equal region,
-	// and we can shift to put the blank in Equal, that's better
-	// (blank lines should be separators, not part of changes)
-
-	// This is already handled by scoreBoundary, so just return unchanged
-	return change, eq
+// tryShiftChangeBoundary moves separators that close both the deletion and
+// the insertion of a replace pair to the start of the Equal after it.
+func tryShiftChangeBoundary(del, ins, eq DiffOp, a, b []Element) (DiffOp, DiffOp, DiffOp) {
+	for del.AStart < del.AEnd && ins.BStart < ins.BEnd {
+		x, y := a[del.AEnd-1], b[ins.BEnd-1]
+		if !isSeparator(x) || !x.Equal(y) {
+			break
+		}
+		eq.AStart--
+		eq.BStart--
+		del.AEnd--
+		ins.AStart--
+		ins.AEnd--
+		ins.BEnd--
+	}
+	return del, ins, eq
 }
 
 // mergeAdjacentOps merges consecutive operations of the same type.
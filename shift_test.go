@@ -103,12 +103,12 @@ func TestShiftBoundaries_Punctuation(t *testing.T) {
 }
 
 func TestShiftBoundaries_Empty(t *testing.T) {
-	result := shiftBoundaries(nil, nil, nil)
+	result := shiftBoundaries(nil, nil, nil, nil)
 	if result != nil {
 		t.Errorf("expected nil for empty input, got %v", result)
 	}
 
-	result = shiftBoundaries([]DiffOp{}, nil, nil)
+	result = shiftBoundaries([]DiffOp{}, nil, nil, nil)
 	if len(result) != 0 {
 		t.Errorf("expected empty for empty input, got %v", result)
 	}
@@ -137,6 +137,29 @@ func TestIsBlank(t *testing.T) {
 	}
 }
 
+func TestIsSeparator(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"", true},
+		{"  \n", true},
+		{",", true},
+		{" } ", true},
+		{"»", true},
+		{"a", false},
+		{"),", false},
+		{"+", false},
+	}
+
+	for _, tt := range tests {
+		got := isSeparator(StringElement(tt.input))
+		if got != tt.want {
+			t.Errorf("isSeparator(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
 func TestEndsWithPunctuation(t *testing.T) {
 	tests := []struct {
 		input string
@@ -275,6 +298,350 @@ func TestShiftInsert(t *testing.T) {
 	}
 }
 
+func TestIsPairedChange(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+		{Type: Delete, AStart: 3, AEnd: 4, BStart: 3, BEnd: 3},
+	}
+
+	want := []bool{false, true, true, false, false}
+	for i, w := range want {
+		if got := isPairedChange(ops, i); got != w {
+			t.Errorf("isPairedChange(ops, %d) = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestShiftPairedChanges_SlidesTogether(t *testing.T) {
+	a := toElements([]string{"", "x", "", "y"})
+	b := toElements([]string{"", "z", "", "y"})
+
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 3},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 3, BEnd: 4},
+	}
+
+	got := shiftPairedChanges(ops, a, b, defaultOptions())
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 4, BStart: 2, BEnd: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shiftPairedChanges() = %v, want %v", got, want)
+	}
+}
+
+func TestShiftPairedChanges_OnlyOneSideSlidable(t *testing.T) {
+	// The deletion could slide, but the insertion cannot: keep the pair
+	a := toElements([]string{"", "x", "", "y"})
+	b := toElements([]string{"", "z", "w", "y"})
+
+	ops := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 3},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 3, BEnd: 4},
+	}
+
+	got := shiftPairedChanges(ops, a, b, defaultOptions())
+	if !reflect.DeepEqual(got, ops) {
+		t.Errorf("expected ops unchanged, got %v", got)
+	}
+}
+
+func TestShiftPairedChanges_AtStart(t *testing.T) {
+	// A pair at the start of the script slides down by growing an Equal
+	// region before it
+	tests := []struct {
+		indent bool
+		a, b   []string
+	}{
+		{false, []string{"q", "r", "q", ""}, []string{"q", "s", "q", ""}},
+		{true, []string{"", "x", "", "y"}, []string{"", "z", "", "y"}},
+	}
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 2},
+		{Type: Equal, AStart: 2, AEnd: 4, BStart: 2, BEnd: 4},
+	}
+	want := []DiffOp{
+		{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+		{Type: Insert, AStart: 3, AEnd: 3, BStart: 1, BEnd: 3},
+		{Type: Equal, AStart: 3, AEnd: 4, BStart: 3, BEnd: 4},
+	}
+
+	for _, tt := range tests {
+		opts := defaultOptions()
+		opts.indentHeuristic = tt.indent
+		if got := shiftPairedChanges(ops, toElements(tt.a), toElements(tt.b), opts); !reflect.DeepEqual(got, want) {
+			t.Errorf("indent %v: shiftPairedChanges() = %v, want %v", tt.indent, got, want)
+		}
+	}
+}
+
+func TestShiftBoundaries_PairsStayAdjacent(t *testing.T) {
+	a := []string{"intro", "", "old text", "", "outro"}
+	b := []string{"intro", "", "new text", "", "more", "", "outro"}
+
+	ops := Diff(a, b, WithPreprocessing(false))
+	if got := applyDiffStrings(a, b, ops); !reflect.DeepEqual(got, b) {
+		t.Fatalf("applying diff = %v, want %v", got, b)
+	}
+
+	// Every op must start where the previous one ended
+	aPos, bPos := 0, 0
+	for _, op := range ops {
+		if op.AStart != aPos || op.BStart != bPos {
+			t.Fatalf("op %v does not continue from (%d, %d): %v", op, aPos, bPos, ops)
+		}
+		aPos, bPos = op.AEnd, op.BEnd
+	}
+}
+
+func TestShiftBoundaries_SlidesEqualOps(t *testing.T) {
+	// An isolated change slides over the Equal operations around it, which
+	// shrink and grow to keep the script contiguous
+	tests := []struct {
+		name string
+		a, b []string
+		ops  []DiffOp
+		want []DiffOp
+	}{
+		{
+			name: "delete",
+			a:    []string{"p.", "q", "r", "q", "s"},
+			b:    []string{"p.", "q", "s"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+				{Type: Equal, AStart: 4, AEnd: 5, BStart: 2, BEnd: 3},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1},
+				{Type: Equal, AStart: 3, AEnd: 5, BStart: 1, BEnd: 3},
+			},
+		},
+		{
+			name: "insert",
+			a:    []string{"p.", "q", "s"},
+			b:    []string{"p.", "q", "r", "q", "s"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 4},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 4, BEnd: 5},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Insert, AStart: 1, AEnd: 1, BStart: 1, BEnd: 3},
+				{Type: Equal, AStart: 1, AEnd: 3, BStart: 3, BEnd: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shiftBoundaries(tt.ops, toElements(tt.a), toElements(tt.b), defaultOptions())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shiftBoundaries() = %v, want %v", got, tt.want)
+			}
+			if applied := applyDiffStrings(tt.a, tt.b, got); !reflect.DeepEqual(applied, tt.b) {
+				t.Errorf("applying diff = %v, want %v", applied, tt.b)
+			}
+		})
+	}
+}
+
+func TestOptimizeBoundaries_ExchangesSeparators(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		ops  []DiffOp
+		want []DiffOp
+	}{
+		{
+			name: "blank lines at both ends",
+			a:    []string{"head", "", "old", "", "tail"},
+			b:    []string{"head", "", "new", "", "tail"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 4, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 4, AEnd: 4, BStart: 1, BEnd: 4},
+				{Type: Equal, AStart: 4, AEnd: 5, BStart: 4, BEnd: 5},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Delete, AStart: 2, AEnd: 3, BStart: 2, BEnd: 2},
+				{Type: Insert, AStart: 3, AEnd: 3, BStart: 2, BEnd: 3},
+				{Type: Equal, AStart: 3, AEnd: 5, BStart: 3, BEnd: 5},
+			},
+		},
+		{
+			name: "at the edges of the sequences",
+			a:    []string{"", "old", ","},
+			b:    []string{"", "new", "newer", ","},
+			ops: []DiffOp{
+				{Type: Delete, AStart: 0, AEnd: 3, BStart: 0, BEnd: 0},
+				{Type: Insert, AStart: 3, AEnd: 3, BStart: 0, BEnd: 4},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 3},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 3, BEnd: 4},
+			},
+		},
+		{
+			name: "one side becomes empty",
+			a:    []string{"x", "", "y"},
+			b:    []string{"x", "", "", "y"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 3},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 3, BEnd: 4},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 2, BEnd: 3},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 3, BEnd: 4},
+			},
+		},
+		{
+			name: "matching content is not moved",
+			a:    []string{"same", "old"},
+			b:    []string{"same", "new"},
+			ops: []DiffOp{
+				{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 2},
+			},
+			want: []DiffOp{
+				{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 2},
+			},
+		},
+		{
+			name: "a whole replacement of blank lines",
+			a:    []string{"x", "", "y"},
+			b:    []string{"x", "", "y"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+			},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 3, BStart: 0, BEnd: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := optimizeBoundaries(tt.ops, toElements(tt.a), toElements(tt.b))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("optimizeBoundaries() = %v, want %v", got, tt.want)
+			}
+			if applied := applyDiffStrings(tt.a, tt.b, got); !reflect.DeepEqual(applied, tt.b) {
+				t.Errorf("applying diff = %v, want %v", applied, tt.b)
+			}
+		})
+	}
+}
+
+func TestShiftBoundaries_MovesEqualNeighbors(t *testing.T) {
+	// Isolated changes that slide must take the Equal ops around them
+	// along, or the script gets gaps and overlaps
+	tests := []struct {
+		name string
+		a, b []string
+		diff func([]string, []string, ...Option) []DiffOp
+	}{
+		{"insert at the start", []string{"c", "b", "a"}, []string{"b", "b", "d"}, Diff},
+		{"blank lines", []string{"a\n", "\n", ""}, []string{"\n", "\n", "0"}, Diff},
+		{"histogram", []string{"a", "c", "a", "c", "a", "a"}, []string{"b", "c", "a"}, DiffHistogram},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := tt.diff(tt.a, tt.b)
+			aPos, bPos := 0, 0
+			for _, op := range ops {
+				if op.AStart != aPos || op.BStart != bPos {
+					t.Fatalf("op %v does not continue from (%d, %d): %v", op, aPos, bPos, ops)
+				}
+				aPos, bPos = op.AEnd, op.BEnd
+			}
+			if aPos != len(tt.a) || bPos != len(tt.b) {
+				t.Fatalf("script ends at (%d, %d), want (%d, %d): %v", aPos, bPos, len(tt.a), len(tt.b), ops)
+			}
+			if got := applyDiffStrings(tt.a, tt.b, ops); !reflect.DeepEqual(got, tt.b) {
+				t.Errorf("applying %v gives %q, want %q", ops, got, tt.b)
+			}
+		})
+	}
+}
+
+func TestShiftBoundaries_JoinsOnTie(t *testing.T) {
+	// The deleted block can slide up by one "end" and join the replace
+	// above it. It does so only when both positions score the same.
+	tests := []struct {
+		name string
+		a, b []string
+		want []DiffOp
+	}{
+		{
+			name: "tie joins",
+			a:    []string{"head", "old", "end", "block", "end", "tail"},
+			b:    []string{"head", "new", "end", "tail"},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+				{Type: Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+				{Type: Equal, AStart: 4, AEnd: 6, BStart: 2, BEnd: 4},
+			},
+		},
+		{
+			name: "blank line separator wins",
+			a:    []string{"head", "old", "end", "block", "end", "", "tail"},
+			b:    []string{"head", "new", "end", "", "tail"},
+			want: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+				{Type: Delete, AStart: 3, AEnd: 5, BStart: 3, BEnd: 3},
+				{Type: Equal, AStart: 5, AEnd: 7, BStart: 3, BEnd: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 2, BStart: 1, BEnd: 1},
+				{Type: Insert, AStart: 2, AEnd: 2, BStart: 1, BEnd: 2},
+				{Type: Equal, AStart: 2, AEnd: 3, BStart: 2, BEnd: 3},
+				{Type: Delete, AStart: 3, AEnd: 5, BStart: 3, BEnd: 3},
+				{Type: Equal, AStart: 5, AEnd: len(tt.a), BStart: 3, BEnd: len(tt.b)},
+			}
+			got := shiftBoundaries(ops, toElements(tt.a), toElements(tt.b), defaultOptions())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shiftBoundaries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // Helper to apply diff (duplicated here to avoid import cycle)
 func applyDiffStrings(a, b []string, ops []DiffOp) []string {
 	var result []string
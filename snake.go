@@ -64,25 +64,30 @@ func (ctx *diffContext) findMiddleSnake(xoff, xlim, yoff, ylim int, findMinimal
 		return partition{xmid: xlim, ymid: yoff, loMinimal: true, hiMinimal: true}
 	}
 
-	// Delta is the difference in sequence lengths
+	// Delta is the difference in sequence lengths. Forward diagonals are
+	// k = x - y; backward diagonals are measured from (n, m), so forward
+	// diagonal k is backward diagonal delta - k.
 	delta := n - m
 	deltaOdd := delta&1 != 0
 
-	// Offset for diagonal indexing (diagonals range from -m to n)
-	offset := m + 1
-
-	// Use pre-allocated diagonal arrays from context
-	fdiag := ctx.fdiag
-	bdiag := ctx.bdiag
-
-	// Initialize: forward search starts at (0,0), backward at (n,m)
-	fdiag[offset+1] = 0
-	bdiag[offset+delta-1] = n
-
 	// Maximum edit distance we might need to explore
 	// In bidirectional search, each side explores half
 	maxD := (n + m + 1) / 2
 
+	// Diagonal k is stored at index k + offset. Both arrays hold the
+	// furthest x reached on each diagonal, counted from the start for the
+	// forward search and from the end for the backward search; -1 marks
+	// diagonals not reached yet.
+	offset := maxD + 1
+	fdiag := ctx.fdiag[:2*offset+1]
+	bdiag := ctx.bdiag[:2*offset+1]
+	for i := range fdiag {
+		fdiag[i] = -1
+		bdiag[i] = -1
+	}
+	fdiag[offset+1] = 0
+	bdiag[offset+1] = 0
+
 	// Apply cost limit heuristic
 	costLimit := maxD
 	if ctx.costLimit > 0 && !findMinimal {
@@ -95,6 +100,18 @@ func (ctx *diffContext) findMiddleSnake(xoff, xlim, yoff, ylim int, findMinimal
 	// Track the best snake found (for heuristic fallback)
 	var bestSnake snakeInfo
 	bestSnakeScore := 0 // score = snake length, with bonus for being near middle
+	trackSnake := func(x, y, snakeLen int, forward bool) {
+		if !ctx.useHeuristic || snakeLen < significantMatchLen {
+			return
+		}
+		// Score: snake length + bonus for being near the middle
+		midDist := abs((x+y)/2 - (n+m)/4)
+		score := snakeLen*2 - midDist
+		if score > bestSnakeScore {
+			bestSnakeScore = score
+			bestSnake = snakeInfo{x: x, y: y, len: snakeLen, forward: forward}
+		}
+	}
 
 	// "Too expensive" threshold: if we exceed this without finding overlap,
 	// use the best snake we've found
@@ -107,184 +124,133 @@ func (ctx *diffContext) findMiddleSnake(xoff, xlim, yoff, ylim int, findMinimal
 		}
 	}
 
+	// Diagonals that have left the edit graph are skipped in later rounds:
+	// fkLo and fkHi trim the low and high ends of the forward range, and
+	// bkLo and bkHi those of the backward range.
+	fkLo, fkHi, bkLo, bkHi := 0, 0, 0, 0
+
+	// Work done since the last charge to the work budget: the diagonals
+	// cleared above, then the diagonals visited and elements compared in
+	// each round
+	work := len(fdiag) + len(bdiag)
+
 	for d := 0; d <= maxD; d++ {
+		ctx.budget.spend(work)
+		work = 0
+
 		// Check if we've exceeded heuristic thresholds
 		if ctx.useHeuristic && !findMinimal && d > tooExpensive && bestSnakeScore > 0 {
-			return snakeToPartition(bestSnake, xoff, yoff, n, m)
+			return snakeToPartition(bestSnake, xoff, yoff, n, m).at(d, true)
 		}
 
 		// Forward search
-		// Clamp k to valid range: k must satisfy 0 <= x <= n and 0 <= y <= m
-		// Since y = x - k, we need k >= x - m and k <= x
-		// Since x ranges 0..n, k ranges -m..n
-		kMin := -d
-		if kMin < -m {
-			kMin = -m
-		}
-		kMax := d
-		if kMax > n {
-			kMax = n
-		}
-		// Adjust to maintain parity with d
-		if (kMin+d)%2 != 0 {
-			kMin++
-		}
-
-		for k := kMin; k <= kMax; k += 2 {
+		for k := -d + fkLo; k <= d-fkHi; k += 2 {
 			kIdx := offset + k
 
-			// Bounds check for diagonal array access
-			if kIdx-1 < 0 || kIdx+1 >= len(fdiag) {
-				continue
-			}
-
-			// Determine starting x: come from k-1 (insertion) or k+1 (deletion)
+			// Determine starting x: come from k+1 (insertion) or k-1 (deletion)
 			var x int
 			if k == -d || (k != d && fdiag[kIdx-1] < fdiag[kIdx+1]) {
-				x = fdiag[kIdx+1] // From k+1, moving down (deletion from A)
+				x = fdiag[kIdx+1]
 			} else {
-				x = fdiag[kIdx-1] + 1 // From k-1, moving right (insertion to B)
+				x = fdiag[kIdx-1] + 1
 			}
 			y := x - k
 
-			// Bounds check for edit graph
-			if y < 0 || y > m || x < 0 || x > n {
-				fdiag[kIdx] = x // Still update to avoid stale values
-				continue
-			}
-
-			// Record start of potential snake
-			snakeStartX := x
-
 			// Follow diagonal (matching elements)
+			snakeStartX := x
 			for x < n && y < m && ctx.equal(xoff+x, yoff+y) {
 				x++
 				y++
 			}
-
-			snakeLen := x - snakeStartX
 			fdiag[kIdx] = x
+			work += x - snakeStartX + 1
 
-			// Track significant matches for heuristic fallback
-			if ctx.useHeuristic && snakeLen >= significantMatchLen {
-				// Score: snake length + bonus for being near the middle
-				midDist := abs((x+y)/2 - (n+m)/4)
-				score := snakeLen*2 - midDist
-				if score > bestSnakeScore {
-					bestSnakeScore = score
-					bestSnake = snakeInfo{x: x, y: y, len: snakeLen, forward: true}
-				}
+			if x > n {
+				fkHi += 2 // Ran off the right of the graph
+				continue
 			}
+			if y > m {
+				fkLo += 2 // Ran off the bottom of the graph
+				continue
+			}
+			trackSnake(x, y, x-snakeStartX, true)
 
 			// Check for overlap with backward search
 			// When delta is odd, we check on forward steps
-			if deltaOdd && k >= delta-(d-1) && k <= delta+(d-1) {
-				bIdx := offset + k - delta
-				if bIdx >= 0 && bIdx < len(bdiag) && fdiag[kIdx] >= bdiag[bIdx] {
-					// Found overlap - return the snake endpoint
-					return partition{
-						xmid:      xoff + x,
-						ymid:      yoff + y,
-						loMinimal: true,
-						hiMinimal: true,
-					}
+			if bIdx := offset + delta - k; deltaOdd && bIdx >= 0 && bIdx < len(bdiag) &&
+				bdiag[bIdx] != -1 && bdiag[bIdx] <= n && x >= n-bdiag[bIdx] {
+				return partition{
+					xmid:      xoff + x,
+					ymid:      yoff + y,
+					loMinimal: true,
+					hiMinimal: true,
+					d:         d,
 				}
 			}
 		}
 
-		// Backward search
-		// Clamp k to valid range for backward search
-		bkMin := -d
-		if bkMin < -m {
-			bkMin = -m
-		}
-		bkMax := d
-		if bkMax > n {
-			bkMax = n
-		}
-		// Adjust to maintain parity with d
-		if (bkMin+d)%2 != 0 {
-			bkMin++
-		}
-
-		for k := bkMin; k <= bkMax; k += 2 {
+		// Backward search, in coordinates counted from (n, m)
+		for k := -d + bkLo; k <= d-bkHi; k += 2 {
 			kIdx := offset + k
 
-			// Bounds check for diagonal array access
-			if kIdx-1 < 0 || kIdx+1 >= len(bdiag) {
-				continue
-			}
-
-			// Determine starting x
 			var x int
-			if k == d || (k != -d && bdiag[kIdx-1] < bdiag[kIdx+1]) {
-				x = bdiag[kIdx-1] // From k-1
+			if k == -d || (k != d && bdiag[kIdx-1] < bdiag[kIdx+1]) {
+				x = bdiag[kIdx+1]
 			} else {
-				x = bdiag[kIdx+1] - 1 // From k+1
-			}
-			y := x - k - delta
-
-			// Bounds check for edit graph
-			if y < 0 || y > m || x < 0 || x > n {
-				bdiag[kIdx] = x // Still update to avoid stale values
-				continue
+				x = bdiag[kIdx-1] + 1
 			}
-
-			// Record start of potential snake (going backward)
-			snakeStartX := x
+			y := x - k
 
 			// Follow diagonal backward
-			for x > 0 && y > 0 && ctx.equal(xoff+x-1, yoff+y-1) {
-				x--
-				y--
+			snakeStartX := x
+			for x < n && y < m && ctx.equal(xlim-x-1, ylim-y-1) {
+				x++
+				y++
 			}
-
-			snakeLen := snakeStartX - x
 			bdiag[kIdx] = x
+			work += x - snakeStartX + 1
 
-			// Track significant matches for heuristic fallback
-			if ctx.useHeuristic && snakeLen >= significantMatchLen {
-				// Score: snake length + bonus for being near the middle
-				midDist := abs((x+y)/2 - (n+m)/4)
-				score := snakeLen*2 - midDist
-				if score > bestSnakeScore {
-					bestSnakeScore = score
-					bestSnake = snakeInfo{x: x, y: y, len: snakeLen, forward: false}
-				}
+			if x > n {
+				bkHi += 2 // Ran off the left of the graph
+				continue
+			}
+			if y > m {
+				bkLo += 2 // Ran off the top of the graph
+				continue
 			}
+			trackSnake(n-x, m-y, x-snakeStartX, false)
 
 			// Check for overlap with forward search
 			// When delta is even, we check on backward steps
-			if !deltaOdd && k+delta >= -d && k+delta <= d {
-				fIdx := offset + k + delta
-				if fIdx >= 0 && fIdx < len(fdiag) && fdiag[fIdx] >= bdiag[kIdx] {
-					// Found overlap
-					fx := fdiag[fIdx]
-					fy := fx - (k + delta)
-					return partition{
-						xmid:      xoff + fx,
-						ymid:      yoff + fy,
-						loMinimal: true,
-						hiMinimal: true,
-					}
+			if fIdx := offset + delta - k; !deltaOdd && fIdx >= 0 && fIdx < len(fdiag) &&
+				fdiag[fIdx] != -1 && fdiag[fIdx] <= n && fdiag[fIdx] >= n-x {
+				fx := fdiag[fIdx]
+				fy := fx - (delta - k)
+				return partition{
+					xmid:      xoff + fx,
+					ymid:      yoff + fy,
+					loMinimal: true,
+					hiMinimal: true,
+					d:         d,
 				}
 			}
 		}
 
 		// Check cost limit (distinct from "too expensive")
 		if d >= costLimit && bestSnakeScore > 0 {
-			return snakeToPartition(bestSnake, xoff, yoff, n, m)
+			return snakeToPartition(bestSnake, xoff, yoff, n, m).at(d, true)
 		}
 	}
 
 	// If we reach here, we've exhausted the search without finding overlap
 	// This can happen with cost limits. Use the best snake if we have one.
 	if bestSnakeScore > 0 {
-		return snakeToPartition(bestSnake, xoff, yoff, n, m)
+		return snakeToPartition(bestSnake, xoff, yoff, n, m).at(maxD, true)
 	}
 
 	// Last resort: greedy fallback that guarantees progress
-	return greedyFallback(ctx, xoff, xlim, yoff, ylim)
+	ctx.stats.recordGreedy()
+	return greedyFallback(ctx, xoff, xlim, yoff, ylim).at(maxD, true)
 }
 
 // snakeToPartition converts a diagonal match run into a partition for divide-and-conquer.
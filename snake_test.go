@@ -1,6 +1,10 @@
 package diffx
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestIsqrt(t *testing.T) {
 	tests := []struct {
@@ -123,7 +127,73 @@ func TestFindMiddleSnake_WithHeuristics(t *testing.T) {
 	}
 }
 
+func TestFindMiddleSnake_OnShortestPath(t *testing.T) {
+	// The split must lie on a shortest edit path for either parity of the
+	// length difference, and within the bounds of a subrange
+	tests := []struct {
+		a, b                   string
+		xoff, xlim, yoff, ylim int
+	}{
+		{"abcabba", "cbabac", 0, 7, 0, 6},
+		{"abcd", "ab", 0, 4, 0, 2},
+		{"a", "bcd", 0, 1, 0, 3},
+		{"abab", "baba", 0, 4, 0, 4},
+		{"xxabcabbayy", "zcbabacz", 2, 9, 1, 7},
+		{"a", "baaaa", 0, 1, 0, 5},
+		{"xa", "baaaay", 1, 2, 0, 5},
+	}
+
+	for _, tt := range tests {
+		a := toElements(strings.Split(tt.a, ""))
+		b := toElements(strings.Split(tt.b, ""))
+		o := defaultOptions()
+		o.useHeuristic = false
+		ctx := newDiffContext(a, b, o)
+		part := ctx.findMiddleSnake(tt.xoff, tt.xlim, tt.yoff, tt.ylim, true)
+
+		if part.xmid < tt.xoff || part.xmid > tt.xlim || part.ymid < tt.yoff || part.ymid > tt.ylim {
+			t.Errorf("%s/%s: split (%d,%d) is outside the range", tt.a, tt.b, part.xmid, part.ymid)
+			continue
+		}
+		d, _ := minimalEditDistance(a[tt.xoff:tt.xlim], b[tt.yoff:tt.ylim])
+		lo, _ := minimalEditDistance(a[tt.xoff:part.xmid], b[tt.yoff:part.ymid])
+		hi, _ := minimalEditDistance(a[part.xmid:tt.xlim], b[part.ymid:tt.ylim])
+		if lo+hi != d {
+			t.Errorf("%s/%s: split (%d,%d) costs %d+%d, want %d", tt.a, tt.b, part.xmid, part.ymid, lo, hi, d)
+		}
+	}
+}
+
 // Benchmark snake finding
+func TestFindMiddleSnake_BackwardDiagonals(t *testing.T) {
+	// Inputs where the backward search used to index diagonals out of
+	// range, when sub-problems started away from the origin
+	tests := []struct {
+		name string
+		a, b []string
+	}{
+		{
+			name: "single characters",
+			a:    []string{"b", "\n", "\n", "a", "\n", "c", "a"},
+			b:    []string{"b", "a", "\n", "a", "\n", "a", "\n", "a", "c"},
+		},
+		{
+			name: "blank lines",
+			a:    []string{"\n", "\n", "\n", "\n", ""},
+			b:    []string{"0\n", "\n", "\n", "\n", "0\n", "\n", "\n", "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := Diff(tt.a, tt.b)
+			if got := applyDiff(tt.a, tt.b, ops); !reflect.DeepEqual(got, tt.b) {
+				t.Errorf("applying %v gives %q, want %q", ops, got, tt.b)
+			}
+		})
+	}
+}
+
 func BenchmarkFindMiddleSnake_Small(b *testing.B) {
 	a := toElements([]string{"a", "b", "c", "d", "e"})
 	bSeq := toElements([]string{"a", "x", "c", "y", "e"})
@@ -0,0 +1,267 @@
+package diffx
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SQL statement alignment.
+//
+// Schema dumps and migration files are sequences of statements whose line
+// breaks are up to the tool that wrote them: a column added to a CREATE
+// TABLE reflows the statement, and a line diff aligns lines of different
+// statements. SplitSQL splits a dump into statements, and DiffSQL aligns
+// them by their text with comments removed and white space normalized, so
+// each changed statement is reported whole and only once. String literals
+// and quoted identifiers are compared exactly.
+//
+// Where a string literal ends depends on the dialect: in MySQL a backslash
+// escapes the next character, so 'C:\' is unterminated, while in
+// PostgreSQL, SQLite, and standard SQL it is an ordinary character, so
+// the same text is a complete literal. Splitting a dump with the wrong
+// dialect merges every statement after such a literal into one.
+
+// SQLDialect selects the string literal syntax of SQL text.
+type SQLDialect int
+
+const (
+	// SQLStandard is standard SQL, as written by pg_dump and SQLite: a
+	// backslash is an ordinary character, except in PostgreSQL E'...'
+	// escape strings.
+	SQLStandard SQLDialect = iota
+	// SQLMySQL is MySQL and MariaDB syntax, as written by mysqldump: a
+	// backslash escapes the next character in every string literal.
+	SQLMySQL
+)
+
+// String returns a string representation of the SQLDialect.
+func (d SQLDialect) String() string {
+	switch d {
+	case SQLStandard:
+		return "Standard"
+	case SQLMySQL:
+		return "MySQL"
+	default:
+		return "Unknown"
+	}
+}
+
+// SQLResult is a statement-level diff of two SQL texts.
+type SQLResult struct {
+	// A and B are the statements of each text, as split by SplitSQL.
+	// Ops is a diff of A and B by their NormalizeSQL form.
+	A, B []string
+	Ops  []DiffOp
+}
+
+// DiffSQL splits two SQL texts in the given dialect into statements with
+// SplitSQL and diffs them, comparing statements in their NormalizeSQL
+// form. Options configure the diff; WithIgnoreCase, for example, also
+// ignores the case of keywords and identifiers.
+func DiffSQL(aText, bText string, dialect SQLDialect, opts ...Option) *SQLResult {
+	r := &SQLResult{A: SplitSQL(aText, dialect), B: SplitSQL(bText, dialect)}
+	r.Ops = Diff(sqlKeys(r.A, dialect), sqlKeys(r.B, dialect), opts...)
+	return r
+}
+
+// sqlKeys returns the normalized form of each statement.
+func sqlKeys(stmts []string, dialect SQLDialect) []string {
+	keys := make([]string, len(stmts))
+	for i, s := range stmts {
+		keys[i] = NormalizeSQL(s, dialect)
+	}
+	return keys
+}
+
+// copyFromStdin matches a PostgreSQL COPY statement whose data follows it
+// in the dump.
+var copyFromStdin = regexp.MustCompile(`(?is)^\s*COPY\b.*\bFROM\s+stdin\b`)
+
+// SplitSQL splits text in the given dialect into statements, each ending
+// at a semicolon and including the white space after it, so that
+// concatenating them reproduces text. Comments before a statement belong
+// to it. Semicolons inside string literals, quoted identifiers (in double
+// quotes or backquotes), comments, and PostgreSQL dollar-quoted bodies do
+// not end a statement, and the data lines after a "COPY ... FROM stdin;"
+// statement of a pg_dump file are part of it, up to the closing "\."
+// line. Text after the last semicolon is a final statement if it is not
+// only white space.
+func SplitSQL(text string, dialect SQLDialect) []string {
+	var stmts []string
+	start := 0
+	for i := 0; i < len(text); {
+		if end := skipSQLQuoted(text, i, dialect); end > i {
+			i = end
+			continue
+		}
+		if text[i] != ';' {
+			i++
+			continue
+		}
+
+		i++
+		if copyFromStdin.MatchString(stripSQLComments(text[start:i], dialect)) {
+			// The data ends with a line holding only "\."
+			if k := strings.Index(text[i:], "\n\\.\n"); k >= 0 {
+				i += k + 3
+			} else if strings.HasSuffix(text, "\n\\.") {
+				i = len(text)
+			}
+		}
+		for i < len(text) && isSQLSpace(text[i]) {
+			i++
+		}
+		stmts = append(stmts, text[start:i])
+		start = i
+	}
+	if start < len(text) {
+		if strings.TrimSpace(text[start:]) == "" && len(stmts) > 0 {
+			stmts[len(stmts)-1] += text[start:]
+		} else {
+			stmts = append(stmts, text[start:])
+		}
+	}
+	return stmts
+}
+
+// NormalizeSQL returns stmt, in the given dialect, with comments removed
+// and white space outside string literals and quoted identifiers
+// collapsed: runs of white space become one space, and white space next to
+// parentheses, commas, and semicolons and at the ends is removed. Text
+// after the first semicolon, such as the data of a COPY statement, is kept
+// as it is.
+func NormalizeSQL(stmt string, dialect SQLDialect) string {
+	var sb strings.Builder
+	sb.Grow(len(stmt))
+	space := false // white space is pending
+	for i := 0; i < len(stmt); {
+		if end := skipSQLQuoted(stmt, i, dialect); end > i {
+			if isSQLComment(stmt, i) {
+				space = true
+			} else {
+				sb.WriteString(sqlSeparator(&sb, space, stmt[i]))
+				sb.WriteString(stmt[i:end])
+				space = false
+			}
+			i = end
+			continue
+		}
+		c := stmt[i]
+		if isSQLSpace(c) {
+			space = true
+			i++
+			continue
+		}
+		sb.WriteString(sqlSeparator(&sb, space, c))
+		sb.WriteByte(c)
+		space = false
+		i++
+		if c == ';' {
+			sb.WriteString(strings.TrimRightFunc(stmt[i:], unicode.IsSpace))
+			break
+		}
+	}
+	return sb.String()
+}
+
+// sqlSeparator returns the space to write before c when white space is
+// pending, or "" when c or the text written so far does not need it.
+func sqlSeparator(sb *strings.Builder, space bool, c byte) string {
+	if !space || sb.Len() == 0 || strings.IndexByte("(),;", c) >= 0 {
+		return ""
+	}
+	if last := sb.String()[sb.Len()-1]; last == '(' || last == ',' {
+		return ""
+	}
+	return " "
+}
+
+// stripSQLComments returns s without comments.
+func stripSQLComments(s string, dialect SQLDialect) string {
+	if !strings.Contains(s, "--") && !strings.Contains(s, "/*") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if end := skipSQLQuoted(s, i, dialect); end > i {
+			if !isSQLComment(s, i) {
+				sb.WriteString(s[i:end])
+			}
+			i = end
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// isSQLComment reports whether a comment starts at s[i].
+func isSQLComment(s string, i int) bool {
+	return strings.HasPrefix(s[i:], "--") || strings.HasPrefix(s[i:], "/*")
+}
+
+// dollarTag matches the opening delimiter of a PostgreSQL dollar-quoted
+// string, such as $$ or $body$.
+var dollarTag = regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// skipSQLQuoted returns the end of the string literal, quoted identifier,
+// comment, or dollar-quoted string starting at s[i], or i if none starts
+// there. An unterminated one extends to the end of s. A doubled quote
+// stands for the quote; in single-quoted literals, a backslash escapes the
+// next character too in MySQL, and in escape strings in other dialects.
+func skipSQLQuoted(s string, i int, dialect SQLDialect) int {
+	switch c := s[i]; {
+	case c == '\'' || c == '"' || c == '`':
+		escapes := c == '\'' && (dialect == SQLMySQL || isEscapeString(s, i))
+		for j := i + 1; j < len(s); j++ {
+			switch {
+			case s[j] == '\\' && escapes:
+				j++
+			case s[j] == c && j+1 < len(s) && s[j+1] == c:
+				j++
+			case s[j] == c:
+				return j + 1
+			}
+		}
+		return len(s)
+	case strings.HasPrefix(s[i:], "--"):
+		if k := strings.IndexByte(s[i:], '\n'); k >= 0 {
+			return i + k
+		}
+		return len(s)
+	case strings.HasPrefix(s[i:], "/*"):
+		if k := strings.Index(s[i+2:], "*/"); k >= 0 {
+			return i + 2 + k + 2
+		}
+		return len(s)
+	case c == '$' && (i == 0 || !isSQLIdentByte(s[i-1])):
+		tag := dollarTag.FindString(s[i:])
+		if tag == "" {
+			return i
+		}
+		if k := strings.Index(s[i+len(tag):], tag); k >= 0 {
+			return i + len(tag) + k + len(tag)
+		}
+		return len(s)
+	}
+	return i
+}
+
+// isEscapeString reports whether the single quote at s[i] opens a
+// PostgreSQL escape string, E'...'.
+func isEscapeString(s string, i int) bool {
+	return i > 0 && (s[i-1] == 'E' || s[i-1] == 'e') && (i == 1 || !isSQLIdentByte(s[i-2]))
+}
+
+// isSQLSpace reports whether c is ASCII white space.
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// isSQLIdentByte reports whether c can be part of an unquoted identifier,
+// so that a following '$' does not open a dollar-quoted string.
+func isSQLIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= 0x80
+}
@@ -0,0 +1,133 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSQL(t *testing.T) {
+	text := `-- Schema
+CREATE TABLE users (
+  id int,
+  note text DEFAULT 'a;b'
+);
+INSERT INTO "odd;name" VALUES ('it''s; fine', E'c:\';');
+CREATE FUNCTION f() RETURNS int AS $body$
+BEGIN RETURN 1; END;
+$body$ LANGUAGE plpgsql;
+/* ; */ SELECT 1;
+COPY users (id, note) FROM stdin;
+1	x;y
+2	\N
+\.
+
+SELECT 2`
+	want := []string{
+		"-- Schema\nCREATE TABLE users (\n  id int,\n  note text DEFAULT 'a;b'\n);\n",
+		`INSERT INTO "odd;name" VALUES ('it''s; fine', E'c:\';');` + "\n",
+		"CREATE FUNCTION f() RETURNS int AS $body$\nBEGIN RETURN 1; END;\n$body$ LANGUAGE plpgsql;\n",
+		"/* ; */ SELECT 1;\n",
+		"COPY users (id, note) FROM stdin;\n1\tx;y\n2\t\\N\n\\.\n\n",
+		"SELECT 2",
+	}
+
+	got := SplitSQL(text, SQLStandard)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitSQL() =\n%q\nwant\n%q", got, want)
+	}
+	if strings.Join(got, "") != text {
+		t.Error("statements do not reproduce the text")
+	}
+
+	if got := SplitSQL("SELECT 1;\n\n", SQLStandard); len(got) != 1 {
+		t.Errorf("trailing white space: SplitSQL() = %q, want one statement", got)
+	}
+	if got := SplitSQL("", SQLStandard); got != nil {
+		t.Errorf("empty: SplitSQL() = %q, want nil", got)
+	}
+}
+
+func TestSplitSQL_Dialect(t *testing.T) {
+	tests := []struct {
+		text    string
+		dialect SQLDialect
+		want    []string
+	}{
+		{
+			// A backslash is an ordinary character in pg_dump output
+			"INSERT INTO p VALUES ('C:\\');\nCREATE TABLE x (id int);\nCREATE TABLE y (id int);\n",
+			SQLStandard,
+			[]string{"INSERT INTO p VALUES ('C:\\');\n", "CREATE TABLE x (id int);\n", "CREATE TABLE y (id int);\n"},
+		},
+		{
+			"INSERT INTO p VALUES (E'it\\'s;', e'\\\\');\nSELECT 1;\n",
+			SQLStandard,
+			[]string{"INSERT INTO p VALUES (E'it\\'s;', e'\\\\');\n", "SELECT 1;\n"},
+		},
+		{
+			// An identifier ending in e does not start an escape string
+			"SELECT type'\\';\nSELECT 1;\n",
+			SQLStandard,
+			[]string{"SELECT type'\\';\n", "SELECT 1;\n"},
+		},
+		{
+			// mysqldump escapes quotes with a backslash
+			"INSERT INTO p VALUES ('it\\'s;', 'C:\\\\');\nSELECT 1;\n",
+			SQLMySQL,
+			[]string{"INSERT INTO p VALUES ('it\\'s;', 'C:\\\\');\n", "SELECT 1;\n"},
+		},
+	}
+	for _, tt := range tests {
+		if got := SplitSQL(tt.text, tt.dialect); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitSQL(%q, %v) = %q, want %q", tt.text, tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"CREATE TABLE t (\n  id int,\n  name text -- display name\n);\n", "CREATE TABLE t(id int,name text);"},
+		{"CREATE TABLE t(id int, name text);", "CREATE TABLE t(id int,name text);"},
+		{"SELECT  'two  spaces',\t\"Mixed  Case\"  FROM t;", `SELECT 'two  spaces',"Mixed  Case" FROM t;`},
+		{"/* header */\nSELECT 1 /* inline */ + 2;", "SELECT 1 + 2;"},
+		{"COPY t (a) FROM stdin;\n1\t  x\n\\.\n\n", "COPY t(a) FROM stdin;\n1\t  x\n\\."},
+	}
+	for _, tt := range tests {
+		if got := NormalizeSQL(tt.in, SQLStandard); got != tt.want {
+			t.Errorf("NormalizeSQL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDiffSQL(t *testing.T) {
+	a := `CREATE TABLE users (id int, name text);
+CREATE INDEX users_name ON users (name);
+CREATE TABLE orders (id int, user_id int);
+`
+	b := `-- Generated by dump 2.0
+CREATE TABLE users (
+    id int,
+    name text,
+    email text
+);
+
+CREATE INDEX users_name ON users (name);
+
+CREATE TABLE orders (
+    id int,
+    user_id int
+);
+`
+	r := DiffSQL(a, b, SQLStandard)
+	want := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 1, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 1, AEnd: 1, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 1, AEnd: 3, BStart: 1, BEnd: 3},
+	}
+	if !reflect.DeepEqual(r.Ops, want) {
+		t.Errorf("Ops = %v, want %v", r.Ops, want)
+	}
+}
@@ -0,0 +1,38 @@
+package diffx
+
+// DiffStat summarizes the size of an edit script, as printed by
+// "git diff --stat".
+type DiffStat struct {
+	Insertions int // elements inserted from B
+	Deletions  int // elements deleted from A
+	Hunks      int // runs of consecutive change operations
+}
+
+// Stat counts the insertions, deletions, and change regions of ops.
+func Stat(ops []DiffOp) DiffStat {
+	var s DiffStat
+	for _, op := range ops {
+		switch op.Type {
+		case Insert:
+			s.Insertions += op.LenB()
+		case Delete:
+			s.Deletions += op.LenA()
+		}
+	}
+	s.Hunks = countChangeRegions(ops)
+	return s
+}
+
+// Changes returns the total number of inserted and deleted elements.
+func (s DiffStat) Changes() int {
+	return s.Insertions + s.Deletions
+}
+
+// Add returns the sum of two stats, for totals across files.
+func (s DiffStat) Add(other DiffStat) DiffStat {
+	return DiffStat{
+		Insertions: s.Insertions + other.Insertions,
+		Deletions:  s.Deletions + other.Deletions,
+		Hunks:      s.Hunks + other.Hunks,
+	}
+}
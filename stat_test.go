@@ -0,0 +1,30 @@
+package diffx
+
+import "testing"
+
+func TestStat(t *testing.T) {
+	ops := []DiffOp{
+		{Type: Delete, AStart: 0, AEnd: 2, BStart: 0, BEnd: 0},
+		{Type: Insert, AStart: 2, AEnd: 2, BStart: 0, BEnd: 1},
+		{Type: Equal, AStart: 2, AEnd: 5, BStart: 1, BEnd: 4},
+		{Type: Insert, AStart: 5, AEnd: 5, BStart: 4, BEnd: 7},
+	}
+
+	got := Stat(ops)
+	want := DiffStat{Insertions: 4, Deletions: 2, Hunks: 2}
+	if got != want {
+		t.Errorf("Stat() = %+v, want %+v", got, want)
+	}
+	if got.Changes() != 6 {
+		t.Errorf("Changes() = %d, want 6", got.Changes())
+	}
+	if sum := got.Add(want); sum != (DiffStat{Insertions: 8, Deletions: 4, Hunks: 4}) {
+		t.Errorf("Add() = %+v", sum)
+	}
+}
+
+func TestStat_Empty(t *testing.T) {
+	if got := Stat(nil); got != (DiffStat{}) {
+		t.Errorf("Stat(nil) = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,149 @@
+package diffx
+
+// Run statistics.
+//
+// The heuristics that keep diffx fast on large inputs change its output
+// when they take effect: the cost limit and expense threshold split at the
+// best snake found instead of the optimal one, the greedy fallback gives up
+// on optimality altogether, and preprocessing hides frequent elements from
+// the core algorithm. Stats makes those decisions visible, so operators can
+// see when and why they happened on production inputs.
+
+// Stats records what the diff algorithm did. Pass a *Stats to WithStats to
+// have a diff fill it in.
+//
+// Counters accumulate across calls that share a Stats, so one Stats can
+// total the work of a whole directory diff; reset it with *s = Stats{}. A
+// Stats must not be shared by concurrent calls.
+type Stats struct {
+	Calls int // diff calls recorded
+	LenA  int // elements of A compared
+	LenB  int // elements of B compared
+
+	// Preprocessing
+	FilteredA int // elements of A hidden from the core algorithm
+	FilteredB int // elements of B hidden from the core algorithm
+
+	// Myers core
+	Subproblems        int // subsequence pairs compared by divide and conquer
+	MiddleSnakes       int // middle-snake searches
+	MaxD               int // largest edit distance D reached by a middle-snake search
+	HeuristicFallbacks int // searches split by a heuristic, greedy fallbacks included
+	GreedyFallbacks    int // searches that found no snake and split greedily
+
+	// Histogram core
+	HistogramAnchors int // anchors chosen by histogram diff
+	MyersFallbacks   int // sections without an anchor diffed with Myers
+
+	// Work limit
+	CoarseFallbacks int // diffs over the work limit computed coarsely
+}
+
+// FilterRatio returns the fraction of compared elements that preprocessing
+// hid from the core algorithm, or 0 if nothing was compared.
+func (s *Stats) FilterRatio() float64 {
+	if s.LenA+s.LenB == 0 {
+		return 0
+	}
+	return float64(s.FilteredA+s.FilteredB) / float64(s.LenA+s.LenB)
+}
+
+// The recording methods below accept a nil receiver, so the algorithm can
+// call them unconditionally when no Stats was requested.
+
+// recordCall records the start of a diff of a and b.
+func (s *Stats) recordCall(a, b []Element) {
+	if s == nil {
+		return
+	}
+	s.Calls++
+	s.LenA += len(a)
+	s.LenB += len(b)
+}
+
+// recordFilter records preprocessing that reduced a to n elements and b to
+// m elements.
+func (s *Stats) recordFilter(a, b []Element, n, m int) {
+	if s == nil {
+		return
+	}
+	s.FilteredA += len(a) - n
+	s.FilteredB += len(b) - m
+}
+
+// recordSubproblem records one call of compareSeq.
+func (s *Stats) recordSubproblem() {
+	if s != nil {
+		s.Subproblems++
+	}
+}
+
+// recordSnake records a middle-snake search that stopped at edit distance
+// d, and whether it stopped through a heuristic.
+func (s *Stats) recordSnake(d int, heuristic bool) {
+	if s == nil {
+		return
+	}
+	s.MiddleSnakes++
+	if d > s.MaxD {
+		s.MaxD = d
+	}
+	if heuristic {
+		s.HeuristicFallbacks++
+	}
+}
+
+// recordGreedy records a greedy fallback.
+func (s *Stats) recordGreedy() {
+	if s != nil {
+		s.GreedyFallbacks++
+	}
+}
+
+// recordAnchor records a histogram anchor, or a Myers fallback when no
+// anchor was found.
+func (s *Stats) recordAnchor(found bool) {
+	switch {
+	case s == nil:
+	case found:
+		s.HistogramAnchors++
+	default:
+		s.MyersFallbacks++
+	}
+}
+
+// recordCoarse records a diff abandoned at the work limit and computed
+// coarsely.
+func (s *Stats) recordCoarse() {
+	if s != nil {
+		s.CoarseFallbacks++
+	}
+}
+
+// add adds the counters of t to s.
+func (s *Stats) add(t *Stats) {
+	if s == nil {
+		return
+	}
+	s.Calls += t.Calls
+	s.LenA += t.LenA
+	s.LenB += t.LenB
+	s.FilteredA += t.FilteredA
+	s.FilteredB += t.FilteredB
+	s.Subproblems += t.Subproblems
+	s.MiddleSnakes += t.MiddleSnakes
+	s.MaxD = max(s.MaxD, t.MaxD)
+	s.HeuristicFallbacks += t.HeuristicFallbacks
+	s.GreedyFallbacks += t.GreedyFallbacks
+	s.HistogramAnchors += t.HistogramAnchors
+	s.MyersFallbacks += t.MyersFallbacks
+	s.CoarseFallbacks += t.CoarseFallbacks
+}
+
+// WithStats records statistics about the diff in s. See Stats.
+// Default: nil (no statistics).
+func WithStats(s *Stats) Option {
+	return func(o *options) {
+		o.stats = s
+	}
+}
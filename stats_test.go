@@ -0,0 +1,139 @@
+package diffx
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWithStats_Basic(t *testing.T) {
+	var s Stats
+	Diff([]string{"a", "b", "c", "d"}, []string{"a", "x", "c", "y"}, WithPreprocessing(false), WithStats(&s))
+
+	if s.Calls != 1 || s.LenA != 4 || s.LenB != 4 {
+		t.Errorf("Calls, LenA, LenB = %d, %d, %d, want 1, 4, 4", s.Calls, s.LenA, s.LenB)
+	}
+	if s.Subproblems == 0 || s.MiddleSnakes == 0 {
+		t.Errorf("Subproblems = %d, MiddleSnakes = %d, want both > 0", s.Subproblems, s.MiddleSnakes)
+	}
+	if s.MaxD == 0 {
+		t.Error("MaxD = 0, want > 0")
+	}
+	if s.HeuristicFallbacks != 0 || s.GreedyFallbacks != 0 {
+		t.Errorf("fallbacks = %d, %d, want 0, 0", s.HeuristicFallbacks, s.GreedyFallbacks)
+	}
+}
+
+func TestWithStats_Accumulates(t *testing.T) {
+	var s Stats
+	a := []string{"a", "b", "c"}
+	b := []string{"a", "x", "c"}
+	Diff(a, b, WithStats(&s))
+	once := s
+	Diff(a, b, WithStats(&s))
+
+	if s.Calls != 2 || s.LenA != 2*once.LenA || s.Subproblems != 2*once.Subproblems {
+		t.Errorf("after two calls: %+v, after one: %+v", s, once)
+	}
+}
+
+func TestWithStats_HeuristicFallback(t *testing.T) {
+	// A long common run right after the first change lets the search
+	// split there once the cost limit is reached
+	var a, b []string
+	a = append(a, "p")
+	b = append(b, "q")
+	for i := 0; i < 40; i++ {
+		a = append(a, fmt.Sprintf("run%d", i))
+		b = append(b, fmt.Sprintf("run%d", i))
+	}
+	for i := 0; i < 100; i++ {
+		a = append(a, fmt.Sprintf("x%d", i))
+		b = append(b, fmt.Sprintf("y%d", i))
+	}
+
+	var s Stats
+	ops := Diff(a, b, WithCostLimit(5), WithPreprocessing(false), WithStats(&s))
+	if s.HeuristicFallbacks == 0 {
+		t.Errorf("HeuristicFallbacks = 0, want > 0 (stats %+v)", s)
+	}
+	if got := applyDiffStrings(a, b, ops); !reflect.DeepEqual(got, b) {
+		t.Errorf("script does not rebuild B")
+	}
+
+	s = Stats{}
+	Diff(a, b, WithMinimal(true), WithPreprocessing(false), WithStats(&s))
+	if s.HeuristicFallbacks != 0 {
+		t.Errorf("minimal: HeuristicFallbacks = %d, want 0", s.HeuristicFallbacks)
+	}
+}
+
+func TestWithStats_Filter(t *testing.T) {
+	// Elements found on one side only are hidden by preprocessing
+	var a, b []string
+	for i := 0; i < 50; i++ {
+		a = append(a, "}", fmt.Sprintf("k%d", i), fmt.Sprintf("a%d", i))
+		b = append(b, "}", fmt.Sprintf("k%d", i), fmt.Sprintf("b%d", i))
+	}
+
+	var s Stats
+	Diff(a, b, WithStats(&s))
+	if s.FilteredA == 0 || s.FilteredB == 0 {
+		t.Errorf("FilteredA, FilteredB = %d, %d, want both > 0", s.FilteredA, s.FilteredB)
+	}
+	if r := s.FilterRatio(); r <= 0 || r > 1 {
+		t.Errorf("FilterRatio() = %v, want in (0, 1]", r)
+	}
+
+	s = Stats{}
+	Diff(a, b, WithPreprocessing(false), WithStats(&s))
+	if s.FilteredA != 0 || s.FilteredB != 0 {
+		t.Errorf("without preprocessing: FilteredA, FilteredB = %d, %d, want 0, 0", s.FilteredA, s.FilteredB)
+	}
+}
+
+func TestWithStats_Histogram(t *testing.T) {
+	var s Stats
+	DiffHistogram(
+		[]string{"a", "b", "anchor", "c", "d"},
+		[]string{"x", "y", "anchor", "z", "w"},
+		WithStats(&s),
+	)
+	if s.Calls != 1 || s.HistogramAnchors == 0 {
+		t.Errorf("Calls = %d, HistogramAnchors = %d, want 1, > 0", s.Calls, s.HistogramAnchors)
+	}
+
+	// Only stopwords in common: no anchor, so Myers takes over. Without
+	// preprocessing, which would hide the unmatched elements from it
+	s = Stats{}
+	DiffHistogram([]string{"x", "the", "y"}, []string{"z", "the", "w"}, WithStats(&s), WithPreprocessing(false))
+	if s.MyersFallbacks == 0 || s.MiddleSnakes == 0 {
+		t.Errorf("MyersFallbacks = %d, MiddleSnakes = %d, want both > 0", s.MyersFallbacks, s.MiddleSnakes)
+	}
+}
+
+func TestStats_FilterRatio(t *testing.T) {
+	tests := []struct {
+		s    Stats
+		want float64
+	}{
+		{Stats{}, 0},
+		{Stats{LenA: 10, LenB: 10}, 0},
+		{Stats{LenA: 10, LenB: 10, FilteredA: 3, FilteredB: 2}, 0.25},
+	}
+	for _, tt := range tests {
+		if got := tt.s.FilterRatio(); got != tt.want {
+			t.Errorf("FilterRatio() of %+v = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestStats_NilReceiver(t *testing.T) {
+	var s *Stats
+	s.recordCall(nil, nil)
+	s.recordFilter(nil, nil, 0, 0)
+	s.recordSubproblem()
+	s.recordSnake(1, true)
+	s.recordGreedy()
+	s.recordAnchor(true)
+}
@@ -0,0 +1,176 @@
+package diffx
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+)
+
+// Struct slice diff.
+//
+// Audit logs need to say which records changed and how: "user 42: Email
+// changed from a@x to b@x". DiffStructs aligns two slices of structs by a
+// key field, so that insertions and reordering do not make unrelated
+// records look changed, and then compares the exported fields of each
+// matched pair.
+
+// StructChange describes a record that differs between two struct slices.
+// A is -1 for added records and B is -1 for removed records; for records
+// present in both, Fields lists the fields that changed.
+type StructChange struct {
+	Key    any // value of the key field
+	A      int // index in slice A, or -1 if added
+	B      int // index in slice B, or -1 if removed
+	Fields []FieldChange
+}
+
+// FieldChange describes a field whose value differs between two records.
+type FieldChange struct {
+	Name string
+	A, B any // the field's value in each record
+}
+
+// DiffStructs compares two slices of structs (or pointers to structs) of
+// the same type, matching elements by the value of the exported field
+// keyField. It reports added and removed elements and, for matched
+// elements, the exported fields whose values differ, compared with
+// reflect.DeepEqual. Elements that did not change are not reported.
+//
+// It returns an error if a or b is not such a slice, if the element types
+// differ, if keyField is not an exported field, or if an element is a nil
+// pointer.
+func DiffStructs(a, b any, keyField string) ([]StructChange, error) {
+	va, err := structSlice(a, "a")
+	if err != nil {
+		return nil, err
+	}
+	vb, err := structSlice(b, "b")
+	if err != nil {
+		return nil, err
+	}
+	if va.Type().Elem() != vb.Type().Elem() {
+		return nil, fmt.Errorf("diffx: element types differ: %s and %s", va.Type().Elem(), vb.Type().Elem())
+	}
+
+	st := va.Type().Elem()
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	key, ok := st.FieldByName(keyField)
+	if !ok || !key.IsExported() {
+		return nil, fmt.Errorf("diffx: %s has no exported field %q", st, keyField)
+	}
+
+	recsA, err := structRecords(va, key.Index)
+	if err != nil {
+		return nil, fmt.Errorf("diffx: slice a: %w", err)
+	}
+	recsB, err := structRecords(vb, key.Index)
+	if err != nil {
+		return nil, fmt.Errorf("diffx: slice b: %w", err)
+	}
+
+	ea := make([]Element, len(recsA))
+	for i, r := range recsA {
+		ea[i] = r
+	}
+	eb := make([]Element, len(recsB))
+	for i, r := range recsB {
+		eb[i] = r
+	}
+
+	var changes []StructChange
+	for _, op := range DiffElements(ea, eb, WithPostprocessing(false)) {
+		switch op.Type {
+		case Equal:
+			for n := 0; n < op.AEnd-op.AStart; n++ {
+				i, j := op.AStart+n, op.BStart+n
+				if fields := changedFields(recsA[i].value, recsB[j].value); len(fields) > 0 {
+					changes = append(changes, StructChange{Key: recsA[i].key, A: i, B: j, Fields: fields})
+				}
+			}
+		case Delete:
+			for i := op.AStart; i < op.AEnd; i++ {
+				changes = append(changes, StructChange{Key: recsA[i].key, A: i, B: -1})
+			}
+		case Insert:
+			for j := op.BStart; j < op.BEnd; j++ {
+				changes = append(changes, StructChange{Key: recsB[j].key, A: -1, B: j})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// structRecord is an Element comparing structs by key field value.
+type structRecord struct {
+	key   any
+	id    string // formatted key, used for equality and hashing
+	value reflect.Value
+}
+
+// Equal reports whether other is a record with the same key.
+func (r structRecord) Equal(other Element) bool {
+	o, ok := other.(structRecord)
+	return ok && r.id == o.id
+}
+
+// Hash returns a FNV-1a hash of the formatted key.
+func (r structRecord) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(r.id))
+	return h.Sum64()
+}
+
+// structSlice checks that v is a slice or array of structs or struct
+// pointers.
+func structSlice(v any, name string) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return reflect.Value{}, fmt.Errorf("diffx: %s is %T, not a slice", name, v)
+	}
+	et := rv.Type().Elem()
+	if et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("diffx: %s has element type %s, not a struct", name, rv.Type().Elem())
+	}
+	return rv, nil
+}
+
+// structRecords converts slice elements to records keyed by the field at
+// index.
+func structRecords(v reflect.Value, index []int) ([]structRecord, error) {
+	recs := make([]structRecord, v.Len())
+	for i := range recs {
+		ev := v.Index(i)
+		if ev.Kind() == reflect.Pointer {
+			if ev.IsNil() {
+				return nil, fmt.Errorf("element %d is nil", i)
+			}
+			ev = ev.Elem()
+		}
+		key := ev.FieldByIndex(index).Interface()
+		recs[i] = structRecord{key: key, id: fmt.Sprintf("%#v", key), value: ev}
+	}
+	return recs, nil
+}
+
+// changedFields compares the exported fields of two structs of the same
+// type.
+func changedFields(a, b reflect.Value) []FieldChange {
+	var fields []FieldChange
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fa, fb := a.Field(i).Interface(), b.Field(i).Interface()
+		if !reflect.DeepEqual(fa, fb) {
+			fields = append(fields, FieldChange{Name: f.Name, A: fa, B: fb})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,83 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testUser struct {
+	ID    int
+	Email string
+	Tags  []string
+	note  string
+}
+
+func TestDiffStructs(t *testing.T) {
+	a := []testUser{
+		{ID: 1, Email: "a@example.com"},
+		{ID: 2, Email: "b@example.com", Tags: []string{"admin"}},
+		{ID: 3, Email: "c@example.com"},
+	}
+	b := []testUser{
+		{ID: 1, Email: "a@example.com", note: "ignored"},
+		{ID: 4, Email: "d@example.com"},
+		{ID: 2, Email: "b@example.org", Tags: []string{"admin", "ops"}},
+	}
+
+	got, err := DiffStructs(a, b, "ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []StructChange{
+		{Key: 4, A: -1, B: 1},
+		{Key: 2, A: 1, B: 2, Fields: []FieldChange{
+			{Name: "Email", A: "b@example.com", B: "b@example.org"},
+			{Name: "Tags", A: []string{"admin"}, B: []string{"admin", "ops"}},
+		}},
+		{Key: 3, A: 2, B: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffStructs() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffStructs_Pointers(t *testing.T) {
+	a := []*testUser{{ID: 1, Email: "x"}}
+	b := []*testUser{{ID: 1, Email: "y"}}
+
+	got, err := DiffStructs(a, b, "ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].Fields) != 1 || got[0].Fields[0].Name != "Email" {
+		t.Errorf("DiffStructs() = %+v, want Email change", got)
+	}
+}
+
+func TestDiffStructs_Errors(t *testing.T) {
+	users := []testUser{{ID: 1}}
+	tests := []struct {
+		name string
+		a, b any
+		key  string
+		want string
+	}{
+		{"not a slice", testUser{}, users, "ID", "not a slice"},
+		{"not structs", []int{1}, users, "ID", "not a struct"},
+		{"different types", users, []struct{ ID int }{}, "ID", "element types differ"},
+		{"unknown field", users, users, "Name", `no exported field "Name"`},
+		{"unexported field", users, users, "note", `no exported field "note"`},
+		{"nil element", []*testUser{nil}, []*testUser{}, "ID", "element 0 is nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DiffStructs(tt.a, tt.b, tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Package tablediff compares tabular data, such as parsed CSV files, by
+// aligning both rows and columns.
+//
+// A line diff of two CSV exports marks every row as changed when a column
+// is added or removed. tablediff aligns the columns by header first, then
+// aligns the rows using only the columns the tables share, and reports a
+// cell-level change matrix that can be rendered as a spreadsheet of
+// differences.
+package tablediff
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// ChangeType identifies how a column, row, or cell changed. It is an
+// alias of diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Unchanged means the item is the same in both tables.
+	Unchanged = diffx.ChangeUnchanged
+	// Added means the item is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the item is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the row or cell is present in both with different
+	// content.
+	Modified = diffx.ChangeModified
+)
+
+// Column describes a column of the combined table.
+type Column struct {
+	Type ChangeType // Unchanged, Added, or Removed
+	Name string     // header text, or empty without a header
+	A, B int        // column index in each table, or -1 if absent
+}
+
+// Cell is one cell of the change matrix.
+type Cell struct {
+	Type ChangeType
+	A, B string // value in each table; empty if absent
+}
+
+// Row describes a row of the combined table. Cells has one entry per
+// column of the Result.
+type Row struct {
+	Type  ChangeType
+	A, B  int // data row index in each table, or -1 if absent
+	Cells []Cell
+}
+
+// Result is the change matrix of two tables.
+type Result struct {
+	Columns []Column
+	Rows    []Row
+}
+
+// Changed reports whether the tables differ.
+func (r *Result) Changed() bool {
+	for _, c := range r.Columns {
+		if c.Type != Unchanged {
+			return true
+		}
+	}
+	for _, row := range r.Rows {
+		if row.Type != Unchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// Option configures the comparison.
+type Option func(*options)
+
+type options struct {
+	header    bool
+	keyColumn string
+	diffOpts  []diffx.Option
+}
+
+// WithHeader sets whether the first row of each table is a header. With a
+// header, columns are aligned by name; without one, by position.
+// Default: true.
+func WithHeader(enabled bool) Option {
+	return func(o *options) {
+		o.header = enabled
+	}
+}
+
+// WithKeyColumn sets a column, by header name, whose value identifies a
+// row. Rows then match only if their keys are equal, and a row whose key
+// changed is reported as removed and added. Without a key column, rows
+// are aligned by the content of the shared columns, and unmatched rows at
+// the same place are reported as modified. Requires a header.
+func WithKeyColumn(name string) Option {
+	return func(o *options) {
+		o.keyColumn = name
+	}
+}
+
+// WithDiffOptions sets options passed to diffx when aligning rows and
+// columns.
+func WithDiffOptions(opts ...diffx.Option) Option {
+	return func(o *options) {
+		o.diffOpts = opts
+	}
+}
+
+// Diff compares two tables, given as rows of cells. Rows may have
+// different lengths; missing cells are treated as empty.
+func Diff(a, b [][]string, opts ...Option) *Result {
+	o := &options{header: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var headerA, headerB []string
+	rowsA, rowsB := a, b
+	if o.header {
+		if len(a) > 0 {
+			headerA, rowsA = a[0], a[1:]
+		}
+		if len(b) > 0 {
+			headerB, rowsB = b[0], b[1:]
+		}
+	}
+
+	var res *Result
+	if o.header {
+		res = &Result{Columns: alignColumns(headerA, headerB, o)}
+	} else {
+		res = &Result{Columns: positionalColumns(width(a), width(b))}
+	}
+
+	keyA, keyB := -1, -1
+	if o.header && o.keyColumn != "" {
+		keyA, keyB = indexOf(headerA, o.keyColumn), indexOf(headerB, o.keyColumn)
+	}
+	keyed := keyA >= 0 && keyB >= 0
+
+	ea := rowKeys(rowsA, res.Columns, keyA, true)
+	eb := rowKeys(rowsB, res.Columns, keyB, false)
+	ops := diffx.DiffElements(ea, eb, o.diffOpts...)
+
+	for k := 0; k < len(ops); {
+		if ops[k].Type == diffx.Equal {
+			for n := 0; n < ops[k].AEnd-ops[k].AStart; n++ {
+				res.Rows = append(res.Rows, res.row(rowsA, rowsB, ops[k].AStart+n, ops[k].BStart+n))
+			}
+			k++
+			continue
+		}
+
+		// Gather the change region
+		aStart, bStart := ops[k].AStart, ops[k].BStart
+		aEnd, bEnd := ops[k].AEnd, ops[k].BEnd
+		for k < len(ops) && ops[k].Type != diffx.Equal {
+			aEnd = max(aEnd, ops[k].AEnd)
+			bEnd = max(bEnd, ops[k].BEnd)
+			k++
+		}
+
+		common := 0
+		if !keyed {
+			common = min(aEnd-aStart, bEnd-bStart)
+		}
+		for n := 0; n < common; n++ {
+			res.Rows = append(res.Rows, res.row(rowsA, rowsB, aStart+n, bStart+n))
+		}
+		for i := aStart + common; i < aEnd; i++ {
+			res.Rows = append(res.Rows, res.row(rowsA, rowsB, i, -1))
+		}
+		for j := bStart + common; j < bEnd; j++ {
+			res.Rows = append(res.Rows, res.row(rowsA, rowsB, -1, j))
+		}
+	}
+
+	return res
+}
+
+// alignColumns aligns the columns of two headers.
+func alignColumns(headerA, headerB []string, o *options) []Column {
+	var cols []Column
+	for _, op := range diffx.Diff(headerA, headerB, o.diffOpts...) {
+		switch op.Type {
+		case diffx.Equal:
+			for n := 0; n < op.AEnd-op.AStart; n++ {
+				cols = append(cols, Column{Type: Unchanged, Name: headerA[op.AStart+n], A: op.AStart + n, B: op.BStart + n})
+			}
+		case diffx.Delete:
+			for i := op.AStart; i < op.AEnd; i++ {
+				cols = append(cols, Column{Type: Removed, Name: headerA[i], A: i, B: -1})
+			}
+		case diffx.Insert:
+			for j := op.BStart; j < op.BEnd; j++ {
+				cols = append(cols, Column{Type: Added, Name: headerB[j], A: -1, B: j})
+			}
+		}
+	}
+	return cols
+}
+
+// positionalColumns pairs columns by index.
+func positionalColumns(widthA, widthB int) []Column {
+	cols := make([]Column, 0, max(widthA, widthB))
+	for c := 0; c < max(widthA, widthB); c++ {
+		switch {
+		case c >= widthA:
+			cols = append(cols, Column{Type: Added, A: -1, B: c})
+		case c >= widthB:
+			cols = append(cols, Column{Type: Removed, A: c, B: -1})
+		default:
+			cols = append(cols, Column{Type: Unchanged, A: c, B: c})
+		}
+	}
+	return cols
+}
+
+// row builds the matrix row for data row i of A and j of B; either may be
+// -1 if the row is absent.
+func (r *Result) row(rowsA, rowsB [][]string, i, j int) Row {
+	row := Row{Type: Unchanged, A: i, B: j, Cells: make([]Cell, len(r.Columns))}
+	switch {
+	case i < 0:
+		row.Type = Added
+	case j < 0:
+		row.Type = Removed
+	}
+
+	for c, col := range r.Columns {
+		var cell Cell
+		inA := i >= 0 && col.A >= 0
+		inB := j >= 0 && col.B >= 0
+		if inA {
+			cell.A = cellAt(rowsA[i], col.A)
+		}
+		if inB {
+			cell.B = cellAt(rowsB[j], col.B)
+		}
+
+		switch {
+		case inA && inB:
+			if cell.A != cell.B {
+				cell.Type = Modified
+				row.Type = Modified
+			}
+		case inA:
+			cell.Type = Removed
+		case inB:
+			cell.Type = Added
+		}
+		row.Cells[c] = cell
+	}
+	return row
+}
+
+// rowKey is a diffx.Element identifying a row.
+type rowKey string
+
+// Equal reports whether other identifies the same row.
+func (k rowKey) Equal(other diffx.Element) bool {
+	o, ok := other.(rowKey)
+	return ok && k == o
+}
+
+// Hash returns a FNV-1a hash of the key.
+func (k rowKey) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(k))
+	return h.Sum64()
+}
+
+// rowKeys returns the alignment key of each row: the key column if there
+// is one, otherwise the cells of the columns both tables share.
+func rowKeys(rows [][]string, cols []Column, keyCol int, sideA bool) []diffx.Element {
+	elems := make([]diffx.Element, len(rows))
+	for i, row := range rows {
+		if keyCol >= 0 {
+			elems[i] = rowKey(cellAt(row, keyCol))
+			continue
+		}
+
+		var parts []string
+		for _, col := range cols {
+			if col.Type != Unchanged {
+				continue
+			}
+			idx := col.B
+			if sideA {
+				idx = col.A
+			}
+			parts = append(parts, cellAt(row, idx))
+		}
+		elems[i] = rowKey(strings.Join(parts, "\x00"))
+	}
+	return elems
+}
+
+// cellAt returns row[i], or "" if the row is too short.
+func cellAt(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// indexOf returns the index of name in header, or -1.
+func indexOf(header []string, name string) int {
+	for i, h := range header {
+		if h == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// width returns the length of the longest row.
+func width(rows [][]string) int {
+	w := 0
+	for _, row := range rows {
+		w = max(w, len(row))
+	}
+	return w
+}
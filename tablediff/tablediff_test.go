@@ -0,0 +1,100 @@
+package tablediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff_ColumnAdded(t *testing.T) {
+	a := [][]string{
+		{"id", "name"},
+		{"1", "alpha"},
+		{"2", "beta"},
+	}
+	b := [][]string{
+		{"id", "name", "owner"},
+		{"1", "alpha", "ann"},
+		{"2", "beta", "bob"},
+	}
+
+	res := Diff(a, b)
+
+	wantCols := []Column{
+		{Type: Unchanged, Name: "id", A: 0, B: 0},
+		{Type: Unchanged, Name: "name", A: 1, B: 1},
+		{Type: Added, Name: "owner", A: -1, B: 2},
+	}
+	if !reflect.DeepEqual(res.Columns, wantCols) {
+		t.Errorf("Columns = %+v, want %+v", res.Columns, wantCols)
+	}
+
+	// Rows still align even though every line of the file changed
+	for _, row := range res.Rows {
+		if row.Type != Unchanged {
+			t.Errorf("row %+v should be unchanged", row)
+		}
+		if row.Cells[2].Type != Added {
+			t.Errorf("owner cell = %+v, want Added", row.Cells[2])
+		}
+	}
+	if !res.Changed() {
+		t.Error("Changed() = false, want true")
+	}
+}
+
+func TestDiff_CellModified(t *testing.T) {
+	a := [][]string{{"k", "v"}, {"a", "1"}, {"b", "2"}, {"c", "3"}}
+	b := [][]string{{"k", "v"}, {"a", "1"}, {"b", "20"}, {"c", "3"}, {"d", "4"}}
+
+	res := Diff(a, b)
+
+	var types []ChangeType
+	for _, row := range res.Rows {
+		types = append(types, row.Type)
+	}
+	want := []ChangeType{Unchanged, Modified, Unchanged, Added}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("row types = %v, want %v", types, want)
+	}
+
+	cell := res.Rows[1].Cells[1]
+	if cell != (Cell{Type: Modified, A: "2", B: "20"}) {
+		t.Errorf("modified cell = %+v", cell)
+	}
+}
+
+func TestDiff_KeyColumn(t *testing.T) {
+	a := [][]string{{"id", "v"}, {"1", "x"}, {"2", "y"}}
+	b := [][]string{{"id", "v"}, {"1", "x"}, {"3", "y"}}
+
+	res := Diff(a, b, WithKeyColumn("id"))
+
+	var types []ChangeType
+	for _, row := range res.Rows {
+		types = append(types, row.Type)
+	}
+	want := []ChangeType{Unchanged, Removed, Added}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("row types = %v, want %v", types, want)
+	}
+}
+
+func TestDiff_NoHeader(t *testing.T) {
+	a := [][]string{{"1", "2"}, {"3", "4"}}
+	b := [][]string{{"1", "2", "x"}, {"3", "4"}}
+
+	res := Diff(a, b, WithHeader(false))
+	if len(res.Columns) != 3 || res.Columns[2].Type != Added {
+		t.Fatalf("Columns = %+v, want third column added", res.Columns)
+	}
+	if got := res.Rows[0].Cells[2]; got != (Cell{Type: Added, B: "x"}) {
+		t.Errorf("cell = %+v", got)
+	}
+}
+
+func TestDiff_Identical(t *testing.T) {
+	a := [][]string{{"h"}, {"1"}}
+	if res := Diff(a, a); res.Changed() {
+		t.Errorf("Changed() = true for identical tables: %+v", res)
+	}
+}
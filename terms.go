@@ -0,0 +1,146 @@
+package diffx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Terminology substitution report.
+//
+// Docs renames ("master" to "main", a product renamed, "e-mail" to
+// "email") are applied by search and replace and reviewed as hundreds of
+// one-word changes. What a reviewer wants to know is which terms were
+// replaced by which, how often, and whether any occurrence was missed.
+// DetectSubstitutions summarizes the replacements of a word-level diff by
+// term pair and counts the occurrences of each old term that were kept.
+
+// DefaultSubstitutionCount is the number of times a term must be replaced
+// by the same term for DetectSubstitutions to report it.
+const DefaultSubstitutionCount = 2
+
+// maxSubstitutionWords is the longest replaced phrase, in words, that
+// counts as a term rather than a rewritten passage.
+const maxSubstitutionWords = 4
+
+// Substitution is a term that was replaced by another term throughout a
+// text.
+type Substitution struct {
+	From  string // the replaced term
+	To    string // the term that replaced it
+	Count int    // number of replacements of From by To
+	Kept  int    // number of occurrences of From left unchanged
+}
+
+// String returns a description such as `"e-mail" => "email" (12 replaced,
+// 1 kept)`.
+func (s Substitution) String() string {
+	return fmt.Sprintf("%q => %q (%d replaced, %d kept)", s.From, s.To, s.Count, s.Kept)
+}
+
+// Consistent reports whether every occurrence of From was replaced.
+func (s Substitution) Consistent() bool {
+	return s.Kept == 0
+}
+
+// DetectSubstitutions reports the terms that a word-level diff replaces by
+// the same other term at least minCount times; a minCount of zero or less
+// uses DefaultSubstitutionCount. a and b are the tokens the ops index, for
+// example from strings.Fields; tokens of only white space are ignored, so
+// tokens that keep the white space between words work as well. A
+// replacement is a Delete directly followed by an Insert, each of at most
+// four words. A term replaced by different terms is reported once per
+// replacement term.
+//
+// The result is sorted by Count, highest first, then by From and To.
+func DetectSubstitutions(ops []DiffOp, a, b []string, minCount int) []Substitution {
+	if minCount <= 0 {
+		minCount = DefaultSubstitutionCount
+	}
+
+	type pair struct{ from, to string }
+	counts := make(map[pair]int)
+	var order []pair
+	for k := 0; k+1 < len(ops); k++ {
+		d, in := ops[k], ops[k+1]
+		if d.Type != Delete || in.Type != Insert {
+			continue
+		}
+		from, to := termWords(a[d.AStart:d.AEnd]), termWords(b[in.BStart:in.BEnd])
+		if len(from) == 0 || len(to) == 0 || len(from) > maxSubstitutionWords || len(to) > maxSubstitutionWords {
+			continue
+		}
+		p := pair{strings.Join(from, " "), strings.Join(to, " ")}
+		if counts[p] == 0 {
+			order = append(order, p)
+		}
+		counts[p]++
+	}
+
+	var subs []Substitution
+	for _, p := range order {
+		if counts[p] >= minCount {
+			subs = append(subs, Substitution{From: p.from, To: p.to, Count: counts[p]})
+		}
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	// Count the occurrences of each replaced term in the unchanged text
+	var kept [][]string
+	for _, op := range ops {
+		if op.Type == Equal {
+			kept = append(kept, termWords(a[op.AStart:op.AEnd]))
+		}
+	}
+	for i := range subs {
+		from := strings.Fields(subs[i].From)
+		for _, words := range kept {
+			subs[i].Kept += countPhrase(words, from)
+		}
+	}
+
+	sort.SliceStable(subs, func(x, y int) bool {
+		if subs[x].Count != subs[y].Count {
+			return subs[x].Count > subs[y].Count
+		}
+		if subs[x].From != subs[y].From {
+			return subs[x].From < subs[y].From
+		}
+		return subs[x].To < subs[y].To
+	})
+	return subs
+}
+
+// termWords returns tokens without white space, dropping tokens that are
+// only white space and splitting tokens that contain several words.
+func termWords(tokens []string) []string {
+	var words []string
+	for _, t := range tokens {
+		words = append(words, strings.Fields(t)...)
+	}
+	return words
+}
+
+// countPhrase returns the number of non-overlapping occurrences of phrase
+// in words.
+func countPhrase(words, phrase []string) int {
+	n := 0
+	for i := 0; i+len(phrase) <= len(words); {
+		match := true
+		for k, w := range phrase {
+			if words[i+k] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			n++
+			i += len(phrase)
+		} else {
+			i++
+		}
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectSubstitutions(t *testing.T) {
+	a := strings.Fields(`Send an e-mail to the admin. The e-mail server is on the master branch.
+		Check the master branch before you merge. Your e-mail is private. Ask the admin.`)
+	b := strings.Fields(`Send an email to the administrator. The email server is on the main branch.
+		Check the main branch before you merge. Your e-mail is private. Ask the administrator.`)
+
+	got := DetectSubstitutions(Diff(a, b), a, b, 0)
+	want := []Substitution{
+		{From: "admin.", To: "administrator.", Count: 2},
+		{From: "e-mail", To: "email", Count: 2, Kept: 1},
+		{From: "master", To: "main", Count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	if got[1].Consistent() || !got[2].Consistent() {
+		t.Errorf("Consistent() = %v, %v, want false, true", got[1].Consistent(), got[2].Consistent())
+	}
+	if s := got[1].String(); s != `"e-mail" => "email" (2 replaced, 1 kept)` {
+		t.Errorf("String() = %s", s)
+	}
+
+	if got := DetectSubstitutions(Diff(a, b), a, b, 3); got != nil {
+		t.Errorf("minCount 3: got %v, want nil", got)
+	}
+}
+
+func TestDetectSubstitutions_Tokens(t *testing.T) {
+	// Tokens that keep white space, and phrases of several words
+	a := SplitWords("Open the Control Panel. Then close the Control Panel.")
+	b := SplitWords("Open the Settings app. Then close the Settings app.")
+
+	got := DetectSubstitutions(Diff(a, b), a, b, 2)
+	want := []Substitution{{From: "Control Panel", To: "Settings app", Count: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDetectSubstitutions_Rewrites(t *testing.T) {
+	// Long rewritten passages and one-off edits are not terminology
+	a := strings.Fields("one two three four five six. alpha. one two three four five six. beta.")
+	b := strings.Fields("uno dos tres cuatro cinco seis. gamma. uno dos tres cuatro cinco seis. delta.")
+
+	if got := DetectSubstitutions(Diff(a, b), a, b, 2); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
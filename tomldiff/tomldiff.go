@@ -0,0 +1,261 @@
+// Package tomldiff compares TOML configuration files semantically.
+//
+// A textual diff of two configuration files reports reordered keys,
+// reformatted tables, and changed comments as differences even when the
+// effective configuration is the same. tomldiff decodes both files and
+// compares tables key by key, so only real drift is reported, addressed by
+// dotted key path. Arrays are aligned with diffx, so an inserted array
+// member is reported once rather than as a change to every member after it.
+package tomldiff
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dacharyc/diffx"
+)
+
+// ChangeType identifies how a value changed. It is an alias of
+// diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the key or array member is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the key or array member is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the value differs between A and B.
+	Modified = diffx.ChangeModified
+)
+
+// Change describes a value that differs between two configurations.
+type Change struct {
+	Type ChangeType
+	Path string // dotted key path, such as server.port or servers[1].host
+	A    any    // value in A, or nil if added
+	B    any    // value in B, or nil if removed
+}
+
+// String returns a one-line description such as "~ server.port: 80 -> 8080".
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.B)
+	case Removed:
+		return fmt.Sprintf("- %s: %v", c.Path, c.A)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.A, c.B)
+	}
+}
+
+// Diff decodes two TOML documents and returns their differences, sorted
+// by key within each table. opts are passed to diffx.DiffElements when
+// aligning arrays.
+func Diff(a, b []byte, opts ...diffx.Option) ([]Change, error) {
+	var ta, tb map[string]any
+	if _, err := toml.NewDecoder(bytes.NewReader(a)).Decode(&ta); err != nil {
+		return nil, fmt.Errorf("tomldiff: document A: %w", err)
+	}
+	if _, err := toml.NewDecoder(bytes.NewReader(b)).Decode(&tb); err != nil {
+		return nil, fmt.Errorf("tomldiff: document B: %w", err)
+	}
+	return DiffTables(ta, tb, opts...), nil
+}
+
+// DiffTables is like Diff but takes tables that have already been decoded.
+func DiffTables(a, b map[string]any, opts ...diffx.Option) []Change {
+	return diffTable(nil, "", a, b, opts)
+}
+
+// diffValue appends the changes between a and b at path.
+func diffValue(changes []Change, path string, a, b any, opts []diffx.Option) []Change {
+	if ta, ok := a.(map[string]any); ok {
+		if tb, ok := b.(map[string]any); ok {
+			return diffTable(changes, path, ta, tb, opts)
+		}
+	}
+	if aa, ok := asArray(a); ok {
+		if ab, ok := asArray(b); ok {
+			return diffArray(changes, path, aa, ab, opts)
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return changes
+	}
+	return append(changes, Change{Type: Modified, Path: path, A: a, B: b})
+}
+
+// diffTable compares table keys in sorted order.
+func diffTable(changes []Change, path string, a, b map[string]any, opts []diffx.Option) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := joinKey(path, k)
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			changes = append(changes, Change{Type: Removed, Path: p, A: va})
+		case !inA:
+			changes = append(changes, Change{Type: Added, Path: p, B: vb})
+		default:
+			changes = diffValue(changes, p, va, vb, opts)
+		}
+	}
+	return changes
+}
+
+// diffArray aligns array members with diffx. Within each change region,
+// members are compared pairwise; the surplus is reported as removed (by
+// index in A) or added (by index in B).
+func diffArray(changes []Change, path string, a, b []any, opts []diffx.Option) []Change {
+	ops := diffx.DiffElements(toValues(a), toValues(b), opts...)
+
+	for k := 0; k < len(ops); {
+		if ops[k].Type == diffx.Equal {
+			k++
+			continue
+		}
+
+		aStart, bStart := ops[k].AStart, ops[k].BStart
+		aEnd, bEnd := ops[k].AEnd, ops[k].BEnd
+		for k < len(ops) && ops[k].Type != diffx.Equal {
+			aEnd = max(aEnd, ops[k].AEnd)
+			bEnd = max(bEnd, ops[k].BEnd)
+			k++
+		}
+
+		common := min(aEnd-aStart, bEnd-bStart)
+		for n := 0; n < common; n++ {
+			changes = diffValue(changes, indexKey(path, bStart+n), a[aStart+n], b[bStart+n], opts)
+		}
+		for i := aStart + common; i < aEnd; i++ {
+			changes = append(changes, Change{Type: Removed, Path: indexKey(path, i), A: a[i]})
+		}
+		for j := bStart + common; j < bEnd; j++ {
+			changes = append(changes, Change{Type: Added, Path: indexKey(path, j), B: b[j]})
+		}
+	}
+	return changes
+}
+
+// asArray returns v as a generic slice. Arrays of tables decode as
+// []map[string]any and are converted.
+func asArray(v any) ([]any, bool) {
+	switch arr := v.(type) {
+	case []any:
+		return arr, true
+	case []map[string]any:
+		out := make([]any, len(arr))
+		for i, t := range arr {
+			out[i] = t
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// value is a diffx.Element comparing decoded values by their canonical
+// encoding; see canonical.
+type value string
+
+// Equal reports whether other encodes the same value.
+func (v value) Equal(other diffx.Element) bool {
+	o, ok := other.(value)
+	return ok && v == o
+}
+
+// Hash returns a FNV-1a hash of the encoded value.
+func (v value) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(v))
+	return h.Sum64()
+}
+
+// toValues converts array members to elements.
+func toValues(members []any) []diffx.Element {
+	elems := make([]diffx.Element, len(members))
+	for i, m := range members {
+		var b strings.Builder
+		canonical(&b, m)
+		elems[i] = value(b.String())
+	}
+	return elems
+}
+
+// canonical writes an encoding of the decoded value v to b that differs
+// for any two values that differ: strings and keys are quoted, tables are
+// written with sorted keys, and scalars with their type, so that the
+// string "1" and the integer 1, or [["a b"]] and [["a", "b"]], are told
+// apart.
+func canonical(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(k))
+			b.WriteByte(':')
+			canonical(b, v[k])
+		}
+		b.WriteByte('}')
+	default:
+		if arr, ok := asArray(v); ok {
+			b.WriteByte('[')
+			for i, m := range arr {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				canonical(b, m)
+			}
+			b.WriteByte(']')
+			return
+		}
+		fmt.Fprintf(b, "%T(%v)", v, v)
+	}
+}
+
+// bareKey matches keys that need no quoting in a dotted path.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// joinKey appends key to a dotted path, quoting it if necessary.
+func joinKey(path, key string) string {
+	if !bareKey.MatchString(key) {
+		key = strconv.Quote(key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// indexKey appends an array index to path.
+func indexKey(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
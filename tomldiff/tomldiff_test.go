@@ -0,0 +1,114 @@
+package tomldiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := `
+title = "service"
+
+[server]
+host = "localhost"
+port = 80
+
+[[backends]]
+name = "a"
+weight = 1
+
+[[backends]]
+name = "b"
+weight = 1
+`
+	// Same configuration reordered and reformatted, with real changes
+	b := `
+title = "service"
+
+[[backends]]
+name = "a"
+weight = 1
+
+[[backends]]
+name = "b"
+weight = 2
+
+[[backends]]
+name = "c"
+weight = 1
+
+[server]
+port = 8080
+host = "localhost"
+tls = true
+`
+
+	changes, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"~ backends[1].weight: 1 -> 2",
+		"+ backends[2]: map[name:c weight:1]",
+		"~ server.port: 80 -> 8080",
+		"+ server.tls: true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDiff_Identical(t *testing.T) {
+	a := "a = 1\nb = [1, 2]\n[t]\nc = 'x'\n"
+	b := "[t]\nc = \"x\"\n"
+	b = "a = 1\nb = [ 1, 2 ]\n" + b
+
+	changes, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestDiff_ArrayMembers(t *testing.T) {
+	// Members that print the same with %v but differ
+	tests := []struct {
+		a, b string
+	}{
+		{`x = [["a b"]]`, `x = [["a", "b"]]`},
+		{`x = ["1"]`, `x = [1]`},
+		{`x = [{k = "a, b"}]`, `x = [{k = "a", b = ""}]`},
+	}
+	for _, tt := range tests {
+		changes, err := Diff([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) == 0 {
+			t.Errorf("Diff(%q, %q) reported no changes", tt.a, tt.b)
+		}
+	}
+}
+
+func TestDiff_QuotedKeys(t *testing.T) {
+	changes, err := Diff([]byte(`"a.b" = 1`), []byte(`"a.b" = 2`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != `"a.b"` {
+		t.Errorf("Diff() = %v, want change at \"a.b\"", changes)
+	}
+}
+
+func TestDiff_Invalid(t *testing.T) {
+	if _, err := Diff([]byte("a = "), []byte("")); err == nil {
+		t.Error("expected error for invalid TOML")
+	}
+}
@@ -0,0 +1,115 @@
+package diffx
+
+// Debug tracing.
+//
+// When a diff comes out surprising, the cause is usually one decision deep
+// inside the algorithm: a trim that swallowed a repeated line, a middle
+// snake chosen by a heuristic, a histogram anchor in an unexpected place, or
+// a boundary shift. WithTrace reports each of those decisions as it is made,
+// so the output can be explained without patching the library.
+
+// TraceKind identifies the decision a TraceEvent reports.
+type TraceKind int
+
+const (
+	// TraceTrim reports a common prefix and suffix trimmed from a region
+	// before it is diffed. Prefix and Suffix hold their lengths.
+	TraceTrim TraceKind = iota
+	// TraceMiddleSnake reports the split point chosen for a region by the
+	// Myers middle-snake search. X and Y hold the split point, D the edit
+	// distance the search reached, and Heuristic whether a heuristic chose
+	// the split instead of the search meeting in the middle.
+	TraceMiddleSnake
+	// TraceAnchor reports the anchor chosen for a region by histogram diff.
+	// X and Y hold the start of the matched run in A and B, Len its length,
+	// and Frequency how often the anchor element occurs in the region of A.
+	TraceAnchor
+	// TraceShift reports a change region slid along its sequence by
+	// postprocessing. The region holds the change operations after the
+	// slide and Shift the distance they moved, negative for upward.
+	TraceShift
+	// TraceCoarse reports a region whose search exceeded the work limit
+	// and was abandoned, in the indices of the inputs. The region was
+	// diffed coarsely instead; the events reported for it before come from
+	// the abandoned search.
+	TraceCoarse
+)
+
+// String returns a string representation of the TraceKind.
+func (k TraceKind) String() string {
+	switch k {
+	case TraceTrim:
+		return "Trim"
+	case TraceMiddleSnake:
+		return "MiddleSnake"
+	case TraceAnchor:
+		return "Anchor"
+	case TraceShift:
+		return "Shift"
+	case TraceCoarse:
+		return "Coarse"
+	default:
+		return "Unknown"
+	}
+}
+
+// TraceEvent describes one decision made by the diff algorithm. Which
+// fields are set depends on Kind; see the TraceKind constants.
+//
+// Every event applies to the region [AStart, AEnd) of A and [BStart, BEnd)
+// of B. Trim and middle-snake events from the Myers algorithm use indices
+// into the sequences after preprocessing, which hides some elements; run
+// with WithPreprocessing(false) to have them refer to the inputs directly.
+type TraceEvent struct {
+	Kind TraceKind
+
+	AStart, AEnd int
+	BStart, BEnd int
+
+	Prefix, Suffix int  // TraceTrim
+	X, Y           int  // TraceMiddleSnake, TraceAnchor
+	D              int  // TraceMiddleSnake
+	Heuristic      bool // TraceMiddleSnake
+	Len, Frequency int  // TraceAnchor
+	Shift          int  // TraceShift
+}
+
+// traceTrim reports the trimming of region [aStart, aEnd) x [bStart, bEnd)
+// by prefix and suffix elements. Nothing is reported when nothing was
+// trimmed.
+func traceTrim(trace func(TraceEvent), aStart, aEnd, bStart, bEnd, prefix, suffix int) {
+	if trace == nil || prefix+suffix == 0 {
+		return
+	}
+	trace(TraceEvent{
+		Kind:   TraceTrim,
+		AStart: aStart, AEnd: aEnd,
+		BStart: bStart, BEnd: bEnd,
+		Prefix: prefix,
+		Suffix: suffix,
+	})
+}
+
+// traceShift reports the slide of the change region ops[i:j] by shift
+// positions, after slideRun has moved it.
+func traceShift(trace func(TraceEvent), ops []DiffOp, i, j, shift int) {
+	if trace == nil || shift == 0 {
+		return
+	}
+	trace(TraceEvent{
+		Kind:   TraceShift,
+		AStart: ops[i].AStart, AEnd: ops[j-1].AEnd,
+		BStart: ops[i].BStart, BEnd: ops[j-1].BEnd,
+		Shift: shift,
+	})
+}
+
+// WithTrace calls fn with an event for each trim, middle snake, histogram
+// anchor, and boundary shift decided while diffing. fn is called
+// synchronously and must not retain the diff's inputs beyond the call.
+// Default: nil (no tracing).
+func WithTrace(fn func(event TraceEvent)) Option {
+	return func(o *options) {
+		o.trace = fn
+	}
+}
@@ -0,0 +1,174 @@
+package diffx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// collectTrace returns an option recording trace events into events.
+func collectTrace(events *[]TraceEvent) Option {
+	return WithTrace(func(e TraceEvent) {
+		*events = append(*events, e)
+	})
+}
+
+// eventsOf returns the events of the given kind.
+func eventsOf(events []TraceEvent, kind TraceKind) []TraceEvent {
+	var out []TraceEvent
+	for _, e := range events {
+		if e.Kind == kind {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestTraceKind_String(t *testing.T) {
+	tests := []struct {
+		k    TraceKind
+		want string
+	}{
+		{TraceTrim, "Trim"},
+		{TraceMiddleSnake, "MiddleSnake"},
+		{TraceAnchor, "Anchor"},
+		{TraceShift, "Shift"},
+		{TraceKind(99), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.k.String(); got != tt.want {
+			t.Errorf("TraceKind(%d).String() = %q, want %q", tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestWithTrace_Myers(t *testing.T) {
+	a := strings.Fields("p a b c d q")
+	b := strings.Fields("p a x c y q")
+
+	var events []TraceEvent
+	Diff(a, b, WithPreprocessing(false), collectTrace(&events))
+
+	trims := eventsOf(events, TraceTrim)
+	if len(trims) == 0 {
+		t.Fatal("no trim events")
+	}
+	want := TraceEvent{Kind: TraceTrim, AStart: 0, AEnd: 6, BStart: 0, BEnd: 6, Prefix: 2, Suffix: 1}
+	if trims[0] != want {
+		t.Errorf("first trim = %+v, want %+v", trims[0], want)
+	}
+
+	snakes := eventsOf(events, TraceMiddleSnake)
+	if len(snakes) == 0 {
+		t.Fatal("no middle snake events")
+	}
+	s := snakes[0]
+	if s.AStart != 2 || s.AEnd != 5 || s.BStart != 2 || s.BEnd != 5 {
+		t.Errorf("first snake region = [%d,%d) x [%d,%d), want [2,5) x [2,5)", s.AStart, s.AEnd, s.BStart, s.BEnd)
+	}
+	if s.X < s.AStart || s.X > s.AEnd || s.Y < s.BStart || s.Y > s.BEnd || s.D == 0 || s.Heuristic {
+		t.Errorf("first snake = %+v, want an optimal split inside the region", s)
+	}
+}
+
+func TestWithTrace_Histogram(t *testing.T) {
+	a := strings.Fields("a b anchor c d")
+	b := strings.Fields("x y anchor z w")
+
+	var events []TraceEvent
+	DiffHistogram(a, b, collectTrace(&events))
+
+	anchors := eventsOf(events, TraceAnchor)
+	if len(anchors) != 1 {
+		t.Fatalf("got %d anchor events, want 1: %+v", len(anchors), events)
+	}
+	want := TraceEvent{Kind: TraceAnchor, AStart: 0, AEnd: 5, BStart: 0, BEnd: 5, X: 2, Y: 2, Len: 1, Frequency: 1}
+	if anchors[0] != want {
+		t.Errorf("anchor = %+v, want %+v", anchors[0], want)
+	}
+}
+
+func TestWithTrace_HistogramMyersFallback(t *testing.T) {
+	// The section after the anchor has only stopwords in common, so it is
+	// diffed with Myers; its events must use indices of the whole input
+	a := strings.Fields("anchor x the y")
+	b := strings.Fields("anchor z the w")
+
+	for _, preprocessing := range []bool{false, true} {
+		var events []TraceEvent
+		DiffHistogram(a, b, collectTrace(&events), WithPreprocessing(preprocessing))
+
+		// Preprocessing hides x, y, z, and w, leaving nothing to split
+		snakes := eventsOf(events, TraceMiddleSnake)
+		if !preprocessing && len(snakes) == 0 {
+			t.Fatalf("no middle snake events: %+v", events)
+		}
+		for _, s := range snakes {
+			if s.AStart < 1 || s.AEnd > 4 || s.BStart < 1 || s.BEnd > 4 || s.X < s.AStart || s.Y < s.BStart {
+				t.Errorf("snake %+v lies outside the fallback section [1,4) x [1,4)", s)
+			}
+		}
+		for _, e := range eventsOf(events, TraceTrim) {
+			if e.AStart < 0 || e.AEnd > 4 || e.BStart < 0 || e.BEnd > 4 {
+				t.Errorf("preprocessing %v: trim %+v lies outside the input", preprocessing, e)
+			}
+		}
+	}
+}
+
+func TestWithTrace_Shift(t *testing.T) {
+	tests := []struct {
+		name   string
+		indent bool
+		a, b   []string
+		ops    []DiffOp
+		want   TraceEvent
+	}{
+		{
+			// The deleted block slides up to start after the line ending in
+			// punctuation
+			name: "punctuation",
+			a:    []string{"p.", "q", "r", "q", "s"},
+			b:    []string{"p.", "q", "s"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 2, BStart: 0, BEnd: 2},
+				{Type: Delete, AStart: 2, AEnd: 4, BStart: 2, BEnd: 2},
+				{Type: Equal, AStart: 4, AEnd: 5, BStart: 2, BEnd: 3},
+			},
+			want: TraceEvent{Kind: TraceShift, AStart: 1, AEnd: 3, BStart: 1, BEnd: 1, Shift: -1},
+		},
+		{
+			// The deleted block slides down to start after the blank line
+			name:   "indent heuristic",
+			indent: true,
+			a:      []string{"a", "", "b", "c", "", "d"},
+			b:      []string{"a", "", "d"},
+			ops: []DiffOp{
+				{Type: Equal, AStart: 0, AEnd: 1, BStart: 0, BEnd: 1},
+				{Type: Delete, AStart: 1, AEnd: 4, BStart: 1, BEnd: 1},
+				{Type: Equal, AStart: 4, AEnd: 6, BStart: 1, BEnd: 3},
+			},
+			want: TraceEvent{Kind: TraceShift, AStart: 2, AEnd: 5, BStart: 2, BEnd: 2, Shift: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		var events []TraceEvent
+		o := defaultOptions()
+		o.indentHeuristic = tt.indent
+		o.trace = func(e TraceEvent) { events = append(events, e) }
+		shiftBoundaries(tt.ops, toElements(tt.a), toElements(tt.b), o)
+
+		if len(events) != 1 || events[0] != tt.want {
+			t.Errorf("%s: events = %+v, want [%+v]", tt.name, events, tt.want)
+		}
+	}
+}
+
+func TestWithTrace_Nil(t *testing.T) {
+	a := strings.Fields("a b c d")
+	b := strings.Fields("a x c y")
+	if got, want := Diff(a, b, WithTrace(nil)), Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("WithTrace(nil) changed the result: %v, want %v", got, want)
+	}
+}
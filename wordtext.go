@@ -0,0 +1,155 @@
+package diffx
+
+import "strings"
+
+// Whitespace-faithful word diff display.
+//
+// A word diff is usually computed on strings.Fields or a similar split, which
+// drops the white space between words. Joining the words back with single
+// spaces turns every line break and indentation run of the document into a
+// space, so the displayed diff no longer looks like the text it came from.
+// ReconstructWords finds the words in the original texts and lays the diff
+// out with the white space and line breaks of the new text, placing deleted
+// words where they were in the old text.
+
+// WordRun is a run of display text of a word diff.
+type WordRun struct {
+	Type OpType // Equal for unchanged text and white space, else the change
+	Text string
+}
+
+// ReconstructWords lays out a word-level edit script for display. aWords and
+// bWords are the words that ops was computed on and must appear, in order,
+// in aText and bText; anything between two words is taken as the white
+// space separating them. ok is false if a word cannot be found or ops
+// does not fit the words.
+//
+// Unchanged and inserted words are written with the white space and line
+// breaks of bText. Deleted words are written with the white space of aText
+// between them, directly before the words that replace them. A deletion
+// that is not replaced is separated from the text before it by the white
+// space that preceded it in aText, or from the text after it when that
+// white space contains a line break, so a deletion never adds a line.
+func ReconstructWords(ops []DiffOp, aWords, bWords []string, aText, bText string) (runs []WordRun, ok bool) {
+	startA, okA := locateWords(aWords, aText)
+	startB, okB := locateWords(bWords, bText)
+	if !okA || !okB {
+		return nil, false
+	}
+	gapA := func(i int) string { return wordGap(aWords, startA, aText, i) }
+	gapB := func(j int) string { return wordGap(bWords, startB, bText, j) }
+
+	gapDone := false // the white space before the current word of B was written
+	for k, op := range ops {
+		if op.AEnd > len(aWords) || op.BEnd > len(bWords) {
+			return nil, false
+		}
+		switch op.Type {
+		case Equal, Insert:
+			if op.BStart == op.BEnd {
+				continue
+			}
+			if !gapDone {
+				runs = appendWordRun(runs, Equal, gapB(op.BStart))
+			}
+			text := bText[startB[op.BStart] : startB[op.BEnd-1]+len(bWords[op.BEnd-1])]
+			runs = appendWordRun(runs, op.Type, text)
+			gapDone = false
+		case Delete:
+			if op.AStart == op.AEnd {
+				continue
+			}
+			text := aText[startA[op.AStart] : startA[op.AEnd-1]+len(aWords[op.AEnd-1])]
+			switch {
+			case k+1 < len(ops) && ops[k+1].Type == Insert:
+				// The replacement follows the deleted words directly
+				if !gapDone {
+					runs = appendWordRun(runs, Equal, gapB(op.BStart))
+				}
+				runs = appendWordRun(runs, Delete, text)
+				gapDone = true
+			case !gapDone && op.AStart > 0 && !strings.Contains(gapA(op.AStart), "\n"):
+				runs = appendWordRun(runs, Equal, gapA(op.AStart))
+				runs = appendWordRun(runs, Delete, text)
+			default:
+				if !gapDone {
+					runs = appendWordRun(runs, Equal, gapB(op.BStart))
+				}
+				runs = appendWordRun(runs, Delete, text)
+				if op.AEnd < len(aWords) {
+					runs = appendWordRun(runs, Equal, gapA(op.AEnd))
+				}
+				gapDone = true
+			}
+		}
+	}
+	if !gapDone {
+		runs = appendWordRun(runs, Equal, gapB(len(bWords)))
+	}
+	return runs, true
+}
+
+// FormatWordRuns renders runs as text, wrapping deleted runs in delOpen and
+// delClose and inserted runs in insOpen and insClose, for example "[-",
+// "-]", "{+", and "+}" as git diff --word-diff does.
+func FormatWordRuns(runs []WordRun, delOpen, delClose, insOpen, insClose string) string {
+	var sb strings.Builder
+	for _, r := range runs {
+		switch r.Type {
+		case Delete:
+			sb.WriteString(delOpen)
+			sb.WriteString(r.Text)
+			sb.WriteString(delClose)
+		case Insert:
+			sb.WriteString(insOpen)
+			sb.WriteString(r.Text)
+			sb.WriteString(insClose)
+		default:
+			sb.WriteString(r.Text)
+		}
+	}
+	return sb.String()
+}
+
+// locateWords returns the byte offset of each word in text, searching from
+// the end of the previous word. It reports false if a word is missing.
+func locateWords(words []string, text string) ([]int, bool) {
+	starts := make([]int, len(words))
+	pos := 0
+	for i, w := range words {
+		k := strings.Index(text[pos:], w)
+		if k < 0 {
+			return nil, false
+		}
+		starts[i] = pos + k
+		pos = starts[i] + len(w)
+	}
+	return starts, true
+}
+
+// wordGap returns the text of text between word i-1 and word i; the text
+// before the first word for i == 0, and after the last word for
+// i == len(words).
+func wordGap(words []string, starts []int, text string, i int) string {
+	from, to := 0, len(text)
+	if i > 0 {
+		from = starts[i-1] + len(words[i-1])
+	}
+	if i < len(words) {
+		to = starts[i]
+	}
+	return text[from:to]
+}
+
+// appendWordRun appends text to runs, extending the last run when it has
+// the same type.
+func appendWordRun(runs []WordRun, typ OpType, text string) []WordRun {
+	if text == "" {
+		return runs
+	}
+	if n := len(runs); n > 0 && runs[n-1].Type == typ {
+		runs[n-1].Text += text
+		return runs
+	}
+	return append(runs, WordRun{Type: typ, Text: text})
+}
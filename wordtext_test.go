@@ -0,0 +1,68 @@
+package diffx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconstructWords(t *testing.T) {
+	tests := []struct {
+		name, a, b, want string
+	}{
+		{"identical", "one  two\n\tthree\n", "one  two\n\tthree\n", "one  two\n\tthree\n"},
+		{"replace", "the quick fox", "the slow fox", "the [-quick-]{+slow+} fox"},
+		{"delete", "the quick brown fox", "the fox", "the [-quick brown-] fox"},
+		{"insert", "the fox", "the quick fox", "the {+quick+} fox"},
+		{"keeps B line breaks", "one two three four", "one two\nthree\n  five", "one two\nthree\n  [-four-]{+five+}"},
+		{"delete at line end", "foo bar\nbaz", "foo\nbaz", "foo [-bar-]\nbaz"},
+		{"delete at line start", "foo\nbar baz", "foo\nbaz", "foo\n[-bar-] baz"},
+		{"delete first", "quick fox", "fox", "[-quick-] fox"},
+		{"delete last", "the fox\n", "the\n", "the [-fox-]\n"},
+		{"delete all", "a b", "", "[-a b-]"},
+		{"insert into empty", "", "  a\n", "  {+a+}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aw, bw := strings.Fields(tt.a), strings.Fields(tt.b)
+			runs, ok := ReconstructWords(Diff(aw, bw), aw, bw, tt.a, tt.b)
+			if !ok {
+				t.Fatal("ok = false")
+			}
+			if got := FormatWordRuns(runs, "[-", "-]", "{+", "+}"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconstructWords_Tokens(t *testing.T) {
+	// Tokens that include their white space reproduce both texts exactly
+	a, b := "Hello,  world.\nBye.", "Hello, there  world.\nBye!"
+	at, bt := SplitWords(a), SplitWords(b)
+	runs, ok := ReconstructWords(Diff(at, bt), at, bt, a, b)
+	if !ok {
+		t.Fatal("ok = false")
+	}
+	var gotA, gotB strings.Builder
+	for _, r := range runs {
+		if r.Type != Insert {
+			gotA.WriteString(r.Text)
+		}
+		if r.Type != Delete {
+			gotB.WriteString(r.Text)
+		}
+	}
+	if gotA.String() != a || gotB.String() != b {
+		t.Errorf("runs %v reproduce %q and %q", runs, gotA.String(), gotB.String())
+	}
+}
+
+func TestReconstructWords_Invalid(t *testing.T) {
+	words := []string{"a", "b"}
+	if _, ok := ReconstructWords(nil, []string{"b", "a"}, words, "a b", "a b"); ok {
+		t.Error("words out of order: ok = true")
+	}
+	if _, ok := ReconstructWords([]DiffOp{{Type: Equal, AEnd: 3, BEnd: 3}}, words, words, "a b", "a b"); ok {
+		t.Error("ops out of range: ok = true")
+	}
+}
@@ -0,0 +1,329 @@
+// Package xmldiff compares XML documents structurally.
+//
+// Generated XML artifacts often differ textually in ways that do not matter,
+// such as attribute order, indentation, or the position of an element that
+// is identified by an attribute. xmldiff parses both documents into trees,
+// aligns child elements by tag and optional identity attributes with diffx,
+// and reports added, removed, and moved elements and changed attributes and
+// text.
+package xmldiff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+)
+
+// ChangeType identifies how a part of the document changed. It is an
+// alias of diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the element or attribute is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the element or attribute is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the attribute value or text differs.
+	Modified = diffx.ChangeModified
+	// Moved means an element with identity attributes changed position
+	// among its siblings. Changes inside it are reported separately.
+	Moved = diffx.ChangeMoved
+)
+
+// Change describes a difference between two XML documents.
+type Change struct {
+	Type ChangeType
+	// Path locates the change with an XPath-like expression, such as
+	// /config/server[@id='a']/@port or /config/item[2]/text().
+	Path string
+	A    string // attribute value or text in A; empty for elements
+	B    string // attribute value or text in B; empty for elements
+}
+
+// Node is a parsed XML element. Namespaces are not considered; elements
+// and attributes are identified by local name.
+type Node struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string // character data directly inside the element, trimmed
+	Children []*Node
+}
+
+// Option configures the comparison.
+type Option func(*options)
+
+type options struct {
+	identityAttrs []string
+	diffOpts      []diffx.Option
+}
+
+// WithIdentityAttrs sets attributes that identify an element among its
+// siblings, such as "id" or "name". Sibling elements match only if they
+// have the same tag and the same value for the first identity attribute
+// present on them. Default: none; siblings match by tag only.
+func WithIdentityAttrs(attrs ...string) Option {
+	return func(o *options) {
+		o.identityAttrs = attrs
+	}
+}
+
+// WithDiffOptions sets options passed to diffx.DiffElements when aligning
+// child elements.
+func WithDiffOptions(opts ...diffx.Option) Option {
+	return func(o *options) {
+		o.diffOpts = opts
+	}
+}
+
+// Diff parses two XML documents and returns their differences in document
+// order.
+func Diff(a, b []byte, opts ...Option) ([]Change, error) {
+	na, err := Parse(a)
+	if err != nil {
+		return nil, fmt.Errorf("xmldiff: document A: %w", err)
+	}
+	nb, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("xmldiff: document B: %w", err)
+	}
+	return DiffNodes(na, nb, opts...), nil
+}
+
+// DiffNodes is like Diff but compares parsed trees.
+func DiffNodes(a, b *Node, opts ...Option) []Change {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if a.Name != b.Name {
+		return []Change{
+			{Type: Removed, Path: "/" + a.Name},
+			{Type: Added, Path: "/" + b.Name},
+		}
+	}
+	return diffNode(nil, "/"+step(a, 1, o), a, b, o)
+}
+
+// Parse parses an XML document into a tree rooted at its document element.
+// Comments, processing instructions, and directives are ignored.
+func Parse(data []byte) (*Node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *Node
+	var stack []*Node
+	var text []*strings.Builder
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &Node{Name: t.Name.Local, Attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				n.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+			text = append(text, &strings.Builder{})
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			n.Text = strings.TrimSpace(text[len(text)-1].String())
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// diffNode compares two matched elements at path.
+func diffNode(changes []Change, path string, a, b *Node, o *options) []Change {
+	changes = diffAttrs(changes, path, a.Attrs, b.Attrs)
+	if a.Text != b.Text {
+		changes = append(changes, Change{Type: Modified, Path: path + "/text()", A: a.Text, B: b.Text})
+	}
+
+	ea, eb := toKeys(a.Children, o), toKeys(b.Children, o)
+	posA, posB := positions(a.Children), positions(b.Children)
+	ops := diffx.DiffElements(ea, eb, o.diffOpts...)
+
+	// Elements with identity that were deleted in one place and inserted
+	// in another have moved
+	deleted := make(map[diffx.Element]int)
+	for _, op := range ops {
+		if op.Type == diffx.Delete {
+			for i := op.AStart; i < op.AEnd; i++ {
+				if _, _, ok := identity(a.Children[i], o); ok {
+					if _, dup := deleted[ea[i]]; !dup {
+						deleted[ea[i]] = i
+					}
+				}
+			}
+		}
+	}
+	moved := make(map[int]int) // index in A -> index in B
+	for _, op := range ops {
+		if op.Type == diffx.Insert {
+			for j := op.BStart; j < op.BEnd; j++ {
+				if i, ok := deleted[eb[j]]; ok {
+					moved[i] = j
+					delete(deleted, eb[j])
+				}
+			}
+		}
+	}
+	movedTo := make(map[int]int, len(moved))
+	for i, j := range moved {
+		movedTo[j] = i
+	}
+
+	for _, op := range ops {
+		switch op.Type {
+		case diffx.Equal:
+			for k := 0; k < op.AEnd-op.AStart; k++ {
+				ca, cb := a.Children[op.AStart+k], b.Children[op.BStart+k]
+				changes = diffNode(changes, path+"/"+step(cb, posB[op.BStart+k], o), ca, cb, o)
+			}
+		case diffx.Delete:
+			for i := op.AStart; i < op.AEnd; i++ {
+				if _, ok := moved[i]; !ok {
+					changes = append(changes, Change{Type: Removed, Path: path + "/" + step(a.Children[i], posA[i], o)})
+				}
+			}
+		case diffx.Insert:
+			for j := op.BStart; j < op.BEnd; j++ {
+				p := path + "/" + step(b.Children[j], posB[j], o)
+				if i, ok := movedTo[j]; ok {
+					changes = append(changes, Change{Type: Moved, Path: p})
+					changes = diffNode(changes, p, a.Children[i], b.Children[j], o)
+					continue
+				}
+				changes = append(changes, Change{Type: Added, Path: p})
+			}
+		}
+	}
+	return changes
+}
+
+// diffAttrs compares attributes in name order.
+func diffAttrs(changes []Change, path string, a, b map[string]string) []Change {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		va, inA := a[name]
+		vb, inB := b[name]
+		p := path + "/@" + name
+		switch {
+		case !inB:
+			changes = append(changes, Change{Type: Removed, Path: p, A: va})
+		case !inA:
+			changes = append(changes, Change{Type: Added, Path: p, B: vb})
+		case va != vb:
+			changes = append(changes, Change{Type: Modified, Path: p, A: va, B: vb})
+		}
+	}
+	return changes
+}
+
+// identity returns the name and value of the first identity attribute
+// present on n.
+func identity(n *Node, o *options) (name, value string, ok bool) {
+	for _, attr := range o.identityAttrs {
+		if v, found := n.Attrs[attr]; found {
+			return attr, v, true
+		}
+	}
+	return "", "", false
+}
+
+// step returns the path step for n: its tag with an identity predicate if
+// it has one, or with its 1-based position among same-tag siblings.
+func step(n *Node, pos int, o *options) string {
+	if name, value, ok := identity(n, o); ok {
+		return fmt.Sprintf("%s[@%s=%s]", n.Name, name, quoteXPath(value))
+	}
+	return n.Name + "[" + strconv.Itoa(pos) + "]"
+}
+
+// positions returns the 1-based position of each node among siblings with
+// the same tag.
+func positions(nodes []*Node) []int {
+	seen := make(map[string]int)
+	pos := make([]int, len(nodes))
+	for i, n := range nodes {
+		seen[n.Name]++
+		pos[i] = seen[n.Name]
+	}
+	return pos
+}
+
+// quoteXPath quotes s as an XPath string literal.
+func quoteXPath(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+// key is a diffx.Element identifying a child by tag and identity value.
+type key string
+
+// Equal reports whether other identifies the same child.
+func (k key) Equal(other diffx.Element) bool {
+	o, ok := other.(key)
+	return ok && k == o
+}
+
+// Hash returns a FNV-1a hash of the key.
+func (k key) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(k))
+	return h.Sum64()
+}
+
+// toKeys converts child elements to alignment keys.
+func toKeys(nodes []*Node, o *options) []diffx.Element {
+	elems := make([]diffx.Element, len(nodes))
+	for i, n := range nodes {
+		k := n.Name
+		if name, value, ok := identity(n, o); ok {
+			k += "\x00" + name + "\x00" + value
+		}
+		elems[i] = key(k)
+	}
+	return elems
+}
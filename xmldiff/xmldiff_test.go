@@ -0,0 +1,74 @@
+package xmldiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := `<config>
+  <server id="a" port="80">alpha</server>
+  <server id="b" port="80"/>
+  <debug/>
+</config>`
+	b := `<config>
+  <server port="80" id="b" tls="on"/>
+  <server id="a" port="8080">ALPHA</server>
+</config>`
+
+	got, err := Diff([]byte(a), []byte(b), WithIdentityAttrs("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Type: Added, Path: "/config[1]/server[@id='b']/@tls", B: "on"},
+		{Type: Removed, Path: "/config[1]/debug[1]"},
+		{Type: Moved, Path: "/config[1]/server[@id='a']"},
+		{Type: Modified, Path: "/config[1]/server[@id='a']/@port", A: "80", B: "8080"},
+		{Type: Modified, Path: "/config[1]/server[@id='a']/text()", A: "alpha", B: "ALPHA"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiff_ByTag(t *testing.T) {
+	a := `<list><item>one</item><item>two</item></list>`
+	b := `<list><item>one</item><item>2</item><note/></list>`
+
+	got, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Type: Modified, Path: "/list[1]/item[2]/text()", A: "two", B: "2"},
+		{Type: Added, Path: "/list[1]/note[1]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiff_IgnoresFormatting(t *testing.T) {
+	a := `<?xml version="1.0"?><!-- generated --><r b="2" a="1"><c>x</c></r>`
+	b := "<r a=\"1\" b=\"2\">\n  <c>\n    x\n  </c>\n</r>\n"
+
+	got, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no changes, got %+v", got)
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	if _, err := Diff([]byte(`<a>`), []byte(`<a/>`)); err == nil {
+		t.Error("expected error for unclosed element")
+	}
+	if _, err := Diff([]byte(``), []byte(`<a/>`)); err == nil {
+		t.Error("expected error for empty document")
+	}
+}
@@ -0,0 +1,471 @@
+// Package yamldiff compares YAML documents semantically.
+//
+// Kubernetes manifests and other YAML configuration are compared to find
+// drift between what is declared and what is running. A textual diff is
+// dominated by reordered keys and reformatting, and the live object carries
+// fields the cluster manages itself, such as status and
+// metadata.resourceVersion. yamldiff decodes both sides, drops the fields
+// matched by ignore paths, and compares mappings key by key, so only
+// meaningful drift is reported, addressed by dotted key path. Sequences are
+// aligned with diffx, so an inserted item is reported once rather than as a
+// change to every item after it.
+package yamldiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dacharyc/diffx"
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeType identifies how a value changed. It is an alias of
+// diffx.ChangeType.
+type ChangeType = diffx.ChangeType
+
+const (
+	// Added means the key or sequence item is only present in B.
+	Added = diffx.ChangeAdded
+	// Removed means the key or sequence item is only present in A.
+	Removed = diffx.ChangeRemoved
+	// Modified means the value differs between A and B.
+	Modified = diffx.ChangeModified
+)
+
+// Change describes a value that differs between two documents.
+type Change struct {
+	Type ChangeType
+	Path string // dotted key path, such as spec.replicas or spec.containers[0].image
+	A    any    // value in A, or nil if added
+	B    any    // value in B, or nil if removed
+}
+
+// String returns a one-line description such as "~ spec.replicas: 2 -> 3".
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.B)
+	case Removed:
+		return fmt.Sprintf("- %s: %v", c.Path, c.A)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.A, c.B)
+	}
+}
+
+// Option configures the comparison.
+type Option func(*options)
+
+type options struct {
+	ignorePaths []string
+	diffOpts    []diffx.Option
+}
+
+// WithIgnorePaths excludes the values at the given paths from the
+// comparison. A path is written like Change.Path, with keys separated by
+// dots, sequence items as [index], and keys that are not plain words
+// quoted, as in metadata.annotations."example.com/owner". A "*" segment
+// matches any key or item and "[*]" any item. A path also excludes
+// everything below the value it matches, so "status" and "status.*" both
+// ignore the whole status; a mapping or sequence left empty only because
+// its contents were ignored is ignored too. For multi-document input, the
+// paths apply within each document. Default: none.
+func WithIgnorePaths(paths ...string) Option {
+	return func(o *options) {
+		o.ignorePaths = append(o.ignorePaths, paths...)
+	}
+}
+
+// WithDiffOptions sets options passed to diffx.DiffElements when aligning
+// sequences.
+func WithDiffOptions(opts ...diffx.Option) Option {
+	return func(o *options) {
+		o.diffOpts = opts
+	}
+}
+
+// Diff decodes two YAML streams and returns their differences, sorted by
+// key within each mapping. A stream of several documents, separated by
+// "---", is compared as a sequence of documents, so paths start with the
+// document index, as in [1].spec.replicas.
+func Diff(a, b []byte, opts ...Option) ([]Change, error) {
+	docsA, err := decode(a)
+	if err != nil {
+		return nil, fmt.Errorf("yamldiff: document A: %w", err)
+	}
+	docsB, err := decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("yamldiff: document B: %w", err)
+	}
+	return diffDocuments(docsA, docsB, opts)
+}
+
+// DiffValues is like Diff but compares single documents that have already
+// been decoded, such as with yaml.Unmarshal into an any.
+func DiffValues(a, b any, opts ...Option) ([]Change, error) {
+	return diffDocuments([]any{stringKeys(a)}, []any{stringKeys(b)}, opts)
+}
+
+// diffDocuments prunes ignored values from the documents and compares
+// them.
+func diffDocuments(docsA, docsB []any, opts []Option) ([]Change, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	patterns := make([][]string, len(o.ignorePaths))
+	for i, p := range o.ignorePaths {
+		var err error
+		if patterns[i], err = parsePath(p); err != nil {
+			return nil, fmt.Errorf("yamldiff: ignore path %q: %w", p, err)
+		}
+	}
+	for i := range docsA {
+		docsA[i], _ = prune(docsA[i], nil, patterns)
+	}
+	for i := range docsB {
+		docsB[i], _ = prune(docsB[i], nil, patterns)
+	}
+
+	if len(docsA) == 1 && len(docsB) == 1 {
+		return diffValue(nil, "", docsA[0], docsB[0], o.diffOpts), nil
+	}
+	return diffSequence(nil, "", docsA, docsB, o.diffOpts), nil
+}
+
+// decode returns the non-empty documents of a YAML stream, with mapping
+// keys converted to strings.
+func decode(data []byte) ([]any, error) {
+	var docs []any
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, stringKeys(doc))
+		}
+	}
+	if len(docs) == 0 {
+		docs = append(docs, nil)
+	}
+	return docs, nil
+}
+
+// stringKeys returns a copy of v with the keys of mappings converted to
+// strings; yaml.v3 decodes mappings with non-string keys as map[any]any.
+func stringKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = stringKeys(e)
+		}
+		return m
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = stringKeys(e)
+		}
+		return s
+	}
+	return v
+}
+
+// diffValue appends the changes between a and b at path.
+func diffValue(changes []Change, path string, a, b any, opts []diffx.Option) []Change {
+	if ma, ok := a.(map[string]any); ok {
+		if mb, ok := b.(map[string]any); ok {
+			return diffMapping(changes, path, ma, mb, opts)
+		}
+	}
+	if sa, ok := a.([]any); ok {
+		if sb, ok := b.([]any); ok {
+			return diffSequence(changes, path, sa, sb, opts)
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return changes
+	}
+	return append(changes, Change{Type: Modified, Path: path, A: a, B: b})
+}
+
+// diffMapping compares mapping keys in sorted order.
+func diffMapping(changes []Change, path string, a, b map[string]any, opts []diffx.Option) []Change {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := joinKey(path, k)
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			changes = append(changes, Change{Type: Removed, Path: p, A: va})
+		case !inA:
+			changes = append(changes, Change{Type: Added, Path: p, B: vb})
+		default:
+			changes = diffValue(changes, p, va, vb, opts)
+		}
+	}
+	return changes
+}
+
+// diffSequence aligns sequence items with diffx. Within each change
+// region, items are compared pairwise; the surplus is reported as removed
+// (by index in A) or added (by index in B).
+func diffSequence(changes []Change, path string, a, b []any, opts []diffx.Option) []Change {
+	ops := diffx.DiffElements(toValues(a), toValues(b), opts...)
+
+	for k := 0; k < len(ops); {
+		if ops[k].Type == diffx.Equal {
+			k++
+			continue
+		}
+
+		aStart, bStart := ops[k].AStart, ops[k].BStart
+		aEnd, bEnd := ops[k].AEnd, ops[k].BEnd
+		for k < len(ops) && ops[k].Type != diffx.Equal {
+			aEnd = max(aEnd, ops[k].AEnd)
+			bEnd = max(bEnd, ops[k].BEnd)
+			k++
+		}
+
+		common := min(aEnd-aStart, bEnd-bStart)
+		for n := 0; n < common; n++ {
+			changes = diffValue(changes, indexKey(path, bStart+n), a[aStart+n], b[bStart+n], opts)
+		}
+		for i := aStart + common; i < aEnd; i++ {
+			changes = append(changes, Change{Type: Removed, Path: indexKey(path, i), A: a[i]})
+		}
+		for j := bStart + common; j < bEnd; j++ {
+			changes = append(changes, Change{Type: Added, Path: indexKey(path, j), B: b[j]})
+		}
+	}
+	return changes
+}
+
+// prune removes the values matched by patterns from v, found at the path
+// with segments path. It returns nil and false if v itself is ignored,
+// including a mapping or sequence that only held ignored values. Ignored
+// sequence items are replaced by nil so that the indexes of the other
+// items are kept.
+func prune(v any, path []string, patterns [][]string) (any, bool) {
+	if len(patterns) == 0 {
+		return v, true
+	}
+	for _, p := range patterns {
+		if matchPath(p, path) {
+			return nil, false
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return v, true
+		}
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			if e, keep := prune(e, append(path[:len(path):len(path)], k), patterns); keep {
+				m[k] = e
+			}
+		}
+		return m, len(m) > 0
+	case []any:
+		if len(v) == 0 {
+			return v, true
+		}
+		s := make([]any, len(v))
+		kept := false
+		for i, e := range v {
+			var keep bool
+			s[i], keep = prune(e, append(path[:len(path):len(path)], "["+strconv.Itoa(i)+"]"), patterns)
+			kept = kept || keep
+		}
+		return s, kept
+	}
+	return v, true
+}
+
+// matchPath reports whether pattern matches path or one of its ancestors.
+func matchPath(pattern, path []string) bool {
+	if len(pattern) > len(path) {
+		return false
+	}
+	for i, p := range pattern {
+		s := path[i]
+		switch {
+		case p == "*":
+		case p == "[*]":
+			if !strings.HasPrefix(s, "[") {
+				return false
+			}
+		case p != s:
+			return false
+		}
+	}
+	return true
+}
+
+// parsePath splits a path into segments: keys, unquoted, and sequence
+// indexes, kept as "[n]" or "[*]".
+func parsePath(s string) ([]string, error) {
+	var segs []string
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [")
+			}
+			idx := s[i+1 : i+end]
+			if _, err := strconv.Atoi(idx); err != nil && idx != "*" {
+				return nil, fmt.Errorf("invalid index %q", idx)
+			}
+			segs = append(segs, s[i:i+end+1])
+			i += end + 1
+		case s[i] == '"':
+			q, err := strconv.QuotedPrefix(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			key, _ := strconv.Unquote(q)
+			segs = append(segs, key)
+			i += len(q)
+		default:
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			if end == 0 {
+				return nil, errors.New("empty key")
+			}
+			segs = append(segs, s[i:i+end])
+			i += end
+		}
+		if i < len(s) && s[i] == '.' {
+			i++
+			if i == len(s) {
+				return nil, errors.New("empty key")
+			}
+		}
+	}
+	if len(segs) == 0 {
+		return nil, errors.New("empty path")
+	}
+	return segs, nil
+}
+
+// value is a diffx.Element comparing decoded values by their canonical
+// encoding; see canonical.
+type value string
+
+// Equal reports whether other encodes the same value.
+func (v value) Equal(other diffx.Element) bool {
+	o, ok := other.(value)
+	return ok && v == o
+}
+
+// Hash returns a FNV-1a hash of the encoded value.
+func (v value) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(v))
+	return h.Sum64()
+}
+
+// toValues converts sequence items to elements.
+func toValues(items []any) []diffx.Element {
+	elems := make([]diffx.Element, len(items))
+	for i, it := range items {
+		var b strings.Builder
+		canonical(&b, it)
+		elems[i] = value(b.String())
+	}
+	return elems
+}
+
+// canonical writes an encoding of the decoded value v to b that differs
+// for any two values that differ: strings and keys are quoted, mappings
+// are written with sorted keys, and scalars with their type, so that the
+// string "1" and the integer 1, or [["a b"]] and [["a", "b"]], are told
+// apart.
+func canonical(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(k))
+			b.WriteByte(':')
+			canonical(b, v[k])
+		}
+		b.WriteByte('}')
+	case []any:
+		b.WriteByte('[')
+		for i, it := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			canonical(b, it)
+		}
+		b.WriteByte(']')
+	default:
+		fmt.Fprintf(b, "%T(%v)", v, v)
+	}
+}
+
+// bareKey matches keys that need no quoting in a dotted path.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// joinKey appends key to a dotted path, quoting it if necessary.
+func joinKey(path, key string) string {
+	if !bareKey.MatchString(key) {
+		key = strconv.Quote(key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// indexKey appends a sequence index to path.
+func indexKey(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
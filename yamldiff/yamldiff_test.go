@@ -0,0 +1,174 @@
+package yamldiff
+
+import (
+	"strings"
+	"testing"
+)
+
+// declared is a Deployment as written in a repository.
+const declared = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: web
+          image: web:1.4
+          ports:
+            - containerPort: 8080
+        - name: proxy
+          image: envoy:1.29
+`
+
+// live is the same Deployment as read back from a cluster: keys reordered,
+// server-managed fields added, and two real changes.
+const live = `
+kind: Deployment
+apiVersion: apps/v1
+metadata:
+  labels: {app: web}
+  name: web
+  resourceVersion: "48213"
+  uid: 6f1c2a4e-0b7e-4d0f-9b1a-1b2c3d4e5f60
+  generation: 7
+  annotations:
+    deployment.kubernetes.io/revision: "7"
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: web
+          image: web:1.5
+          ports:
+            - containerPort: 8080
+              protocol: TCP
+        - name: proxy
+          image: envoy:1.29
+status:
+  replicas: 3
+  readyReplicas: 3
+`
+
+func changeStrings(changes []Change) string {
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff([]byte(declared), []byte(live))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`+ metadata.annotations: map[deployment.kubernetes.io/revision:7]`,
+		`+ metadata.generation: 7`,
+		`+ metadata.resourceVersion: 48213`,
+		`+ metadata.uid: 6f1c2a4e-0b7e-4d0f-9b1a-1b2c3d4e5f60`,
+		`~ spec.replicas: 2 -> 3`,
+		`~ spec.template.spec.containers[0].image: web:1.4 -> web:1.5`,
+		`+ spec.template.spec.containers[0].ports[0].protocol: TCP`,
+		`+ status: map[readyReplicas:3 replicas:3]`,
+	}
+	if got := changeStrings(changes); got != strings.Join(want, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestDiff_IgnorePaths(t *testing.T) {
+	changes, err := Diff([]byte(declared), []byte(live), WithIgnorePaths(
+		"status.*",
+		"metadata.resourceVersion",
+		"metadata.uid",
+		"metadata.generation",
+		`metadata.annotations."deployment.kubernetes.io/revision"`,
+		"spec.template.spec.containers[*].ports[*].protocol",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`~ spec.replicas: 2 -> 3`,
+		`~ spec.template.spec.containers[0].image: web:1.4 -> web:1.5`,
+	}
+	if got := changeStrings(changes); got != strings.Join(want, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestDiff_IgnoreWildcard(t *testing.T) {
+	a := "items:\n  - {name: a, at: 1}\n  - {name: b, at: 2}\n"
+	b := "items:\n  - {name: a, at: 5}\n  - {name: c, at: 6}\n"
+	changes, err := Diff([]byte(a), []byte(b), WithIgnorePaths("*[*].at"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := changeStrings(changes), "~ items[1].name: b -> c"; got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiff_MultiDocument(t *testing.T) {
+	a := "kind: Service\nname: web\n---\nkind: Deployment\nname: web\nreplicas: 2\n"
+	b := "kind: Service\nname: web\n---\nkind: Deployment\nname: web\nreplicas: 3\n---\nkind: ConfigMap\nname: web\n"
+	changes, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "~ [1].replicas: 2 -> 3\n+ [2]: map[kind:ConfigMap name:web]"
+	if got := changeStrings(changes); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffValues(t *testing.T) {
+	a := map[string]any{"a": 1, "b": map[any]any{1: "x"}}
+	b := map[string]any{"a": 1, "b": map[any]any{1: "y"}}
+	changes, err := DiffValues(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := changeStrings(changes), "~ b.1: x -> y"; got != want {
+		t.Errorf("DiffValues() = %s, want %s", got, want)
+	}
+}
+
+func TestDiff_SequenceItems(t *testing.T) {
+	// Items that print the same with %v but differ
+	tests := []struct {
+		a, b string
+	}{
+		{`x: [["a b"]]`, `x: [["a", "b"]]`},
+		{`x: ["1"]`, `x: [1]`},
+		{`x: [{k: "a b"}]`, `x: [{k: "a", b: ""}]`},
+	}
+	for _, tt := range tests {
+		changes, err := Diff([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) == 0 {
+			t.Errorf("Diff(%q, %q) reported no changes", tt.a, tt.b)
+		}
+	}
+}
+
+func TestDiff_Invalid(t *testing.T) {
+	if _, err := Diff([]byte("a: [1"), []byte("a: 1")); err == nil || !strings.HasPrefix(err.Error(), "yamldiff: document A:") {
+		t.Errorf("invalid A: err = %v", err)
+	}
+	for _, p := range []string{"", "a.", "a[x]", "a[1", `"a`, "a..b"} {
+		if _, err := Diff([]byte("a: 1"), []byte("a: 1"), WithIgnorePaths(p)); err == nil {
+			t.Errorf("ignore path %q: err = nil", p)
+		}
+	}
+}